@@ -10,55 +10,38 @@
 package main
 
 import (
+	"context"
+	"log"
+	"money-tracker/internal/app"
 	"money-tracker/internal/config"
-	"money-tracker/internal/routes"
-
 	"os"
-	"time"
+	"os/signal"
+	"syscall"
 
-	"github.com/gin-contrib/cors"
-	"github.com/gin-gonic/gin"
-	swaggerFiles "github.com/swaggo/files"     // swagger embed files
-	ginSwagger "github.com/swaggo/gin-swagger" // gin-swagger middleware
-	_ "money-tracker/cmd/api/docs"
+	"money-tracker/cmd/api/docs"
 )
 
-func init() {
-	config.LoadEnvVariables()
-	config.ConnectToDB()
-
-}
-
 func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("configuration:\n%s", cfg)
 
-	mode := os.Getenv("GIN_MODE")
-	gin.SetMode(mode)
-
-	router := gin.Default()
-
-	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
-	}))
-
-	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	if cfg.Server.SwaggerHost != "" {
+		docs.SwaggerInfo.Host = cfg.Server.SwaggerHost
+	}
 
-	router.Static("/public/images", "./uploads/images")
+	application, err := app.New(cfg)
+	if err != nil {
+		log.Fatalf("Failed to start: %v", err)
+	}
 
-	//---------Routes--------
-	routes.AdminRoutes("/api/v0/admin", router)
-	routes.AuthRoutes("/api/v0", router)
-	routes.MainRoutes("/api/v0/system", router)
-	//---------------------
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	port := os.Getenv("PORT")
-	if port != "" {
-		port = "4011"
+	if err := application.Run(ctx); err != nil {
+		log.Fatalf("Server stopped: %v", err)
 	}
-	router.Run(":" + port)
-
+	log.Println("Server stopped")
 }
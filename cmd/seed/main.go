@@ -0,0 +1,88 @@
+// seed fills the database with demo data for development and QA: an admin,
+// default categories and tags and, with -months, random purchases. The same
+// -seed and -until give the same purchases, and running it again only adds
+// what is missing. Run the migrations first.
+//
+//	go run ./cmd/seed -months 12 -seed 42
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"log"
+	"money-tracker/internal/config"
+	"money-tracker/internal/password"
+	"money-tracker/internal/repository"
+	"money-tracker/internal/seed"
+	"time"
+)
+
+func main() {
+	seedFlag := flag.Int64("seed", 1, "seed of the random purchases")
+	months := flag.Int("months", 0, "months of purchases to generate, up to -until")
+	until := flag.String("until", "", "date of the last purchases, YYYY-MM-DD (default today)")
+	admin := flag.String("admin", "admin", "user name of the admin to create; empty for none")
+	adminPassword := flag.String("password", "", "password of a new admin (default a random one, printed once)")
+	flag.Parse()
+
+	opts := seed.Options{
+		Seed:          *seedFlag,
+		Months:        *months,
+		AdminName:     *admin,
+		AdminPassword: *adminPassword,
+	}
+	if *until != "" {
+		t, err := time.Parse(time.DateOnly, *until)
+		if err != nil {
+			log.Fatalf("invalid -until: %v", err)
+		}
+		opts.Until = t
+	}
+
+	dbConfig, err := config.LoadDB()
+	if err != nil {
+		log.Fatal(err)
+	}
+	passwordConfig, err := config.LoadPassword()
+	if err != nil {
+		log.Fatal(err)
+	}
+	policy, err := password.NewPolicy(passwordConfig.MinLength, passwordConfig.BreachedList)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if opts.AdminPassword == "" {
+		opts.AdminPassword = randomPassword(passwordConfig.MinLength)
+	}
+
+	db, err := config.OpenDB(dbConfig)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	seeder := seed.New(repository.NewGormTransactor(db), policy)
+	res, err := seeder.Run(opts)
+	if err != nil {
+		log.Fatalf("Seeding failed: %v", err)
+	}
+
+	switch {
+	case res.AdminCreated:
+		fmt.Printf("Admin %q created with password %q\n", res.Admin.UserName, opts.AdminPassword)
+	case res.Admin != nil:
+		fmt.Printf("Admin %q already exists, left as is\n", res.Admin.UserName)
+	}
+	fmt.Printf("✅ %d categories and %d tags in place, %d purchases added (%d were already seeded)\n",
+		len(res.Categories), len(res.Tags), res.Purchases, res.PurchasesSkipped)
+}
+
+// randomPassword returns at least minLength hex digits.
+func randomPassword(minLength int) string {
+	b := make([]byte, max(9, (minLength+1)/2))
+	if _, err := rand.Read(b); err != nil {
+		log.Fatal(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,254 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"money-tracker/internal/config"
+	"money-tracker/internal/constants"
+	"money-tracker/internal/handler"
+	"money-tracker/internal/middleware"
+	"money-tracker/internal/notify"
+	"money-tracker/internal/password"
+	"money-tracker/internal/pubsub"
+	"money-tracker/internal/ratelimit"
+	"money-tracker/internal/repository"
+	"money-tracker/internal/routes"
+	"money-tracker/internal/usecase"
+	"money-tracker/internal/webhook"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+	"gorm.io/gorm"
+)
+
+const (
+	idempotencyPruneEvery = time.Hour
+
+	// changeHistory events are kept for clients resuming the event stream
+	changeHistory = 1000
+	changeBuffer  = 64
+)
+
+// Worker is a background job of the server. It runs until ctx is done.
+type Worker func(ctx context.Context)
+
+// App is the application container: it owns the database pool, the
+// handlers and the background workers, and is built once at startup.
+type App struct {
+	Config   *config.Config
+	DB       *gorm.DB
+	Handlers *routes.Handlers
+
+	changes *pubsub.Broker
+	workers []Worker
+}
+
+// New connects to the database and wires repositories, use cases and
+// handlers.
+func New(cfg *config.Config) (*App, error) {
+	db, err := config.OpenDB(cfg.DB)
+	if err != nil {
+		return nil, err
+	}
+	a, err := NewWithDB(cfg, db)
+	if err != nil {
+		if sqlDB, dbErr := db.DB(); dbErr == nil {
+			_ = sqlDB.Close()
+		}
+		return nil, err
+	}
+	return a, nil
+}
+
+// NewWithDB wires the app on a database that is already open, e.g. one
+// prepared by a test. The app owns db from then on and closes it in Close.
+func NewWithDB(cfg *config.Config, db *gorm.DB) (*App, error) {
+	policy, err := password.NewPolicy(cfg.Auth.Password.MinLength, cfg.Auth.Password.BreachedList)
+	if err != nil {
+		return nil, err
+	}
+
+	// repositories
+	repoCat := repository.NewRepositoryGorm(db)
+	repoUser := repository.NewUserRepositoryGorm(db)
+	repoToken := repository.NewUserTokenRepositoryGorm(db)
+	repoRecovery := repository.NewRecoveryCodeRepo(db)
+	repoReset := repository.NewPasswordResetRepo(db)
+	repoAPIKey := repository.NewAPIKeyRepo(db)
+	repoTag := repository.NewTagRepoGorm(db)
+	repoPurchase := repository.NewPurchaseRepo(db)
+	transactor := repository.NewGormTransactor(db)
+	repoIdempotency := repository.NewIdempotencyRepo(db)
+	repoWebhook := repository.NewWebhookRepo(db)
+	repoOutbox := repository.NewOutboxRepo(db)
+	repoDelivery := repository.NewWebhookDeliveryRepo(db)
+	repoGoal := repository.NewGoalRepo(db)
+	repoContribution := repository.NewGoalContributionRepo(db)
+	repoLoan := repository.NewLoanRepo(db)
+	repoLoanPayment := repository.NewLoanPaymentRepo(db)
+	repoRecurring := repository.NewRecurringItemRepo(db)
+	repoNotification := repository.NewNotificationRepo(db)
+
+	changes := pubsub.NewBroker(changeHistory, changeBuffer)
+
+	// use cases
+	ucCategory := usecase.NewCategoryUseCase(repoCat, changes)
+	loginGuard := usecase.NewLoginGuard(ratelimit.NewMemoryStore(), usecase.LoginLimits(cfg.Auth.Login))
+	ucUser := usecase.NewUserUseCase(repoUser, repoToken, repoRecovery, policy, loginGuard, cfg.Auth.JWTSecret, cfg.Auth.TokenTTL)
+	ucAPIKey := usecase.NewAPIKeyUseCase(repoAPIKey)
+	ucPassword := usecase.NewPasswordUseCase(repoUser, repoToken, repoReset, policy, notify.NewLog(), loginGuard, cfg.Auth.Password.ResetTTL)
+	ucTag := usecase.NewTagUseCase(repoTag, changes)
+	ucSuggestion := usecase.NewSuggestionUseCase(repoPurchase, repoTag, repoCat)
+	ucWebhook := usecase.NewWebhookUseCase(repoWebhook, repoOutbox, repoDelivery)
+	ucAnomaly := usecase.NewAnomalyUseCase(repoPurchase, repoCat, repoNotification, changes, usecase.AnomalyOptions(cfg.Anomalies))
+	ucPurchase := usecase.NewPurchaseUseCase(repoPurchase, repoTag, repoCat, transactor, ucSuggestion, ucWebhook, changes, ucAnomaly)
+	ucBackup := usecase.NewBackupUseCase(repoPurchase, repoTag, repoCat, transactor, ucSuggestion)
+	ucGoal := usecase.NewGoalUseCase(repoGoal, repoContribution)
+	ucLoan := usecase.NewLoanUseCase(repoLoan, repoLoanPayment)
+	ucForecast := usecase.NewForecastUseCase(repoRecurring, repoCat, repoPurchase)
+
+	a := &App{
+		Config: cfg,
+		DB:     db,
+		Handlers: &routes.Handlers{
+			Category: handler.NewCategoryHandler(ucCategory),
+			User:     handler.NewUserHandler(ucUser),
+			Password: handler.NewPasswordHandler(ucPassword),
+			APIKey:   handler.NewAPIKeyHandler(ucAPIKey),
+			Tag:      handler.NewTagHandler(ucTag),
+			Purchase: handler.NewPurchaseHandler(ucPurchase, ucSuggestion),
+			Backup:   handler.NewBackupHandler(ucBackup),
+			Webhook:  handler.NewWebhookHandler(ucWebhook),
+			Events:   handler.NewEventsHandler(changes),
+			Goal:     handler.NewGoalHandler(ucGoal),
+			Loan:     handler.NewLoanHandler(ucLoan),
+			Forecast: handler.NewForecastHandler(ucForecast),
+			Anomaly:  handler.NewAnomalyHandler(ucAnomaly),
+
+			Admin:       middleware.AuthMiddleware(db, cfg.Auth.JWTSecret, ucAPIKey, []int8{constants.LevelManageAdmin}),
+			Auth:        middleware.AuthMiddleware(db, cfg.Auth.JWTSecret, ucAPIKey, []int8{constants.LevelManageAdmin, constants.LevelManageUser}),
+			SessionOnly: middleware.SessionOnly(),
+			Idempotency: middleware.Idempotency(repoIdempotency, cfg.IdempotencyTTL),
+		},
+		changes: changes,
+	}
+
+	a.workers = append(a.workers, func(ctx context.Context) {
+		middleware.PruneIdempotencyKeys(ctx, repoIdempotency, idempotencyPruneEvery)
+	})
+	dispatcher := webhook.NewDispatcher(repoOutbox, repoWebhook, repoDelivery, webhook.Options(cfg.Webhooks))
+	a.workers = append(a.workers, dispatcher.Run)
+	a.workers = append(a.workers, ucAnomaly.Run)
+
+	return a, nil
+}
+
+// Router builds the gin engine with all routes.
+func (a *App) Router() *gin.Engine {
+	router := newEngine(a.Config.Server)
+
+	router.Use(cors.New(cors.Config{
+		AllowOrigins:     []string{"*"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization", "Idempotency-Key", "If-Match", "Last-Event-ID"},
+		ExposeHeaders:    []string{"Content-Length", "Idempotent-Replayed", "ETag", "Retry-After"},
+		AllowCredentials: true,
+		MaxAge:           12 * time.Hour,
+	}))
+	router.Use(middleware.ErrorHandler())
+
+	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+
+	router.Static("/public/images", "./uploads/images")
+
+	//---------Routes--------
+	routes.AdminRoutes("/api/v0/admin", router, a.Handlers)
+	routes.AuthRoutes("/api/v0", router, a.Handlers)
+	routes.AccountRoutes("/api/v0/account", router, a.Handlers)
+	routes.MainRoutes("/api/v0/system", router, a.Handlers)
+	//---------------------
+
+	return router
+}
+
+// newEngine returns a gin engine without routes that takes the client
+// address from X-Forwarded-For only when the request comes from one of the
+// trusted proxies.
+func newEngine(cfg config.ServerConfig) *gin.Engine {
+	gin.SetMode(cfg.GinMode)
+	router := gin.Default()
+	// without trusted proxies X-Forwarded-For is ignored, so a client cannot
+	// pick the address that login throttling counts against
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Printf("trusted proxies: %v", err)
+	}
+	return router
+}
+
+// Run serves HTTP until ctx is done, then shuts down in order: stop
+// accepting connections and drain in-flight requests, stop the workers,
+// close the database pool.
+func (a *App) Run(ctx context.Context) error {
+	srv := &http.Server{
+		Addr:         ":" + a.Config.Server.Port,
+		Handler:      a.Router(),
+		ReadTimeout:  a.Config.Server.ReadTimeout,
+		WriteTimeout: a.Config.Server.WriteTimeout,
+	}
+	// open event streams would hold Shutdown up until its timeout
+	srv.RegisterOnShutdown(a.changes.Close)
+
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for _, w := range a.workers {
+		wg.Add(1)
+		go func(w Worker) {
+			defer wg.Done()
+			w(workerCtx)
+		}(w)
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		log.Printf("listening on %s", srv.Addr)
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	var err error
+	select {
+	case err = <-serveErr:
+		// the server could not start
+	case <-ctx.Done():
+		log.Println("shutting down, draining requests")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), a.Config.Server.ShutdownTimeout)
+		err = srv.Shutdown(shutdownCtx)
+		cancel()
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		err = nil
+	}
+
+	stopWorkers()
+	wg.Wait()
+
+	if cerr := a.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Close closes the database pool.
+func (a *App) Close() error {
+	sqlDB, err := a.DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"money-tracker/internal/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// clientIP returns the address the engine sees for a request from remote
+// that claims to be forwarded for forwardedFor.
+func clientIP(t *testing.T, proxies []string, remote string, forwardedFor string) string {
+	t.Helper()
+	router := newEngine(config.ServerConfig{GinMode: gin.TestMode, TrustedProxies: proxies})
+	router.GET("/ip", func(c *gin.Context) { c.String(http.StatusOK, c.ClientIP()) })
+
+	req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+	req.RemoteAddr = remote
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		req.Header.Set("X-Real-IP", forwardedFor)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec.Body.String()
+}
+
+func TestClientIPIgnoresForwardedForWithoutProxies(t *testing.T) {
+	for _, proxies := range [][]string{nil, {}} {
+		if got := clientIP(t, proxies, "203.0.113.7:51000", "198.51.100.1"); got != "203.0.113.7" {
+			t.Errorf("proxies %q: client ip %q, want the connection address", proxies, got)
+		}
+	}
+}
+
+func TestClientIPFromTrustedProxy(t *testing.T) {
+	proxies := []string{"10.0.0.0/8"}
+	if got := clientIP(t, proxies, "10.1.2.3:51000", "198.51.100.1"); got != "198.51.100.1" {
+		t.Errorf("forwarded by a trusted proxy: %q", got)
+	}
+	// the header of anybody else is ignored
+	if got := clientIP(t, proxies, "203.0.113.7:51000", "198.51.100.1"); got != "203.0.113.7" {
+		t.Errorf("forwarded by an untrusted address: %q", got)
+	}
+	if got := clientIP(t, proxies, "10.1.2.3:51000", ""); got != "10.1.2.3" {
+		t.Errorf("no header: %q", got)
+	}
+}
@@ -0,0 +1,184 @@
+package backup
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	Format  = "money-tracker-backup"
+	Version = 1
+
+	ManifestFile   = "manifest.json"
+	CategoriesFile = "categories.jsonl"
+	TagsFile       = "tags.jsonl"
+	PurchasesFile  = "purchases.jsonl"
+)
+
+// ErrInvalidRecord is wrapped by the errors of Each for records that cannot
+// be decoded.
+var ErrInvalidRecord = errors.New("backup: invalid record")
+
+// Manifest describes the content of an archive. Counts is keyed by file name.
+type Manifest struct {
+	Format    string         `json:"format"`
+	Version   int            `json:"version"`
+	CreatedAt time.Time      `json:"created_at"`
+	Counts    map[string]int `json:"counts"`
+}
+
+type Category struct {
+	ID        uint      `json:"id"`
+	Title     string    `json:"title"`
+	Slug      string    `json:"slug"`
+	Color     string    `json:"color"`
+	StatusID  uint      `json:"status_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Tag struct {
+	ID        uint      `json:"id"`
+	Title     string    `json:"title"`
+	StatusID  uint      `json:"status_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Purchase struct {
+	ID            uint                   `json:"id"`
+	Date          time.Time              `json:"date"`
+	Amount        int64                  `json:"amount"`
+	Reason        string                 `json:"reason"`
+	Note          string                 `json:"note"`
+	Color         string                 `json:"color"`
+	Method        int8                   `json:"method"`
+	StatusID      uint                   `json:"status_id"`
+	CategoryID    *uint                  `json:"category_id"`
+	SubCategoryID *uint                  `json:"sub_category_id"`
+	TagIDs        []uint                 `json:"tag_ids"`
+	Details       map[string]interface{} `json:"details,omitempty"`
+	ExternalID    string                 `json:"external_id,omitempty"`
+	CreatedAt     time.Time              `json:"created_at"`
+	UpdatedAt     time.Time              `json:"updated_at"`
+}
+
+// /----------------------------- writer -----------------------------
+
+// Writer streams records into a zip archive, one JSON Lines file per entity.
+// Files must be written one after the other; the manifest is added on Close.
+type Writer struct {
+	zw      *zip.Writer
+	enc     *json.Encoder
+	current string
+	counts  map[string]int
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{zw: zip.NewWriter(w), counts: map[string]int{}}
+}
+
+// Begin starts the next file of the archive.
+func (bw *Writer) Begin(file string) error {
+	f, err := bw.zw.Create(file)
+	if err != nil {
+		return err
+	}
+	bw.enc = json.NewEncoder(f)
+	bw.current = file
+	bw.counts[file] = 0
+	return nil
+}
+
+// Write appends a record to the current file.
+func (bw *Writer) Write(record any) error {
+	if bw.enc == nil {
+		return errors.New("backup: Begin must be called before Write")
+	}
+	if err := bw.enc.Encode(record); err != nil {
+		return err
+	}
+	bw.counts[bw.current]++
+	return nil
+}
+
+func (bw *Writer) Close() error {
+	f, err := bw.zw.Create(ManifestFile)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	err = enc.Encode(Manifest{
+		Format:    Format,
+		Version:   Version,
+		CreatedAt: time.Now(),
+		Counts:    bw.counts,
+	})
+	if err != nil {
+		return err
+	}
+	return bw.zw.Close()
+}
+
+// /----------------------------- reader -----------------------------
+
+type Reader struct {
+	zr       *zip.Reader
+	Manifest Manifest
+}
+
+// NewReader opens an archive and checks its manifest.
+func NewReader(r io.ReaderAt, size int64) (*Reader, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("backup: invalid archive: %w", err)
+	}
+
+	br := &Reader{zr: zr}
+	f, err := zr.Open(ManifestFile)
+	if err != nil {
+		return nil, errors.New("backup: manifest not found")
+	}
+	defer f.Close()
+
+	if err := json.NewDecoder(f).Decode(&br.Manifest); err != nil {
+		return nil, fmt.Errorf("backup: invalid manifest: %w", err)
+	}
+	if br.Manifest.Format != Format {
+		return nil, errors.New("backup: not a money tracker backup")
+	}
+	if br.Manifest.Version < 1 || br.Manifest.Version > Version {
+		return nil, fmt.Errorf("backup: unsupported version %d", br.Manifest.Version)
+	}
+
+	return br, nil
+}
+
+// Each decodes the records of file one by one and passes them to fn. A file
+// missing from the archive has no records.
+func Each[T any](br *Reader, file string, fn func(record *T) error) error {
+	f, err := br.zr.Open(file)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	for line := 1; ; line++ {
+		var record T
+		if err := dec.Decode(&record); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("%w: %s record %d: %v", ErrInvalidRecord, file, line, err)
+		}
+		if err := fn(&record); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,473 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	defaultConfigFile = "config.yaml"
+	redacted          = "[redacted]"
+)
+
+// Config is everything the API server is built from. It is loaded once in
+// main and passed down; nothing below main reads the environment.
+//
+// Values come from, in increasing priority: the defaults, the YAML file
+// (CONFIG_FILE, or config.yaml when it exists), the .env file and the
+// environment.
+type Config struct {
+	Server         ServerConfig  `yaml:"server"`
+	DB             DBConfig      `yaml:"db"`
+	Auth           AuthConfig    `yaml:"auth"`
+	Webhooks       WebhookConfig `yaml:"webhooks"`
+	Anomalies      AnomalyConfig `yaml:"anomalies"`
+	IdempotencyTTL time.Duration `yaml:"idempotency_ttl"`
+}
+
+type ServerConfig struct {
+	Port            string        `yaml:"port"`
+	GinMode         string        `yaml:"gin_mode"`
+	SwaggerHost     string        `yaml:"swagger_host"`
+	ReadTimeout     time.Duration `yaml:"read_timeout"`
+	WriteTimeout    time.Duration `yaml:"write_timeout"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+
+	// TrustedProxies are the addresses or CIDRs of the reverse proxies whose
+	// X-Forwarded-For header is believed. Empty trusts none: the client
+	// address is the address of the connection.
+	TrustedProxies []string `yaml:"trusted_proxies"`
+}
+
+type DBConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Name     string `yaml:"name"`
+
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+}
+
+type AuthConfig struct {
+	JWTSecret string           `yaml:"jwt_secret"`
+	TokenTTL  time.Duration    `yaml:"token_ttl"`
+	Login     LoginLimitConfig `yaml:"login"`
+	Password  PasswordConfig   `yaml:"password"`
+}
+
+// PasswordConfig is the policy for new passwords and the lifetime of
+// password reset tokens. BreachedList names a file of passwords to refuse,
+// one per line, on top of the built-in list.
+type PasswordConfig struct {
+	MinLength    int           `yaml:"min_length"`
+	BreachedList string        `yaml:"breached_list"`
+	ResetTTL     time.Duration `yaml:"reset_ttl"`
+}
+
+// LoginLimitConfig limits login attempts per client address and per user
+// name, and locks a user name out after repeated failures. An attempt limit
+// of 0 turns it off.
+type LoginLimitConfig struct {
+	IPAttempts   int           `yaml:"ip_attempts"`
+	IPWindow     time.Duration `yaml:"ip_window"`
+	UserAttempts int           `yaml:"user_attempts"`
+	UserWindow   time.Duration `yaml:"user_window"`
+	// LockoutAfter failures lock the user name for LockoutBase, doubled for
+	// each further failure up to LockoutMax.
+	LockoutAfter int           `yaml:"lockout_after"`
+	LockoutBase  time.Duration `yaml:"lockout_base"`
+	LockoutMax   time.Duration `yaml:"lockout_max"`
+}
+
+// WebhookConfig is how outgoing webhooks are delivered. A failed delivery
+// is retried after BackoffBase, doubled for each further failure up to
+// BackoffMax, until MaxAttempts attempts failed.
+type WebhookConfig struct {
+	MaxAttempts  int           `yaml:"max_attempts"`
+	BackoffBase  time.Duration `yaml:"backoff_base"`
+	BackoffMax   time.Duration `yaml:"backoff_max"`
+	PollInterval time.Duration `yaml:"poll_interval"`
+	Timeout      time.Duration `yaml:"timeout"`
+}
+
+// AnomalyConfig is how unusual spending is flagged. A purchase is compared
+// with the purchases of its category over the last HistoryMonths, once the
+// category has MinSamples of them; it is flagged when its robust z-score is
+// above Threshold. The nightly scan runs ScanAt after midnight UTC.
+type AnomalyConfig struct {
+	Threshold     float64       `yaml:"threshold"`
+	HistoryMonths int           `yaml:"history_months"`
+	MinSamples    int           `yaml:"min_samples"`
+	ScanAt        time.Duration `yaml:"scan_at"`
+}
+
+// ValidationError lists every problem found in the configuration, so all
+// of them can be fixed at once.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:            "4011",
+			GinMode:         gin.DebugMode,
+			ReadTimeout:     30 * time.Second,
+			WriteTimeout:    120 * time.Second,
+			ShutdownTimeout: 30 * time.Second,
+		},
+		DB: DBConfig{
+			Port:            5432,
+			MaxIdleConns:    10,
+			MaxOpenConns:    100,
+			ConnMaxLifetime: time.Hour,
+		},
+		Auth: AuthConfig{
+			TokenTTL: 720 * time.Hour, // month
+			Login: LoginLimitConfig{
+				IPAttempts:   30,
+				IPWindow:     time.Minute,
+				UserAttempts: 10,
+				UserWindow:   15 * time.Minute,
+				LockoutAfter: 5,
+				LockoutBase:  time.Minute,
+				LockoutMax:   time.Hour,
+			},
+			Password: PasswordConfig{
+				MinLength: 8,
+				ResetTTL:  time.Hour,
+			},
+		},
+		Webhooks: WebhookConfig{
+			MaxAttempts:  8,
+			BackoffBase:  30 * time.Second,
+			BackoffMax:   time.Hour,
+			PollInterval: 5 * time.Second,
+			Timeout:      10 * time.Second,
+		},
+		Anomalies: AnomalyConfig{
+			Threshold:     3.5,
+			HistoryMonths: 12,
+			MinSamples:    8,
+			ScanAt:        3 * time.Hour,
+		},
+		IdempotencyTTL: 24 * time.Hour,
+	}
+}
+
+// Load reads and validates the whole configuration.
+func Load() (*Config, error) {
+	cfg, problems := read()
+	problems = append(problems, cfg.validate()...)
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	return cfg, nil
+}
+
+// LoadDB reads the configuration but only validates the database part, for
+// tools like the migration runner that need nothing else.
+func LoadDB() (DBConfig, error) {
+	cfg, problems := read()
+	problems = append(problems, cfg.DB.validate()...)
+	if len(problems) > 0 {
+		return DBConfig{}, &ValidationError{Problems: problems}
+	}
+	return cfg.DB, nil
+}
+
+// LoadPassword reads the configuration but only validates the password
+// policy, for tools like the seed command that create users.
+func LoadPassword() (PasswordConfig, error) {
+	cfg, problems := read()
+	problems = append(problems, cfg.Auth.Password.validate()...)
+	if len(problems) > 0 {
+		return PasswordConfig{}, &ValidationError{Problems: problems}
+	}
+	return cfg.Auth.Password, nil
+}
+
+func read() (*Config, []string) {
+	cfg := Default()
+	var problems []string
+
+	if err := cfg.readFile(); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	LoadEnvVariables()
+	env := envReader{}
+	env.str("PORT", &cfg.Server.Port)
+	env.str("GIN_MODE", &cfg.Server.GinMode)
+	env.str("SWAGGER_HOST", &cfg.Server.SwaggerHost)
+	env.seconds("HTTP_READ_TIMEOUT_SECONDS", &cfg.Server.ReadTimeout)
+	env.seconds("HTTP_WRITE_TIMEOUT_SECONDS", &cfg.Server.WriteTimeout)
+	env.seconds("SHUTDOWN_TIMEOUT_SECONDS", &cfg.Server.ShutdownTimeout)
+	env.list("TRUSTED_PROXIES", &cfg.Server.TrustedProxies)
+
+	env.str("DB_HOST", &cfg.DB.Host)
+	env.int("DB_PORT", &cfg.DB.Port)
+	env.str("DB_USER", &cfg.DB.User)
+	env.str("DB_PASS", &cfg.DB.Password)
+	env.str("DB_NAME", &cfg.DB.Name)
+	env.int("DB_MAX_IDLE_CONNS", &cfg.DB.MaxIdleConns)
+	env.int("DB_MAX_OPEN_CONNS", &cfg.DB.MaxOpenConns)
+
+	env.str("JWT_SECRET", &cfg.Auth.JWTSecret)
+	env.hours("JWT_TTL_HOURS", &cfg.Auth.TokenTTL)
+
+	env.hours("IDEMPOTENCY_TTL_HOURS", &cfg.IdempotencyTTL)
+
+	return cfg, append(problems, env.problems...)
+}
+
+// readFile merges the YAML file into cfg. The default file is optional, a
+// file named by CONFIG_FILE must exist.
+func (c *Config) readFile() error {
+	path := os.Getenv("CONFIG_FILE")
+	required := path != ""
+	if !required {
+		path = defaultConfigFile
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !required {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+
+	dec := yaml.NewDecoder(strings.NewReader(string(data)))
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	return nil
+}
+
+func (c *Config) validate() []string {
+	var problems []string
+
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port < 1 || port > 65535 {
+		problems = append(problems, "server port must be a number between 1 and 65535, got "+strconv.Quote(c.Server.Port))
+	}
+	switch c.Server.GinMode {
+	case gin.DebugMode, gin.ReleaseMode, gin.TestMode:
+	default:
+		problems = append(problems, "gin mode must be debug, release or test, got "+strconv.Quote(c.Server.GinMode))
+	}
+	if c.Server.ReadTimeout <= 0 || c.Server.WriteTimeout <= 0 || c.Server.ShutdownTimeout <= 0 {
+		problems = append(problems, "server timeouts must be positive")
+	}
+	for _, proxy := range c.Server.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			problems = append(problems, "trusted proxies must be IP addresses or CIDRs, got "+strconv.Quote(proxy))
+		}
+	}
+
+	problems = append(problems, c.DB.validate()...)
+
+	if c.Auth.JWTSecret == "" {
+		problems = append(problems, "JWT_SECRET is required")
+	}
+	if c.Auth.TokenTTL <= 0 {
+		problems = append(problems, "token ttl must be positive")
+	}
+	if l := c.Auth.Login; l.IPAttempts < 0 || l.UserAttempts < 0 || l.LockoutAfter < 0 {
+		problems = append(problems, "login attempt limits must not be negative")
+	} else if (l.IPAttempts > 0 && l.IPWindow <= 0) || (l.UserAttempts > 0 && l.UserWindow <= 0) {
+		problems = append(problems, "login limit windows must be positive")
+	} else if l.LockoutAfter > 0 && (l.LockoutBase <= 0 || l.LockoutMax < l.LockoutBase) {
+		problems = append(problems, "login lockout needs 0 < lockout_base <= lockout_max")
+	}
+	problems = append(problems, c.Auth.Password.validate()...)
+	if w := c.Webhooks; w.MaxAttempts < 1 {
+		problems = append(problems, "webhooks max_attempts must be at least 1")
+	} else if w.BackoffBase <= 0 || w.BackoffMax < w.BackoffBase {
+		problems = append(problems, "webhooks need 0 < backoff_base <= backoff_max")
+	} else if w.PollInterval <= 0 || w.Timeout <= 0 {
+		problems = append(problems, "webhooks poll_interval and timeout must be positive")
+	}
+	if a := c.Anomalies; a.Threshold <= 0 {
+		problems = append(problems, "anomalies threshold must be positive")
+	} else if a.HistoryMonths < 1 || a.MinSamples < 2 {
+		problems = append(problems, "anomalies need history_months >= 1 and min_samples >= 2")
+	} else if a.ScanAt < 0 || a.ScanAt >= 24*time.Hour {
+		problems = append(problems, "anomalies scan_at must be between 0s and 24h")
+	}
+	if c.IdempotencyTTL <= 0 {
+		problems = append(problems, "idempotency ttl must be positive")
+	}
+
+	return problems
+}
+
+func (c PasswordConfig) validate() []string {
+	var problems []string
+	if c.MinLength < 1 || c.MinLength > 72 {
+		problems = append(problems, "password min_length must be between 1 and 72")
+	}
+	if c.ResetTTL <= 0 {
+		problems = append(problems, "password reset_ttl must be positive")
+	}
+	return problems
+}
+
+func (c DBConfig) validate() []string {
+	var problems []string
+	if c.Host == "" {
+		problems = append(problems, "DB_HOST is required")
+	}
+	if c.Name == "" {
+		problems = append(problems, "DB_NAME is required")
+	}
+	if c.User == "" {
+		problems = append(problems, "DB_USER is required")
+	}
+	if c.Port < 1 || c.Port > 65535 {
+		problems = append(problems, "DB_PORT must be between 1 and 65535")
+	}
+	if c.MaxOpenConns < 1 || c.MaxIdleConns < 0 || c.MaxIdleConns > c.MaxOpenConns {
+		problems = append(problems, "db pool needs max_open_conns >= 1 and 0 <= max_idle_conns <= max_open_conns")
+	}
+	return problems
+}
+
+func (c DBConfig) DSN() string {
+	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=disable", c.Host, c.User, c.Password, c.Name, c.Port)
+}
+
+// String leaves the password out, so a DBConfig is safe to log.
+func (c DBConfig) String() string {
+	return fmt.Sprintf("host=%s user=%s dbname=%s port=%d", c.Host, c.User, c.Name, c.Port)
+}
+
+// Redacted returns a copy of c that is safe to log.
+func (c Config) Redacted() Config {
+	if c.DB.Password != "" {
+		c.DB.Password = redacted
+	}
+	if c.Auth.JWTSecret != "" {
+		c.Auth.JWTSecret = redacted
+	}
+	return c
+}
+
+// String prints the configuration with the secrets redacted.
+func (c Config) String() string {
+	r := c.Redacted()
+	lines := []string{
+		"server.port=" + r.Server.Port,
+		"server.gin_mode=" + r.Server.GinMode,
+		"server.swagger_host=" + r.Server.SwaggerHost,
+		"server.read_timeout=" + r.Server.ReadTimeout.String(),
+		"server.write_timeout=" + r.Server.WriteTimeout.String(),
+		"server.shutdown_timeout=" + r.Server.ShutdownTimeout.String(),
+		"server.trusted_proxies=" + strings.Join(r.Server.TrustedProxies, ","),
+		"db.host=" + r.DB.Host,
+		"db.port=" + strconv.Itoa(r.DB.Port),
+		"db.user=" + r.DB.User,
+		"db.password=" + r.DB.Password,
+		"db.name=" + r.DB.Name,
+		"db.max_idle_conns=" + strconv.Itoa(r.DB.MaxIdleConns),
+		"db.max_open_conns=" + strconv.Itoa(r.DB.MaxOpenConns),
+		"db.conn_max_lifetime=" + r.DB.ConnMaxLifetime.String(),
+		"auth.jwt_secret=" + r.Auth.JWTSecret,
+		"auth.token_ttl=" + r.Auth.TokenTTL.String(),
+		"auth.login.ip_attempts=" + strconv.Itoa(r.Auth.Login.IPAttempts),
+		"auth.login.ip_window=" + r.Auth.Login.IPWindow.String(),
+		"auth.login.user_attempts=" + strconv.Itoa(r.Auth.Login.UserAttempts),
+		"auth.login.user_window=" + r.Auth.Login.UserWindow.String(),
+		"auth.login.lockout_after=" + strconv.Itoa(r.Auth.Login.LockoutAfter),
+		"auth.login.lockout_base=" + r.Auth.Login.LockoutBase.String(),
+		"auth.login.lockout_max=" + r.Auth.Login.LockoutMax.String(),
+		"auth.password.min_length=" + strconv.Itoa(r.Auth.Password.MinLength),
+		"auth.password.breached_list=" + r.Auth.Password.BreachedList,
+		"auth.password.reset_ttl=" + r.Auth.Password.ResetTTL.String(),
+		"webhooks.max_attempts=" + strconv.Itoa(r.Webhooks.MaxAttempts),
+		"webhooks.backoff_base=" + r.Webhooks.BackoffBase.String(),
+		"webhooks.backoff_max=" + r.Webhooks.BackoffMax.String(),
+		"webhooks.poll_interval=" + r.Webhooks.PollInterval.String(),
+		"webhooks.timeout=" + r.Webhooks.Timeout.String(),
+		"anomalies.threshold=" + strconv.FormatFloat(r.Anomalies.Threshold, 'g', -1, 64),
+		"anomalies.history_months=" + strconv.Itoa(r.Anomalies.HistoryMonths),
+		"anomalies.min_samples=" + strconv.Itoa(r.Anomalies.MinSamples),
+		"anomalies.scan_at=" + r.Anomalies.ScanAt.String(),
+		"idempotency_ttl=" + r.IdempotencyTTL.String(),
+	}
+	return strings.Join(lines, "\n")
+}
+
+// /----------------------------- env -----------------------------
+
+// envReader overrides config values with the environment variables that
+// are set and collects the ones that cannot be parsed.
+type envReader struct {
+	problems []string
+}
+
+func (r *envReader) str(key string, dst *string) {
+	if val, ok := os.LookupEnv(key); ok && val != "" {
+		*dst = val
+	}
+}
+
+// int reports whether dst was set.
+func (r *envReader) int(key string, dst *int) bool {
+	val, ok := os.LookupEnv(key)
+	if !ok || val == "" {
+		return false
+	}
+	n, err := strconv.Atoi(val)
+	if err != nil {
+		r.problems = append(r.problems, key+" must be a number, got "+strconv.Quote(val))
+		return false
+	}
+	*dst = n
+	return true
+}
+
+// list reads a comma separated list.
+func (r *envReader) list(key string, dst *[]string) {
+	val, ok := os.LookupEnv(key)
+	if !ok || strings.TrimSpace(val) == "" {
+		return
+	}
+	*dst = nil
+	for _, item := range strings.Split(val, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*dst = append(*dst, item)
+		}
+	}
+}
+
+func (r *envReader) seconds(key string, dst *time.Duration) {
+	r.duration(key, dst, time.Second)
+}
+
+func (r *envReader) hours(key string, dst *time.Duration) {
+	r.duration(key, dst, time.Hour)
+}
+
+func (r *envReader) duration(key string, dst *time.Duration, unit time.Duration) {
+	var n int
+	if r.int(key, &n) {
+		*dst = time.Duration(n) * unit
+	}
+}
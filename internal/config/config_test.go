@@ -0,0 +1,200 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var envKeys = []string{
+	"CONFIG_FILE", "PORT", "GIN_MODE", "SWAGGER_HOST", "HTTP_READ_TIMEOUT_SECONDS",
+	"HTTP_WRITE_TIMEOUT_SECONDS", "SHUTDOWN_TIMEOUT_SECONDS", "TRUSTED_PROXIES",
+	"DB_HOST", "DB_PORT", "DB_USER", "DB_PASS", "DB_NAME", "DB_MAX_IDLE_CONNS",
+	"DB_MAX_OPEN_CONNS", "JWT_SECRET", "JWT_TTL_HOURS", "IDEMPOTENCY_TTL_HOURS",
+}
+
+// isolate runs the test in an empty directory, without a config.yaml or a
+// .env nearby, and with the variables read here unset (empty counts as
+// unset), then sets env.
+func isolate(t *testing.T, env map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Chdir(dir)
+	for _, key := range envKeys {
+		t.Setenv(key, "")
+	}
+	for key, val := range env {
+		t.Setenv(key, val)
+	}
+	return dir
+}
+
+var required = map[string]string{"DB_HOST": "localhost", "DB_USER": "postgres", "DB_NAME": "money", "JWT_SECRET": "secret"}
+
+func TestLoadDefaults(t *testing.T) {
+	isolate(t, required)
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Default()
+	want.DB.Host, want.DB.User, want.DB.Name = "localhost", "postgres", "money"
+	want.Auth.JWTSecret = "secret"
+	if !reflect.DeepEqual(cfg, want) {
+		t.Fatalf("Load() =\n%v\nwant\n%v", cfg, want)
+	}
+	if cfg.Server.Port != "4011" || cfg.DB.Port != 5432 || cfg.Auth.Password.MinLength != 8 || cfg.Server.TrustedProxies != nil {
+		t.Fatalf("defaults %v", cfg)
+	}
+}
+
+func TestLoadFileThenEnv(t *testing.T) {
+	dir := isolate(t, required)
+	file := `
+server:
+  port: "5000"
+  read_timeout: 10s
+  trusted_proxies: ["10.0.0.1"]
+db:
+  host: file-host
+  password: from-file
+auth:
+  token_ttl: 48h
+  password:
+    min_length: 12
+idempotency_ttl: 2h
+`
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DB_HOST", "")
+	t.Setenv("PORT", "6000")
+	t.Setenv("HTTP_WRITE_TIMEOUT_SECONDS", "5")
+	t.Setenv("JWT_TTL_HOURS", "3")
+	t.Setenv("TRUSTED_PROXIES", " 10.0.0.0/8, ,127.0.0.1 ")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the environment wins over the file, the file over the defaults
+	if cfg.Server.Port != "6000" || cfg.Server.ReadTimeout != 10*time.Second || cfg.Server.WriteTimeout != 5*time.Second {
+		t.Errorf("server %+v", cfg.Server)
+	}
+	if !reflect.DeepEqual(cfg.Server.TrustedProxies, []string{"10.0.0.0/8", "127.0.0.1"}) {
+		t.Errorf("trusted proxies %q", cfg.Server.TrustedProxies)
+	}
+	if cfg.DB.Host != "file-host" || cfg.DB.Password != "from-file" || cfg.DB.Port != 5432 {
+		t.Errorf("db %v", cfg.DB)
+	}
+	if cfg.Auth.TokenTTL != 3*time.Hour || cfg.Auth.Password.MinLength != 12 || cfg.Auth.Password.ResetTTL != time.Hour || cfg.IdempotencyTTL != 2*time.Hour {
+		t.Errorf("auth %+v, idempotency ttl %v", cfg.Auth, cfg.IdempotencyTTL)
+	}
+
+	// secrets stay out of the printed configuration
+	if s := cfg.String(); strings.Contains(s, "from-file") || strings.Contains(s, "=secret") || !strings.Contains(s, "db.password=[redacted]") {
+		t.Errorf("String() leaks a secret:\n%s", s)
+	}
+}
+
+// problems returns the problems Load reports, or fails the test when it
+// accepts the configuration.
+func problems(t *testing.T) []string {
+	t.Helper()
+	_, err := Load()
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("Load() = %v, want a ValidationError", err)
+	}
+	return invalid.Problems
+}
+
+func hasProblem(list []string, part string) bool {
+	for _, p := range list {
+		if strings.Contains(p, part) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestLoadRejectsInvalidValues(t *testing.T) {
+	isolate(t, map[string]string{
+		"PORT":              "http",
+		"GIN_MODE":          "loud",
+		"DB_PORT":           "five",
+		"DB_MAX_IDLE_CONNS": "200",
+		"TRUSTED_PROXIES":   "10.0.0.0/8,proxy.local",
+		"JWT_TTL_HOURS":     "-1",
+	})
+
+	got := problems(t)
+	for _, want := range []string{
+		`server port must be a number between 1 and 65535, got "http"`,
+		`gin mode must be debug, release or test, got "loud"`,
+		`DB_PORT must be a number, got "five"`,
+		"db pool needs",
+		`trusted proxies must be IP addresses or CIDRs, got "proxy.local"`,
+		"DB_HOST is required",
+		"DB_NAME is required",
+		"DB_USER is required",
+		"JWT_SECRET is required",
+		"token ttl must be positive",
+	} {
+		if !hasProblem(got, want) {
+			t.Errorf("no problem %q in\n%s", want, strings.Join(got, "\n"))
+		}
+	}
+	if hasProblem(got, "10.0.0.0/8") {
+		t.Errorf("a valid CIDR was refused:\n%s", strings.Join(got, "\n"))
+	}
+}
+
+func TestLoadRejectsBadFiles(t *testing.T) {
+	dir := isolate(t, required)
+
+	t.Setenv("CONFIG_FILE", filepath.Join(dir, "missing.yaml"))
+	if got := problems(t); !hasProblem(got, "config file") {
+		t.Errorf("missing CONFIG_FILE: %q", got)
+	}
+
+	path := filepath.Join(dir, "typo.yaml")
+	if err := os.WriteFile(path, []byte("server:\n  prot: \"5000\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+	if got := problems(t); !hasProblem(got, "field prot not found") {
+		t.Errorf("unknown key: %q", got)
+	}
+
+	if err := os.WriteFile(path, []byte("auth:\n  password:\n    min_length: 100\n    reset_ttl: 0s\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got := problems(t)
+	if !hasProblem(got, "password min_length must be between 1 and 72") || !hasProblem(got, "password reset_ttl must be positive") {
+		t.Errorf("password policy: %q", got)
+	}
+}
+
+func TestLoadPartial(t *testing.T) {
+	// the tools only need their own part
+	isolate(t, map[string]string{"DB_HOST": "localhost", "DB_USER": "postgres", "DB_NAME": "money"})
+	db, err := LoadDB()
+	if err != nil || db.Host != "localhost" || db.Port != 5432 {
+		t.Fatalf("LoadDB() = %v, %v", db, err)
+	}
+	password, err := LoadPassword()
+	if err != nil || password.MinLength != 8 {
+		t.Fatalf("LoadPassword() = %+v, %v", password, err)
+	}
+
+	t.Setenv("DB_HOST", "")
+	if _, err := LoadDB(); err == nil || !strings.Contains(err.Error(), "DB_HOST is required") {
+		t.Fatalf("LoadDB() without a host = %v", err)
+	}
+}
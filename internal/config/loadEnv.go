@@ -4,7 +4,6 @@ import (
 	"log"
 	"os"
 
-	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 )
 
@@ -33,13 +32,4 @@ func LoadEnvVariables() {
 	if !loaded {
 		log.Println("No .env file found in expected locations. Continuing without it...")
 	}
-
-	// Set Gin mode from environment variable, fallback to debug
-	mode := os.Getenv("GIN_MODE")
-	switch mode {
-	case gin.ReleaseMode, gin.DebugMode, gin.TestMode:
-		gin.SetMode(mode)
-	default:
-		gin.SetMode(gin.DebugMode)
-	}
 }
@@ -25,3 +25,9 @@ const (
 	FileProcessReady                  //1
 	FileProcessProcessing             //2
 )
+
+// API key scopes
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+)
@@ -28,7 +28,8 @@ type UpdateCategoryRequest struct {
 	StatusID uint   `json:"status_id" binding:"oneof=1 0"`
 	TagIDs   string `json:"tag_ids"`
 	// Slug     string `json:"slug"`
-	Color string `json:"color"`
+	Color   string `json:"color"`
+	Version *uint  `json:"version"`
 }
 type UpdateCategoryInput struct {
 	ID       uint   `json:"id" binding:"required"`
@@ -37,6 +38,7 @@ type UpdateCategoryInput struct {
 	TagIDs   string `json:"tag_ids"`
 	Slug     string `json:"slug"`
 	Color    string `json:"color"`
+	Version  *uint  `json:"version"`
 }
 
 type FetchedParent struct {
@@ -59,7 +61,7 @@ type AddCategoryInput struct {
 	Title    string `json:"title" binding:"required"`
 	StatusID uint   `json:"status_id"`
 	Slug     string `json:"slug" binding:"required"`
-	Color    string `json;"color"`
+	Color    string `json:"color"`
 }
 
 type CategoryFindAll struct {
@@ -73,4 +75,7 @@ type CategoryFindAll struct {
 	TagIDs   []int  `form:"tag_ids[]" json:"tag_ids"`   // Filter by tag IDs (array)
 	Slug     string `form:"slug" json:"slug"`
 	Color    string `form:"color"`
+
+	// AnyStatus selects every status, for backups; not a query parameter
+	AnyStatus bool `form:"-" json:"-"`
 }
@@ -0,0 +1,23 @@
+package dto
+
+// ErrorResponse is the body of every error response. Response carries
+// extra data for some errors, e.g. the current resource after a version
+// conflict.
+type ErrorResponse struct {
+	Message  string      `json:"message"`
+	Error    ErrorBody   `json:"error"`
+	Response interface{} `json:"response"`
+}
+
+type ErrorBody struct {
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields,omitempty"`
+}
+
+// FieldError points a validation failure at one input field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
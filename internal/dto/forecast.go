@@ -0,0 +1,71 @@
+package dto
+
+import "time"
+
+// RecurringItemRequest creates a recurring item, or replaces one.
+type RecurringItemRequest struct {
+	Name string `json:"name" binding:"required,max=255"`
+	// Amount is positive for money coming in and negative for money going
+	// out
+	Amount    int64      `json:"amount" binding:"required"`
+	Frequency string     `json:"frequency" binding:"required,oneof=weekly biweekly monthly quarterly yearly"`
+	StartDate time.Time  `json:"start_date" binding:"required"`
+	EndDate   *time.Time `json:"end_date"`
+	// Account defaults to "main"
+	Account string `json:"account" binding:"max=255"`
+	// CategoryID is the category the payments are recorded in as
+	// purchases, left out of the historical averages
+	CategoryID *uint  `json:"category_id"`
+	Note       string `json:"note" binding:"max=1000"`
+}
+
+type ForecastInput struct {
+	// Days to forecast after today, 90 by default
+	Days int `form:"days" binding:"omitempty,min=1,max=366"`
+	// Lookback is how many days of purchases the averages are taken
+	// over, 90 by default
+	Lookback int `form:"lookback" binding:"omitempty,min=7,max=730"`
+	// SpendingAccount is charged the average spending, "main" by default
+	SpendingAccount string `form:"spending_account" binding:"max=255"`
+	// Balances are the balances of the accounts today, from the
+	// balance[account]=amount query parameters
+	Balances map[string]int64 `form:"-"`
+}
+
+// CategoryAverage is the spending of a category over the lookback days.
+type CategoryAverage struct {
+	CategoryID uint    `json:"category_id"`
+	Category   string  `json:"category"`
+	Total      int64   `json:"total"`
+	Daily      float64 `json:"daily"`
+}
+
+type ForecastDay struct {
+	Date    time.Time `json:"date"`
+	Income  int64     `json:"income"`
+	Expense int64     `json:"expense"`
+	Balance int64     `json:"balance"`
+}
+
+type AccountForecast struct {
+	Account string `json:"account"`
+	Opening int64  `json:"opening"`
+	Closing int64  `json:"closing"`
+	// Low is the first day with the lowest balance
+	Low ForecastDay `json:"low"`
+	// NegativeFrom are the days the balance drops below zero
+	NegativeFrom []time.Time   `json:"negative_from"`
+	NegativeDays int           `json:"negative_days"`
+	Days         []ForecastDay `json:"days"`
+}
+
+// Forecast projects the daily balance of every account from the recurring
+// items and the average daily spending per category.
+type Forecast struct {
+	From            time.Time         `json:"from"`
+	To              time.Time         `json:"to"`
+	Lookback        int               `json:"lookback"`
+	SpendingAccount string            `json:"spending_account"`
+	Averages        []CategoryAverage `json:"averages"`
+	Accounts        []AccountForecast `json:"accounts"`
+}
@@ -0,0 +1,51 @@
+package dto
+
+import "time"
+
+type CreateGoalRequest struct {
+	Title        string    `json:"title" binding:"required,max=255"`
+	TargetAmount int64     `json:"target_amount" binding:"required,gt=0"`
+	Deadline     time.Time `json:"deadline" binding:"required"`
+	// Account names the account the savings are kept in, optional
+	Account string `json:"account" binding:"max=255"`
+	Note    string `json:"note" binding:"max=1000"`
+}
+
+// UpdateGoalRequest changes the members that are present.
+type UpdateGoalRequest struct {
+	Title        *string    `json:"title" binding:"omitempty,max=255"`
+	TargetAmount *int64     `json:"target_amount" binding:"omitempty,gt=0"`
+	Deadline     *time.Time `json:"deadline"`
+	Account      *string    `json:"account" binding:"omitempty,max=255"`
+	Note         *string    `json:"note" binding:"omitempty,max=1000"`
+}
+
+type AddContributionRequest struct {
+	// Amount is negative for money taken out of the goal
+	Amount int64 `json:"amount" binding:"required"`
+	// Date defaults to now
+	Date *time.Time `json:"date"`
+	Note string     `json:"note" binding:"max=1000"`
+}
+
+// GoalProgress is where a goal stands. The pace is the net amount
+// contributed per month since the first contribution.
+type GoalProgress struct {
+	GoalID       uint      `json:"goal_id"`
+	TargetAmount int64     `json:"target_amount"`
+	Deadline     time.Time `json:"deadline"`
+	Saved        int64     `json:"saved"`
+	Remaining    int64     `json:"remaining"`
+	Percent      float64   `json:"percent"`
+	Completed    bool      `json:"completed"`
+	Overdue      bool      `json:"overdue"`
+	MonthsLeft   float64   `json:"months_left"`
+	// RequiredMonthly is what has to be saved each month from now on to
+	// make the deadline
+	RequiredMonthly int64 `json:"required_monthly"`
+	AverageMonthly  int64 `json:"average_monthly"`
+	// ProjectedCompletion is when the goal is reached at the current pace,
+	// or was reached; null when the pace is not positive
+	ProjectedCompletion *time.Time `json:"projected_completion"`
+	OnTrack             bool       `json:"on_track"`
+}
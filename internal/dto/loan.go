@@ -0,0 +1,105 @@
+package dto
+
+import "time"
+
+type CreateLoanRequest struct {
+	Name string `json:"name" binding:"required,max=255"`
+	// Direction is borrowed, the default, or lent
+	Direction string `json:"direction" binding:"omitempty,oneof=borrowed lent"`
+	Principal int64  `json:"principal" binding:"required,gt=0"`
+	// InterestRate is the nominal annual rate in percent, 0 for an IOU
+	InterestRate float64 `json:"interest_rate" binding:"min=0,max=100"`
+	// Term is the number of payments
+	Term      int    `json:"term" binding:"required,gt=0,max=1200"`
+	Frequency string `json:"frequency" binding:"required,oneof=weekly biweekly monthly quarterly yearly"`
+	// StartDate is when the money changed hands; the first payment is due
+	// one period later
+	StartDate time.Time `json:"start_date" binding:"required"`
+	Note      string    `json:"note" binding:"max=1000"`
+}
+
+// UpdateLoanRequest changes the members that are present. The terms
+// (principal, interest_rate, term, frequency, start_date) are fixed once a
+// payment is recorded.
+type UpdateLoanRequest struct {
+	Name         *string    `json:"name" binding:"omitempty,max=255"`
+	Direction    *string    `json:"direction" binding:"omitempty,oneof=borrowed lent"`
+	Principal    *int64     `json:"principal" binding:"omitempty,gt=0"`
+	InterestRate *float64   `json:"interest_rate" binding:"omitempty,min=0,max=100"`
+	Term         *int       `json:"term" binding:"omitempty,gt=0,max=1200"`
+	Frequency    *string    `json:"frequency" binding:"omitempty,oneof=weekly biweekly monthly quarterly yearly"`
+	StartDate    *time.Time `json:"start_date"`
+	Note         *string    `json:"note" binding:"omitempty,max=1000"`
+}
+
+type AddLoanPaymentRequest struct {
+	Amount int64 `json:"amount" binding:"required,gt=0"`
+	// Date defaults to now
+	Date *time.Time `json:"date"`
+	Note string     `json:"note" binding:"max=1000"`
+}
+
+type LoanScheduleInput struct {
+	// Extra is paid on top of every regular payment
+	Extra int64 `form:"extra" binding:"min=0"`
+}
+
+// LoanStatusInput asks what paying more would change: Extra on top of
+// every remaining payment and LumpSum paid off at once.
+type LoanStatusInput struct {
+	Extra   int64 `form:"extra" binding:"min=0"`
+	LumpSum int64 `form:"lump_sum" binding:"min=0"`
+}
+
+type LoanInstallment struct {
+	Number    int       `json:"number"`
+	Date      time.Time `json:"date"`
+	Payment   int64     `json:"payment"`
+	Interest  int64     `json:"interest"`
+	Principal int64     `json:"principal"`
+	Balance   int64     `json:"balance"`
+}
+
+// LoanPayoff sums up the payments that pay a balance off.
+type LoanPayoff struct {
+	Payments      int        `json:"payments"`
+	PayoffDate    *time.Time `json:"payoff_date"`
+	TotalInterest int64      `json:"total_interest"`
+	TotalPaid     int64      `json:"total_paid"`
+}
+
+// LoanSchedule is the amortization schedule of a loan from its start.
+type LoanSchedule struct {
+	LoanID         uint              `json:"loan_id"`
+	RegularPayment int64             `json:"regular_payment"`
+	Extra          int64             `json:"extra"`
+	Installments   []LoanInstallment `json:"installments"`
+	Payoff         LoanPayoff        `json:"payoff"`
+}
+
+// LoanStatus is where a loan stands after the payments recorded so far.
+// Remaining projects the regular payments from the next one due.
+// InterestOwed is interest charged that the payments did not cover.
+type LoanStatus struct {
+	LoanID         uint       `json:"loan_id"`
+	Principal      int64      `json:"principal"`
+	Balance        int64      `json:"balance"`
+	PrincipalPaid  int64      `json:"principal_paid"`
+	InterestPaid   int64      `json:"interest_paid"`
+	InterestOwed   int64      `json:"interest_owed"`
+	PaymentsMade   int        `json:"payments_made"`
+	RegularPayment int64      `json:"regular_payment"`
+	NextDueDate    *time.Time `json:"next_due_date"`
+	PaidOff        bool       `json:"paid_off"`
+	Remaining      LoanPayoff `json:"remaining"`
+	// WhatIf is set when extra or lump_sum was asked for
+	WhatIf *LoanWhatIf `json:"what_if,omitempty"`
+}
+
+type LoanWhatIf struct {
+	Extra         int64      `json:"extra"`
+	LumpSum       int64      `json:"lump_sum"`
+	Remaining     LoanPayoff `json:"remaining"`
+	InterestSaved int64      `json:"interest_saved"`
+	PaymentsSaved int        `json:"payments_saved"`
+}
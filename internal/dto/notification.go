@@ -0,0 +1,7 @@
+package dto
+
+type NotificationListInput struct {
+	Unread bool `form:"unread"`
+	Start  int  `form:"start"`
+	Limit  int  `form:"limit"`
+}
@@ -3,26 +3,37 @@ package dto
 import "time"
 
 type PurchaseFindAll struct {
-	ID            uint   `form:"id"`
-	CategoryID    *uint  `form:"category_id"`
-	SubCategoryID *uint  `form:"sub_category_id"`
-	Reason        string `form:"reason"`
-	Note          string `form:"note"`
-	Color         string `form:"color"`
-	Method        int8   `form:"method"`
-	Amount        int64  `form:"amount"`
-	StatusID      uint   `form:"status_id"`
-	TagIDs        []uint `form:"tag_ids"`
-	Start         int    `form:"start" default:"0"`
-	Limit         int    `form:"limit" default:"10"`
-	OrderBy       string `form:"order_by" default:"id"`
-	Sort          string `form:"sort" default:"desc"`
-	OtherFields   bool   `form:"other_fields"`
+	ID            uint   `form:"id" json:"id"`
+	CategoryID    *uint  `form:"category_id" json:"category_id"`
+	SubCategoryID *uint  `form:"sub_category_id" json:"sub_category_id"`
+	Reason        string `form:"reason" json:"reason"`
+	Note          string `form:"note" json:"note"`
+	Color         string `form:"color" json:"color"`
+	Method        int8   `form:"method" json:"method"`
+	Amount        int64  `form:"amount" json:"amount"`
+	StatusID      uint   `form:"status_id" json:"status_id"`
+	TagIDs        []uint `form:"tag_ids" json:"tag_ids"`
+	Start         int    `form:"start" json:"start" default:"0"`
+	Limit         int    `form:"limit" json:"limit" default:"10"`
+	OrderBy       string `form:"order_by" json:"order_by" default:"id"`
+	Sort          string `form:"sort" json:"sort" default:"desc"`
+	OtherFields   bool   `form:"other_fields" json:"other_fields"`
+
+	// DateFrom and DateTo select the purchases dated in [DateFrom, DateTo)
+	DateFrom *time.Time `form:"date_from" json:"date_from"`
+	DateTo   *time.Time `form:"date_to" json:"date_to"`
+	// UpdatedFrom selects the purchases created or changed since,
+	// AnyStatus the purchases in every status and AfterID those after an id
+	// in the sort order, for keyset pagination when ordered by id; they are
+	// not query parameters
+	UpdatedFrom *time.Time `form:"-" json:"-"`
+	AnyStatus   bool       `form:"-" json:"-"`
+	AfterID     uint       `form:"-" json:"-"`
 }
 
 type AddPurchaseInput struct {
 	CategoryId    *uint     `json:"category_id" binding:"required"`
-	SubCategoryId *uint     `json:"sub_category_id`
+	SubCategoryId *uint     `json:"sub_category_id"`
 	Reason        string    `json:"reason"`
 	Date          time.Time `json:"date" binding:"required"`
 	Note          string    `json:"note"`
@@ -60,4 +71,105 @@ type UpdatePurchaseInput struct {
 	Amount        int64     `json:"amount"`
 	StatusID      uint      `json:"status_id" binding:"oneof=1 0"`
 	TagIDs        string    `json:"tag_ids"`
+	Version       *uint     `json:"version"`
+}
+
+type SuggestPurchaseInput struct {
+	Reason string `form:"reason" json:"reason"`
+	Note   string `form:"note" json:"note"`
+	Amount int64  `form:"amount" json:"amount"`
+	Limit  int    `form:"limit" json:"limit"`
+}
+
+type SuggestedCategory struct {
+	ID    uint    `json:"id"`
+	Title string  `json:"title"`
+	Slug  string  `json:"slug"`
+	Color string  `json:"color"`
+	Score float64 `json:"score"`
+}
+
+type SuggestedTag struct {
+	ID    uint    `json:"id"`
+	Title string  `json:"title"`
+	Score float64 `json:"score"`
+}
+
+type PurchaseSuggestionResponse struct {
+	Categories []SuggestedCategory `json:"categories"`
+	Tags       []SuggestedTag      `json:"tags"`
+}
+
+type ImportPurchasesInput struct {
+	Format        string `form:"format"`
+	CategoryId    *uint  `form:"category_id" binding:"required"`
+	SubCategoryId *uint  `form:"sub_category_id"`
+	TagIDs        string `form:"tag_ids"`
+}
+
+// ImportPurchasesResult counts the transactions of an imported statement.
+// Deposits are not supported and only counted in SkippedIncome.
+type ImportPurchasesResult struct {
+	Total         int    `json:"total"`
+	Imported      int    `json:"imported"`
+	Duplicates    int    `json:"duplicates"`
+	SkippedIncome int    `json:"skipped_income"`
+	IDs           []uint `json:"ids"`
+}
+
+type RestoreResult struct {
+	Categories       int `json:"categories"`
+	CategoriesReused int `json:"categories_reused"`
+	Tags             int `json:"tags"`
+	TagsReused       int `json:"tags_reused"`
+	Purchases        int `json:"purchases"`
+}
+
+// PurchasePatchDocument is the part of a purchase a merge patch can change.
+type PurchasePatchDocument struct {
+	CategoryId    *uint                  `json:"category_id"`
+	SubCategoryId *uint                  `json:"sub_category_id"`
+	Reason        string                 `json:"reason"`
+	Date          *time.Time             `json:"date"`
+	Note          string                 `json:"note"`
+	Color         string                 `json:"color"`
+	Method        int8                   `json:"method"`
+	Amount        int64                  `json:"amount"`
+	StatusID      *uint                  `json:"status_id"`
+	TagIDs        string                 `json:"tag_ids"`
+	Details       map[string]interface{} `json:"details"`
+	Version       *uint                  `json:"version"`
+}
+
+type BatchCreatePurchaseInput struct {
+	Items        []AddPurchaseInput `json:"items" binding:"required,min=1,dive"`
+	AllOrNothing bool               `json:"all_or_nothing"`
+}
+
+// BulkPurchaseInput selects purchases by IDs or, when IDs is empty, by
+// Filter and applies one action to all of them.
+type BulkPurchaseInput struct {
+	IDs           []uint           `json:"ids"`
+	Filter        *PurchaseFindAll `json:"filter"`
+	Action        string           `json:"action" binding:"required,oneof=set_category add_tags remove_tags set_status delete"`
+	CategoryId    *uint            `json:"category_id"`
+	SubCategoryId *uint            `json:"sub_category_id"`
+	TagIDs        []uint           `json:"tag_ids"`
+	StatusID      *uint            `json:"status_id"`
+	AllOrNothing  bool             `json:"all_or_nothing"`
+}
+
+type BulkItemResult struct {
+	Index int    `json:"index"`
+	ID    uint   `json:"id,omitempty"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+	Code  string `json:"code,omitempty"`
+}
+
+type BulkResult struct {
+	Succeeded  int              `json:"succeeded"`
+	Failed     int              `json:"failed"`
+	RolledBack bool             `json:"rolled_back"`
+	Items      []BulkItemResult `json:"items"`
 }
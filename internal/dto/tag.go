@@ -6,6 +6,7 @@ type UpdateTagRequest struct {
 	ID       uint   `json:"id" binding:"required"`
 	Title    string `json:"title"`
 	StatusID uint   `json:"status_id" binding:"oneof=1 0"`
+	Version  *uint  `json:"version"`
 }
 
 type ListTagsInput struct {
@@ -44,17 +45,11 @@ type LoginReponse struct {
 	Message     string `json:"message"`
 	Token       string `json:"token"`
 	LevelManage int8   `json:"level_manage"`
-	UserId      uint   `json:"user_id`
+	UserId      uint   `json:"user_id"`
 }
 
 type GetResponse struct {
 	Message  string      `json:"message" `
 	Response interface{} `json:"response"`
 	Count    int64       `json:"count"`
-}
-type ErrorGetResponse struct {
-	Message  string      `json:"message" `
-	Response interface{} `json:"response"`
-	Count    int64       `json:"count"`
-	Err      interface{} `json:"err"`
 }
\ No newline at end of file
@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 type ListUsersInput struct {
 	ID          uint   `form:"id"`
 	UserName    string `form:"username"`
@@ -40,7 +42,73 @@ type RegisterRequest struct {
 	StatusID uint   `json:"status_id"`
 }
 
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+type ForgotPasswordRequest struct {
+	UserName string `json:"username" binding:"required"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
 type LogoutInput struct {
 	Token  string `json:"token"`
 	UserId *uint  `json:"user_id"`
 }
+
+// LoginResult is a session token or, when the user has two-factor
+// authentication on, a challenge to exchange at /auth/login/2fa together
+// with a code.
+type LoginResult struct {
+	Token             string `json:"token,omitempty"`
+	LevelManage       int8   `json:"levelManage,omitempty"`
+	UserId            uint   `json:"userId,omitempty"`
+	TwoFactorRequired bool   `json:"twoFactorRequired,omitempty"`
+	Challenge         string `json:"challenge,omitempty"`
+	// ExpiresIn is the lifetime of the challenge in seconds
+	ExpiresIn int `json:"expiresIn,omitempty"`
+}
+
+type TwoFactorLoginRequest struct {
+	Challenge string `json:"challenge" binding:"required"`
+	// Code is a TOTP code or a recovery code
+	Code string `json:"code" binding:"required"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type TOTPEnrollment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// RecoveryCodes are shown once; only their hashes are kept.
+type RecoveryCodes struct {
+	Codes []string `json:"recovery_codes"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+	// Scope is read (the default) or write
+	Scope string `json:"scope" binding:"omitempty,oneof=read write"`
+	// ExpiresAt is optional; a key without it does not expire
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// APIKeyCreated is the new key, shown this once; only its hash is kept.
+type APIKeyCreated struct {
+	ID        uint       `json:"id"`
+	Name      string     `json:"name"`
+	Prefix    string     `json:"prefix"`
+	Scope     string     `json:"scope"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	Key       string     `json:"key"`
+}
@@ -0,0 +1,34 @@
+package dto
+
+import "time"
+
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" binding:"required,max=2048"`
+	Events      []string `json:"events" binding:"required,min=1"`
+	Description string   `json:"description" binding:"max=255"`
+}
+
+// UpdateWebhookRequest changes the members that are present.
+type UpdateWebhookRequest struct {
+	URL         *string  `json:"url" binding:"omitempty,max=2048"`
+	Events      []string `json:"events"`
+	Description *string  `json:"description" binding:"omitempty,max=255"`
+	Active      *bool    `json:"active"`
+}
+
+// WebhookCreated is the new subscription with its signing secret, shown
+// this once.
+type WebhookCreated struct {
+	ID          uint      `json:"id"`
+	URL         string    `json:"url"`
+	Events      []string  `json:"events"`
+	Description string    `json:"description"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	Secret      string    `json:"secret"`
+}
+
+type WebhookDeliveriesInput struct {
+	Start int `form:"start"`
+	Limit int `form:"limit"`
+}
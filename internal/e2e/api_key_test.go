@@ -0,0 +1,41 @@
+package e2e
+
+import (
+	"fmt"
+	"money-tracker/internal/constants"
+	"money-tracker/internal/dto"
+	"money-tracker/internal/usecase"
+	"net/http"
+	"testing"
+)
+
+func TestAPIKeys(t *testing.T) {
+	s := newServer(t)
+	s.asAdmin()
+	session := s.token
+
+	var read, write dto.APIKeyCreated
+	s.post("/api/v0/account/api-keys", dto.CreateAPIKeyRequest{Name: "report"}).
+		expect(http.StatusCreated).into(t, &read)
+	s.post("/api/v0/account/api-keys", dto.CreateAPIKeyRequest{Name: "import", Scope: constants.ScopeWrite}).
+		expect(http.StatusCreated).into(t, &write)
+	if list := s.get("/api/v0/account/api-keys").expect(http.StatusOK); list.Count != 2 {
+		t.Fatalf("listed %d keys, want 2", list.Count)
+	}
+
+	// a read key reads, a write key writes too
+	s.token = read.Key
+	s.get("/api/v0/admin/users").expect(http.StatusOK)
+	s.post("/api/v0/admin/category", dto.CreateCategoryRequest{Title: "Food", StatusID: 1}).
+		expectError(http.StatusForbidden, usecase.CodeForbidden)
+	s.token = write.Key
+	s.post("/api/v0/admin/category", dto.CreateCategoryRequest{Title: "Food", StatusID: 1}).expect(http.StatusCreated)
+
+	// keys cannot manage the account, not even write keys
+	s.get("/api/v0/account/api-keys").expectError(http.StatusForbidden, usecase.CodeForbidden)
+
+	s.token = session
+	s.delete(fmt.Sprintf("/api/v0/account/api-keys/%d", read.ID)).expect(http.StatusOK)
+	s.token = read.Key
+	s.get("/api/v0/admin/users").expectError(http.StatusUnauthorized, usecase.CodeInvalidAPIKey)
+}
@@ -0,0 +1,125 @@
+package e2e
+
+import (
+	"money-tracker/internal/constants"
+	"money-tracker/internal/dto"
+	"money-tracker/internal/totp"
+	"money-tracker/internal/usecase"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestAuthSignupAndLogin(t *testing.T) {
+	s := newServer(t)
+
+	signup := dto.RegisterRequest{UserName: "alice", Password: "correct horse"}
+	s.post("/api/v0/auth/signup", signup).expect(http.StatusCreated)
+	s.post("/api/v0/auth/signup", signup).expectError(http.StatusConflict, usecase.CodeUserDuplicate)
+	s.post("/api/v0/auth/signup", dto.RegisterRequest{UserName: "bob"}).expectError(http.StatusBadRequest, usecase.CodeValidation)
+	s.post("/api/v0/auth/signup", dto.RegisterRequest{UserName: "bob", Password: "password1"}).expectError(http.StatusBadRequest, usecase.CodeValidation)
+
+	s.post("/api/v0/auth/login", dto.LoginRequest{UserName: "alice", Password: "wrong"}).
+		expectError(http.StatusUnauthorized, usecase.CodeInvalidCredentials)
+	s.post("/api/v0/auth/login", dto.LoginRequest{UserName: "nobody", Password: "secret"}).
+		expectError(http.StatusUnauthorized, usecase.CodeInvalidCredentials)
+
+	var res struct {
+		Token       string `json:"token"`
+		LevelManage int8   `json:"levelManage"`
+		UserID      uint   `json:"userId"`
+	}
+	s.post("/api/v0/auth/login", dto.LoginRequest{UserName: "alice", Password: "correct horse"}).
+		expect(http.StatusOK).into(t, &res)
+	if res.Token == "" || res.UserID == 0 || res.LevelManage != constants.LevelManageUser {
+		t.Fatalf("login returned %+v", res)
+	}
+}
+
+func TestAdminRoutesNeedAnAdmin(t *testing.T) {
+	s := newServer(t)
+	f := s.fixtures()
+	f.user("alice", "secret", constants.LevelManageUser)
+
+	s.get("/api/v0/admin/users").expectError(http.StatusUnauthorized, usecase.CodeUnauthorized)
+
+	s.token = "not-a-jwt"
+	s.get("/api/v0/admin/users").expectError(http.StatusUnauthorized, usecase.CodeUnauthorized)
+
+	s.token = s.login("alice", "secret")
+	s.get("/api/v0/admin/users").expectError(http.StatusForbidden, usecase.CodeForbidden)
+
+	s.asAdmin()
+	list := s.get("/api/v0/admin/users?limit=10").expect(http.StatusOK)
+	if list.Count != 2 {
+		t.Fatalf("count %d, want 2", list.Count)
+	}
+}
+
+func TestTwoFactorLogin(t *testing.T) {
+	s := newServer(t)
+	s.fixtures().user("alice", "secret", constants.LevelManageUser)
+	s.token = s.login("alice", "secret")
+
+	var enrollment dto.TOTPEnrollment
+	s.post("/api/v0/account/2fa/enroll", nil).expect(http.StatusOK).into(t, &enrollment)
+	code := func(offset int64) string {
+		c, err := totp.Code(enrollment.Secret, totp.Step(time.Now())+offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	var codes dto.RecoveryCodes
+	s.post("/api/v0/account/2fa/activate", dto.TwoFactorCodeRequest{Code: code(-1)}).
+		expect(http.StatusOK).into(t, &codes)
+	if len(codes.Codes) == 0 {
+		t.Fatal("no recovery codes")
+	}
+
+	s.token = ""
+	var step dto.LoginResult
+	s.post("/api/v0/auth/login", dto.LoginRequest{UserName: "alice", Password: "secret"}).
+		expect(http.StatusOK).into(t, &step)
+	if !step.TwoFactorRequired || step.Token != "" {
+		t.Fatalf("login returned %+v", step)
+	}
+	// the challenge is no session token
+	s.token = step.Challenge
+	s.post("/api/v0/account/2fa/enroll", nil).expectError(http.StatusUnauthorized, usecase.CodeUnauthorized)
+	s.token = ""
+
+	s.post("/api/v0/auth/login/2fa", dto.TwoFactorLoginRequest{Challenge: step.Challenge, Code: "000000x"}).
+		expectError(http.StatusUnauthorized, usecase.CodeInvalidTwoFactor)
+	var res dto.LoginResult
+	s.post("/api/v0/auth/login/2fa", dto.TwoFactorLoginRequest{Challenge: step.Challenge, Code: codes.Codes[0]}).
+		expect(http.StatusOK).into(t, &res)
+	if res.Token == "" {
+		t.Fatalf("second step returned %+v", res)
+	}
+}
+
+func TestChangePasswordEndsSessions(t *testing.T) {
+	s := newServer(t)
+	s.fixtures().user("alice", "secret", constants.LevelManageUser)
+	other := s.login("alice", "secret")
+	s.token = s.login("alice", "secret")
+
+	s.post("/api/v0/account/password", dto.ChangePasswordRequest{CurrentPassword: "wrong", NewPassword: "correct horse"}).
+		expectError(http.StatusBadRequest, usecase.CodeValidation)
+	s.post("/api/v0/account/password", dto.ChangePasswordRequest{CurrentPassword: "secret", NewPassword: "correct horse"}).
+		expect(http.StatusOK)
+
+	s.post("/api/v0/account/2fa/enroll", nil).expectError(http.StatusUnauthorized, usecase.CodeUnauthorized)
+	s.token = other
+	s.post("/api/v0/account/2fa/enroll", nil).expectError(http.StatusUnauthorized, usecase.CodeUnauthorized)
+
+	s.token = ""
+	s.post("/api/v0/auth/login", dto.LoginRequest{UserName: "alice", Password: "secret"}).
+		expectError(http.StatusUnauthorized, usecase.CodeInvalidCredentials)
+	s.token = s.login("alice", "correct horse")
+
+	s.post("/api/v0/auth/password/forgot", dto.ForgotPasswordRequest{UserName: "nobody"}).expect(http.StatusAccepted)
+	s.post("/api/v0/auth/password/reset", dto.ResetPasswordRequest{Token: "made-up", NewPassword: "another good one"}).
+		expectError(http.StatusBadRequest, usecase.CodeInvalidResetToken)
+}
@@ -0,0 +1,63 @@
+package e2e
+
+import (
+	"fmt"
+	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
+	"money-tracker/internal/usecase"
+	"net/http"
+	"testing"
+)
+
+func TestCategoryFlow(t *testing.T) {
+	s := newServer(t)
+	s.asAdmin()
+
+	var food entity.Category
+	s.post("/api/v0/admin/category", dto.CreateCategoryRequest{Title: "Eating Out", StatusID: 1}).
+		expect(http.StatusCreated).into(t, &food)
+	if food.ID == 0 || food.Slug != "eating-out" || food.Version != 1 {
+		t.Fatalf("created %+v", food)
+	}
+	s.post("/api/v0/system/category", dto.CreateCategoryRequest{Title: "Eating Out", StatusID: 1}).
+		expectError(http.StatusConflict, usecase.CodeCategoryDuplicate)
+
+	list := s.get("/api/v0/system/category").expect(http.StatusOK)
+	var categories []dto.CategoryResponse
+	list.into(t, &categories)
+	if list.Count != 1 || len(categories) != 1 || categories[0].ID != food.ID {
+		t.Fatalf("listed %d: %+v", list.Count, categories)
+	}
+
+	// optimistic locking: the second writer of version 1 loses
+	version := food.Version
+	update := dto.UpdateCategoryRequest{ID: food.ID, Title: "Restaurants", StatusID: 1, Version: &version}
+	res := s.put("/api/v0/system/category", update)
+	var updated entity.Category
+	res.expect(http.StatusOK).into(t, &updated)
+	if updated.Title != "Restaurants" || updated.Version != 2 || res.Header.Get("ETag") != `"2"` {
+		t.Fatalf("updated %+v, etag %s", updated, res.Header.Get("ETag"))
+	}
+
+	update.Title = "Dining"
+	res = s.put("/api/v0/system/category", update)
+	var current entity.Category
+	res.expectError(http.StatusConflict, usecase.CodeVersionConflict).into(t, &current)
+	if current.Title != "Restaurants" || res.Header.Get("ETag") != `"2"` {
+		t.Fatalf("conflict returned %+v, etag %s", current, res.Header.Get("ETag"))
+	}
+
+	// If-Match works like the version member
+	update.Version = nil
+	s.put("/api/v0/system/category", update, "If-Match", `"2"`).expect(http.StatusOK)
+	// and one of them is required
+	s.put("/api/v0/system/category", update).expectError(http.StatusPreconditionRequired, usecase.CodeVersionRequired)
+
+	s.delete(fmt.Sprintf("/api/v0/system/category/%d", food.ID)).expect(http.StatusOK)
+	s.delete(fmt.Sprintf("/api/v0/system/category/%d", food.ID)).expectError(http.StatusNotFound, usecase.CodeCategoryNotFound)
+	s.delete("/api/v0/system/category/abc").expectError(http.StatusBadRequest, usecase.CodeValidation)
+
+	if list := s.get("/api/v0/system/category").expect(http.StatusOK); list.Count != 0 {
+		t.Fatalf("deleted category still listed, count %d", list.Count)
+	}
+}
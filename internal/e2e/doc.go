@@ -0,0 +1,4 @@
+// Package e2e holds the end-to-end tests of the API. They serve the real
+// router over HTTP on a migrated Postgres, see package testdb, and skip
+// when no Postgres is available.
+package e2e
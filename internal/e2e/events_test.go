@@ -0,0 +1,100 @@
+package e2e
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"money-tracker/internal/constants"
+	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sseEvent is one event read off a stream.
+type sseEvent struct {
+	id, event, data string
+}
+
+// openStream subscribes to the event stream and returns its events.
+func (s *server) openStream(t *testing.T) <-chan sseEvent {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url+"/api/v0/system/events", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusOK || !strings.HasPrefix(res.Header.Get("Content-Type"), "text/event-stream") {
+		t.Fatalf("stream answered %d %s", res.StatusCode, res.Header.Get("Content-Type"))
+	}
+
+	events := make(chan sseEvent, 16)
+	go func() {
+		defer res.Body.Close()
+		defer close(events)
+		var e sseEvent
+		scanner := bufio.NewScanner(res.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case line == "":
+				if e.event != "" {
+					events <- e
+				}
+				e = sseEvent{}
+			case strings.HasPrefix(line, "id:"):
+				e.id = strings.TrimSpace(line[3:])
+			case strings.HasPrefix(line, "event:"):
+				e.event = strings.TrimSpace(line[6:])
+			case strings.HasPrefix(line, "data:"):
+				e.data = strings.TrimSpace(line[5:])
+			}
+		}
+	}()
+	return events
+}
+
+func TestEventStream(t *testing.T) {
+	s := newServer(t)
+	f := s.fixtures()
+	food := f.category("Food")
+	f.user("alice", "secret", constants.LevelManageUser)
+
+	s.get("/api/v0/system/events").expect(http.StatusUnauthorized)
+
+	s.token = s.login("alice", "secret")
+	events := s.openStream(t)
+
+	var created entity.Purchase
+	s.post("/api/v0/system/purchase", dto.AddPurchaseInput{CategoryId: &food.ID, Amount: 450, Date: time.Now()}).
+		expect(http.StatusCreated).into(t, &created)
+	s.delete(fmt.Sprintf("/api/v0/system/purchase/%d", created.ID)).expect(http.StatusOK)
+
+	for _, want := range []string{"purchase.created", "purchase.deleted"} {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				t.Fatal("stream closed")
+			}
+			var p entity.Purchase
+			if err := json.Unmarshal([]byte(e.data), &p); err != nil {
+				t.Fatal(err)
+			}
+			if e.event != want || e.id == "" || p.ID != created.ID {
+				t.Fatalf("got %+v, want %s of purchase %d", e, want, created.ID)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no %s event", want)
+		}
+	}
+}
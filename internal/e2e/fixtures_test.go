@@ -0,0 +1,91 @@
+package e2e
+
+import (
+	"money-tracker/internal/constants"
+	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
+	"money-tracker/internal/repository"
+	"money-tracker/internal/utils"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// fixtures create rows straight through the repositories, for tests that
+// need data but do not test how it is made. Every factory takes optional
+// edits that run before the insert.
+type fixtures struct {
+	t *testing.T
+	s *server
+}
+
+func (s *server) fixtures() *fixtures {
+	return &fixtures{t: s.t, s: s}
+}
+
+func (f *fixtures) must(err error) {
+	f.t.Helper()
+	if err != nil {
+		f.t.Fatal(err)
+	}
+}
+
+func (f *fixtures) category(title string, edits ...func(c *entity.Category)) *entity.Category {
+	f.t.Helper()
+	c, err := entity.NewCategory(title, utils.GenerateSlugUnicode(title), constants.StatusActive, "")
+	f.must(err)
+	for _, edit := range edits {
+		edit(c)
+	}
+	f.must(repository.NewRepositoryGorm(f.s.db).Insert(c))
+	return c
+}
+
+func (f *fixtures) tag(title string, edits ...func(t *entity.Tag)) *entity.Tag {
+	f.t.Helper()
+	tag, err := entity.NewTag(title, constants.StatusActive)
+	f.must(err)
+	for _, edit := range edits {
+		edit(tag)
+	}
+	f.must(repository.NewTagRepoGorm(f.s.db).Insert(tag))
+	return tag
+}
+
+func (f *fixtures) purchase(category *entity.Category, amount int64, edits ...func(p *entity.Purchase)) *entity.Purchase {
+	f.t.Helper()
+	p, err := entity.NewPurchase(amount, time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC), &category.ID, constants.StatusActive)
+	f.must(err)
+	for _, edit := range edits {
+		edit(p)
+	}
+	f.must(repository.NewPurchaseRepo(f.s.db).Insert(p))
+	return p
+}
+
+func (f *fixtures) user(name string, password string, level int8) *entity.User {
+	f.t.Helper()
+	u, err := entity.NewUser(name, password, level, constants.StatusActive)
+	f.must(err)
+	f.must(repository.NewUserRepositoryGorm(f.s.db).Insert(u))
+	return u
+}
+
+// login logs in over the API and returns the token.
+func (s *server) login(name string, password string) string {
+	s.t.Helper()
+	var res struct {
+		Token string `json:"token"`
+	}
+	s.post("/api/v0/auth/login", dto.LoginRequest{UserName: name, Password: password}).
+		expect(http.StatusOK).into(s.t, &res)
+	return res.Token
+}
+
+// asAdmin creates an admin and sends its token from then on.
+func (s *server) asAdmin() *entity.User {
+	s.t.Helper()
+	admin := s.fixtures().user("admin", "admin-password", constants.LevelManageAdmin)
+	s.token = s.login("admin", "admin-password")
+	return admin
+}
@@ -0,0 +1,167 @@
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"money-tracker/internal/app"
+	"money-tracker/internal/config"
+	"money-tracker/internal/dto"
+	"money-tracker/internal/testdb"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const jwtSecret = "e2e-secret"
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	gin.DefaultWriter = io.Discard
+	testdb.Main(m)
+}
+
+// server is the API on an empty database.
+type server struct {
+	t   *testing.T
+	url string
+	db  *gorm.DB
+	// token is sent as the bearer token when set
+	token string
+}
+
+func newServer(t *testing.T) *server {
+	t.Helper()
+	db := testdb.Open(t)
+	testdb.Truncate(t, db)
+
+	cfg := config.Default()
+	cfg.Server.GinMode = gin.TestMode
+	cfg.Auth.JWTSecret = jwtSecret
+
+	a, err := app.NewWithDB(cfg, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(a.Router())
+	t.Cleanup(srv.Close)
+
+	return &server{t: t, url: srv.URL, db: db}
+}
+
+// response is a response with its body read.
+type response struct {
+	t      *testing.T
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// envelope is the JSON body every endpoint answers with.
+type envelope struct {
+	Message  string          `json:"message"`
+	Response json.RawMessage `json:"response"`
+	Count    int             `json:"count"`
+	Error    *dto.ErrorBody  `json:"error"`
+}
+
+// do sends body as JSON. headers are name, value pairs.
+func (s *server) do(method string, path string, body interface{}, headers ...string) *response {
+	s.t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			s.t.Fatal(err)
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequest(method, s.url+path, reader)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	return &response{t: s.t, Status: res.StatusCode, Header: res.Header, Body: data}
+}
+
+func (s *server) get(path string, headers ...string) *response {
+	s.t.Helper()
+	return s.do(http.MethodGet, path, nil, headers...)
+}
+
+func (s *server) post(path string, body interface{}, headers ...string) *response {
+	s.t.Helper()
+	return s.do(http.MethodPost, path, body, headers...)
+}
+
+func (s *server) put(path string, body interface{}, headers ...string) *response {
+	s.t.Helper()
+	return s.do(http.MethodPut, path, body, headers...)
+}
+
+func (s *server) patch(path string, body interface{}, headers ...string) *response {
+	s.t.Helper()
+	return s.do(http.MethodPatch, path, body, headers...)
+}
+
+func (s *server) delete(path string) *response {
+	s.t.Helper()
+	return s.do(http.MethodDelete, path, nil)
+}
+
+// expect fails the test unless the response has status, and returns the
+// decoded body.
+func (r *response) expect(status int) *envelope {
+	r.t.Helper()
+	if r.Status != status {
+		r.t.Fatalf("status %d, want %d: %s", r.Status, status, r.Body)
+	}
+
+	var e envelope
+	if err := json.Unmarshal(r.Body, &e); err != nil {
+		r.t.Fatalf("decoding %s: %v", r.Body, err)
+	}
+	return &e
+}
+
+// expectError checks the status and the error code.
+func (r *response) expectError(status int, code string) *envelope {
+	r.t.Helper()
+	e := r.expect(status)
+	if e.Error == nil || e.Error.Code != code {
+		r.t.Fatalf("error %+v, want code %q: %s", e.Error, code, r.Body)
+	}
+	return e
+}
+
+// into decodes the "response" member into v.
+func (e *envelope) into(t *testing.T, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(e.Response, v); err != nil {
+		t.Fatalf("decoding response %s: %v", e.Response, err)
+	}
+}
@@ -0,0 +1,100 @@
+package e2e
+
+import (
+	"fmt"
+	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
+	"money-tracker/internal/usecase"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestPurchaseFlow(t *testing.T) {
+	s := newServer(t)
+	s.asAdmin()
+	f := s.fixtures()
+	food := f.category("Food")
+	rent := f.category("Rent")
+	coffee := f.tag("coffee")
+	f.purchase(rent, 90000)
+
+	date := time.Date(2026, 10, 2, 8, 30, 0, 0, time.UTC)
+	var created entity.Purchase
+	s.post("/api/v0/system/purchase", dto.AddPurchaseInput{
+		CategoryId: &food.ID,
+		Amount:     450,
+		Date:       date,
+		Reason:     "latte",
+		TagIDs:     fmt.Sprint(coffee.ID),
+	}).expect(http.StatusCreated).into(t, &created)
+	if created.ID == 0 || created.Version != 1 {
+		t.Fatalf("created %+v", created)
+	}
+
+	missing := food.ID + 100
+	s.post("/api/v0/system/purchase", dto.AddPurchaseInput{CategoryId: &missing, Amount: 1, Date: date}).
+		expectError(http.StatusBadRequest, usecase.CodeValidation)
+	s.post("/api/v0/system/purchase", map[string]interface{}{"amount": 1}).
+		expectError(http.StatusBadRequest, usecase.CodeValidation)
+
+	list := s.get(fmt.Sprintf("/api/v0/system/purchase?category_id=%d", food.ID)).expect(http.StatusOK)
+	var purchases []dto.PurchaseResponse
+	list.into(t, &purchases)
+	if list.Count != 1 || len(purchases) != 1 {
+		t.Fatalf("listed %d: %+v", list.Count, purchases)
+	}
+	got := purchases[0]
+	if got.ID != created.ID || got.Amount != 450 || !got.Date.Equal(date) {
+		t.Fatalf("listed %+v", got)
+	}
+	if got.Category == nil || got.Category.ID != food.ID || len(got.Tags) != 1 || got.Tags[0].ID != coffee.ID {
+		t.Fatalf("listed category %+v, tags %+v", got.Category, got.Tags)
+	}
+
+	// a merge patch changes only the members it has
+	var patched entity.Purchase
+	s.patch(fmt.Sprintf("/api/v0/system/purchase/%d", created.ID), map[string]interface{}{"note": "with oat milk", "version": 1}).
+		expect(http.StatusOK).into(t, &patched)
+	if patched.Note != "with oat milk" || patched.Reason != "latte" || patched.Amount != 450 || patched.Version != 2 {
+		t.Fatalf("patched %+v", patched)
+	}
+	s.patch(fmt.Sprintf("/api/v0/system/purchase/%d", created.ID), map[string]interface{}{"amount": 500}, "If-Match", `"1"`).
+		expectError(http.StatusConflict, usecase.CodeVersionConflict)
+	s.patch(fmt.Sprintf("/api/v0/system/purchase/%d", created.ID), map[string]interface{}{"amount": 500}).
+		expectError(http.StatusPreconditionRequired, usecase.CodeVersionRequired)
+
+	s.delete(fmt.Sprintf("/api/v0/system/purchase/%d", created.ID)).expect(http.StatusOK)
+	s.delete(fmt.Sprintf("/api/v0/system/purchase/%d", created.ID)).expectError(http.StatusNotFound, usecase.CodePurchaseNotFound)
+
+	if list := s.get("/api/v0/system/purchase").expect(http.StatusOK); list.Count != 1 {
+		t.Fatalf("count %d after delete, want 1", list.Count)
+	}
+}
+
+func TestPurchaseBatchAllOrNothing(t *testing.T) {
+	s := newServer(t)
+	food := s.fixtures().category("Food")
+	missing := food.ID + 100
+	date := time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC)
+
+	s.post("/api/v0/system/purchase/batch", dto.BatchCreatePurchaseInput{Items: []dto.AddPurchaseInput{{CategoryId: &food.ID, Amount: 100, Date: date}}}).
+		expectError(http.StatusUnauthorized, usecase.CodeUnauthorized)
+	s.asAdmin()
+
+	var result dto.BulkResult
+	s.post("/api/v0/system/purchase/batch", dto.BatchCreatePurchaseInput{
+		AllOrNothing: true,
+		Items: []dto.AddPurchaseInput{
+			{CategoryId: &food.ID, Amount: 100, Date: date},
+			{CategoryId: &missing, Amount: 200, Date: date},
+		},
+	}).expect(http.StatusOK).into(t, &result)
+	if !result.RolledBack || result.Succeeded != 0 {
+		t.Fatalf("batch result %+v", result)
+	}
+
+	if list := s.get("/api/v0/system/purchase").expect(http.StatusOK); list.Count != 0 {
+		t.Fatalf("rolled back batch left %d purchases", list.Count)
+	}
+}
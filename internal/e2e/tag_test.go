@@ -0,0 +1,73 @@
+package e2e
+
+import (
+	"fmt"
+	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
+	"money-tracker/internal/middleware"
+	"money-tracker/internal/usecase"
+	"net/http"
+	"testing"
+)
+
+func TestTagFlow(t *testing.T) {
+	s := newServer(t)
+	f := s.fixtures()
+	f.tag("work")
+
+	var coffee entity.Tag
+	s.post("/api/v0/system/tag", dto.CreateTagRequest{Title: "coffee", StatusID: 1}).
+		expect(http.StatusCreated).into(t, &coffee)
+	s.post("/api/v0/system/tag", dto.CreateTagRequest{Title: "coffee", StatusID: 1}).
+		expectError(http.StatusConflict, usecase.CodeTagDuplicate)
+	s.post("/api/v0/system/tag", map[string]interface{}{"status_id": 1}).
+		expectError(http.StatusBadRequest, usecase.CodeValidation)
+
+	list := s.get("/api/v0/system/tag?title=cof").expect(http.StatusOK)
+	var tags []entity.Tag
+	list.into(t, &tags)
+	if list.Count != 1 || len(tags) != 1 || tags[0].ID != coffee.ID {
+		t.Fatalf("listed %d: %+v", list.Count, tags)
+	}
+
+	version := coffee.Version
+	s.put("/api/v0/system/tag", dto.UpdateTagRequest{ID: coffee.ID, Title: "espresso", Version: &version}).
+		expect(http.StatusOK)
+	s.put("/api/v0/system/tag", dto.UpdateTagRequest{ID: coffee.ID, Title: "latte", Version: &version}).
+		expectError(http.StatusConflict, usecase.CodeVersionConflict)
+	s.put("/api/v0/system/tag", dto.UpdateTagRequest{ID: coffee.ID, Title: "latte"}).
+		expectError(http.StatusPreconditionRequired, usecase.CodeVersionRequired)
+	s.put("/api/v0/system/tag", dto.UpdateTagRequest{ID: coffee.ID + 100, Title: "latte", Version: &version}).
+		expectError(http.StatusNotFound, usecase.CodeTagNotFound)
+
+	s.delete(fmt.Sprintf("/api/v0/system/tag/%d", coffee.ID)).expect(http.StatusOK)
+	if list := s.get("/api/v0/system/tag").expect(http.StatusOK); list.Count != 1 {
+		t.Fatalf("count %d after delete, want 1", list.Count)
+	}
+}
+
+func TestTagCreateIsIdempotent(t *testing.T) {
+	s := newServer(t)
+	key := []string{middleware.IdempotencyHeader, "tag-1"}
+
+	var first, replay entity.Tag
+	s.post("/api/v0/system/tag", dto.CreateTagRequest{Title: "coffee"}, key...).
+		expect(http.StatusCreated).into(t, &first)
+
+	res := s.post("/api/v0/system/tag", dto.CreateTagRequest{Title: "coffee"}, key...)
+	res.expect(http.StatusCreated).into(t, &replay)
+	if replay.ID != first.ID || res.Header.Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("retry created %+v, replayed %q", replay, res.Header.Get("Idempotent-Replayed"))
+	}
+
+	s.post("/api/v0/system/tag", dto.CreateTagRequest{Title: "tea"}, key...).
+		expectError(http.StatusUnprocessableEntity, usecase.CodeIdempotencyReused)
+
+	// another client picking the same key gets its own request
+	var other entity.Tag
+	res = s.post("/api/v0/system/tag", dto.CreateTagRequest{Title: "tea"}, append(key, "Authorization", "Bearer other")...)
+	res.expect(http.StatusCreated).into(t, &other)
+	if other.ID == first.ID || res.Header.Get("Idempotent-Replayed") != "" {
+		t.Fatalf("other client got %+v, replayed %q", other, res.Header.Get("Idempotent-Replayed"))
+	}
+}
@@ -0,0 +1,64 @@
+package entity
+
+import (
+	"errors"
+	"money-tracker/internal/constants"
+	"time"
+)
+
+// APIKey lets a script act as its user without a password. Only the SHA-256
+// of the key is stored; Prefix is the start of the key, to tell keys apart.
+type APIKey struct {
+	ID         uint       `json:"id"`
+	UserID     uint       `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Scope      string     `json:"scope"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func NewAPIKey(user_id uint, name string, scope string, prefix string, key_hash string, expires_at *time.Time) (*APIKey, error) {
+	if user_id == 0 || name == "" || key_hash == "" {
+		return nil, errors.New("user_id, name and key hash are required")
+	}
+	if scope != constants.ScopeRead && scope != constants.ScopeWrite {
+		return nil, errors.New("scope must be read or write")
+	}
+	if expires_at != nil && !expires_at.After(time.Now()) {
+		return nil, errors.New("expires_at must be in the future")
+	}
+
+	return &APIKey{
+		UserID:    user_id,
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   key_hash,
+		Scope:     scope,
+		ExpiresAt: expires_at,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// Expired reports whether the key has expired at now.
+func (k *APIKey) Expired(now time.Time) bool {
+	return k.ExpiresAt != nil && !k.ExpiresAt.After(now)
+}
+
+// CanWrite reports whether the key may change data.
+func (k *APIKey) CanWrite() bool {
+	return k.Scope == constants.ScopeWrite
+}
+
+type APIKeyRepository interface {
+	Insert(key *APIKey) error
+	FindByHash(key_hash string) (*APIKey, error)
+	// FindByUser returns the keys of the user, newest first.
+	FindByUser(user_id uint) ([]APIKey, error)
+	// Delete removes a key of the user; gorm.ErrRecordNotFound when the user
+	// has no such key.
+	Delete(user_id uint, id uint) error
+	Touch(id uint, at time.Time) error
+}
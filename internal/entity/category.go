@@ -13,6 +13,7 @@ type Category struct {
 	StatusID  uint      `json:"status_id"`
 	Slug      string    `json:"slug"`
 	Color     string    `json:"color"`
+	Version   uint      `json:"version"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 	DeletedAt time.Time `json:"deleted_at,omitempty"`
@@ -28,10 +29,15 @@ func NewCategory(title string, slug string, status_id uint, color string) (*Cate
 		StatusID:  status_id,
 		Slug:      slug,
 		Color:     color,
+		Version:   1,
 		CreatedAt: time.Now(),
 	}, nil
 }
 
+// ErrVersionConflict is returned by the Update method of a repository when the
+// row was changed since the given version was read.
+var ErrVersionConflict = errors.New("version conflict")
+
 type CategoryRepository interface {
 	Insert(category *Category) error
 	FindById(id uint) (*Category, error)
@@ -0,0 +1,96 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Goal is a savings goal: TargetAmount saved by Deadline. Account
+// optionally names the account the savings are kept in, the way the bank
+// statement importer names accounts.
+type Goal struct {
+	ID           uint      `json:"id"`
+	Title        string    `json:"title"`
+	TargetAmount int64     `json:"target_amount"`
+	Deadline     time.Time `json:"deadline"`
+	Account      string    `json:"account"`
+	Note         string    `json:"note"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func NewGoal(title string, target int64, deadline time.Time, account string) (*Goal, error) {
+	g := &Goal{
+		Account:   strings.TrimSpace(account),
+		CreatedAt: time.Now(),
+	}
+	if err := g.Set(title, target, deadline); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Set changes the title, target and deadline together, so a goal is never
+// left half valid.
+func (g *Goal) Set(title string, target int64, deadline time.Time) error {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return errors.New("title is required")
+	}
+	if target <= 0 {
+		return errors.New("target_amount must be positive")
+	}
+	if deadline.IsZero() {
+		return errors.New("deadline is required")
+	}
+	g.Title = title
+	g.TargetAmount = target
+	g.Deadline = deadline
+	return nil
+}
+
+// GoalContribution is money put towards a goal, or taken out of it when
+// Amount is negative.
+type GoalContribution struct {
+	ID        uint      `json:"id"`
+	GoalID    uint      `json:"goal_id"`
+	Amount    int64     `json:"amount"`
+	Date      time.Time `json:"date"`
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewGoalContribution(goalID uint, amount int64, date time.Time, note string) (*GoalContribution, error) {
+	if amount == 0 {
+		return nil, errors.New("amount is required")
+	}
+	if date.IsZero() {
+		return nil, errors.New("date is required")
+	}
+	return &GoalContribution{
+		GoalID:    goalID,
+		Amount:    amount,
+		Date:      date,
+		Note:      strings.TrimSpace(note),
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+type GoalRepository interface {
+	Insert(goal *Goal) error
+	Update(goal *Goal) error
+	// Delete removes the goal with its contributions.
+	Delete(id uint) error
+	FindById(id uint) (*Goal, error)
+	// FindAll returns the goals by deadline, soonest first.
+	FindAll() ([]Goal, error)
+}
+
+type GoalContributionRepository interface {
+	Insert(c *GoalContribution) error
+	// Delete removes a contribution of the goal.
+	Delete(goal_id uint, id uint) error
+	// FindByGoal returns the contributions of a goal by date, oldest first.
+	FindByGoal(goal_id uint) ([]GoalContribution, error)
+}
@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// IdempotencyKey remembers the response to a request sent with an
+// Idempotency-Key header, so that a retry gets the same response instead of
+// repeating the request. Scope is the method and route the key was used on
+// and the client that sent it.
+type IdempotencyKey struct {
+	ID          uint      `json:"id"`
+	Key         string    `json:"key"`
+	Scope       string    `json:"scope"`
+	RequestHash string    `json:"request_hash"`
+	Completed   bool      `json:"completed"`
+	StatusCode  int       `json:"status_code"`
+	Response    []byte    `json:"response"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func NewIdempotencyKey(key string, scope string, request_hash string, ttl time.Duration) (*IdempotencyKey, error) {
+	if key == "" || scope == "" {
+		return nil, errors.New("key and scope are required")
+	}
+
+	now := time.Now()
+	return &IdempotencyKey{
+		Key:         key,
+		Scope:       scope,
+		RequestHash: request_hash,
+		ExpiresAt:   now.Add(ttl),
+		CreatedAt:   now,
+	}, nil
+}
+
+type IdempotencyRepository interface {
+	// Reserve inserts the key unless it is already stored for the scope and
+	// reports whether it did.
+	Reserve(key *IdempotencyKey) (bool, error)
+	Find(key string, scope string) (*IdempotencyKey, error)
+	Complete(id uint, status_code int, response []byte) error
+	Delete(id uint) error
+	DeleteExpired(now time.Time) error
+}
@@ -0,0 +1,192 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	LoanBorrowed = "borrowed"
+	LoanLent     = "lent"
+)
+
+// Frequencies of loan payments and recurring items.
+const (
+	FrequencyWeekly    = "weekly"
+	FrequencyBiweekly  = "biweekly"
+	FrequencyMonthly   = "monthly"
+	FrequencyQuarterly = "quarterly"
+	FrequencyYearly    = "yearly"
+)
+
+// maxLoanTerm is 100 years of monthly payments.
+const maxLoanTerm = 1200
+
+// Loan is money borrowed, or lent to someone when Direction is LoanLent.
+// Interest is charged once per period on the balance at InterestRate / the
+// periods in a year; a personal IOU is a loan at zero interest. The first
+// of the Term payments is due one period after StartDate.
+type Loan struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	Direction string `json:"direction"`
+	Principal int64  `json:"principal"`
+	// InterestRate is the nominal annual rate in percent
+	InterestRate float64   `json:"interest_rate"`
+	Term         int       `json:"term"`
+	Frequency    string    `json:"frequency"`
+	StartDate    time.Time `json:"start_date"`
+	Note         string    `json:"note"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func NewLoan(name string, direction string, principal int64, rate float64, term int, frequency string, start time.Time) (*Loan, error) {
+	l := &Loan{CreatedAt: time.Now()}
+	if err := l.SetName(name, direction); err != nil {
+		return nil, err
+	}
+	if err := l.SetTerms(principal, rate, term, frequency, start); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *Loan) SetName(name string, direction string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("name is required")
+	}
+	if direction == "" {
+		direction = LoanBorrowed
+	}
+	if direction != LoanBorrowed && direction != LoanLent {
+		return errors.New("direction must be borrowed or lent")
+	}
+	l.Name = name
+	l.Direction = direction
+	return nil
+}
+
+// SetTerms changes the terms together, so a loan is never left half valid.
+func (l *Loan) SetTerms(principal int64, rate float64, term int, frequency string, start time.Time) error {
+	if principal <= 0 {
+		return errors.New("principal must be positive")
+	}
+	if rate < 0 || rate > 100 {
+		return errors.New("interest_rate must be between 0 and 100")
+	}
+	if term < 1 || term > maxLoanTerm {
+		return errors.New("term must be between 1 and 1200 payments")
+	}
+	if !ValidFrequency(frequency) {
+		return errors.New("frequency must be weekly, biweekly, monthly, quarterly or yearly")
+	}
+	if start.IsZero() {
+		return errors.New("start_date is required")
+	}
+	l.Principal = principal
+	l.InterestRate = rate
+	l.Term = term
+	l.Frequency = frequency
+	l.StartDate = start
+	return nil
+}
+
+// PeriodRate is the interest charged per period as a fraction.
+func (l *Loan) PeriodRate() float64 {
+	return l.InterestRate / 100 / float64(periodsPerYear(l.Frequency))
+}
+
+// DueDate is the date of the n-th payment, counting from 1.
+func (l *Loan) DueDate(n int) time.Time {
+	return Occurrence(l.StartDate, l.Frequency, n)
+}
+
+// DuesBy counts the payments due on or before t, at most Term.
+func (l *Loan) DuesBy(t time.Time) int {
+	n := 0
+	for n < l.Term && !l.DueDate(n+1).After(t) {
+		n++
+	}
+	return n
+}
+
+// Occurrence is the date n periods of frequency after start. Monthly dates
+// keep the day of start, or the last day of a shorter month.
+func Occurrence(start time.Time, frequency string, n int) time.Time {
+	switch frequency {
+	case FrequencyWeekly:
+		return start.AddDate(0, 0, 7*n)
+	case FrequencyBiweekly:
+		return start.AddDate(0, 0, 14*n)
+	case FrequencyQuarterly:
+		return addMonths(start, 3*n)
+	case FrequencyYearly:
+		return addMonths(start, 12*n)
+	default:
+		return addMonths(start, n)
+	}
+}
+
+// ValidFrequency tells whether frequency is one of the Frequency constants.
+func ValidFrequency(frequency string) bool {
+	return periodsPerYear(frequency) > 0
+}
+
+func periodsPerYear(frequency string) int {
+	switch frequency {
+	case FrequencyWeekly:
+		return 52
+	case FrequencyBiweekly:
+		return 26
+	case FrequencyMonthly:
+		return 12
+	case FrequencyQuarterly:
+		return 4
+	case FrequencyYearly:
+		return 1
+	}
+	return 0
+}
+
+func addMonths(t time.Time, months int) time.Time {
+	y, m, d := t.Date()
+	first := time.Date(y, m+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	if last := first.AddDate(0, 1, -1).Day(); d > last {
+		d = last
+	}
+	return first.AddDate(0, 0, d-1)
+}
+
+// LoanPayment is a payment on a loan, split into the interest charged since
+// the payment before and the principal it paid off.
+type LoanPayment struct {
+	ID        uint      `json:"id"`
+	LoanID    uint      `json:"loan_id"`
+	Date      time.Time `json:"date"`
+	Amount    int64     `json:"amount"`
+	Principal int64     `json:"principal"`
+	Interest  int64     `json:"interest"`
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type LoanRepository interface {
+	Insert(loan *Loan) error
+	Update(loan *Loan) error
+	// Delete removes the loan with its payments.
+	Delete(id uint) error
+	FindById(id uint) (*Loan, error)
+	// FindAll returns the loans by name.
+	FindAll() ([]Loan, error)
+}
+
+type LoanPaymentRepository interface {
+	Insert(p *LoanPayment) error
+	// Delete removes a payment of the loan.
+	Delete(loan_id uint, id uint) error
+	// FindByLoan returns the payments of a loan by date, oldest first.
+	FindByLoan(loan_id uint) ([]LoanPayment, error)
+}
@@ -0,0 +1,40 @@
+package entity
+
+import "time"
+
+// Kinds of spending anomalies.
+const (
+	AnomalyLargePurchase = "large_purchase"
+	AnomalyCategorySpike = "category_spike"
+	AnomalyNewMerchant   = "new_merchant"
+)
+
+// Notification tells about something that needs a look, e.g. an unusual
+// purchase. Key identifies what it is about, so the same finding is stored
+// once however often it is found.
+type Notification struct {
+	ID         uint   `json:"id"`
+	Kind       string `json:"kind"`
+	Key        string `json:"-"`
+	Title      string `json:"title"`
+	Body       string `json:"body"`
+	PurchaseID *uint  `json:"purchase_id"`
+	CategoryID *uint  `json:"category_id"`
+	Amount     int64  `json:"amount"`
+	// Baseline is the typical amount Amount was compared with
+	Baseline  int64      `json:"baseline"`
+	Score     float64    `json:"score"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type NotificationRepository interface {
+	// Insert stores the notification unless one with the same Key is
+	// stored, and reports whether it did.
+	Insert(n *Notification) (bool, error)
+	// FindAll returns the notifications newest first, only the unread ones
+	// when unread is set, with their count.
+	FindAll(unread bool, start int, limit int) ([]Notification, int, error)
+	MarkRead(id uint, at time.Time) error
+	Delete(id uint) error
+}
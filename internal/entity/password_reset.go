@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// PasswordResetToken lets a user set a new password without the old one.
+// Only the SHA-256 of the token is stored; it works once, until ExpiresAt.
+type PasswordResetToken struct {
+	ID        uint       `json:"id"`
+	UserID    uint       `json:"user_id"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func NewPasswordResetToken(user_id uint, token_hash string, expires_at time.Time) (*PasswordResetToken, error) {
+	if user_id == 0 || token_hash == "" {
+		return nil, errors.New("user_id and token hash are required")
+	}
+
+	return &PasswordResetToken{
+		UserID:    user_id,
+		TokenHash: token_hash,
+		ExpiresAt: expires_at,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+type PasswordResetRepository interface {
+	Insert(token *PasswordResetToken) error
+	// Find returns the token with the hash if it is unused and not expired
+	// at now, without using it. Otherwise it returns gorm.ErrRecordNotFound.
+	Find(token_hash string, now time.Time) (*PasswordResetToken, error)
+	// Use marks the token with the hash as used, if it is unused and not
+	// expired at now, and returns it. Otherwise it returns
+	// gorm.ErrRecordNotFound.
+	Use(token_hash string, now time.Time) (*PasswordResetToken, error)
+	DeleteByUser(user_id uint) error
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"money-tracker/internal/constants"
 	"money-tracker/internal/dto"
+	"strings"
 	"time"
 )
 
@@ -22,6 +23,8 @@ type Purchase struct {
 	SubCategoryId *uint             `json:"sub_category_id"`
 	SubCategory   *Category         `json:"sub_category"`
 	Details       constants.JSONMap `json:"details"`
+	ExternalID    string            `json:"external_id,omitempty"`
+	Version       uint              `json:"version"`
 	CreatedAt     time.Time         `json:"created_at"`
 	UpdatedAt     time.Time         `json:"updated_at"`
 	DeletedAt     time.Time         `json:"deleted_at,omitempty"`
@@ -29,7 +32,7 @@ type Purchase struct {
 
 func NewPurchase(amount int64, date time.Time, category_id *uint, status_id uint) (*Purchase, error) {
 	if amount == 0 || category_id == nil {
-		return nil, errors.New("title is required")
+		return nil, errors.New("amount and category are required")
 	}
 
 	return &Purchase{
@@ -37,6 +40,7 @@ func NewPurchase(amount int64, date time.Time, category_id *uint, status_id uint
 		StatusID:   status_id,
 		Date:       date,
 		CategoryId: category_id,
+		Version:    1,
 		CreatedAt:  time.Now(),
 	}, nil
 }
@@ -45,7 +49,17 @@ type PurchaseRepository interface {
 	Insert(purchase *Purchase) error
 	FindById(id uint, status_id []uint) (*Purchase, error)
 	FindAll(input dto.PurchaseFindAll) ([]Purchase, int, error)
+	FindByExternalIDs(ids []string) ([]Purchase, error)
+	// HasMerchant tells whether an active purchase other than except,
+	// dated on or before date, has a reason with the MerchantKey merchant.
+	HasMerchant(merchant string, date time.Time, except uint) (bool, error)
 	Update(p *Purchase) (*Purchase, error)
 	Delete(id uint) error
 	// Search(input dto.SearchWithTagInput) ([]dto.FetchedSearchCategory, int64, error)
 }
+
+// MerchantKey is how reasons are compared to find a merchant: lower case
+// without surrounding spaces, like the purchases index on lower(btrim(reason)).
+func MerchantKey(reason string) string {
+	return strings.ToLower(strings.Trim(reason, " "))
+}
@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+// RecoveryCode is a one-time code that stands in for a TOTP code when the
+// authenticator is lost. Only the SHA-256 of the code is stored.
+type RecoveryCode struct {
+	ID        uint       `json:"id"`
+	UserID    uint       `json:"user_id"`
+	CodeHash  string     `json:"-"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type RecoveryCodeRepository interface {
+	// Replace deletes the codes of the user and stores new ones with the
+	// given hashes.
+	Replace(user_id uint, hashes []string) error
+	// Use marks the unused code with the hash as used and reports whether
+	// there was one.
+	Use(user_id uint, hash string) (bool, error)
+	// CountUnused returns how many codes the user has left.
+	CountUnused(user_id uint) (int, error)
+	DeleteAll(user_id uint) error
+}
@@ -0,0 +1,89 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// DefaultAccount stands in for an empty account name.
+const DefaultAccount = "main"
+
+// RecurringItem is money that comes in (Amount > 0) or goes out
+// (Amount < 0) on a schedule, e.g. a salary or the rent. The first
+// occurrence is on StartDate and the last on or before EndDate, if set.
+// CategoryID ties an expense to the category its purchases are recorded
+// in, so the forecast does not count them twice.
+type RecurringItem struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Amount     int64      `json:"amount"`
+	Frequency  string     `json:"frequency"`
+	StartDate  time.Time  `json:"start_date"`
+	EndDate    *time.Time `json:"end_date"`
+	Account    string     `json:"account"`
+	CategoryID *uint      `json:"category_id"`
+	Note       string     `json:"note"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+func NewRecurringItem(name string, amount int64, frequency string, start time.Time, end *time.Time, account string) (*RecurringItem, error) {
+	r := &RecurringItem{CreatedAt: time.Now()}
+	if err := r.Set(name, amount, frequency, start, end, account); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *RecurringItem) Set(name string, amount int64, frequency string, start time.Time, end *time.Time, account string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("name is required")
+	}
+	if amount == 0 {
+		return errors.New("amount is required")
+	}
+	if !ValidFrequency(frequency) {
+		return errors.New("frequency must be weekly, biweekly, monthly, quarterly or yearly")
+	}
+	if start.IsZero() {
+		return errors.New("start_date is required")
+	}
+	if end != nil && end.Before(start) {
+		return errors.New("end_date is before start_date")
+	}
+	account = strings.TrimSpace(account)
+	if account == "" {
+		account = DefaultAccount
+	}
+	r.Name = name
+	r.Amount = amount
+	r.Frequency = frequency
+	r.StartDate = start
+	r.EndDate = end
+	r.Account = account
+	return nil
+}
+
+// Occurrences calls fn with the dates of the item in [from, to].
+func (r *RecurringItem) Occurrences(from time.Time, to time.Time, fn func(date time.Time)) {
+	for n := 0; ; n++ {
+		date := Occurrence(r.StartDate, r.Frequency, n)
+		if date.After(to) || (r.EndDate != nil && date.After(*r.EndDate)) {
+			return
+		}
+		if !date.Before(from) {
+			fn(date)
+		}
+	}
+}
+
+type RecurringItemRepository interface {
+	Insert(item *RecurringItem) error
+	Update(item *RecurringItem) error
+	Delete(id uint) error
+	FindById(id uint) (*RecurringItem, error)
+	// FindAll returns the items by name.
+	FindAll() ([]RecurringItem, error)
+}
@@ -9,6 +9,7 @@ type Tag struct {
 	ID        uint      `json:"id"`
 	Title     string    `json:"title"`
 	StatusID  uint      `json:"status_id"`
+	Version   uint      `json:"version"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"-"`
 	DeletedAt time.Time `json:"-"`
@@ -22,6 +23,7 @@ func NewTag(title string, status_id uint) (*Tag, error) {
 	return &Tag{
 		Title:     title,
 		StatusID:  status_id,
+		Version:   1,
 		CreatedAt: time.Now(),
 	}, nil
 }
@@ -34,4 +36,6 @@ type TagRepository interface {
 	FindAll(start int, limit int, orderBy string, sort string, id uint, status_id uint, title string) ([]Tag, int, error)
 	Delete(id uint) error
 	FindByIDs(ids []uint) ([]Tag, error)
+	// FindAllStatuses returns a page of the tags in every status by id.
+	FindAllStatuses(start int, limit int) ([]Tag, error)
 }
\ No newline at end of file
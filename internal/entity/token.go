@@ -30,4 +30,6 @@ type UserTokenRepository interface {
 	Insert(user_token *UserToken) error
 	FindByToken(token string, status_id []uint) (*UserToken, error)
 	Delete(id uint) error
+	// DeleteByUser ends every session of the user.
+	DeleteByUser(user_id uint) error
 }
@@ -0,0 +1,23 @@
+package entity
+
+// Repositories groups the repositories that share one database transaction.
+type Repositories struct {
+	Category CategoryRepository
+	Tag      TagRepository
+	Purchase PurchaseRepository
+	User     UserRepository
+	// Outbox queues webhook events with the change that caused them
+	Outbox OutboxRepository
+
+	// Tx runs a nested transaction (a savepoint) inside this one.
+	Tx Transactor
+}
+
+// Transactor runs fn in a transaction: it is committed when fn returns nil
+// and rolled back otherwise.
+type Transactor interface {
+	Transaction(fn func(repos Repositories) error) error
+	// Snapshot runs fn in a read-only transaction that sees the data as it
+	// was when it started, for reads that take several queries.
+	Snapshot(fn func(repos Repositories) error) error
+}
@@ -9,20 +9,29 @@ import (
 )
 
 type User struct {
-	ID          uint      `json:"id"`
-	UserName    string    `json:"username"`
-	Password    string    `json:"-"`
-	LevelManage int8      `json:"level_manage"`
-	StatusID    uint      `json:"status_id"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
-	DeletedAt   time.Time `json:"deleted_at,omitempty"`
+	ID          uint   `json:"id"`
+	UserName    string `json:"username"`
+	Password    string `json:"-"`
+	LevelManage int8   `json:"level_manage"`
+	StatusID    uint   `json:"status_id"`
+	// TOTPSecret is set on enrollment and used once TOTPEnabled is set by
+	// the first valid code. TOTPLastStep is the step of the last code
+	// accepted, so a code works only once.
+	TOTPSecret   string    `json:"-"`
+	TOTPEnabled  bool      `json:"-"`
+	TOTPLastStep int64     `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	DeletedAt    time.Time `json:"deleted_at,omitempty"`
 }
 
 func NewUser(user_name string, password string, level_manage int8, status_id uint) (*User, error) {
 	if user_name == "" || level_manage == 0 {
 		return nil, errors.New("name and level manage are required")
 	}
+	if password == "" {
+		return nil, errors.New("password is required")
+	}
 
 	hashedPassword, err := hashPassword(password)
 	if err != nil {
@@ -53,6 +62,20 @@ func (u *User) CheckPassword(password string) bool {
 	return err == nil
 }
 
+// SetPassword replaces the password hash. Checking the password against the
+// policy is up to the caller.
+func (u *User) SetPassword(password string) error {
+	if password == "" {
+		return errors.New("password is required")
+	}
+	hashed, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+	u.Password = hashed
+	return nil
+}
+
 func hashPassword(password string) (string, error) {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	return string(bytes), err
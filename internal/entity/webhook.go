@@ -0,0 +1,136 @@
+package entity
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Webhook is a subscription: the events in Events are posted to URL, signed
+// with Secret.
+type Webhook struct {
+	ID     uint   `json:"id"`
+	URL    string `json:"url"`
+	Secret string `json:"-"`
+	// Events is a comma separated list of event types
+	Events      string    `json:"events"`
+	Description string    `json:"description"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func NewWebhook(target string, secret string, events []string, description string) (*Webhook, error) {
+	w := &Webhook{
+		Secret:      secret,
+		Description: description,
+		Active:      true,
+		CreatedAt:   time.Now(),
+	}
+	if err := w.SetURL(target); err != nil {
+		return nil, err
+	}
+	if err := w.SetEvents(events); err != nil {
+		return nil, err
+	}
+	if secret == "" {
+		return nil, errors.New("secret is required")
+	}
+	return w, nil
+}
+
+// SetURL accepts absolute http and https URLs.
+func (w *Webhook) SetURL(target string) error {
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("url must be an absolute http or https URL")
+	}
+	w.URL = target
+	return nil
+}
+
+func (w *Webhook) SetEvents(events []string) error {
+	if len(events) == 0 {
+		return errors.New("events are required")
+	}
+	w.Events = strings.Join(events, ",")
+	return nil
+}
+
+func (w *Webhook) EventList() []string {
+	if w.Events == "" {
+		return nil
+	}
+	return strings.Split(w.Events, ",")
+}
+
+// Wants reports whether the webhook is subscribed to the event.
+func (w *Webhook) Wants(event string) bool {
+	for _, e := range w.EventList() {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// OutboxMessage is one event waiting to be posted to one webhook. It is
+// written in the transaction that made the change, so an event is sent if
+// and only if the change was committed.
+type OutboxMessage struct {
+	ID        uint   `json:"id"`
+	WebhookID uint   `json:"webhook_id"`
+	EventID   string `json:"event_id"`
+	EventType string `json:"event_type"`
+	Payload   []byte `json:"-"`
+	Attempts  int    `json:"attempts"`
+	// NextAttemptAt is when the message is due; a worker that claims it
+	// moves it forward, so no other worker picks it up meanwhile.
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	DeliveredAt   *time.Time `json:"delivered_at"`
+	FailedAt      *time.Time `json:"failed_at"`
+	LastError     string     `json:"last_error"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// WebhookDelivery records one attempt to post a message.
+type WebhookDelivery struct {
+	ID         uint   `json:"id"`
+	WebhookID  uint   `json:"webhook_id"`
+	MessageID  uint   `json:"message_id"`
+	EventID    string `json:"event_id"`
+	EventType  string `json:"event_type"`
+	Attempt    int    `json:"attempt"`
+	StatusCode int    `json:"status_code"`
+	// Error is why the attempt failed, empty when it succeeded
+	Error      string    `json:"error"`
+	Response   string    `json:"response"`
+	DurationMs int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type WebhookRepository interface {
+	Insert(webhook *Webhook) error
+	Update(webhook *Webhook) error
+	Delete(id uint) error
+	FindById(id uint) (*Webhook, error)
+	FindAll() ([]Webhook, error)
+	FindActive() ([]Webhook, error)
+}
+
+type OutboxRepository interface {
+	Insert(msg *OutboxMessage) error
+	// Claim returns up to limit messages due at now that are neither
+	// delivered nor failed, oldest first, and moves them to now+lease.
+	Claim(now time.Time, lease time.Duration, limit int) ([]OutboxMessage, error)
+	// Update saves the attempts, schedule and outcome of a message.
+	Update(msg *OutboxMessage) error
+}
+
+type WebhookDeliveryRepository interface {
+	Insert(delivery *WebhookDelivery) error
+	// FindByWebhook returns the deliveries of a webhook, newest first, and
+	// their total count.
+	FindByWebhook(webhook_id uint, start int, limit int) ([]WebhookDelivery, int, error)
+}
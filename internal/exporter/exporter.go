@@ -0,0 +1,47 @@
+package exporter
+
+import (
+	"errors"
+	"io"
+	"strings"
+)
+
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+	FormatXLSX  = "xlsx"
+)
+
+// Writer streams a table row by row. Values may be strings, signed or
+// unsigned integers, floats, bools, time.Time or nil.
+type Writer interface {
+	WriteHeader(columns []string) error
+	WriteRow(values []any) error
+	Close() error
+}
+
+// NewWriter returns the writer for format ("csv", "jsonl" or "xlsx").
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch strings.ToLower(format) {
+	case FormatCSV:
+		return NewCSVWriter(w), nil
+	case FormatJSONL:
+		return NewJSONLWriter(w), nil
+	case FormatXLSX:
+		return NewXLSXWriter(w), nil
+	}
+	return nil, errors.New("unsupported export format: " + format)
+}
+
+// ContentType returns the MIME type of an export format.
+func ContentType(format string) string {
+	switch strings.ToLower(format) {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatJSONL:
+		return "application/x-ndjson"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,84 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+)
+
+// JSONLWriter writes one JSON object per row, keyed by the header columns.
+type JSONLWriter struct {
+	enc     *json.Encoder
+	columns []string
+}
+
+func NewJSONLWriter(w io.Writer) *JSONLWriter {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &JSONLWriter{enc: enc}
+}
+
+func (jw *JSONLWriter) WriteHeader(columns []string) error {
+	jw.columns = columns
+	return nil
+}
+
+func (jw *JSONLWriter) WriteRow(values []any) error {
+	if len(values) != len(jw.columns) {
+		return errors.New("row does not match the header")
+	}
+
+	// an ordered object keeps the columns in header order
+	obj := make(orderedObject, len(values))
+	for i, v := range values {
+		if t, ok := v.(time.Time); ok && t.IsZero() {
+			v = nil
+		}
+		obj[i] = field{Key: jw.columns[i], Value: v}
+	}
+	return jw.enc.Encode(obj)
+}
+
+func (jw *JSONLWriter) Close() error {
+	return nil
+}
+
+type field struct {
+	Key   string
+	Value any
+}
+
+type orderedObject []field
+
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	buf := []byte{'{'}
+	for i, f := range o {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		key, err := marshalNoEscape(f.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := marshalNoEscape(f.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, key...)
+		buf = append(buf, ':')
+		buf = append(buf, value...)
+	}
+	return append(buf, '}'), nil
+}
+
+func marshalNoEscape(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
@@ -0,0 +1,93 @@
+package exporter
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestColumnName(t *testing.T) {
+	cases := map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"}
+	for i, want := range cases {
+		if got := columnName(i); got != want {
+			t.Errorf("columnName(%d) = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func sheetXML(t *testing.T, workbook []byte) string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(workbook), int64(len(workbook)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	if strings.Join(names, " ") != "[Content_Types].xml _rels/.rels xl/workbook.xml xl/_rels/workbook.xml.rels xl/styles.xml xl/worksheets/sheet1.xml" {
+		t.Fatalf("parts %v", names)
+	}
+	f, err := zr.Open("xl/worksheets/sheet1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestXLSXWriterCells(t *testing.T) {
+	var out bytes.Buffer
+	xw := NewXLSXWriter(&out)
+	if err := xw.WriteRow([]any{1}); err == nil {
+		t.Fatal("a row before the header was written")
+	}
+	if err := xw.WriteHeader([]string{"amount", "rate", "when", "note", "none", "zero time", "ok"}); err != nil {
+		t.Fatal(err)
+	}
+	row := []any{int64(-1_234_567_890_123), 0.25, time.Date(2026, 10, 19, 8, 30, 0, 0, time.UTC), "a < b & c", nil, time.Time{}, true}
+	if err := xw.WriteRow(row); err != nil {
+		t.Fatal(err)
+	}
+	if err := xw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	sheet := sheetXML(t, out.Bytes())
+	for _, want := range []string{
+		`<c r="A1" t="inlineStr"><is><t xml:space="preserve">amount</t></is></c>`,
+		`<c r="A2"><v>-1234567890123</v></c>`,
+		`<c r="B2"><v>0.25</v></c>`,
+		`<c r="C2" t="inlineStr"><is><t xml:space="preserve">2026-10-19T08:30:00Z</t></is></c>`,
+		`<c r="D2" t="inlineStr"><is><t xml:space="preserve">a &lt; b &amp; c</t></is></c>`,
+		`<c r="G2" t="inlineStr"><is><t xml:space="preserve">true</t></is></c>`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("sheet has no %s:\n%s", want, sheet)
+		}
+	}
+	// nil and zero times leave the cell out
+	if strings.Contains(sheet, `r="E2"`) || strings.Contains(sheet, `r="F2"`) {
+		t.Errorf("empty cells written:\n%s", sheet)
+	}
+	if !strings.HasSuffix(sheet, `</row></sheetData></worksheet>`) {
+		t.Errorf("sheet not closed:\n%s", sheet)
+	}
+}
+
+func TestXLSXWriterEmpty(t *testing.T) {
+	var out bytes.Buffer
+	if err := NewXLSXWriter(&out).Close(); err != nil {
+		t.Fatal(err)
+	}
+	if sheet := sheetXML(t, out.Bytes()); !strings.Contains(sheet, `<sheetData><row r="1"></row></sheetData>`) {
+		t.Errorf("empty workbook sheet:\n%s", sheet)
+	}
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"money-tracker/internal/dto"
+	"money-tracker/internal/usecase"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AnomalyHandler struct {
+	AnomalyUC *usecase.AnomalyUseCase
+}
+
+func NewAnomalyHandler(uc *usecase.AnomalyUseCase) *AnomalyHandler {
+	return &AnomalyHandler{AnomalyUC: uc}
+}
+
+// @Summary List notifications
+// @Description Alerts about unusual spending, newest first: large_purchase (a purchase far above the usual amount of its category), category_spike (the month's total of a category far above its usual months) and new_merchant (a large charge from a merchant never seen before). Baseline is the usual amount the purchase or month was compared with and score how many robust standard deviations it is above it. New notifications are also sent on the event stream as notification.created.
+// @Tags notifications
+// @Produce json
+// @Param unread query bool false "Only the unread notifications"
+// @Param start query int false "Start offset for pagination"
+// @Param limit query int false "Limit number of records (max 100)"
+// @Success 200 {object} dto.GetResponse
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Security BearerAuth
+// @Router /api/v0/system/notification [get]
+func (h *AnomalyHandler) ListHandler(c *gin.Context) {
+	var req dto.NotificationListInput
+	if err := c.ShouldBindQuery(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	notifications, count, err := h.AnomalyUC.List(req.Unread, req.Start, req.Limit)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "notifications found", "response": notifications, "count": count})
+}
+
+// @Summary Mark a notification read
+// @Tags notifications
+// @Produce json
+// @Param id path int true "Notification ID"
+// @Success 200 {object} dto.Response
+// @Failure 400 {object} dto.ErrorResponse "Invalid ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 404 {object} dto.ErrorResponse "Notification not found"
+// @Security BearerAuth
+// @Router /api/v0/system/notification/{id}/read [post]
+func (h *AnomalyHandler) ReadHandler(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.AnomalyUC.MarkRead(id); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "notification marked read", "response": ""})
+}
+
+// @Summary Delete a notification
+// @Tags notifications
+// @Produce json
+// @Param id path int true "Notification ID"
+// @Success 200 {object} dto.Response
+// @Failure 400 {object} dto.ErrorResponse "Invalid ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 404 {object} dto.ErrorResponse "Notification not found"
+// @Security BearerAuth
+// @Router /api/v0/system/notification/{id} [delete]
+func (h *AnomalyHandler) DeleteHandler(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.AnomalyUC.Remove(id); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "notification deleted", "response": ""})
+}
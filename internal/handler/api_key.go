@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"money-tracker/internal/dto"
+	"money-tracker/internal/usecase"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyHandler struct {
+	APIKeyUC *usecase.APIKeyUseCase
+}
+
+func NewAPIKeyHandler(uc *usecase.APIKeyUseCase) *APIKeyHandler {
+	return &APIKeyHandler{APIKeyUC: uc}
+}
+
+// @Summary Create an API key
+// @Description Creates a key to send as the bearer token instead of a login token. A read key only works for GET requests. The key is shown only in this response.
+// @Tags account
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateAPIKeyRequest true "name, scope and expiry"
+// @Success 201 {object} dto.APIKeyCreated
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 409 {object} dto.ErrorResponse "Too many keys"
+// @Security BearerAuth
+// @Router /api/v0/account/api-keys [post]
+func (h *APIKeyHandler) CreateHandler(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	var req dto.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	key, err := h.APIKeyUC.Create(user.ID, req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "API key created, copy it now", "response": key})
+}
+
+// @Summary List API keys
+// @Description Lists the API keys of the current user, without the keys themselves.
+// @Tags account
+// @Produce json
+// @Success 200 {object} dto.GetResponse
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Security BearerAuth
+// @Router /api/v0/account/api-keys [get]
+func (h *APIKeyHandler) ListHandler(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	keys, err := h.APIKeyUC.List(user.ID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API keys found", "response": keys, "count": len(keys)})
+}
+
+// @Summary Revoke an API key
+// @Description Deletes an API key of the current user; it stops working at once.
+// @Tags account
+// @Produce json
+// @Param id path int true "API key ID"
+// @Success 200 {object} dto.Response
+// @Failure 400 {object} dto.ErrorResponse "Invalid ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 404 {object} dto.ErrorResponse "API key not found"
+// @Security BearerAuth
+// @Router /api/v0/account/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeHandler(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.APIKeyUC.Revoke(user.ID, id); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked", "response": ""})
+}
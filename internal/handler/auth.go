@@ -24,12 +24,13 @@ func NewUserHandler(uc *usecase.UserUseCase) *UserHandler {
 // @Produce json
 // @Param request body dto.RegisterRequest true "sign up request"
 // @Success 201 {object} dto.Response
-// @Failure 400 {object} dto.Response "Bad Request"
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
+// @Failure 409 {object} dto.ErrorResponse "Duplicate"
 // @Router /api/v0/auth/signup [post]
 func (h *UserHandler) RegisterHandler(c *gin.Context) {
 	var req dto.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"response": err.Error(), "message": "validation error"})
+		bindError(c, err)
 		return
 	}
 
@@ -40,7 +41,7 @@ func (h *UserHandler) RegisterHandler(c *gin.Context) {
 		StatusID:    1,
 	})
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"response": err.Error(), "message": "creation error"})
+		_ = c.Error(err)
 		return
 	}
 
@@ -64,25 +65,29 @@ func (h *UserHandler) RegisterHandler(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param request body dto.LoginRequest true "sign up request"
-// @Success 201 {object} dto.LoginReponse
-// @Failure 400 {object} dto.Response "Bad Request"
+// @Success 200 {object} dto.LoginResult "A token, or a challenge for /auth/login/2fa when two-factor authentication is on"
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
+// @Failure 401 {object} dto.ErrorResponse "Invalid user name or password"
+// @Failure 429 {object} dto.ErrorResponse "Too many attempts or account locked, see Retry-After"
 // @Router /api/v0/auth/login [post]
 func (h *UserHandler) LoginHandler(c *gin.Context) {
 	var req dto.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"response": err.Error(), "message": "error validation"})
+		bindError(c, err)
 		return
 	}
 
-	token, level_manage, user_id, err := h.UserUC.Login(req)
+	result, err := h.UserUC.Login(req, c.ClientIP())
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"response": err.Error(), "message": "error"})
+		_ = c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "logged in", "response": gin.H{
-		"token": token, "levelManage": level_manage, "userId": user_id,
-	}})
+	message := "logged in"
+	if result.TwoFactorRequired {
+		message = "two-factor code required"
+	}
+	c.JSON(http.StatusOK, gin.H{"message": message, "response": result})
 }
 
 // @Summary Get all users
@@ -102,25 +107,20 @@ func (h *UserHandler) LoginHandler(c *gin.Context) {
 // @Param order_by query string false "Column to order by (default: id)"
 // @Param sort query string false "Sort order: ASC or DESC"
 // @Success 200 {object} dto.GetResponse
-// @Failure 400 {object} dto.ErrorGetResponse "Bad Request"
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
 // @Security BearerAuth
 // @Router /api/v0/admin/users [get]
 func (h *UserHandler) GetAllUsers(c *gin.Context) {
 	var req dto.ListUsersInput
 	if err := c.ShouldBindQuery(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"err": err.Error(), "message": "validation input", "count": 0})
+		bindError(c, err)
 		return
 	}
 
 	items, count, err := h.UserUC.Get(req)
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"message":  "user not found",
-			"response": items,
-			"count":    count,
-			"err":      err,
-		})
+		_ = c.Error(err)
 		return
 	}
 
@@ -133,13 +133,40 @@ func (h *UserHandler) GetAllUsers(c *gin.Context) {
 
 }
 
+// @Summary Unlock a user
+// @Description Lifts the login lockout and the login attempt limit of a user.
+// @Tags user
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} dto.Response
+// @Failure 400 {object} dto.ErrorResponse "Invalid ID"
+// @Failure 404 {object} dto.ErrorResponse "User not found"
+// @Security BearerAuth
+// @Router /api/v0/admin/users/{id}/unlock [post]
+func (h *UserHandler) UnlockUserHandler(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.UserUC.Unlock(id); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "user unlocked",
+		"response": "",
+	})
+}
+
 // @Summary Signup
 // @Description Creates a new user with the provided data.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Success 201 {object} dto.Response
-// @Failure 400 {object} dto.Response "Bad Request"
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
 // @Router /api/v0/auth/signup-admin [get]
 func (h *UserHandler) SignAdminHandler(c *gin.Context) {
 
@@ -152,7 +179,7 @@ func (h *UserHandler) SignAdminHandler(c *gin.Context) {
 		Mobile:      "",
 	})
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"response": err.Error(), "message": "validation error"})
+		_ = c.Error(err)
 		return
 	}
 
@@ -170,10 +197,10 @@ func (h *UserHandler) SignAdminHandler(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Success 201 {object} dto.Response
-// @Failure 400 {object} dto.Response "Bad Request"
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
 // @Security BearerAuth
 // @Router /api/v0/auth/logout [get]
-func (h *UserHandler) Logout(c gin.Context) {
+func (h *UserHandler) Logout(c *gin.Context) {
 	_, err := h.UserUC.Add(dto.AddUserInput{
 		UserName:    "money.admin",
 		Password:    "12345",
@@ -183,7 +210,7 @@ func (h *UserHandler) Logout(c gin.Context) {
 		Mobile:      "",
 	})
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"response": err.Error(), "message": "validation error"})
+		_ = c.Error(err)
 		return
 	}
 	c.JSON(http.StatusCreated, gin.H{
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"log"
+	"money-tracker/internal/usecase"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BackupHandler struct {
+	BackupUC *usecase.BackupUseCase
+}
+
+func NewBackupHandler(uc *usecase.BackupUseCase) *BackupHandler {
+	return &BackupHandler{BackupUC: uc}
+}
+
+// @Summary Download a backup
+// @Description Downloads the categories, tags and purchases in every status, deleted purchases included, as a versioned zip archive with a manifest.
+// @Tags backup
+// @Produce application/zip
+// @Success 200 {file} file
+// @Security BearerAuth
+// @Router /api/v0/admin/backup [get]
+func (h *BackupHandler) BackupHandler(c *gin.Context) {
+	// a large backup outlives the server's write timeout
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	fileName := "money-tracker-backup-" + time.Now().Format("20060102-150405") + ".zip"
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", `attachment; filename="`+fileName+`"`)
+	c.Status(http.StatusOK)
+
+	// the status is already sent, a failure can only cut the stream short
+	if err := h.BackupUC.Backup(c.Writer); err != nil {
+		log.Printf("backup failed: %v", err)
+		c.Abort()
+	}
+}
+
+// @Summary Restore a backup
+// @Description Restores a backup archive into an instance without purchases, deleted ones included. Records get new IDs; categories with the same slug and tags with the same title are reused. Nothing is restored if any record fails.
+// @Tags backup
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Backup archive"
+// @Success 200 {object} dto.Response{response=dto.RestoreResult}
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
+// @Failure 409 {object} dto.ErrorResponse "The instance has purchases"
+// @Security BearerAuth
+// @Router /api/v0/admin/restore [post]
+func (h *BackupHandler) RestoreHandler(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		_ = c.Error(usecase.InvalidField("file", "required", "file is required"))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		_ = c.Error(usecase.InvalidField("file", usecase.CodeInvalidFile, err.Error()))
+		return
+	}
+	defer file.Close()
+
+	result, err := h.BackupUC.Restore(file, fileHeader.Size)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "restored",
+		"response": result,
+	})
+}
@@ -1,11 +1,12 @@
 package handler
 
 import (
+	"errors"
 	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
 	"money-tracker/internal/usecase"
 	"money-tracker/internal/utils"
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -25,14 +26,16 @@ func NewCategoryHandler(uc *usecase.CategoryUseCase) *CategoryHandler {
 // @Accept json
 // @Produce json
 // @Param request body dto.CreateCategoryRequest true "Category creation request"
+// @Param Idempotency-Key header string false "Retries with the same key and body return the first response"
 // @Success 201 {object} dto.Response
-// @Failure 400 {object} dto.Response "Bad Request"
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
+// @Failure 409 {object} dto.ErrorResponse "Duplicate"
 // @Security BearerAuth
 // @Router /api/v0/system/category [post]
 func (h *CategoryHandler) CreateCategoryHandler(c *gin.Context) {
 	var req dto.CreateCategoryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "error validation input", "response": err.Error()})
+		bindError(c, err)
 		return
 	}
 
@@ -44,10 +47,7 @@ func (h *CategoryHandler) CreateCategoryHandler(c *gin.Context) {
 		Color: req.Color,
 	})
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"response": err.Error(),
-			"message":  "error",
-		})
+		_ = c.Error(err)
 		return
 	}
 
@@ -74,25 +74,20 @@ func (h *CategoryHandler) CreateCategoryHandler(c *gin.Context) {
 // @Param slug query string false "Filter by slug"
 // @Param tag_ids query []int false "Filter by Tag IDs (comma-separated)"
 // @Success 200 {object} dto.GetResponse
-// @Failure 400 {object} dto.ErrorGetResponse "Bad Request"
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
 // @Security BearerAuth
 // @Router /api/v0/system/category [get]
 func (h *CategoryHandler) GetAllCategoryHandler(c *gin.Context) {
 	var req dto.ListCategoriesInput
 	if err := c.ShouldBindQuery(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "error validation input", "response": err.Error()})
+		bindError(c, err)
 		return
 	}
 
 	cateories, count, err := h.CategoryUC.Get(req)
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"message":  "categories not found",
-			"response": cateories,
-			"count":    count,
-			"err":      err,
-		})
+		_ = c.Error(err)
 		return
 	}
 
@@ -119,13 +114,13 @@ func (h *CategoryHandler) GetAllCategoryHandler(c *gin.Context) {
 // @Param slug query string false "Filter by slug"
 // @Param tag_ids query []int false "Filter by Tag IDs (comma-separated)"
 // @Success 200 {object} dto.GetResponse
-// @Failure 400 {object} dto.ErrorGetResponse "Bad Request"
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
 // @Security BearerAuth
 // @Router /api/v0/system/category [get]
 func (h *CategoryHandler) GetAllPublicCategoryHandler(c *gin.Context) {
 	var req dto.ListCategoriesInput
 	if err := c.ShouldBindQuery(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "error validation input", "response": err.Error()})
+		bindError(c, err)
 		return
 	}
 
@@ -134,12 +129,7 @@ func (h *CategoryHandler) GetAllPublicCategoryHandler(c *gin.Context) {
 	cateories, count, err := h.CategoryUC.Get(req)
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"message":  "categories not found",
-			"response": cateories,
-			"count":    count,
-			"err":      err,
-		})
+		_ = c.Error(err)
 		return
 	}
 
@@ -158,18 +148,29 @@ func (h *CategoryHandler) GetAllPublicCategoryHandler(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param request body dto.UpdateCategoryRequest true "Category update request"
+// @Param If-Match header string false "ETag of the category being updated, required when version is not in the body"
 // @Success 200 {object} dto.Response
-// @Failure 400 {object} dto.Response "Bad Request"
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
+// @Failure 404 {object} dto.ErrorResponse "Not Found"
+// @Failure 409 {object} dto.ErrorResponse "Changed by another request; response holds the current category"
+// @Failure 428 {object} dto.ErrorResponse "Neither version nor If-Match was sent"
 // @Security BearerAuth
 // @Router /api/v0/system/category [put]
 func (h *CategoryHandler) UpdateCategoryHandler(c *gin.Context) {
 	var req dto.UpdateCategoryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "error validation input", "response": err.Error()})
+		bindError(c, err)
 		return
 	}
 
-	// req.Slug =
+	if req.Version == nil {
+		version, err := ifMatchVersion(c)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		req.Version = version
+	}
 
 	category, err := h.CategoryUC.Update(dto.UpdateCategoryInput{
 		ID:       req.ID,
@@ -178,12 +179,22 @@ func (h *CategoryHandler) UpdateCategoryHandler(c *gin.Context) {
 		TagIDs:   req.TagIDs,
 		Color:    req.Color,
 		Slug:     utils.GenerateSlugUnicode(req.Title),
+		Version:  req.Version,
 	})
+	if errors.Is(err, entity.ErrVersionConflict) {
+		current, _ := h.CategoryUC.GetByID(req.ID)
+		if current != nil {
+			setETag(c, current.Version)
+		}
+		_ = c.Error(usecase.Conflict(usecase.CodeVersionConflict, "category was changed by another request").WithDetails(current))
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "error validation input", "response": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
+	setETag(c, category.Version)
 	c.JSON(http.StatusOK, gin.H{
 		"message":  "updated successfully",
 		"response": category,
@@ -198,18 +209,18 @@ func (h *CategoryHandler) UpdateCategoryHandler(c *gin.Context) {
 // @Produce json
 // @Param id path int true "Category ID"
 // @Success 200 {object} dto.Response
-// @Failure 400 {object} dto.Response "Bad Request"
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
+// @Failure 404 {object} dto.ErrorResponse "Not Found"
 // @Security BearerAuth
 // @Router /api/v0/system/category/{id} [delete]
 func (h *CategoryHandler) DeleteHandler(c *gin.Context) {
-	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"response": "Invalid ID format", "message": "validation error"})
+	id, ok := paramID(c)
+	if !ok {
 		return
 	}
 
 	if err := h.CategoryUC.Remove(uint(id)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "remove category failed!", "response": ""})
+		_ = c.Error(err)
 		return
 	}
 
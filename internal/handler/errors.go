@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"money-tracker/internal/usecase"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handlers report failures with c.Error and return; middleware.ErrorHandler
+// writes the response.
+
+// bindError reports a request body or query that could not be bound.
+func bindError(c *gin.Context, err error) {
+	_ = c.Error(err).SetType(gin.ErrorTypeBind)
+}
+
+// paramID reads the :id path parameter. On failure the error is already
+// reported.
+func paramID(c *gin.Context) (uint, bool) {
+	return paramNamedID(c, "id")
+}
+
+// paramNamedID reads an id from the path parameter name.
+func paramNamedID(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil {
+		_ = c.Error(usecase.InvalidField(name, "invalid", "Invalid ID format"))
+		return 0, false
+	}
+	return uint(id), true
+}
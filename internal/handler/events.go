@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"money-tracker/internal/pubsub"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+// keepAlive is how often an idle stream gets a comment line, so proxies do
+// not close it.
+const keepAlive = 25 * time.Second
+
+type EventsHandler struct {
+	Broker *pubsub.Broker
+}
+
+func NewEventsHandler(broker *pubsub.Broker) *EventsHandler {
+	return &EventsHandler{Broker: broker}
+}
+
+// @Summary Stream ledger changes
+// @Description Server-sent events for every committed change of purchases, categories and tags: purchase.created, purchase.updated, purchase.deleted, category.*, tag.*, and notification.created for new spending alerts. The data of an event is the changed record. After a reconnect the stream resumes after Last-Event-ID; when events were missed in between it starts with a "reset" event and the client should reload.
+// @Tags system
+// @Produce text/event-stream
+// @Param Last-Event-ID header string false "id of the last event received"
+// @Success 200 {string} string "event stream"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Security BearerAuth
+// @Router /api/v0/system/events [get]
+func (h *EventsHandler) StreamHandler(c *gin.Context) {
+	if _, ok := currentUser(c); !ok {
+		return
+	}
+	lastID, _ := strconv.ParseUint(c.GetHeader("Last-Event-ID"), 10, 64)
+
+	sub, missed, complete := h.Broker.Subscribe(lastID)
+	defer sub.Close()
+
+	// a stream outlives the server's write timeout
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	if !complete {
+		c.Render(-1, sse.Event{Event: "reset", Data: "events were missed, reload"})
+	}
+	for _, e := range missed {
+		c.Render(-1, sseEvent(e))
+	}
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(keepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case e, ok := <-sub.C:
+			if !ok {
+				// dropped for falling behind, or the server shuts down;
+				// the client reconnects with Last-Event-ID
+				return
+			}
+			c.Render(-1, sseEvent(e))
+		case <-ticker.C:
+			if _, err := c.Writer.WriteString(": keep-alive\n\n"); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+func sseEvent(e pubsub.Event) sse.Event {
+	return sse.Event{
+		Id:    strconv.FormatUint(e.ID, 10),
+		Event: e.Type,
+		Data:  e.Data,
+	}
+}
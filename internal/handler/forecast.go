@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"money-tracker/internal/dto"
+	"money-tracker/internal/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ForecastHandler struct {
+	ForecastUC *usecase.ForecastUseCase
+}
+
+func NewForecastHandler(uc *usecase.ForecastUseCase) *ForecastHandler {
+	return &ForecastHandler{ForecastUC: uc}
+}
+
+// @Summary Create a recurring item
+// @Description Money that comes in (positive amount) or goes out (negative amount) on a schedule, e.g. a salary or the rent. Set category_id when the payments are also recorded as purchases, so the forecast does not count them twice.
+// @Tags forecast
+// @Accept json
+// @Produce json
+// @Param request body dto.RecurringItemRequest true "name, amount, frequency, start and end date, account"
+// @Success 201 {object} dto.Response
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Security BearerAuth
+// @Router /api/v0/system/recurring [post]
+func (h *ForecastHandler) CreateItemHandler(c *gin.Context) {
+	var req dto.RecurringItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	item, err := h.ForecastUC.CreateItem(req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "recurring item created", "response": item})
+}
+
+// @Summary List recurring items
+// @Tags forecast
+// @Produce json
+// @Success 200 {object} dto.GetResponse
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Security BearerAuth
+// @Router /api/v0/system/recurring [get]
+func (h *ForecastHandler) ListItemsHandler(c *gin.Context) {
+	items, err := h.ForecastUC.ListItems()
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "recurring items found", "response": items, "count": len(items)})
+}
+
+// @Summary Replace a recurring item
+// @Tags forecast
+// @Accept json
+// @Produce json
+// @Param id path int true "Recurring item ID"
+// @Param request body dto.RecurringItemRequest true "name, amount, frequency, start and end date, account"
+// @Success 200 {object} dto.Response
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 404 {object} dto.ErrorResponse "Recurring item not found"
+// @Security BearerAuth
+// @Router /api/v0/system/recurring/{id} [put]
+func (h *ForecastHandler) UpdateItemHandler(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	var req dto.RecurringItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	item, err := h.ForecastUC.UpdateItem(id, req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "recurring item updated", "response": item})
+}
+
+// @Summary Delete a recurring item
+// @Tags forecast
+// @Produce json
+// @Param id path int true "Recurring item ID"
+// @Success 200 {object} dto.Response
+// @Failure 400 {object} dto.ErrorResponse "Invalid ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 404 {object} dto.ErrorResponse "Recurring item not found"
+// @Security BearerAuth
+// @Router /api/v0/system/recurring/{id} [delete]
+func (h *ForecastHandler) DeleteItemHandler(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.ForecastUC.RemoveItem(id); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "recurring item deleted", "response": ""})
+}
+
+// @Summary Cash-flow forecast
+// @Description Projects the daily balance of every account for the days after today from the balances given today, the recurring items, and the average daily spending per category over the lookback days, which is charged to spending_account. Each account shows its expected low point and the dates its balance drops below zero.
+// @Tags forecast
+// @Produce json
+// @Param days query int false "Days to forecast, 90 by default (max 366)"
+// @Param lookback query int false "Days of purchases to average, 90 by default (7 to 730)"
+// @Param spending_account query string false "Account charged the average spending, main by default"
+// @Param balance[main] query int false "Balance of an account today, one balance[name] per account"
+// @Success 200 {object} dto.Forecast
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Security BearerAuth
+// @Router /api/v0/system/forecast [get]
+func (h *ForecastHandler) ForecastHandler(c *gin.Context) {
+	var req dto.ForecastInput
+	if err := c.ShouldBindQuery(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+	req.Balances = map[string]int64{}
+	for account, value := range c.QueryMap("balance") {
+		balance, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			_ = c.Error(usecase.InvalidField("balance["+account+"]", "type", "balance["+account+"] must be an integer"))
+			return
+		}
+		req.Balances[account] = balance
+	}
+
+	forecast, err := h.ForecastUC.Forecast(req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "forecast", "response": forecast})
+}
@@ -0,0 +1,259 @@
+package handler
+
+import (
+	"money-tracker/internal/dto"
+	"money-tracker/internal/usecase"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GoalHandler struct {
+	GoalUC *usecase.GoalUseCase
+}
+
+func NewGoalHandler(uc *usecase.GoalUseCase) *GoalHandler {
+	return &GoalHandler{GoalUC: uc}
+}
+
+// @Summary Create a savings goal
+// @Description A target amount to save by a deadline, optionally kept in a named account.
+// @Tags goal
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateGoalRequest true "title, target amount, deadline, account and note"
+// @Success 201 {object} dto.Response
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Security BearerAuth
+// @Router /api/v0/system/goal [post]
+func (h *GoalHandler) CreateHandler(c *gin.Context) {
+	var req dto.CreateGoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	goal, err := h.GoalUC.Create(req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "goal created", "response": goal})
+}
+
+// @Summary List savings goals
+// @Description All goals, the nearest deadline first.
+// @Tags goal
+// @Produce json
+// @Success 200 {object} dto.GetResponse
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Security BearerAuth
+// @Router /api/v0/system/goal [get]
+func (h *GoalHandler) ListHandler(c *gin.Context) {
+	goals, err := h.GoalUC.List()
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "goals found", "response": goals, "count": len(goals)})
+}
+
+// @Summary Get a savings goal
+// @Tags goal
+// @Produce json
+// @Param id path int true "Goal ID"
+// @Success 200 {object} dto.Response
+// @Failure 400 {object} dto.ErrorResponse "Invalid ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 404 {object} dto.ErrorResponse "Goal not found"
+// @Security BearerAuth
+// @Router /api/v0/system/goal/{id} [get]
+func (h *GoalHandler) GetHandler(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+
+	goal, err := h.GoalUC.Get(id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "goal found", "response": goal})
+}
+
+// @Summary Update a savings goal
+// @Description Changes the members that are present.
+// @Tags goal
+// @Accept json
+// @Produce json
+// @Param id path int true "Goal ID"
+// @Param request body dto.UpdateGoalRequest true "title, target amount, deadline, account, note"
+// @Success 200 {object} dto.Response
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 404 {object} dto.ErrorResponse "Goal not found"
+// @Security BearerAuth
+// @Router /api/v0/system/goal/{id} [put]
+func (h *GoalHandler) UpdateHandler(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	var req dto.UpdateGoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	goal, err := h.GoalUC.Update(id, req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "goal updated", "response": goal})
+}
+
+// @Summary Delete a savings goal
+// @Description Deletes the goal with its contributions.
+// @Tags goal
+// @Produce json
+// @Param id path int true "Goal ID"
+// @Success 200 {object} dto.Response
+// @Failure 400 {object} dto.ErrorResponse "Invalid ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 404 {object} dto.ErrorResponse "Goal not found"
+// @Security BearerAuth
+// @Router /api/v0/system/goal/{id} [delete]
+func (h *GoalHandler) DeleteHandler(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.GoalUC.Remove(id); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "goal deleted", "response": ""})
+}
+
+// @Summary Progress of a savings goal
+// @Description How much is saved and left, the monthly amount needed to make the deadline, the monthly pace so far and when the goal is reached at that pace.
+// @Tags goal
+// @Produce json
+// @Param id path int true "Goal ID"
+// @Success 200 {object} dto.GoalProgress
+// @Failure 400 {object} dto.ErrorResponse "Invalid ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 404 {object} dto.ErrorResponse "Goal not found"
+// @Security BearerAuth
+// @Router /api/v0/system/goal/{id}/progress [get]
+func (h *GoalHandler) ProgressHandler(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+
+	progress, err := h.GoalUC.Progress(id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "goal progress", "response": progress})
+}
+
+// @Summary Contribute to a savings goal
+// @Description Records money put towards the goal; a negative amount takes money out.
+// @Tags goal
+// @Accept json
+// @Produce json
+// @Param id path int true "Goal ID"
+// @Param request body dto.AddContributionRequest true "amount, date and note"
+// @Success 201 {object} dto.Response
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 404 {object} dto.ErrorResponse "Goal not found"
+// @Security BearerAuth
+// @Router /api/v0/system/goal/{id}/contribution [post]
+func (h *GoalHandler) ContributeHandler(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	var req dto.AddContributionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	contribution, err := h.GoalUC.Contribute(id, req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "contribution added", "response": contribution})
+}
+
+// @Summary List the contributions to a savings goal
+// @Description Oldest first.
+// @Tags goal
+// @Produce json
+// @Param id path int true "Goal ID"
+// @Success 200 {object} dto.GetResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 404 {object} dto.ErrorResponse "Goal not found"
+// @Security BearerAuth
+// @Router /api/v0/system/goal/{id}/contribution [get]
+func (h *GoalHandler) ContributionsHandler(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+
+	contributions, err := h.GoalUC.Contributions(id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "contributions found", "response": contributions, "count": len(contributions)})
+}
+
+// @Summary Delete a contribution
+// @Tags goal
+// @Produce json
+// @Param id path int true "Goal ID"
+// @Param contribution_id path int true "Contribution ID"
+// @Success 200 {object} dto.Response
+// @Failure 400 {object} dto.ErrorResponse "Invalid ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 404 {object} dto.ErrorResponse "Contribution not found"
+// @Security BearerAuth
+// @Router /api/v0/system/goal/{id}/contribution/{contribution_id} [delete]
+func (h *GoalHandler) DeleteContributionHandler(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	contributionID, ok := paramNamedID(c, "contribution_id")
+	if !ok {
+		return
+	}
+
+	if err := h.GoalUC.RemoveContribution(id, contributionID); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "contribution deleted", "response": ""})
+}
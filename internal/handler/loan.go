@@ -0,0 +1,300 @@
+package handler
+
+import (
+	"money-tracker/internal/dto"
+	"money-tracker/internal/usecase"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LoanHandler struct {
+	LoanUC *usecase.LoanUseCase
+}
+
+func NewLoanHandler(uc *usecase.LoanUseCase) *LoanHandler {
+	return &LoanHandler{LoanUC: uc}
+}
+
+// @Summary Create a loan
+// @Description Money borrowed, or lent with direction "lent". Interest is charged once per period; an IOU is a loan at interest_rate 0. The first of term payments is due one period after start_date.
+// @Tags loan
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateLoanRequest true "name, principal, interest rate, term, frequency and start date"
+// @Success 201 {object} dto.Response
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Security BearerAuth
+// @Router /api/v0/system/loan [post]
+func (h *LoanHandler) CreateHandler(c *gin.Context) {
+	var req dto.CreateLoanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	loan, err := h.LoanUC.Create(req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "loan created", "response": loan})
+}
+
+// @Summary List loans
+// @Tags loan
+// @Produce json
+// @Success 200 {object} dto.GetResponse
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Security BearerAuth
+// @Router /api/v0/system/loan [get]
+func (h *LoanHandler) ListHandler(c *gin.Context) {
+	loans, err := h.LoanUC.List()
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "loans found", "response": loans, "count": len(loans)})
+}
+
+// @Summary Get a loan
+// @Tags loan
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Success 200 {object} dto.Response
+// @Failure 400 {object} dto.ErrorResponse "Invalid ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 404 {object} dto.ErrorResponse "Loan not found"
+// @Security BearerAuth
+// @Router /api/v0/system/loan/{id} [get]
+func (h *LoanHandler) GetHandler(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+
+	loan, err := h.LoanUC.Get(id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "loan found", "response": loan})
+}
+
+// @Summary Update a loan
+// @Description Changes the members that are present. The terms are fixed once a payment is recorded.
+// @Tags loan
+// @Accept json
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Param request body dto.UpdateLoanRequest true "name, direction, terms, note"
+// @Success 200 {object} dto.Response
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 404 {object} dto.ErrorResponse "Loan not found"
+// @Failure 409 {object} dto.ErrorResponse "The loan has payments"
+// @Security BearerAuth
+// @Router /api/v0/system/loan/{id} [put]
+func (h *LoanHandler) UpdateHandler(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	var req dto.UpdateLoanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	loan, err := h.LoanUC.Update(id, req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "loan updated", "response": loan})
+}
+
+// @Summary Delete a loan
+// @Description Deletes the loan with its payments.
+// @Tags loan
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Success 200 {object} dto.Response
+// @Failure 400 {object} dto.ErrorResponse "Invalid ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 404 {object} dto.ErrorResponse "Loan not found"
+// @Security BearerAuth
+// @Router /api/v0/system/loan/{id} [delete]
+func (h *LoanHandler) DeleteHandler(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.LoanUC.Remove(id); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "loan deleted", "response": ""})
+}
+
+// @Summary Amortization schedule of a loan
+// @Description Every payment from the start of the loan split into interest and principal, with the balance after it. extra is paid on top of every payment.
+// @Tags loan
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Param extra query int false "Paid on top of every payment"
+// @Success 200 {object} dto.LoanSchedule
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 404 {object} dto.ErrorResponse "Loan not found"
+// @Security BearerAuth
+// @Router /api/v0/system/loan/{id}/schedule [get]
+func (h *LoanHandler) ScheduleHandler(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	var req dto.LoanScheduleInput
+	if err := c.ShouldBindQuery(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	schedule, err := h.LoanUC.Schedule(id, req.Extra)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "loan schedule", "response": schedule})
+}
+
+// @Summary Status of a loan
+// @Description The balance after the payments recorded, the next due date and the payoff date at the regular payment. With extra and/or lump_sum it also shows the payoff date and the interest saved when paying more.
+// @Tags loan
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Param extra query int false "What if this is paid on top of every remaining payment"
+// @Param lump_sum query int false "What if this is paid off now"
+// @Success 200 {object} dto.LoanStatus
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 404 {object} dto.ErrorResponse "Loan not found"
+// @Security BearerAuth
+// @Router /api/v0/system/loan/{id}/status [get]
+func (h *LoanHandler) StatusHandler(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	var req dto.LoanStatusInput
+	if err := c.ShouldBindQuery(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	status, err := h.LoanUC.Status(id, req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "loan status", "response": status})
+}
+
+// @Summary Record a loan payment
+// @Description Splits the payment into the interest of the periods due since the payment before and principal. Payments are recorded in date order and cannot be more than the balance and interest left.
+// @Tags loan
+// @Accept json
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Param request body dto.AddLoanPaymentRequest true "amount, date and note"
+// @Success 201 {object} dto.Response
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 404 {object} dto.ErrorResponse "Loan not found"
+// @Security BearerAuth
+// @Router /api/v0/system/loan/{id}/payment [post]
+func (h *LoanHandler) PayHandler(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	var req dto.AddLoanPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	payment, err := h.LoanUC.Pay(id, req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "payment recorded", "response": payment})
+}
+
+// @Summary List the payments of a loan
+// @Description Oldest first.
+// @Tags loan
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Success 200 {object} dto.GetResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 404 {object} dto.ErrorResponse "Loan not found"
+// @Security BearerAuth
+// @Router /api/v0/system/loan/{id}/payment [get]
+func (h *LoanHandler) PaymentsHandler(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+
+	payments, err := h.LoanUC.Payments(id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "payments found", "response": payments, "count": len(payments)})
+}
+
+// @Summary Delete a loan payment
+// @Description Only the latest payment can be deleted.
+// @Tags loan
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Param payment_id path int true "Payment ID"
+// @Success 200 {object} dto.Response
+// @Failure 400 {object} dto.ErrorResponse "Invalid ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 404 {object} dto.ErrorResponse "Payment not found"
+// @Failure 409 {object} dto.ErrorResponse "Not the latest payment"
+// @Security BearerAuth
+// @Router /api/v0/system/loan/{id}/payment/{payment_id} [delete]
+func (h *LoanHandler) DeletePaymentHandler(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	paymentID, ok := paramNamedID(c, "payment_id")
+	if !ok {
+		return
+	}
+
+	if err := h.LoanUC.RemovePayment(id, paymentID); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "payment deleted", "response": ""})
+}
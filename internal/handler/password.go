@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"money-tracker/internal/dto"
+	"money-tracker/internal/usecase"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PasswordHandler struct {
+	PasswordUC *usecase.PasswordUseCase
+}
+
+func NewPasswordHandler(uc *usecase.PasswordUseCase) *PasswordHandler {
+	return &PasswordHandler{PasswordUC: uc}
+}
+
+// @Summary Change password
+// @Description Sets a new password for the current user. Every session of the user ends, this one too.
+// @Tags account
+// @Accept json
+// @Produce json
+// @Param request body dto.ChangePasswordRequest true "current and new password"
+// @Success 200 {object} dto.Response
+// @Failure 400 {object} dto.ErrorResponse "Wrong current password or weak new password"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 429 {object} dto.ErrorResponse "Account locked, see Retry-After"
+// @Security BearerAuth
+// @Router /api/v0/account/password [post]
+func (h *PasswordHandler) ChangeHandler(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	var req dto.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	if err := h.PasswordUC.Change(user.ID, req); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "password changed, log in again", "response": ""})
+}
+
+// @Summary Forgot password
+// @Description Sends a password reset token to the user. The answer is the same whether the user exists or not.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.ForgotPasswordRequest true "user name"
+// @Success 202 {object} dto.Response
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
+// @Failure 429 {object} dto.ErrorResponse "Too many attempts, see Retry-After"
+// @Router /api/v0/auth/password/forgot [post]
+func (h *PasswordHandler) ForgotHandler(c *gin.Context) {
+	var req dto.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	if err := h.PasswordUC.Forgot(req, c.ClientIP()); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "if the user exists, a reset token is on its way", "response": ""})
+}
+
+// @Summary Reset password
+// @Description Sets a new password with a token from /auth/password/forgot. The token works once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.ResetPasswordRequest true "token and new password"
+// @Success 200 {object} dto.Response
+// @Failure 400 {object} dto.ErrorResponse "Invalid token or weak password"
+// @Router /api/v0/auth/password/reset [post]
+func (h *PasswordHandler) ResetHandler(c *gin.Context) {
+	var req dto.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	if err := h.PasswordUC.Reset(req); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "password reset, log in with the new one", "response": ""})
+}
@@ -1,21 +1,29 @@
 package handler
 
 import (
+	"errors"
 	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
+	"money-tracker/internal/exporter"
+	"money-tracker/internal/importer"
 	"money-tracker/internal/usecase"
 
+	"log"
 	"net/http"
-	"strconv"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 type PurchaseHandler struct {
-	PurchaseUC *usecase.PurchaseUseCase
+	PurchaseUC   *usecase.PurchaseUseCase
+	SuggestionUC *usecase.SuggestionUseCase
 }
 
-func NewPurchaseHandler(uc *usecase.PurchaseUseCase) *PurchaseHandler {
-	return &PurchaseHandler{PurchaseUC: uc}
+func NewPurchaseHandler(uc *usecase.PurchaseUseCase, suggestion *usecase.SuggestionUseCase) *PurchaseHandler {
+	return &PurchaseHandler{PurchaseUC: uc, SuggestionUC: suggestion}
 }
 
 // @Summary Create a new purchase
@@ -24,23 +32,21 @@ func NewPurchaseHandler(uc *usecase.PurchaseUseCase) *PurchaseHandler {
 // @Accept json
 // @Produce json
 // @Param request body dto.AddPurchaseInput true "purchase creation request"
+// @Param Idempotency-Key header string false "Retries with the same key and body return the first response"
 // @Success 201 {object} dto.Response
-// @Failure 400 {object} dto.Response "Bad Request"
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
 // @Security BearerAuth
 // @Router /api/v0/system/purchase [post]
 func (h *PurchaseHandler) CreatepurchaseHandler(c *gin.Context) {
 	var req dto.AddPurchaseInput
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "error validation input", "response": err.Error()})
+		bindError(c, err)
 		return
 	}
 
 	purchase, err := h.PurchaseUC.Add(req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"response": err.Error(),
-			"message":  "error",
-		})
+		_ = c.Error(err)
 		return
 	}
 
@@ -65,26 +71,23 @@ func (h *PurchaseHandler) CreatepurchaseHandler(c *gin.Context) {
 // @Param category_id query int false "Filter"
 // @Param status_id query int false "Filter by StatusID"
 // @Param tag_ids query []int false "Filter by Tag IDs (comma-separated)"
+// @Param date_from query string false "Only purchases dated on or after, RFC 3339"
+// @Param date_to query string false "Only purchases dated before, RFC 3339"
 // @Success 200 {object} dto.GetResponse
-// @Failure 400 {object} dto.ErrorGetResponse "Bad Request"
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
 // @Security BearerAuth
 // @Router /api/v0/system/purchase [get]
 func (h *PurchaseHandler) GetAllPurchaseHandler(c *gin.Context) {
 	var req dto.PurchaseFindAll
 	if err := c.ShouldBindQuery(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "error validation input", "response": err.Error()})
+		bindError(c, err)
 		return
 	}
 
 	purchases, count, err := h.PurchaseUC.Get(req)
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"message":  "purchase not found",
-			"response": purchases,
-			"count":    count,
-			"err":      err,
-		})
+		_ = c.Error(err)
 		return
 	}
 
@@ -97,31 +100,190 @@ func (h *PurchaseHandler) GetAllPurchaseHandler(c *gin.Context) {
 
 }
 
+// @Summary Suggest categories and tags
+// @Description Suggests the most likely categories and tags for a draft purchase, learned from the purchase history.
+// @Tags purchase
+// @Produce json
+// @Param reason query string false "Draft reason"
+// @Param note query string false "Draft note"
+// @Param amount query int false "Draft amount"
+// @Param limit query int false "Number of suggestions per list (default: 5)"
+// @Success 200 {object} dto.Response{response=dto.PurchaseSuggestionResponse}
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Security BearerAuth
+// @Router /api/v0/system/purchase/suggest [get]
+func (h *PurchaseHandler) SuggestHandler(c *gin.Context) {
+	var req dto.SuggestPurchaseInput
+	if err := c.ShouldBindQuery(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	suggestions, err := h.SuggestionUC.Suggest(req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "suggestions found",
+		"response": suggestions,
+	})
+}
+
+// @Summary Import purchases from a bank file
+// @Description Imports the outgoing transactions of an OFX/QFX or QIF bank statement as purchases, all or nothing. Transactions imported before are skipped. Deposits are not supported: they are counted in skipped_income and not stored.
+// @Tags purchase
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "OFX, QFX or QIF file"
+// @Param format formData string false "ofx|qfx|qif (default: from the file extension)"
+// @Param category_id formData int true "Category of the imported purchases"
+// @Param sub_category_id formData int false "Sub category of the imported purchases"
+// @Param tag_ids formData string false "Comma-separated tag IDs"
+// @Success 200 {object} dto.Response{response=dto.ImportPurchasesResult}
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Security BearerAuth
+// @Router /api/v0/system/purchase/import [post]
+func (h *PurchaseHandler) ImportHandler(c *gin.Context) {
+	var req dto.ImportPurchasesInput
+	if err := c.ShouldBind(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		_ = c.Error(usecase.InvalidField("file", "required", "file is required"))
+		return
+	}
+
+	if req.Format == "" {
+		req.Format = strings.TrimPrefix(strings.ToLower(filepath.Ext(fileHeader.Filename)), ".")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		_ = c.Error(usecase.InvalidField("file", usecase.CodeInvalidFile, err.Error()))
+		return
+	}
+	defer file.Close()
+
+	transactions, err := importer.Parse(req.Format, file)
+	if err != nil {
+		_ = c.Error(usecase.InvalidField("file", usecase.CodeInvalidFile, "invalid bank file: "+err.Error()))
+		return
+	}
+
+	result, err := h.PurchaseUC.Import(req, transactions)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	message := "imported"
+	if result.SkippedIncome > 0 {
+		message = "imported; deposits are not supported and were skipped"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":  message,
+		"response": result,
+	})
+}
+
+// @Summary Export purchases
+// @Description Streams all purchases matching the filters as CSV, JSON Lines or XLSX. Category and tag titles are resolved and the details are flattened into columns.
+// @Tags purchase
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string true "csv|jsonl|xlsx"
+// @Param sort query string false "Sort order by id: ASC or DESC"
+// @Param reason query string false "Filter by reason"
+// @Param id query int false "Filter by ID"
+// @Param category_id query int false "Filter"
+// @Param status_id query int false "Filter by StatusID"
+// @Param tag_ids query []int false "Filter by Tag IDs (comma-separated)"
+// @Success 200 {file} file
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Security BearerAuth
+// @Router /api/v0/system/purchase/export [get]
+func (h *PurchaseHandler) ExportHandler(c *gin.Context) {
+	var req dto.PurchaseFindAll
+	if err := c.ShouldBindQuery(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	format := strings.ToLower(c.Query("format"))
+	writer, err := exporter.NewWriter(format, c.Writer)
+	if err != nil {
+		_ = c.Error(usecase.InvalidField("format", "oneof", err.Error()))
+		return
+	}
+
+	// a large export outlives the server's write timeout
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	fileName := "purchases-" + time.Now().Format("20060102") + "." + format
+	c.Header("Content-Type", exporter.ContentType(format))
+	c.Header("Content-Disposition", `attachment; filename="`+fileName+`"`)
+	c.Status(http.StatusOK)
+
+	// the status is already sent, a failure can only cut the stream short
+	if err := h.PurchaseUC.Export(req, writer); err != nil {
+		log.Printf("purchase export failed: %v", err)
+		c.Abort()
+	}
+}
+
 // @Summary Update a purchase
 // @Description Updates an existing purchase with new data.
 // @Tags purchase
 // @Accept json
 // @Produce json
 // @Param request body dto.UpdatePurchaseInput true "Category update request"
+// @Param If-Match header string false "ETag of the purchase being updated, required when version is not in the body"
 // @Success 200 {object} dto.Response
-// @Failure 400 {object} dto.Response "Bad Request"
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
+// @Failure 404 {object} dto.ErrorResponse "Not Found"
+// @Failure 409 {object} dto.ErrorResponse "Changed by another request; response holds the current purchase"
+// @Failure 428 {object} dto.ErrorResponse "Neither version nor If-Match was sent"
 // @Security BearerAuth
 // @Router /api/v0/system/purchase [put]
 func (h *PurchaseHandler) UpdatePurchaseHandler(c *gin.Context) {
 	var req dto.UpdatePurchaseInput
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "error validation input", "response": err.Error()})
+		bindError(c, err)
 		return
 	}
 
-	// req.Slug =
+	if req.Version == nil {
+		version, err := ifMatchVersion(c)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		req.Version = version
+	}
 
 	purchase, err := h.PurchaseUC.Update(req)
+	if errors.Is(err, entity.ErrVersionConflict) {
+		current, _ := h.PurchaseUC.GetByID(req.ID)
+		if current != nil {
+			setETag(c, current.Version)
+		}
+		_ = c.Error(usecase.Conflict(usecase.CodeVersionConflict, "purchase was changed by another request").WithDetails(current))
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "error validation input", "response": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
+	setETag(c, purchase.Version)
 	c.JSON(http.StatusOK, gin.H{
 		"message":  "updated successfully",
 		"response": purchase,
@@ -130,24 +292,80 @@ func (h *PurchaseHandler) UpdatePurchaseHandler(c *gin.Context) {
 
 }
 
+// @Summary Patch a purchase
+// @Description Changes only the given fields of a purchase using a JSON Merge Patch (RFC 7396). A null value clears a field, e.g. {"sub_category_id": null}.
+// @Tags purchase
+// @Accept application/merge-patch+json
+// @Accept json
+// @Produce json
+// @Param id path int true "purchase ID"
+// @Param request body dto.PurchasePatchDocument true "Merge patch; only the members to change"
+// @Param If-Match header string false "ETag of the purchase being patched, required when version is not in the patch"
+// @Success 200 {object} dto.Response
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 404 {object} dto.ErrorResponse "Not Found"
+// @Failure 409 {object} dto.ErrorResponse "Changed by another request; response holds the current purchase"
+// @Failure 428 {object} dto.ErrorResponse "Neither version nor If-Match was sent"
+// @Security BearerAuth
+// @Router /api/v0/system/purchase/{id} [patch]
+func (h *PurchaseHandler) PatchPurchaseHandler(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	patch, err := c.GetRawData()
+	if err != nil {
+		bindError(c, err)
+		return
+	}
+
+	purchase, err := h.PurchaseUC.Patch(uint(id), patch, version)
+	if errors.Is(err, entity.ErrVersionConflict) {
+		current, _ := h.PurchaseUC.GetByID(uint(id))
+		if current != nil {
+			setETag(c, current.Version)
+		}
+		_ = c.Error(usecase.Conflict(usecase.CodeVersionConflict, "purchase was changed by another request").WithDetails(current))
+		return
+	}
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	setETag(c, purchase.Version)
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "updated successfully",
+		"response": purchase,
+	})
+}
+
 // @Summary Delete a purchase
 // @Description Deletes a purchase by its ID.
 // @Tags purchase
 // @Produce json
 // @Param id path int true "purchase ID"
 // @Success 200 {object} dto.Response
-// @Failure 400 {object} dto.Response "Bad Request"
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
+// @Failure 404 {object} dto.ErrorResponse "Not Found"
 // @Security BearerAuth
 // @Router /api/v0/system/purchase/{id} [delete]
 func (h *PurchaseHandler) DeleteHandler(c *gin.Context) {
-	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"response": "Invalid ID format", "message": "validation error"})
+	id, ok := paramID(c)
+	if !ok {
 		return
 	}
 
 	if err := h.PurchaseUC.Remove(uint(id)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "remove purchase failed!", "response": ""})
+		_ = c.Error(err)
 		return
 	}
 
@@ -158,3 +376,65 @@ func (h *PurchaseHandler) DeleteHandler(c *gin.Context) {
 	return
 
 }
+
+// @Summary Create purchases in bulk
+// @Description Creates several purchases in one transaction. With all_or_nothing a single invalid item rolls back the whole batch, otherwise the valid items are kept and the result reports each item.
+// @Tags purchase
+// @Accept json
+// @Produce json
+// @Param request body dto.BatchCreatePurchaseInput true "purchases to create"
+// @Param Idempotency-Key header string false "Retries with the same key and body return the first response"
+// @Success 200 {object} dto.Response{response=dto.BulkResult}
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Security BearerAuth
+// @Router /api/v0/system/purchase/batch [post]
+func (h *PurchaseHandler) BatchCreateHandler(c *gin.Context) {
+	var req dto.BatchCreatePurchaseInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	result, err := h.PurchaseUC.BatchCreate(req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "batch processed",
+		"response": result,
+	})
+}
+
+// @Summary Update purchases in bulk
+// @Description Applies one action (set_category, add_tags, remove_tags, set_status or delete) to the active purchases selected by ids or by filter, in one transaction. set_status only takes status_id 0, which deletes like the delete action.
+// @Tags purchase
+// @Accept json
+// @Produce json
+// @Param request body dto.BulkPurchaseInput true "selection and action"
+// @Param Idempotency-Key header string false "Retries with the same key and body return the first response"
+// @Success 200 {object} dto.Response{response=dto.BulkResult}
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Security BearerAuth
+// @Router /api/v0/system/purchase/bulk [post]
+func (h *PurchaseHandler) BulkHandler(c *gin.Context) {
+	var req dto.BulkPurchaseInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	result, err := h.PurchaseUC.Bulk(req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "bulk processed",
+		"response": result,
+	})
+}
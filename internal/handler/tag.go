@@ -1,10 +1,11 @@
 package handler
 
 import (
+	"errors"
 	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
 	"money-tracker/internal/usecase"
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -23,20 +24,22 @@ func NewTagHandler(uc *usecase.TagUseCase) *TagHandler {
 // @Accept json
 // @Produce json
 // @Param request body dto.CreateTagRequest true "Article creation request"
+// @Param Idempotency-Key header string false "Retries with the same key and body return the first response"
 // @Success 201 {object} dto.Response
-// @Failure 400 {object} dto.Response "Bad Request"
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
+// @Failure 409 {object} dto.ErrorResponse "Duplicate"
 // @Security BearerAuth
 // @Router /api/v0/system/tag [post]
 func (h *TagHandler) CreateTagHandler(c *gin.Context) {
 	var req dto.CreateTagRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "error validation input", "response": err.Error()})
+		bindError(c, err)
 		return
 	}
 
 	tag, err := h.TagUC.Add(req.Title, req.StatusID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -60,25 +63,20 @@ func (h *TagHandler) CreateTagHandler(c *gin.Context) {
 // @Param status_id query int false "status_id"
 // @Param title query string false "title"
 // @Success 200 {object} dto.GetResponse
-// @Failure 400 {object} dto.ErrorGetResponse "Bad Request"
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
 // @Security BearerAuth
 // @Router /api/v0/system/tag [get]
 func (h *TagHandler) GetAllTagsHandler(c *gin.Context) {
 	var req dto.ListTagsInput
 	if err := c.ShouldBindQuery(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "error validation input", "response": err.Error()})
+		bindError(c, err)
 		return
 	}
 
 	tags, count, err := h.TagUC.Get(req)
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"message":  "tags not found",
-			"response": tags,
-			"count":    count,
-			"err":      err,
-		})
+		_ = c.Error(err)
 		return
 	}
 
@@ -97,26 +95,45 @@ func (h *TagHandler) GetAllTagsHandler(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param request body dto.UpdateTagRequest true "Tag update request"
+// @Param If-Match header string false "ETag of the tag being updated, required when version is not in the body"
 // @Success 200 {object} dto.Response
-// @Failure 400 {object} dto.Response "Bad Request"
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
+// @Failure 404 {object} dto.ErrorResponse "Not Found"
+// @Failure 409 {object} dto.ErrorResponse "Changed by another request; response holds the current tag"
+// @Failure 428 {object} dto.ErrorResponse "Neither version nor If-Match was sent"
 // @Security BearerAuth
 // @Router /api/v0/system/tag [put]
 func (h *TagHandler) UpdateTagHandler(c *gin.Context) {
 	var req dto.UpdateTagRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "error validation input", "response": err.Error()})
+		bindError(c, err)
 		return
 	}
 
+	if req.Version == nil {
+		version, err := ifMatchVersion(c)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		req.Version = version
+	}
+
 	tag, err := h.TagUC.Update(req)
+	if errors.Is(err, entity.ErrVersionConflict) {
+		current, _ := h.TagUC.GetByID(req.ID)
+		if current != nil {
+			setETag(c, current.Version)
+		}
+		_ = c.Error(usecase.Conflict(usecase.CodeVersionConflict, "tag was changed by another request").WithDetails(current))
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"response": err.Error(),
-			"message":  "error",
-		})
+		_ = c.Error(err)
 		return
 	}
 
+	setETag(c, tag.Version)
 	c.JSON(http.StatusOK, gin.H{
 		"message":  "updated successfully",
 		"response": tag,
@@ -131,19 +148,18 @@ func (h *TagHandler) UpdateTagHandler(c *gin.Context) {
 // @Produce json
 // @Param id path int true "Tag ID"
 // @Success 200 {object} dto.Response
-// @Failure 400 {object} dto.Response "Bad Request"
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
+// @Failure 404 {object} dto.ErrorResponse "Not Found"
 // @Security BearerAuth
 // @Router /api/v0/system/tag/{id} [delete]
 func (h *TagHandler) DeleteHandler(c *gin.Context) {
-	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid ID format", "response": ""})
+	id, ok := paramID(c)
+	if !ok {
 		return
 	}
 
 	if err := h.TagUC.Remove(uint(id)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "remove tag failed!",
-			"response": err})
+		_ = c.Error(err)
 		return
 	}
 
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
+	"money-tracker/internal/usecase"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// currentUser returns the user that middleware.AuthMiddleware put in the
+// context. On failure the error is already reported.
+func currentUser(c *gin.Context) (entity.User, bool) {
+	v, ok := c.Get("user")
+	user, isUser := v.(entity.User)
+	if !ok || !isUser {
+		_ = c.Error(usecase.Unauthorized(usecase.CodeUnauthorized, "not logged in"))
+		return entity.User{}, false
+	}
+	return user, true
+}
+
+// @Summary Login, second step
+// @Description Exchanges the challenge of /auth/login and a TOTP or recovery code for a token.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.TwoFactorLoginRequest true "challenge and code"
+// @Success 200 {object} dto.LoginResult
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
+// @Failure 401 {object} dto.ErrorResponse "Invalid challenge or code"
+// @Failure 429 {object} dto.ErrorResponse "Too many attempts or account locked, see Retry-After"
+// @Router /api/v0/auth/login/2fa [post]
+func (h *UserHandler) LoginTwoFactorHandler(c *gin.Context) {
+	var req dto.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	result, err := h.UserUC.LoginTwoFactor(req, c.ClientIP())
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "logged in", "response": result})
+}
+
+// @Summary Enroll in two-factor authentication
+// @Description Creates a TOTP secret for the current user. It takes effect once activated with a code.
+// @Tags account
+// @Produce json
+// @Success 200 {object} dto.TOTPEnrollment
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 409 {object} dto.ErrorResponse "Two-factor authentication is already on"
+// @Security BearerAuth
+// @Router /api/v0/account/2fa/enroll [post]
+func (h *UserHandler) EnrollTOTPHandler(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	enrollment, err := h.UserUC.EnrollTOTP(user.ID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "scan the secret and activate it with a code", "response": enrollment})
+}
+
+// @Summary Activate two-factor authentication
+// @Description Turns two-factor authentication on with the first code of the enrolled secret. The recovery codes are shown only once.
+// @Tags account
+// @Accept json
+// @Produce json
+// @Param request body dto.TwoFactorCodeRequest true "TOTP code"
+// @Success 200 {object} dto.RecoveryCodes
+// @Failure 400 {object} dto.ErrorResponse "Invalid code"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 409 {object} dto.ErrorResponse "Not enrolled or already on"
+// @Security BearerAuth
+// @Router /api/v0/account/2fa/activate [post]
+func (h *UserHandler) ActivateTOTPHandler(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	var req dto.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	codes, err := h.UserUC.ActivateTOTP(user.ID, req.Code)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "two-factor authentication on", "response": codes})
+}
+
+// @Summary Disable two-factor authentication
+// @Description Turns two-factor authentication off and deletes the recovery codes. Takes a TOTP or recovery code.
+// @Tags account
+// @Accept json
+// @Produce json
+// @Param request body dto.TwoFactorCodeRequest true "TOTP or recovery code"
+// @Success 200 {object} dto.Response
+// @Failure 400 {object} dto.ErrorResponse "Invalid code"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 409 {object} dto.ErrorResponse "Two-factor authentication is off"
+// @Failure 429 {object} dto.ErrorResponse "Account locked, see Retry-After"
+// @Security BearerAuth
+// @Router /api/v0/account/2fa/disable [post]
+func (h *UserHandler) DisableTOTPHandler(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	var req dto.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	if err := h.UserUC.DisableTOTP(user.ID, req.Code); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "two-factor authentication off", "response": ""})
+}
+
+// @Summary Regenerate recovery codes
+// @Description Replaces the recovery codes of the current user. Takes a TOTP or recovery code.
+// @Tags account
+// @Accept json
+// @Produce json
+// @Param request body dto.TwoFactorCodeRequest true "TOTP or recovery code"
+// @Success 200 {object} dto.RecoveryCodes
+// @Failure 400 {object} dto.ErrorResponse "Invalid code"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 409 {object} dto.ErrorResponse "Two-factor authentication is off"
+// @Failure 429 {object} dto.ErrorResponse "Account locked, see Retry-After"
+// @Security BearerAuth
+// @Router /api/v0/account/2fa/recovery-codes [post]
+func (h *UserHandler) RegenerateRecoveryCodesHandler(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	var req dto.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	codes, err := h.UserUC.RegenerateRecoveryCodes(user.ID, req.Code)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "recovery codes replaced", "response": codes})
+}
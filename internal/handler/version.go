@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"money-tracker/internal/usecase"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ifMatchVersion reads the version a client expects from the If-Match
+// header, e.g. `"3"` or `W/"3"`. It returns nil when the header is absent.
+func ifMatchVersion(c *gin.Context) (*uint, error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+
+	tag := strings.Trim(strings.TrimPrefix(header, "W/"), `"`)
+	version, err := strconv.ParseUint(tag, 10, 64)
+	if err != nil {
+		return nil, usecase.InvalidField("If-Match", "invalid", "If-Match must be the ETag of the resource")
+	}
+	v := uint(version)
+	return &v, nil
+}
+
+func setETag(c *gin.Context, version uint) {
+	c.Header("ETag", `"`+strconv.FormatUint(uint64(version), 10)+`"`)
+}
@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"money-tracker/internal/dto"
+	"money-tracker/internal/usecase"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookHandler struct {
+	WebhookUC *usecase.WebhookUseCase
+}
+
+func NewWebhookHandler(uc *usecase.WebhookUseCase) *WebhookHandler {
+	return &WebhookHandler{WebhookUC: uc}
+}
+
+// @Summary Create a webhook
+// @Description Subscribes a URL to events: purchase.created, purchase.updated, purchase.deleted. Every request is signed in the X-Webhook-Signature header with the secret, which is shown only in this response.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateWebhookRequest true "url, events and description"
+// @Success 201 {object} dto.WebhookCreated
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Security BearerAuth
+// @Router /api/v0/admin/webhooks [post]
+func (h *WebhookHandler) CreateHandler(c *gin.Context) {
+	var req dto.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	webhook, err := h.WebhookUC.Create(req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "webhook created, copy the secret now", "response": webhook})
+}
+
+// @Summary List webhooks
+// @Tags webhooks
+// @Produce json
+// @Success 200 {object} dto.GetResponse
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Security BearerAuth
+// @Router /api/v0/admin/webhooks [get]
+func (h *WebhookHandler) ListHandler(c *gin.Context) {
+	webhooks, err := h.WebhookUC.List()
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "webhooks found", "response": webhooks, "count": len(webhooks)})
+}
+
+// @Summary Update a webhook
+// @Description Changes the members that are present. Deactivating a webhook drops the events still waiting for it.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param request body dto.UpdateWebhookRequest true "url, events, description, active"
+// @Success 200 {object} dto.Response
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 404 {object} dto.ErrorResponse "Webhook not found"
+// @Security BearerAuth
+// @Router /api/v0/admin/webhooks/{id} [put]
+func (h *WebhookHandler) UpdateHandler(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	var req dto.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	webhook, err := h.WebhookUC.Update(id, req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "webhook updated", "response": webhook})
+}
+
+// @Summary Delete a webhook
+// @Description Deletes the webhook with its pending events and delivery log.
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} dto.Response
+// @Failure 400 {object} dto.ErrorResponse "Invalid ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 404 {object} dto.ErrorResponse "Webhook not found"
+// @Security BearerAuth
+// @Router /api/v0/admin/webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteHandler(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.WebhookUC.Remove(id); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "webhook deleted", "response": ""})
+}
+
+// @Summary List the deliveries of a webhook
+// @Description Every attempt to send an event, newest first, with the status code and the start of the response.
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param start query int false "Start offset for pagination"
+// @Param limit query int false "Limit number of records (max 100)"
+// @Success 200 {object} dto.GetResponse
+// @Failure 400 {object} dto.ErrorResponse "Bad Request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 404 {object} dto.ErrorResponse "Webhook not found"
+// @Security BearerAuth
+// @Router /api/v0/admin/webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) DeliveriesHandler(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	var req dto.WebhookDeliveriesInput
+	if err := c.ShouldBindQuery(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	deliveries, count, err := h.WebhookUC.Deliveries(id, req.Start, req.Limit)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "deliveries found", "response": deliveries, "count": count})
+}
+
+// @Summary Ping a webhook
+// @Description Queues a ping event for the webhook, whatever events it subscribes to.
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Success 202 {object} dto.Response
+// @Failure 400 {object} dto.ErrorResponse "Invalid ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 404 {object} dto.ErrorResponse "Webhook not found"
+// @Security BearerAuth
+// @Router /api/v0/admin/webhooks/{id}/ping [post]
+func (h *WebhookHandler) PingHandler(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+
+	event, err := h.WebhookUC.Ping(id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "ping queued", "response": event})
+}
@@ -0,0 +1,68 @@
+package importer
+
+import (
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FormatOFX = "ofx"
+	FormatQIF = "qif"
+)
+
+// Transaction is a single statement line read from a bank file. Amount is
+// negative for money leaving the account and positive for deposits, rounded
+// to whole currency units like every other amount in the tracker.
+type Transaction struct {
+	ID      string
+	Date    time.Time
+	Amount  int64
+	Payee   string
+	Memo    string
+	Account string
+}
+
+// Parse reads a bank statement in the given format ("ofx", "qfx" or "qif").
+func Parse(format string, r io.Reader) ([]Transaction, error) {
+	switch strings.ToLower(format) {
+	case FormatOFX, "qfx":
+		return ParseOFX(r)
+	case FormatQIF:
+		return ParseQIF(r)
+	}
+	return nil, errors.New("unsupported import format: " + format)
+}
+
+// parseAmount accepts amounts such as "-1234.56", "1,234.56" and "+12". A
+// comma followed by exactly two trailing digits is a decimal comma, as in
+// "-12,50" or "1.234,56", and the dots are then thousands separators.
+func parseAmount(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	s = strings.ReplaceAll(s, " ", "")
+	if i := strings.LastIndexByte(s, ','); i >= 0 && i == len(s)-3 && isDigits(s[i+1:]) {
+		s = strings.ReplaceAll(s[:i], ".", "") + "." + s[i+1:]
+	}
+	s = strings.ReplaceAll(s, ",", "")
+	if s == "" {
+		return 0, errors.New("empty amount")
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, errors.New("invalid amount: " + s)
+	}
+	return int64(math.Round(f)), nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
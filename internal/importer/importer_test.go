@@ -0,0 +1,222 @@
+package importer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAmount(t *testing.T) {
+	cases := []struct {
+		in   string
+		want int64
+	}{
+		{in: "-1234.56", want: -1235},
+		{in: "1,234.56", want: 1235},
+		{in: "+12", want: 12},
+		{in: " -40.2 ", want: -40},
+		// decimal comma
+		{in: "-12,50", want: -13},
+		{in: "1.234,56", want: 1235},
+		{in: "1 234,40", want: 1234},
+		// thousands separators only
+		{in: "1,234", want: 1234},
+		{in: "-1,234,567", want: -1234567},
+	}
+	for _, c := range cases {
+		got, err := parseAmount(c.in)
+		if err != nil || got != c.want {
+			t.Errorf("parseAmount(%q) = %d, %v; want %d", c.in, got, err, c.want)
+		}
+	}
+
+	for _, in := range []string{"", "  ", "12.3.4", "abc"} {
+		if _, err := parseAmount(in); err == nil {
+			t.Errorf("parseAmount(%q) did not fail", in)
+		}
+	}
+}
+
+const ofxSGML = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+
+<OFX>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<BANKACCTFROM><BANKID>123<ACCTID>9876<ACCTTYPE>CHECKING</BANKACCTFROM>
+<BANKTRANLIST>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20261002120000[-3:BRT]
+<TRNAMT>-45,90
+<FITID>T1
+<NAME>Market &amp; Co
+<MEMO>card
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20261003
+<TRNAMT>1500.00
+<FITID>T2
+<PAYEE>Salary
+</STMTTRN>
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`
+
+const ofxXML = `<?xml version="1.0" encoding="UTF-8"?>
+<?OFX OFXHEADER="200" VERSION="220"?>
+<OFX>
+  <CREDITCARDMSGSRSV1><CCSTMTTRNRS><CCSTMTRS>
+    <CCACCTFROM><ACCTID>4111</ACCTID></CCACCTFROM>
+    <BANKTRANLIST>
+      <STMTTRN>
+        <TRNTYPE>DEBIT</TRNTYPE>
+        <DTPOSTED>20261005</DTPOSTED>
+        <TRNAMT>-1,200.00</TRNAMT>
+        <FITID>X9</FITID>
+        <NAME>Airline</NAME>
+      </STMTTRN>
+    </BANKTRANLIST>
+  </CCSTMTRS></CCSTMTTRNRS></CREDITCARDMSGSRSV1>
+</OFX>
+`
+
+func TestParseOFX(t *testing.T) {
+	cases := []struct {
+		name   string
+		format string
+		file   string
+		want   []Transaction
+	}{
+		{
+			name:   "sgml",
+			format: "ofx",
+			file:   ofxSGML,
+			want: []Transaction{
+				{ID: "T1", Date: time.Date(2026, 10, 2, 12, 0, 0, 0, time.UTC), Amount: -46, Payee: "Market & Co", Memo: "card", Account: "9876"},
+				{ID: "T2", Date: time.Date(2026, 10, 3, 0, 0, 0, 0, time.UTC), Amount: 1500, Payee: "Salary", Account: "9876"},
+			},
+		},
+		{
+			name:   "xml",
+			format: "ofx",
+			file:   ofxXML,
+			want: []Transaction{
+				{ID: "X9", Date: time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC), Amount: -1200, Payee: "Airline", Account: "4111"},
+			},
+		},
+		{
+			name:   "qfx",
+			format: "QFX",
+			file:   ofxXML,
+			want: []Transaction{
+				{ID: "X9", Date: time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC), Amount: -1200, Payee: "Airline", Account: "4111"},
+			},
+		},
+	}
+	for _, c := range cases {
+		got, err := Parse(c.format, strings.NewReader(c.file))
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: got %+v, want %+v", c.name, got, c.want)
+		}
+	}
+
+	invalid := []struct {
+		name string
+		file string
+	}{
+		{name: "no ofx element", file: "OFXHEADER:100\n<FOO>"},
+		{name: "no fitid", file: "<OFX><STMTTRN><DTPOSTED>20261002<TRNAMT>-1</STMTTRN></OFX>"},
+		{name: "bad date", file: "<OFX><STMTTRN><DTPOSTED>2026<TRNAMT>-1<FITID>A</STMTTRN></OFX>"},
+		{name: "bad amount", file: "<OFX><STMTTRN><DTPOSTED>20261002<TRNAMT>ten<FITID>A</STMTTRN></OFX>"},
+	}
+	for _, c := range invalid {
+		if _, err := ParseOFX(strings.NewReader(c.file)); err == nil {
+			t.Errorf("%s: no error", c.name)
+		}
+	}
+}
+
+func TestParseQIF(t *testing.T) {
+	cases := []struct {
+		name string
+		file string
+		want []Transaction
+	}{
+		{
+			name: "bank",
+			file: "!Type:Bank\nD10/2/2026\nT-45.90\nPMarket\nMcard\n^\nD10/3'26\nU1,500.00\nPSalary\n^\n",
+			want: []Transaction{
+				{Date: time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC), Amount: -46, Payee: "Market", Memo: "card"},
+				{Date: time.Date(2026, 10, 3, 0, 0, 0, 0, time.UTC), Amount: 1500, Payee: "Salary"},
+			},
+		},
+		{
+			name: "decimal comma and iso dates",
+			file: "!Type:CCard\r\nD2026-10-05\r\nT-1.200,00\r\nPAirline\r\n^\r\n",
+			want: []Transaction{
+				{Date: time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC), Amount: -1200, Payee: "Airline"},
+			},
+		},
+		{
+			name: "empty records",
+			file: "!Type:Bank\n^\n^\n",
+		},
+	}
+	for _, c := range cases {
+		got, err := Parse("qif", strings.NewReader(c.file))
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if len(got) != len(c.want) {
+			t.Errorf("%s: got %+v, want %+v", c.name, got, c.want)
+			continue
+		}
+		for i := range got {
+			if got[i].ID == "" {
+				t.Errorf("%s: transaction %d has no id", c.name, i)
+			}
+			got[i].ID = ""
+			if got[i] != c.want[i] {
+				t.Errorf("%s: transaction %d = %+v, want %+v", c.name, i, got[i], c.want[i])
+			}
+		}
+	}
+
+	// identical lines get distinct, stable ids
+	same := "D10/2/2026\nT-5\nPBus\n^\nD10/2/2026\nT-5\nPBus\n^\n"
+	first, err := ParseQIF(strings.NewReader(same))
+	if err != nil || len(first) != 2 || first[0].ID == first[1].ID {
+		t.Fatalf("duplicates = %+v, %v", first, err)
+	}
+	again, _ := ParseQIF(strings.NewReader(same))
+	if again[0].ID != first[0].ID || again[1].ID != first[1].ID {
+		t.Fatalf("ids changed between reads: %+v, %+v", first, again)
+	}
+
+	invalid := []struct {
+		name string
+		file string
+	}{
+		{name: "bad date", file: "D31/31/2026\nT-1\n^\n"},
+		{name: "bad amount", file: "D10/2/2026\nTten\n^\n"},
+		{name: "no date", file: "T-1\nPBus\n^\n"},
+	}
+	for _, c := range invalid {
+		if _, err := ParseQIF(strings.NewReader(c.file)); err == nil {
+			t.Errorf("%s: no error", c.name)
+		}
+	}
+
+	if _, err := Parse("csv", strings.NewReader("")); err == nil {
+		t.Error("Parse of an unsupported format did not fail")
+	}
+}
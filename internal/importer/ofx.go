@@ -0,0 +1,126 @@
+package importer
+
+import (
+	"bufio"
+	"errors"
+	"html"
+	"io"
+	"strings"
+	"time"
+)
+
+// ParseOFX reads the bank and credit card transactions of an OFX/QFX file.
+// Both the SGML flavour (OFX 1.x, leaf elements are not closed) and the XML
+// flavour (OFX 2.x) are supported.
+func ParseOFX(r io.Reader) ([]Transaction, error) {
+	data, err := io.ReadAll(bufio.NewReader(r))
+	if err != nil {
+		return nil, err
+	}
+
+	body := string(data)
+	start := strings.Index(strings.ToUpper(body), "<OFX>")
+	if start < 0 {
+		return nil, errors.New("invalid OFX file: <OFX> element not found")
+	}
+	body = body[start:]
+
+	var (
+		transactions []Transaction
+		current      map[string]string
+		account      string
+	)
+
+	for len(body) > 0 {
+		open := strings.IndexByte(body, '<')
+		if open < 0 {
+			break
+		}
+		end := strings.IndexByte(body[open:], '>')
+		if end < 0 {
+			break
+		}
+		tag := strings.ToUpper(strings.TrimSpace(body[open+1 : open+end]))
+		body = body[open+end+1:]
+
+		// the text up to the next tag is the value of a leaf element
+		next := strings.IndexByte(body, '<')
+		if next < 0 {
+			next = len(body)
+		}
+		value := html.UnescapeString(strings.TrimSpace(body[:next]))
+
+		switch {
+		case tag == "STMTTRN":
+			current = map[string]string{}
+		case tag == "/STMTTRN":
+			if current != nil {
+				t, err := ofxTransaction(current, account)
+				if err != nil {
+					return nil, err
+				}
+				transactions = append(transactions, t)
+			}
+			current = nil
+		case tag == "ACCTID":
+			account = value
+		case strings.HasPrefix(tag, "/"), strings.HasPrefix(tag, "?"), strings.HasPrefix(tag, "!"):
+			// closing tags of leaf elements, processing instructions, comments
+		default:
+			if current != nil && value != "" {
+				if _, ok := current[tag]; !ok {
+					current[tag] = value
+				}
+			}
+		}
+	}
+
+	return transactions, nil
+}
+
+func ofxTransaction(fields map[string]string, account string) (Transaction, error) {
+	date, err := parseOFXDate(fields["DTPOSTED"])
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	amount, err := parseAmount(fields["TRNAMT"])
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	id := fields["FITID"]
+	if id == "" {
+		return Transaction{}, errors.New("invalid OFX transaction: FITID is missing")
+	}
+
+	payee := fields["NAME"]
+	if payee == "" {
+		payee = fields["PAYEE"]
+	}
+
+	return Transaction{
+		ID:      id,
+		Date:    date,
+		Amount:  amount,
+		Payee:   payee,
+		Memo:    fields["MEMO"],
+		Account: account,
+	}, nil
+}
+
+// parseOFXDate parses YYYYMMDD[HHMMSS[.XXX]][[gmt offset:tz name]]. The
+// offset is ignored, only the calendar date matters for a purchase.
+func parseOFXDate(s string) (time.Time, error) {
+	if i := strings.IndexAny(s, ".["); i >= 0 {
+		s = s[:i]
+	}
+
+	switch {
+	case len(s) >= 14:
+		return time.Parse("20060102150405", s[:14])
+	case len(s) >= 8:
+		return time.Parse("20060102", s[:8])
+	}
+	return time.Time{}, errors.New("invalid OFX date: " + s)
+}
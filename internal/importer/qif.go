@@ -0,0 +1,101 @@
+package importer
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+var qifDateLayouts = []string{
+	"1/2/2006",
+	"1/2/06",
+	"1/2'2006",
+	"1/2'06",
+	"1-2-2006",
+	"2006-01-02",
+}
+
+// ParseQIF reads the transactions of a QIF file. QIF has no transaction ids,
+// so each transaction gets a stable id derived from its content; identical
+// lines within a file are told apart by their position among the duplicates.
+func ParseQIF(r io.Reader) ([]Transaction, error) {
+	scanner := bufio.NewScanner(r)
+
+	var (
+		transactions []Transaction
+		current      Transaction
+		hasFields    bool
+		seen         = map[string]int{}
+		line         int
+	)
+
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if text == "" {
+			continue
+		}
+
+		code, value := text[0], strings.TrimSpace(text[1:])
+		switch code {
+		case '!':
+			// section header such as !Type:Bank
+		case 'D':
+			date, err := parseQIFDate(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			current.Date = date
+			hasFields = true
+		case 'T', 'U':
+			amount, err := parseAmount(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			current.Amount = amount
+			hasFields = true
+		case 'P':
+			current.Payee = value
+			hasFields = true
+		case 'M':
+			current.Memo = value
+			hasFields = true
+		case '^':
+			if hasFields {
+				if current.Date.IsZero() {
+					return nil, fmt.Errorf("line %d: transaction without date", line)
+				}
+				key := qifKey(current)
+				seen[key]++
+				current.ID = fmt.Sprintf("%s-%d", key, seen[key])
+				transactions = append(transactions, current)
+			}
+			current, hasFields = Transaction{}, false
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+func parseQIFDate(s string) (time.Time, error) {
+	s = strings.ReplaceAll(s, " ", "")
+	for _, layout := range qifDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("invalid QIF date: " + s)
+}
+
+func qifKey(t Transaction) string {
+	sum := sha1.Sum([]byte(fmt.Sprintf("%s|%d|%s|%s", t.Date.Format("2006-01-02"), t.Amount, t.Payee, t.Memo)))
+	return hex.EncodeToString(sum[:10])
+}
@@ -2,64 +2,86 @@ package middleware
 
 import (
 	"errors"
-	"money-tracker/internal/config"
 	"money-tracker/internal/constants"
 	"money-tracker/internal/entity"
-
+	"money-tracker/internal/usecase"
 	"net/http"
-	"os"
+
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
 )
 
 type UserContext struct {
 	ID          uint   `json:"id"`
 	Email       string `json:"email"`
-	UserName    string `json:"user_name`
+	UserName    string `json:"user_name"`
 	LevelManage int8   `json:"level_manage"`
 	Name        string `json:"name"`
 }
 
-// AuthMiddleware validates the JWT token
-func AuthMiddleware(requiredLevel []int8) gin.HandlerFunc {
+// APIKeyContextKey holds the entity.APIKey of a request authenticated by an
+// API key.
+const APIKeyContextKey = "api_key"
+
+// APIKeyAuthenticator checks API keys, see usecase.APIKeyUseCase.
+type APIKeyAuthenticator interface {
+	Authenticate(key string) (*entity.APIKey, error)
+}
+
+// AuthMiddleware validates the JWT token, or the API key when the bearer
+// token starts with usecase.APIKeyPrefix and apiKeys is not nil. Read-only
+// keys only get through on safe methods.
+func AuthMiddleware(db *gorm.DB, jwtSecret string, apiKeys APIKeyAuthenticator, requiredLevel []int8) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get the token from the Authorization header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header missing"})
-			c.Abort()
+			abortWithError(c, usecase.Unauthorized(usecase.CodeUnauthorized, "Authorization header missing"))
 			return
 		}
 
 		// Ensure the token has the "Bearer " prefix
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 		if tokenString == authHeader {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header format must be Bearer {token}"})
-			c.Abort()
+			abortWithError(c, usecase.Unauthorized(usecase.CodeUnauthorized, "Authorization header format must be Bearer {token}"))
 			return
 		}
 
-		// Validate the token
-		_, err := validateToken(tokenString)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
-			c.Abort()
-			return
-		}
+		var userID uint
+		if apiKeys != nil && strings.HasPrefix(tokenString, usecase.APIKeyPrefix) {
+			key, err := apiKeys.Authenticate(tokenString)
+			if err != nil {
+				abortWithError(c, err)
+				return
+			}
+			if !key.CanWrite() && !safeMethod(c.Request.Method) {
+				abortWithError(c, usecase.Forbidden(usecase.CodeForbidden, "this API key is read-only"))
+				return
+			}
+			userID = key.UserID
+			c.Set(APIKeyContextKey, *key)
+		} else {
+			// Validate the token
+			_, err := validateToken(tokenString, jwtSecret)
+			if err != nil {
+				abortWithError(c, usecase.Unauthorized(usecase.CodeUnauthorized, err.Error()))
+				return
+			}
 
-		var userToken entity.UserToken
-		if err := config.DB.Where("token = ?", tokenString).First(&userToken).Error; err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token is invalid or expired"})
-			c.Abort()
-			return
+			var userToken entity.UserToken
+			if err := db.Where("token = ?", tokenString).First(&userToken).Error; err != nil {
+				abortWithError(c, usecase.Unauthorized(usecase.CodeUnauthorized, "Token is invalid or expired"))
+				return
+			}
+			userID = userToken.UserID
 		}
 
 		var user entity.User
-		if err := config.DB.Where("id = ? AND status_id = ?", userToken.UserID, constants.StatusActive).Select("id", "level_manage", "user_name").First(&user).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find user"})
-			c.Abort()
+		if err := db.Where("id = ? AND status_id = ?", userID, constants.StatusActive).Select("id", "level_manage", "user_name").First(&user).Error; err != nil {
+			abortWithError(c, usecase.Unauthorized(usecase.CodeUnauthorized, "user not found or inactive"))
 			return
 		}
 
@@ -81,8 +103,7 @@ func AuthMiddleware(requiredLevel []int8) gin.HandlerFunc {
 
 		// If not allowed, return Forbidden
 		if !isAllowed {
-			c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to access this resource"})
-			c.Abort()
+			abortWithError(c, usecase.Forbidden(usecase.CodeForbidden, "You do not have permission to access this resource"))
 			return
 		}
 
@@ -102,14 +123,24 @@ func AuthMiddleware(requiredLevel []int8) gin.HandlerFunc {
 	}
 }
 
-// validateToken validates the JWT and returns the claims
-func validateToken(tokenString string) (jwt.MapClaims, error) {
-	// Get the secret key from the environment
-	secretKey := os.Getenv("JWT_SECRET")
-	if secretKey == "" {
-		return nil, errors.New("JWT_SECRET environment variable not set")
+// SessionOnly refuses requests authenticated by an API key, for the routes
+// that manage the account itself. It runs after AuthMiddleware.
+func SessionOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get(APIKeyContextKey); ok {
+			abortWithError(c, usecase.Forbidden(usecase.CodeForbidden, "API keys cannot manage the account, log in instead"))
+			return
+		}
+		c.Next()
 	}
+}
 
+func safeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// validateToken validates the JWT and returns the claims
+func validateToken(tokenString string, secretKey string) (jwt.MapClaims, error) {
 	// Parse and validate the token
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		// Ensure the signing method is HMAC
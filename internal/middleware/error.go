@@ -0,0 +1,205 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
+	"money-tracker/internal/usecase"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+var registerFieldNames sync.Once
+
+// ErrorHandler renders the errors handlers report with c.Error. Handlers
+// add the error and return; the response is written here unless the handler
+// already wrote one. Typed usecase errors keep their code and message, any
+// other error is logged and answered with 500.
+func ErrorHandler() gin.HandlerFunc {
+	registerFieldNames.Do(useJSONFieldNames)
+
+	return func(c *gin.Context) {
+		c.Next()
+		RenderError(c)
+	}
+}
+
+// RenderError writes the response for the last error of c if nothing was
+// written yet. Middlewares that need the final response before the chain
+// returns, like Idempotency, call it themselves.
+func RenderError(c *gin.Context) {
+	if len(c.Errors) == 0 || c.Writer.Written() {
+		return
+	}
+
+	last := c.Errors.Last()
+	e := toUsecaseError(last)
+	status := errorStatus(e)
+	if status == http.StatusInternalServerError {
+		log.Printf("%s %s: %v", c.Request.Method, c.Request.URL.Path, last.Err)
+	}
+
+	writeError(c, status, e)
+}
+
+// abortWithError stops the chain; ErrorHandler writes the response.
+func abortWithError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
+
+func writeError(c *gin.Context, status int, e *usecase.Error) {
+	if e.RetryAfter > 0 {
+		// whole seconds, rounded up so the client never retries too early
+		c.Header("Retry-After", strconv.Itoa(int((e.RetryAfter+time.Second-1)/time.Second)))
+	}
+	c.JSON(status, dto.ErrorResponse{
+		Message: e.Message,
+		Error: dto.ErrorBody{
+			Code:    e.Code,
+			Message: e.Message,
+			Fields:  e.Fields,
+		},
+		Response: e.Details,
+	})
+}
+
+func toUsecaseError(ginErr *gin.Error) *usecase.Error {
+	err := ginErr.Err
+
+	var e *usecase.Error
+	var fields validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+
+	switch {
+	case errors.As(err, &e):
+		return e
+	case errors.As(err, &fields):
+		return usecase.Validation("error validation input", fieldErrors(fields)...)
+	case errors.As(err, &typeErr):
+		return usecase.InvalidField(typeErr.Field, "type", typeErr.Field+" must be "+jsonType(typeErr.Type))
+	case errors.As(err, &syntaxErr):
+		return &usecase.Error{Kind: usecase.KindValidation, Code: usecase.CodeInvalidRequest, Message: "invalid JSON: " + syntaxErr.Error()}
+	case errors.Is(err, io.EOF):
+		return &usecase.Error{Kind: usecase.KindValidation, Code: usecase.CodeInvalidRequest, Message: "request body is empty"}
+	case ginErr.IsType(gin.ErrorTypeBind):
+		return &usecase.Error{Kind: usecase.KindValidation, Code: usecase.CodeInvalidRequest, Message: err.Error()}
+	case errors.Is(err, entity.ErrVersionConflict):
+		return usecase.Conflict(usecase.CodeVersionConflict, err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return usecase.NotFound(usecase.CodeNotFound, "not found")
+	}
+
+	return &usecase.Error{Code: usecase.CodeInternal, Message: "internal server error", Err: err}
+}
+
+func errorStatus(e *usecase.Error) int {
+	switch e.Kind {
+	case usecase.KindValidation:
+		return http.StatusBadRequest
+	case usecase.KindNotFound:
+		return http.StatusNotFound
+	case usecase.KindConflict:
+		return http.StatusConflict
+	case usecase.KindForbidden:
+		return http.StatusForbidden
+	case usecase.KindUnauthorized:
+		return http.StatusUnauthorized
+	case usecase.KindRateLimited:
+		return http.StatusTooManyRequests
+	case usecase.KindPrecondition:
+		return http.StatusPreconditionRequired
+	}
+	return http.StatusInternalServerError
+}
+
+func fieldErrors(errs validator.ValidationErrors) []dto.FieldError {
+	fields := make([]dto.FieldError, 0, len(errs))
+	for _, fe := range errs {
+		// drop the struct name: "AddPurchaseInput.category_id" -> "category_id"
+		field := fe.Namespace()
+		if i := strings.Index(field, "."); i >= 0 {
+			field = field[i+1:]
+		}
+
+		fields = append(fields, dto.FieldError{
+			Field:   field,
+			Code:    fe.Tag(),
+			Message: fieldMessage(field, fe),
+		})
+	}
+	return fields
+}
+
+func fieldMessage(field string, fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return field + " is required"
+	case "oneof":
+		return field + " must be one of: " + fe.Param()
+	case "min":
+		return field + " must be at least " + fe.Param()
+	case "max":
+		return field + " must be at most " + fe.Param()
+	case "email":
+		return field + " must be a valid email"
+	}
+	return field + " failed the " + fe.Tag() + " rule"
+}
+
+// jsonType names t the way a JSON client sees it.
+func jsonType(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Struct, reflect.Map:
+		return "an object"
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	}
+	return "a " + t.String()
+}
+
+// useJSONFieldNames makes the validator report fields by their json (or
+// form) name, which is what clients send.
+func useJSONFieldNames() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		for _, key := range []string{"json", "form"} {
+			name := strings.Split(f.Tag.Get(key), ",")[0]
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+		return f.Name
+	})
+}
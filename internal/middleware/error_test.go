@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
+	"money-tracker/internal/usecase"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// render answers a request whose handler reported err.
+func render(t *testing.T, err error) (*httptest.ResponseRecorder, dto.ErrorResponse) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.GET("/", func(c *gin.Context) { _ = c.Error(err) })
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var body dto.ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("body %q: %v", rec.Body.String(), err)
+	}
+	return rec, body
+}
+
+func TestErrorHandlerStatus(t *testing.T) {
+	cases := []struct {
+		err     error
+		status  int
+		code    string
+		message string
+	}{
+		{usecase.InvalidField("amount", "required", "amount is required"), http.StatusBadRequest, usecase.CodeValidation, "amount is required"},
+		{usecase.ErrPurchaseNotFound, http.StatusNotFound, usecase.CodePurchaseNotFound, "purchase not found"},
+		{usecase.ErrTagDuplicate, http.StatusConflict, usecase.CodeTagDuplicate, "tag duplicate"},
+		{usecase.Forbidden(usecase.CodeForbidden, "read-only"), http.StatusForbidden, usecase.CodeForbidden, "read-only"},
+		{usecase.ErrInvalidCredentials, http.StatusUnauthorized, usecase.CodeInvalidCredentials, "invalid user name or password"},
+		{usecase.RateLimited(usecase.CodeTooManyAttempts, "slow down", time.Second), http.StatusTooManyRequests, usecase.CodeTooManyAttempts, "slow down"},
+		{usecase.ErrVersionRequired, http.StatusPreconditionRequired, usecase.CodeVersionRequired, usecase.ErrVersionRequired.Message},
+		// errors that are not typed yet
+		{fmt.Errorf("update: %w", entity.ErrVersionConflict), http.StatusConflict, usecase.CodeVersionConflict, "update: " + entity.ErrVersionConflict.Error()},
+		{gorm.ErrRecordNotFound, http.StatusNotFound, usecase.CodeNotFound, "not found"},
+		{errors.New("connection refused"), http.StatusInternalServerError, usecase.CodeInternal, "internal server error"},
+		{&usecase.Error{Kind: "unknown", Code: "odd", Message: "odd"}, http.StatusInternalServerError, "odd", "odd"},
+	}
+	for _, c := range cases {
+		rec, body := render(t, c.err)
+		if rec.Code != c.status || body.Error.Code != c.code || body.Error.Message != c.message || body.Message != c.message {
+			t.Errorf("%v: %d %+v; want %d %s %q", c.err, rec.Code, body, c.status, c.code, c.message)
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+			t.Errorf("%v: content type %q", c.err, ct)
+		}
+	}
+}
+
+func TestErrorHandlerBody(t *testing.T) {
+	_, body := render(t, usecase.Validation("bad input",
+		dto.FieldError{Field: "amount", Code: "required", Message: "amount is required"},
+		dto.FieldError{Field: "date", Code: "required", Message: "date is required"}))
+	if len(body.Error.Fields) != 2 || body.Error.Fields[1] != (dto.FieldError{Field: "date", Code: "required", Message: "date is required"}) {
+		t.Errorf("fields %+v", body.Error.Fields)
+	}
+
+	_, body = render(t, usecase.Conflict(usecase.CodeVersionConflict, "changed").WithDetails(map[string]int{"version": 3}))
+	if details, ok := body.Response.(map[string]interface{}); !ok || details["version"] != float64(3) {
+		t.Errorf("details %+v", body.Response)
+	}
+}
+
+func TestErrorHandlerRetryAfter(t *testing.T) {
+	cases := map[time.Duration]string{
+		0:                       "",
+		time.Second:             "1",
+		1500 * time.Millisecond: "2",
+		time.Millisecond:        "1",
+		time.Minute:             "60",
+	}
+	for wait, want := range cases {
+		rec, _ := render(t, usecase.RateLimited(usecase.CodeTooManyAttempts, "slow down", wait))
+		if got := rec.Header().Get("Retry-After"); got != want {
+			t.Errorf("Retry-After for %v = %q, want %q", wait, got, want)
+		}
+	}
+}
+
+func TestErrorHandlerKeepsWrittenResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.GET("/", func(c *gin.Context) {
+		c.String(http.StatusAccepted, "done")
+		_ = c.Error(errors.New("logged only"))
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusAccepted || rec.Body.String() != "done" {
+		t.Errorf("%d %q", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,180 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"money-tracker/internal/entity"
+	"money-tracker/internal/usecase"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	IdempotencyHeader = "Idempotency-Key"
+	maxIdempotencyKey = 255
+)
+
+// responseRecorder keeps a copy of the response body while writing it.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency makes a create endpoint safe to retry. The response to a
+// request with an Idempotency-Key header is stored for ttl: a retry with the
+// same key and body gets the stored response, a retry with the same key and
+// another body gets 422, and a retry while the first request still runs
+// gets 409. Server errors are not stored, so the request can be retried.
+// Keys are scoped to the route and to the client that sent them.
+func Idempotency(repo entity.IdempotencyRepository, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKey {
+			abortWithError(c, usecase.InvalidField(IdempotencyHeader, "max", "Idempotency-Key is too long"))
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			abortWithError(c, &usecase.Error{Kind: usecase.KindValidation, Code: usecase.CodeInvalidRequest, Message: err.Error()})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		sum := sha256.Sum256(body)
+		hash := hex.EncodeToString(sum[:])
+		scope := c.Request.Method + " " + c.FullPath() + " " + idempotencyPrincipal(c)
+
+		record, err := entity.NewIdempotencyKey(key, scope, hash, ttl)
+		if err != nil {
+			abortWithError(c, usecase.InvalidField(IdempotencyHeader, "invalid", err.Error()))
+			return
+		}
+
+		reserved, err := repo.Reserve(record)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		if !reserved {
+			existing, err := repo.Find(key, scope)
+			if err != nil {
+				abortWithError(c, usecase.Conflict(usecase.CodeIdempotencyBusy, "request with this Idempotency-Key is in progress"))
+				return
+			}
+
+			if existing.ExpiresAt.Before(time.Now()) {
+				// an expired key is free again
+				if err := repo.Delete(existing.ID); err == nil {
+					reserved, err = repo.Reserve(record)
+				}
+				if err != nil || !reserved {
+					abortWithError(c, usecase.Conflict(usecase.CodeIdempotencyBusy, "request with this Idempotency-Key is in progress"))
+					return
+				}
+			} else {
+				replayIdempotent(c, existing, hash)
+				return
+			}
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		defer func() {
+			// a panicking handler leaves no response to store
+			if r := recover(); r != nil {
+				repo.Delete(record.ID)
+				panic(r)
+			}
+		}()
+
+		c.Next()
+		// errors are rendered by ErrorHandler after this middleware returns,
+		// too late for the recorder
+		RenderError(c)
+
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			err = repo.Delete(record.ID)
+		} else {
+			err = repo.Complete(record.ID, status, recorder.body.Bytes())
+		}
+		if err != nil {
+			log.Printf("idempotency: storing response for key %q failed: %v", key, err)
+		}
+	}
+}
+
+// idempotencyPrincipal names who sent the request, so clients that pick the
+// same key never see each other's responses: the logged in user, else a
+// hash of the credentials or API key sent, else the client address.
+func idempotencyPrincipal(c *gin.Context) string {
+	if v, ok := c.Get("user"); ok {
+		if user, ok := v.(entity.User); ok {
+			return "user:" + strconv.FormatUint(uint64(user.ID), 10)
+		}
+	}
+	if auth := c.GetHeader("Authorization"); auth != "" {
+		sum := sha256.Sum256([]byte(auth))
+		return "credentials:" + hex.EncodeToString(sum[:16])
+	}
+	return "client:" + c.ClientIP()
+}
+
+// PruneIdempotencyKeys deletes expired keys every interval until ctx is
+// done. It runs as a background worker of the server.
+func PruneIdempotencyKeys(ctx context.Context, repo entity.IdempotencyRepository, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		if err := repo.DeleteExpired(time.Now()); err != nil {
+			log.Printf("idempotency: prune failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func replayIdempotent(c *gin.Context, existing *entity.IdempotencyKey, hash string) {
+	if existing.RequestHash != hash {
+		writeError(c, http.StatusUnprocessableEntity, usecase.Conflict(usecase.CodeIdempotencyReused, "Idempotency-Key was already used with another request body"))
+		c.Abort()
+		return
+	}
+	if !existing.Completed {
+		abortWithError(c, usecase.Conflict(usecase.CodeIdempotencyBusy, "request with this Idempotency-Key is in progress"))
+		return
+	}
+
+	c.Header("Idempotent-Replayed", "true")
+	c.Data(existing.StatusCode, "application/json; charset=utf-8", existing.Response)
+	c.Abort()
+}
@@ -0,0 +1,32 @@
+// Package notify delivers messages to users, e.g. password reset links.
+package notify
+
+import "log"
+
+// Message is addressed to a user; the Notifier knows how to reach them.
+type Message struct {
+	UserID   uint
+	UserName string
+	Subject  string
+	Body     string
+}
+
+type Notifier interface {
+	Notify(msg Message) error
+}
+
+// Log writes messages to the server log instead of delivering them, for
+// local use. The log then holds secrets like reset tokens, so it must not be
+// used in production.
+type Log struct {
+	Logger *log.Logger
+}
+
+func NewLog() *Log {
+	return &Log{Logger: log.Default()}
+}
+
+func (n *Log) Notify(msg Message) error {
+	n.Logger.Printf("notify user %d (%s): %s\n%s", msg.UserID, msg.UserName, msg.Subject, msg.Body)
+	return nil
+}
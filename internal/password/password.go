@@ -0,0 +1,89 @@
+// Package password decides which passwords are good enough to set.
+package password
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxBytes is as much of a password as bcrypt reads.
+const MaxBytes = 72
+
+var (
+	ErrTooShort = errors.New("password is too short")
+	ErrTooLong  = fmt.Errorf("password is longer than %d bytes", MaxBytes)
+	ErrBreached = errors.New("password is too common, it appears in breached password lists")
+	ErrUserName = errors.New("password must not be the user name")
+)
+
+//go:embed breached.txt
+var breachedList string
+
+// Policy checks new passwords: a minimum length, and not a known breached
+// password or the user name. Nothing else is required of them; long
+// passphrases beat character class rules.
+type Policy struct {
+	MinLength int
+
+	breached map[string]bool
+}
+
+// NewPolicy returns a policy with the built-in breached list. listPath
+// names an optional file with more passwords, one per line, lines starting
+// with # are comments.
+func NewPolicy(minLength int, listPath string) (*Policy, error) {
+	p := &Policy{MinLength: minLength, breached: map[string]bool{}}
+	if err := p.addList(strings.NewReader(breachedList)); err != nil {
+		return nil, err
+	}
+	if listPath == "" {
+		return p, nil
+	}
+
+	f, err := os.Open(listPath)
+	if err != nil {
+		return nil, fmt.Errorf("breached password list: %w", err)
+	}
+	defer f.Close()
+	if err := p.addList(f); err != nil {
+		return nil, fmt.Errorf("breached password list %s: %w", listPath, err)
+	}
+	return p, nil
+}
+
+func (p *Policy) addList(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p.breached[strings.ToLower(line)] = true
+	}
+	return scanner.Err()
+}
+
+// Check returns one of the errors of this package when password may not be
+// set for the user.
+func (p *Policy) Check(password string, userName string) error {
+	if utf8.RuneCountInString(password) < p.MinLength || password == "" {
+		return ErrTooShort
+	}
+	if len(password) > MaxBytes {
+		return ErrTooLong
+	}
+	lower := strings.ToLower(password)
+	if p.breached[lower] {
+		return ErrBreached
+	}
+	if userName != "" && lower == strings.ToLower(strings.TrimSpace(userName)) {
+		return ErrUserName
+	}
+	return nil
+}
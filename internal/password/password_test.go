@@ -0,0 +1,56 @@
+package password
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	p, err := NewPolicy(8, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		password string
+		userName string
+		want     error
+	}{
+		{"", "alice", ErrTooShort},
+		{"short", "alice", ErrTooShort},
+		// length counts characters, not bytes
+		{"päßwörtä", "alice", nil},
+		{string(make([]byte, MaxBytes+1)), "alice", ErrTooLong},
+		{"Password123", "alice", ErrBreached},
+		{"Alice.Example", "alice.example", ErrUserName},
+		{"correct horse battery staple", "alice", nil},
+	}
+	for _, tt := range tests {
+		if err := p.Check(tt.password, tt.userName); !errors.Is(err, tt.want) {
+			t.Errorf("Check(%q, %q) = %v, want %v", tt.password, tt.userName, err, tt.want)
+		}
+	}
+}
+
+func TestExtraList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "list.txt")
+	if err := os.WriteFile(path, []byte("# ours\nMoneyMoneyMoney\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	p, err := NewPolicy(8, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Check("moneymoneymoney", ""); !errors.Is(err, ErrBreached) {
+		t.Fatalf("extra list: %v", err)
+	}
+	if err := p.Check("password", ""); !errors.Is(err, ErrBreached) {
+		t.Fatalf("built-in list: %v", err)
+	}
+
+	if _, err := NewPolicy(8, filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Fatal("a missing list was accepted")
+	}
+}
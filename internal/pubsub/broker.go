@@ -0,0 +1,136 @@
+// Package pubsub is an in-process publish/subscribe hub for change events,
+// feeding the server-sent events stream. Nothing is persisted: events live
+// in a short history for reconnecting clients and are gone on restart.
+package pubsub
+
+import (
+	"sync"
+	"time"
+)
+
+// Event is one change. IDs grow by one per event for the life of the
+// process, so a client can resume after the last id it saw.
+type Event struct {
+	ID   uint64      `json:"id"`
+	Type string      `json:"type"`
+	At   time.Time   `json:"at"`
+	Data interface{} `json:"data"`
+}
+
+// Broker fans events out to subscribers. Publish never blocks: a
+// subscriber that falls a full buffer behind is dropped, and is expected to
+// reconnect and catch up from the history.
+type Broker struct {
+	mu      sync.Mutex
+	subs    map[*Subscription]struct{}
+	lastID  uint64
+	history []Event
+	keep    int
+	closed  bool
+
+	buffer int
+	now    func() time.Time
+}
+
+// NewBroker keeps the last history events for resuming subscribers and
+// buffers up to buffer events per subscriber.
+func NewBroker(history int, buffer int) *Broker {
+	return &Broker{
+		subs:   map[*Subscription]struct{}{},
+		keep:   history,
+		buffer: buffer,
+		now:    time.Now,
+	}
+}
+
+// Subscription receives the events published after it was made. C is
+// closed when the subscription is dropped or the broker is closed.
+type Subscription struct {
+	C <-chan Event
+
+	c      chan Event
+	broker *Broker
+}
+
+// Publish sends an event to every subscriber.
+func (b *Broker) Publish(eventType string, data interface{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+
+	b.lastID++
+	e := Event{ID: b.lastID, Type: eventType, At: b.now().UTC(), Data: data}
+	b.history = append(b.history, e)
+	if len(b.history) > b.keep {
+		b.history = b.history[len(b.history)-b.keep:]
+	}
+
+	for s := range b.subs {
+		select {
+		case s.c <- e:
+		default:
+			b.drop(s)
+		}
+	}
+}
+
+// Subscribe starts a subscription. With lastID > 0 it also returns the
+// events after lastID from the history; complete is false when some of
+// them are no longer there, so the client has to reload instead.
+func (b *Broker) Subscribe(lastID uint64) (sub *Subscription, missed []Event, complete bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := make(chan Event, b.buffer)
+	sub = &Subscription{C: c, c: c, broker: b}
+	if b.closed {
+		close(c)
+		return sub, nil, true
+	}
+	b.subs[sub] = struct{}{}
+
+	complete = true
+	if lastID > b.lastID {
+		// the id is from before a restart
+		complete = false
+	} else if lastID > 0 && lastID < b.lastID {
+		if len(b.history) == 0 || b.history[0].ID > lastID+1 {
+			complete = false
+		}
+		for _, e := range b.history {
+			if e.ID > lastID {
+				missed = append(missed, e)
+			}
+		}
+	}
+	return sub, missed, complete
+}
+
+// Close stops the subscription.
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	s.broker.drop(s)
+}
+
+// Close ends every subscription and ignores later events; the server calls
+// it on shutdown so open streams do not hold the shutdown up.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for s := range b.subs {
+		b.drop(s)
+	}
+}
+
+// drop must be called with b.mu held.
+func (b *Broker) drop(s *Subscription) {
+	if _, ok := b.subs[s]; ok {
+		delete(b.subs, s)
+		close(s.c)
+	}
+}
@@ -0,0 +1,94 @@
+package pubsub
+
+import "testing"
+
+func receive(t *testing.T, s *Subscription) Event {
+	t.Helper()
+	select {
+	case e, ok := <-s.C:
+		if !ok {
+			t.Fatal("subscription closed")
+		}
+		return e
+	default:
+		t.Fatal("no event")
+	}
+	return Event{}
+}
+
+func TestBrokerFansOut(t *testing.T) {
+	b := NewBroker(10, 4)
+	a, _, _ := b.Subscribe(0)
+	c, _, _ := b.Subscribe(0)
+
+	b.Publish("purchase.created", 1)
+	for _, s := range []*Subscription{a, c} {
+		if e := receive(t, s); e.ID != 1 || e.Type != "purchase.created" || e.Data != 1 {
+			t.Fatalf("got %+v", e)
+		}
+	}
+
+	c.Close()
+	b.Publish("purchase.deleted", 1)
+	if e := receive(t, a); e.ID != 2 {
+		t.Fatalf("got %+v", e)
+	}
+	if _, ok := <-c.C; ok {
+		t.Fatal("a closed subscription got an event")
+	}
+}
+
+func TestBrokerDropsSlowSubscribers(t *testing.T) {
+	b := NewBroker(10, 2)
+	slow, _, _ := b.Subscribe(0)
+
+	for i := 0; i < 3; i++ {
+		b.Publish("tag.created", i)
+	}
+	receive(t, slow)
+	receive(t, slow)
+	if _, ok := <-slow.C; ok {
+		t.Fatal("a subscriber over its buffer was not dropped")
+	}
+}
+
+func TestBrokerResume(t *testing.T) {
+	b := NewBroker(3, 4)
+	for i := 0; i < 5; i++ {
+		b.Publish("tag.created", i)
+	}
+
+	// events 3 to 5 are still in the history
+	_, missed, complete := b.Subscribe(2)
+	if !complete || len(missed) != 3 || missed[0].ID != 3 || missed[2].ID != 5 {
+		t.Fatalf("resume from 2: complete %v, missed %+v", complete, missed)
+	}
+	// event 2 is gone
+	_, missed, complete = b.Subscribe(1)
+	if complete || len(missed) != 3 {
+		t.Fatalf("resume from 1: complete %v, missed %+v", complete, missed)
+	}
+	_, missed, complete = b.Subscribe(5)
+	if !complete || len(missed) != 0 {
+		t.Fatalf("resume from 5: complete %v, missed %+v", complete, missed)
+	}
+	// ids from before a restart
+	if _, _, complete = b.Subscribe(9); complete {
+		t.Fatal("resume from an unknown id is complete")
+	}
+}
+
+func TestBrokerClose(t *testing.T) {
+	b := NewBroker(3, 4)
+	s, _, _ := b.Subscribe(0)
+	b.Close()
+	if _, ok := <-s.C; ok {
+		t.Fatal("Close left a subscription open")
+	}
+	b.Publish("tag.created", 1)
+	late, _, _ := b.Subscribe(0)
+	if _, ok := <-late.C; ok {
+		t.Fatal("subscribed to a closed broker")
+	}
+	s.Close()
+}
@@ -0,0 +1,106 @@
+// Package ratelimit keeps expiring counters for rate limits and lockouts.
+// The counters live behind Store, so they can move from the process to a
+// shared store (e.g. Redis, whose INCR and EXPIRE map onto it) when the API
+// runs on more than one instance.
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Store is a map of counters that expire.
+type Store interface {
+	// Incr adds one to the counter of key and returns the new value and
+	// when the counter expires. A missing or expired counter starts at zero
+	// and expires ttl from now; counting does not extend it.
+	Incr(key string, ttl time.Duration) (int, time.Time, error)
+	// Get returns the counter of key, zero when it is missing or expired.
+	Get(key string) (int, time.Time, error)
+	// Set replaces the counter of key.
+	Set(key string, value int, expires time.Time) error
+	// Delete removes the counters of keys.
+	Delete(keys ...string) error
+}
+
+const sweepEvery = time.Minute
+
+type counter struct {
+	value   int
+	expires time.Time
+}
+
+// MemoryStore is a Store in the memory of the process. Expired counters are
+// dropped now and then while it is written to.
+type MemoryStore struct {
+	mu        sync.Mutex
+	counters  map[string]counter
+	lastSweep time.Time
+
+	now func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		counters: map[string]counter{},
+		now:      time.Now,
+	}
+}
+
+func (s *MemoryStore) Incr(key string, ttl time.Duration) (int, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+	c, ok := s.counters[key]
+	if !ok || !now.Before(c.expires) {
+		c = counter{expires: now.Add(ttl)}
+	}
+	c.value++
+	s.counters[key] = c
+	return c.value, c.expires, nil
+}
+
+func (s *MemoryStore) Get(key string) (int, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.counters[key]
+	if !ok || !s.now().Before(c.expires) {
+		return 0, time.Time{}, nil
+	}
+	return c.value, c.expires, nil
+}
+
+func (s *MemoryStore) Set(key string, value int, expires time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(s.now())
+	s.counters[key] = counter{value: value, expires: expires}
+	return nil
+}
+
+func (s *MemoryStore) Delete(keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		delete(s.counters, key)
+	}
+	return nil
+}
+
+// sweep drops the expired counters, at most once per sweepEvery.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepEvery {
+		return
+	}
+	s.lastSweep = now
+	for key, c := range s.counters {
+		if !now.Before(c.expires) {
+			delete(s.counters, key)
+		}
+	}
+}
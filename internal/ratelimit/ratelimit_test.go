@@ -0,0 +1,93 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+// newTestStore returns a store whose clock only moves when the test
+// advances it.
+func newTestStore() (*MemoryStore, func(time.Duration)) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+	return s, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestMemoryStoreWindow(t *testing.T) {
+	s, advance := newTestStore()
+	start := s.now()
+
+	for want := 1; want <= 3; want++ {
+		n, expires, _ := s.Incr("k", time.Minute)
+		if n != want || !expires.Equal(start.Add(time.Minute)) {
+			t.Fatalf("Incr #%d = %d, %v", want, n, expires)
+		}
+		// counting does not extend the window
+		advance(10 * time.Second)
+	}
+	if n, _, _ := s.Get("k"); n != 3 {
+		t.Fatalf("Get = %d, want 3", n)
+	}
+
+	// the window ends at its expiry, not after it
+	advance(30 * time.Second)
+	if n, expires, _ := s.Get("k"); n != 0 || !expires.IsZero() {
+		t.Fatalf("Get after the window = %d, %v", n, expires)
+	}
+	n, expires, _ := s.Incr("k", time.Minute)
+	if n != 1 || !expires.Equal(start.Add(2*time.Minute)) {
+		t.Fatalf("Incr in a new window = %d, %v", n, expires)
+	}
+
+	// other keys keep their own windows
+	if n, _, _ := s.Incr("other", time.Hour); n != 1 {
+		t.Fatalf("Incr other = %d", n)
+	}
+}
+
+func TestMemoryStoreSetDelete(t *testing.T) {
+	s, advance := newTestStore()
+
+	until := s.now().Add(time.Hour)
+	_ = s.Set("lock", 4, until)
+	if n, expires, _ := s.Get("lock"); n != 4 || !expires.Equal(until) {
+		t.Fatalf("Get = %d, %v", n, expires)
+	}
+	// an Incr counts on in the window Set started
+	if n, expires, _ := s.Incr("lock", time.Minute); n != 5 || !expires.Equal(until) {
+		t.Fatalf("Incr = %d, %v", n, expires)
+	}
+
+	_ = s.Set("a", 1, s.now().Add(time.Hour))
+	_ = s.Delete("lock", "a", "missing")
+	if n, _, _ := s.Get("lock"); n != 0 {
+		t.Fatalf("Get after Delete = %d", n)
+	}
+
+	// a counter set in the past is expired straight away
+	_ = s.Set("old", 1, s.now().Add(-time.Second))
+	advance(time.Second)
+	if n, _, _ := s.Get("old"); n != 0 {
+		t.Fatalf("Get of an expired Set = %d", n)
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	s, advance := newTestStore()
+	_, _, _ = s.Incr("short", time.Second)
+	_, _, _ = s.Incr("long", time.Hour)
+
+	// expired counters stay until the next sweep is due
+	advance(2 * time.Second)
+	_, _, _ = s.Incr("long", time.Hour)
+	if len(s.counters) != 2 {
+		t.Fatalf("%d counters before the sweep is due", len(s.counters))
+	}
+
+	advance(sweepEvery)
+	_, _, _ = s.Incr("long", time.Hour)
+	if _, ok := s.counters["short"]; ok || len(s.counters) != 1 {
+		t.Fatalf("counters after a sweep: %v", s.counters)
+	}
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"money-tracker/internal/entity"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type APIKey struct {
+	ID         uint   `gorm:"primaryKey"`
+	UserID     uint   `gorm:"not null;index"`
+	Name       string `gorm:"size:100;not null"`
+	Prefix     string `gorm:"size:16;not null"`
+	KeyHash    string `gorm:"size:64;not null;uniqueIndex"`
+	Scope      string `gorm:"size:10;not null"`
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
+}
+
+type APIKeyRepo struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepo(db *gorm.DB) *APIKeyRepo {
+	return &APIKeyRepo{db: db}
+}
+
+func (rep APIKeyRepo) Insert(key *entity.APIKey) error {
+	return rep.db.Create(key).Error
+}
+
+func (rep APIKeyRepo) FindByHash(key_hash string) (*entity.APIKey, error) {
+	var k entity.APIKey
+	if err := rep.db.Where("key_hash = ?", key_hash).First(&k).Error; err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+func (rep APIKeyRepo) FindByUser(user_id uint) ([]entity.APIKey, error) {
+	var keys []entity.APIKey
+	err := rep.db.Where("user_id = ?", user_id).Order("id DESC").Find(&keys).Error
+	return keys, err
+}
+
+func (rep APIKeyRepo) Delete(user_id uint, id uint) error {
+	result := rep.db.Where("id = ? AND user_id = ?", id, user_id).Delete(&entity.APIKey{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (rep APIKeyRepo) Touch(id uint, at time.Time) error {
+	return rep.db.Model(&entity.APIKey{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
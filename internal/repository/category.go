@@ -13,8 +13,9 @@ type Category struct {
 	Title    string `gorm:"size:255"`
 	StatusID uint   `gorm:"default:1;not null"`
 	TagIDs    string    `gorm:"size:255"`
-	Slug      string `gorm:"size:300";index`
+	Slug      string `gorm:"size:300;index"`
 	Color     string
+	Version   uint `gorm:"default:1;not null"`
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	DeletedAt time.Time `gorm:"index"`
@@ -30,7 +31,11 @@ func NewRepositoryGorm(db *gorm.DB) *RepoGormPostgres {
 
 func (rep RepoGormPostgres) Insert(category *entity.Category) error {
 	c := ToRepoCategory(category)
-	return rep.db.Create(c).Error
+	if err := rep.db.Create(c).Error; err != nil {
+		return err
+	}
+	category.ID = c.ID
+	return nil
 }
 
 func (rep RepoGormPostgres) FindById(id uint) (*entity.Category, error) {
@@ -66,11 +71,13 @@ func (rep RepoGormPostgres) FindAll(input dto.CategoryFindAll) ([]entity.Categor
 		query = query.Where("slug = ?", input.Slug)
 	}
 
-	// Status filter (default to 1 if not provided)
-	if input.StatusID == 0 {
-		query = query.Where("status_id = ?", 1)
-	} else {
-		query = query.Where("status_id = ?", input.StatusID)
+	// Status filter (default to 1 if not provided, none for AnyStatus)
+	if !input.AnyStatus {
+		if input.StatusID == 0 {
+			query = query.Where("status_id = ?", 1)
+		} else {
+			query = query.Where("status_id = ?", input.StatusID)
+		}
 	}
 
 	// if input.CoverId != nil && *input.CoverId > 0 {
@@ -127,10 +134,25 @@ func (rep RepoGormPostgres) Delete(id uint) error {
 	return rep.db.Model(&Category{}).Where("id = ?", id).Update("status_id", 0).Error
 }
 
+// Update saves the category if it is still at category.Version and bumps
+// the version, otherwise it returns entity.ErrVersionConflict.
 func (rep RepoGormPostgres) Update(category *entity.Category) (*entity.Category, error) {
-	if err := rep.db.Save(ToRepoCategory(category)).Error; err != nil {
-		return nil, err
+	c := ToRepoCategory(category)
+	c.Version = category.Version + 1
+
+	result := rep.db.Model(&Category{}).
+		Where("id = ? AND version = ?", category.ID, category.Version).
+		Select("*").
+		Omit("id", "created_at").
+		Updates(c)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, versionConflictOrNotFound(rep.db, &Category{}, category.ID)
 	}
+
+	category.Version = c.Version
 	return category, nil
 }
 
@@ -148,6 +170,7 @@ func (m *Category) ToEntityCategory() *entity.Category {
 		StatusID:  m.StatusID,
 		Slug:      m.Slug,
 		Color:     m.Color,
+		Version:   m.Version,
 		CreatedAt: m.CreatedAt,
 		UpdatedAt: m.UpdatedAt,
 		DeletedAt: m.DeletedAt,
@@ -167,7 +190,8 @@ func ToRepoCategory(e *entity.Category) *Category {
 		Title:    e.Title,
 		StatusID: e.StatusID,
 		Slug:     e.Slug,
-		Color:    e.Color,
+		Color:     e.Color,
+		Version:   e.Version,
 		CreatedAt: e.CreatedAt,
 		UpdatedAt: e.UpdatedAt,
 		DeletedAt: e.DeletedAt,
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"money-tracker/internal/entity"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Goal struct {
+	ID           uint      `gorm:"primaryKey"`
+	Title        string    `gorm:"size:255;not null"`
+	TargetAmount int64     `gorm:"not null"`
+	Deadline     time.Time `gorm:"not null"`
+	Account      string    `gorm:"size:255"`
+	Note         string    `gorm:"size:1000"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+type GoalContribution struct {
+	ID        uint      `gorm:"primaryKey"`
+	GoalID    uint      `gorm:"not null;index"`
+	Amount    int64     `gorm:"not null"`
+	Date      time.Time `gorm:"not null"`
+	Note      string    `gorm:"size:1000"`
+	CreatedAt time.Time
+}
+
+// /--------------------------------- goals ---------------------------------
+
+type GoalRepo struct {
+	db *gorm.DB
+}
+
+func NewGoalRepo(db *gorm.DB) *GoalRepo {
+	return &GoalRepo{db: db}
+}
+
+func (rep GoalRepo) Insert(goal *entity.Goal) error {
+	return rep.db.Create(goal).Error
+}
+
+func (rep GoalRepo) Update(goal *entity.Goal) error {
+	result := rep.db.Model(&entity.Goal{}).Where("id = ?", goal.ID).Updates(map[string]interface{}{
+		"title":         goal.Title,
+		"target_amount": goal.TargetAmount,
+		"deadline":      goal.Deadline,
+		"account":       goal.Account,
+		"note":          goal.Note,
+		"updated_at":    goal.UpdatedAt,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (rep GoalRepo) Delete(id uint) error {
+	return rep.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ?", id).Delete(&entity.Goal{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Where("goal_id = ?", id).Delete(&entity.GoalContribution{}).Error
+	})
+}
+
+func (rep GoalRepo) FindById(id uint) (*entity.Goal, error) {
+	var g entity.Goal
+	if err := rep.db.Where("id = ?", id).First(&g).Error; err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+func (rep GoalRepo) FindAll() ([]entity.Goal, error) {
+	var goals []entity.Goal
+	err := rep.db.Order("deadline, id").Find(&goals).Error
+	return goals, err
+}
+
+// /----------------------------- contributions -----------------------------
+
+type GoalContributionRepo struct {
+	db *gorm.DB
+}
+
+func NewGoalContributionRepo(db *gorm.DB) *GoalContributionRepo {
+	return &GoalContributionRepo{db: db}
+}
+
+func (rep GoalContributionRepo) Insert(c *entity.GoalContribution) error {
+	return rep.db.Create(c).Error
+}
+
+func (rep GoalContributionRepo) Delete(goal_id uint, id uint) error {
+	result := rep.db.Where("goal_id = ? AND id = ?", goal_id, id).Delete(&entity.GoalContribution{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (rep GoalContributionRepo) FindByGoal(goal_id uint) ([]entity.GoalContribution, error) {
+	var contributions []entity.GoalContribution
+	err := rep.db.Where("goal_id = ?", goal_id).Order("date, id").Find(&contributions).Error
+	return contributions, err
+}
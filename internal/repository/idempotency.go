@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"money-tracker/internal/entity"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IdempotencyKey struct {
+	ID          uint   `gorm:"primaryKey"`
+	Key         string `gorm:"size:255;not null;uniqueIndex:idx_idempotency_key_scope"`
+	Scope       string `gorm:"size:255;not null;uniqueIndex:idx_idempotency_key_scope"`
+	RequestHash string `gorm:"size:64"`
+	Completed   bool   `gorm:"default:false;not null"`
+	StatusCode  int
+	Response    []byte
+	ExpiresAt   time.Time `gorm:"index"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type IdempotencyRepo struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyRepo(db *gorm.DB) *IdempotencyRepo {
+	return &IdempotencyRepo{db: db}
+}
+
+func (rep IdempotencyRepo) Reserve(key *entity.IdempotencyKey) (bool, error) {
+	k := ToRepoIdempotencyKey(key)
+	result := rep.db.Clauses(clause.OnConflict{DoNothing: true}).Create(k)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	key.ID = k.ID
+	return result.RowsAffected > 0, nil
+}
+
+func (rep IdempotencyRepo) Find(key string, scope string) (*entity.IdempotencyKey, error) {
+	var k IdempotencyKey
+	if err := rep.db.Where("key = ? AND scope = ?", key, scope).First(&k).Error; err != nil {
+		return nil, err
+	}
+	return k.ToEntityIdempotencyKey(), nil
+}
+
+func (rep IdempotencyRepo) Complete(id uint, status_code int, response []byte) error {
+	return rep.db.Model(&IdempotencyKey{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"completed":   true,
+			"status_code": status_code,
+			"response":    response,
+		}).Error
+}
+
+func (rep IdempotencyRepo) Delete(id uint) error {
+	return rep.db.Where("id = ?", id).Delete(&IdempotencyKey{}).Error
+}
+
+func (rep IdempotencyRepo) DeleteExpired(now time.Time) error {
+	return rep.db.Where("expires_at < ?", now).Delete(&IdempotencyKey{}).Error
+}
+
+// ///------------------------------------------------------------
+func (m *IdempotencyKey) ToEntityIdempotencyKey() *entity.IdempotencyKey {
+	return &entity.IdempotencyKey{
+		ID:          m.ID,
+		Key:         m.Key,
+		Scope:       m.Scope,
+		RequestHash: m.RequestHash,
+		Completed:   m.Completed,
+		StatusCode:  m.StatusCode,
+		Response:    m.Response,
+		ExpiresAt:   m.ExpiresAt,
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+	}
+}
+
+// Convert entity → repository model
+func ToRepoIdempotencyKey(e *entity.IdempotencyKey) *IdempotencyKey {
+	return &IdempotencyKey{
+		ID:          e.ID,
+		Key:         e.Key,
+		Scope:       e.Scope,
+		RequestHash: e.RequestHash,
+		Completed:   e.Completed,
+		StatusCode:  e.StatusCode,
+		Response:    e.Response,
+		ExpiresAt:   e.ExpiresAt,
+		CreatedAt:   e.CreatedAt,
+		UpdatedAt:   e.UpdatedAt,
+	}
+}
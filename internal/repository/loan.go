@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"money-tracker/internal/entity"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Loan struct {
+	ID           uint      `gorm:"primaryKey"`
+	Name         string    `gorm:"size:255;not null"`
+	Direction    string    `gorm:"size:16;not null"`
+	Principal    int64     `gorm:"not null"`
+	InterestRate float64   `gorm:"not null"`
+	Term         int       `gorm:"not null"`
+	Frequency    string    `gorm:"size:16;not null"`
+	StartDate    time.Time `gorm:"not null"`
+	Note         string    `gorm:"size:1000"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+type LoanPayment struct {
+	ID        uint      `gorm:"primaryKey"`
+	LoanID    uint      `gorm:"not null;index"`
+	Date      time.Time `gorm:"not null"`
+	Amount    int64     `gorm:"not null"`
+	Principal int64     `gorm:"not null"`
+	Interest  int64     `gorm:"not null"`
+	Note      string    `gorm:"size:1000"`
+	CreatedAt time.Time
+}
+
+// /--------------------------------- loans ---------------------------------
+
+type LoanRepo struct {
+	db *gorm.DB
+}
+
+func NewLoanRepo(db *gorm.DB) *LoanRepo {
+	return &LoanRepo{db: db}
+}
+
+func (rep LoanRepo) Insert(loan *entity.Loan) error {
+	return rep.db.Create(loan).Error
+}
+
+func (rep LoanRepo) Update(loan *entity.Loan) error {
+	result := rep.db.Model(&entity.Loan{}).Where("id = ?", loan.ID).Updates(map[string]interface{}{
+		"name":          loan.Name,
+		"direction":     loan.Direction,
+		"principal":     loan.Principal,
+		"interest_rate": loan.InterestRate,
+		"term":          loan.Term,
+		"frequency":     loan.Frequency,
+		"start_date":    loan.StartDate,
+		"note":          loan.Note,
+		"updated_at":    loan.UpdatedAt,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (rep LoanRepo) Delete(id uint) error {
+	return rep.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ?", id).Delete(&entity.Loan{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Where("loan_id = ?", id).Delete(&entity.LoanPayment{}).Error
+	})
+}
+
+func (rep LoanRepo) FindById(id uint) (*entity.Loan, error) {
+	var l entity.Loan
+	if err := rep.db.Where("id = ?", id).First(&l).Error; err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (rep LoanRepo) FindAll() ([]entity.Loan, error) {
+	var loans []entity.Loan
+	err := rep.db.Order("name, id").Find(&loans).Error
+	return loans, err
+}
+
+// /-------------------------------- payments --------------------------------
+
+type LoanPaymentRepo struct {
+	db *gorm.DB
+}
+
+func NewLoanPaymentRepo(db *gorm.DB) *LoanPaymentRepo {
+	return &LoanPaymentRepo{db: db}
+}
+
+func (rep LoanPaymentRepo) Insert(p *entity.LoanPayment) error {
+	return rep.db.Create(p).Error
+}
+
+func (rep LoanPaymentRepo) Delete(loan_id uint, id uint) error {
+	result := rep.db.Where("loan_id = ? AND id = ?", loan_id, id).Delete(&entity.LoanPayment{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (rep LoanPaymentRepo) FindByLoan(loan_id uint) ([]entity.LoanPayment, error) {
+	var payments []entity.LoanPayment
+	err := rep.db.Where("loan_id = ?", loan_id).Order("date, id").Find(&payments).Error
+	return payments, err
+}
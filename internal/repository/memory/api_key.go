@@ -0,0 +1,79 @@
+package memory
+
+import (
+	"money-tracker/internal/entity"
+	"time"
+)
+
+type APIKeyRepo struct {
+	store *Store
+}
+
+func NewAPIKeyRepo(store *Store) *APIKeyRepo {
+	return &APIKeyRepo{store: store}
+}
+
+func (rep APIKeyRepo) Insert(key *entity.APIKey) error {
+	s := rep.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastAPIKey++
+	key.ID = s.lastAPIKey
+	if key.CreatedAt.IsZero() {
+		key.CreatedAt = time.Now()
+	}
+	s.data.apiKeys[key.ID] = *key
+	return nil
+}
+
+func (rep APIKeyRepo) FindByHash(key_hash string) (*entity.APIKey, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	for _, row := range rep.store.data.apiKeys {
+		if row.KeyHash == key_hash {
+			return &row, nil
+		}
+	}
+	return nil, notFound()
+}
+
+func (rep APIKeyRepo) FindByUser(user_id uint) ([]entity.APIKey, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	keys := []entity.APIKey{}
+	rows := byID(rep.store.data.apiKeys)
+	for i := len(rows) - 1; i >= 0; i-- {
+		if rows[i].UserID == user_id {
+			keys = append(keys, rows[i])
+		}
+	}
+	return keys, nil
+}
+
+func (rep APIKeyRepo) Delete(user_id uint, id uint) error {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	row, ok := rep.store.data.apiKeys[id]
+	if !ok || row.UserID != user_id {
+		return notFound()
+	}
+	delete(rep.store.data.apiKeys, id)
+	return nil
+}
+
+func (rep APIKeyRepo) Touch(id uint, at time.Time) error {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	row, ok := rep.store.data.apiKeys[id]
+	if !ok {
+		return nil
+	}
+	row.LastUsedAt = &at
+	rep.store.data.apiKeys[id] = row
+	return nil
+}
@@ -0,0 +1,149 @@
+package memory
+
+import (
+	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
+	"time"
+)
+
+var categoryColumns = map[string]column[entity.Category]{
+	"id":         func(c entity.Category) interface{} { return c.ID },
+	"title":      func(c entity.Category) interface{} { return c.Title },
+	"slug":       func(c entity.Category) interface{} { return c.Slug },
+	"color":      func(c entity.Category) interface{} { return c.Color },
+	"status_id":  func(c entity.Category) interface{} { return c.StatusID },
+	"version":    func(c entity.Category) interface{} { return c.Version },
+	"created_at": func(c entity.Category) interface{} { return c.CreatedAt },
+	"updated_at": func(c entity.Category) interface{} { return c.UpdatedAt },
+	"deleted_at": func(c entity.Category) interface{} { return c.DeletedAt },
+}
+
+type CategoryRepo struct {
+	store *Store
+}
+
+func NewCategoryRepo(store *Store) *CategoryRepo {
+	return &CategoryRepo{store: store}
+}
+
+func (rep CategoryRepo) Insert(category *entity.Category) error {
+	s := rep.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastCategory++
+	row := *category
+	row.ID = s.lastCategory
+	// the columns default to 1
+	if row.StatusID == 0 {
+		row.StatusID = 1
+	}
+	if row.Version == 0 {
+		row.Version = 1
+	}
+	stamp(&row.CreatedAt, &row.UpdatedAt)
+	s.data.categories[row.ID] = row
+
+	category.ID = row.ID
+	return nil
+}
+
+func (rep CategoryRepo) FindById(id uint) (*entity.Category, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	row, ok := rep.store.data.categories[id]
+	if !ok || row.StatusID != 1 {
+		return nil, notFound()
+	}
+	return &row, nil
+}
+
+func (rep CategoryRepo) FindBySlug(slug string, status_id []uint) (*entity.Category, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	for _, row := range byID(rep.store.data.categories) {
+		if row.Slug == slug && containsUint(status_id, row.StatusID) {
+			return &row, nil
+		}
+	}
+	return nil, notFound()
+}
+
+func (rep CategoryRepo) FindAll(input dto.CategoryFindAll) ([]entity.Category, int, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	status := input.StatusID
+	if status == 0 {
+		status = 1
+	}
+
+	var rows []entity.Category
+	for _, row := range byID(rep.store.data.categories) {
+		if input.Title != "" && !ilike(row.Title, input.Title) {
+			continue
+		}
+		if input.ID > 0 && row.ID != input.ID {
+			continue
+		}
+		if input.Slug != "" && row.Slug != input.Slug {
+			continue
+		}
+		if !input.AnyStatus && row.StatusID != status {
+			continue
+		}
+		rows = append(rows, row)
+	}
+
+	orderBy := input.OrderBy
+	if orderBy == "" {
+		orderBy = "id"
+	}
+	if err := orderRows(rows, categoryColumns, orderBy, input.Sort); err != nil {
+		return nil, 0, err
+	}
+
+	var categories []entity.Category
+	categories = append(categories, page(rows, input.Start, input.Limit)...)
+	return categories, len(rows), nil
+}
+
+func (rep CategoryRepo) Delete(id uint) error {
+	s := rep.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if row, ok := s.data.categories[id]; ok {
+		row.StatusID = 0
+		row.UpdatedAt = time.Now()
+		s.data.categories[id] = row
+	}
+	return nil
+}
+
+// Update saves the category if it is still at category.Version and bumps
+// the version, otherwise it returns entity.ErrVersionConflict.
+func (rep CategoryRepo) Update(category *entity.Category) (*entity.Category, error) {
+	s := rep.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, ok := s.data.categories[category.ID]
+	if !ok {
+		return nil, notFound()
+	}
+	if old.Version != category.Version {
+		return nil, entity.ErrVersionConflict
+	}
+
+	row := *category
+	row.Version = category.Version + 1
+	row.CreatedAt = old.CreatedAt
+	row.UpdatedAt = time.Now()
+	s.data.categories[row.ID] = row
+
+	category.Version = row.Version
+	return category, nil
+}
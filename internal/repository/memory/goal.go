@@ -0,0 +1,136 @@
+package memory
+
+import (
+	"money-tracker/internal/entity"
+	"sort"
+	"time"
+)
+
+// /--------------------------------- goals ---------------------------------
+
+type GoalRepo struct {
+	store *Store
+}
+
+func NewGoalRepo(store *Store) *GoalRepo {
+	return &GoalRepo{store: store}
+}
+
+func (rep GoalRepo) Insert(goal *entity.Goal) error {
+	s := rep.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastGoal++
+	goal.ID = s.lastGoal
+	if goal.CreatedAt.IsZero() {
+		goal.CreatedAt = time.Now()
+	}
+	s.data.goals[goal.ID] = *goal
+	return nil
+}
+
+func (rep GoalRepo) Update(goal *entity.Goal) error {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	row, ok := rep.store.data.goals[goal.ID]
+	if !ok {
+		return notFound()
+	}
+	row.Title = goal.Title
+	row.TargetAmount = goal.TargetAmount
+	row.Deadline = goal.Deadline
+	row.Account = goal.Account
+	row.Note = goal.Note
+	row.UpdatedAt = goal.UpdatedAt
+	rep.store.data.goals[goal.ID] = row
+	return nil
+}
+
+func (rep GoalRepo) Delete(id uint) error {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	data := &rep.store.data
+	if _, ok := data.goals[id]; !ok {
+		return notFound()
+	}
+	delete(data.goals, id)
+	for cid, c := range data.contribs {
+		if c.GoalID == id {
+			delete(data.contribs, cid)
+		}
+	}
+	return nil
+}
+
+func (rep GoalRepo) FindById(id uint) (*entity.Goal, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	row, ok := rep.store.data.goals[id]
+	if !ok {
+		return nil, notFound()
+	}
+	return &row, nil
+}
+
+func (rep GoalRepo) FindAll() ([]entity.Goal, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	goals := byID(rep.store.data.goals)
+	sort.SliceStable(goals, func(i, j int) bool { return goals[i].Deadline.Before(goals[j].Deadline) })
+	return goals, nil
+}
+
+// /----------------------------- contributions -----------------------------
+
+type GoalContributionRepo struct {
+	store *Store
+}
+
+func NewGoalContributionRepo(store *Store) *GoalContributionRepo {
+	return &GoalContributionRepo{store: store}
+}
+
+func (rep GoalContributionRepo) Insert(c *entity.GoalContribution) error {
+	s := rep.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastContrib++
+	c.ID = s.lastContrib
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = time.Now()
+	}
+	s.data.contribs[c.ID] = *c
+	return nil
+}
+
+func (rep GoalContributionRepo) Delete(goal_id uint, id uint) error {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	row, ok := rep.store.data.contribs[id]
+	if !ok || row.GoalID != goal_id {
+		return notFound()
+	}
+	delete(rep.store.data.contribs, id)
+	return nil
+}
+
+func (rep GoalContributionRepo) FindByGoal(goal_id uint) ([]entity.GoalContribution, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	contributions := []entity.GoalContribution{}
+	for _, row := range byID(rep.store.data.contribs) {
+		if row.GoalID == goal_id {
+			contributions = append(contributions, row)
+		}
+	}
+	sort.SliceStable(contributions, func(i, j int) bool { return contributions[i].Date.Before(contributions[j].Date) })
+	return contributions, nil
+}
@@ -0,0 +1,84 @@
+package memory
+
+import (
+	"money-tracker/internal/entity"
+	"time"
+)
+
+type IdempotencyRepo struct {
+	store *Store
+}
+
+func NewIdempotencyRepo(store *Store) *IdempotencyRepo {
+	return &IdempotencyRepo{store: store}
+}
+
+func (rep IdempotencyRepo) Reserve(key *entity.IdempotencyKey) (bool, error) {
+	s := rep.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, row := range s.data.idempotency {
+		if row.Key == key.Key && row.Scope == key.Scope {
+			key.ID = 0
+			return false, nil
+		}
+	}
+
+	s.lastIdempotency++
+	row := *key
+	row.ID = s.lastIdempotency
+	stamp(&row.CreatedAt, &row.UpdatedAt)
+	s.data.idempotency[row.ID] = row
+
+	key.ID = row.ID
+	return true, nil
+}
+
+func (rep IdempotencyRepo) Find(key string, scope string) (*entity.IdempotencyKey, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	for _, row := range rep.store.data.idempotency {
+		if row.Key == key && row.Scope == scope {
+			return &row, nil
+		}
+	}
+	return nil, notFound()
+}
+
+func (rep IdempotencyRepo) Complete(id uint, status_code int, response []byte) error {
+	s := rep.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if row, ok := s.data.idempotency[id]; ok {
+		row.Completed = true
+		row.StatusCode = status_code
+		row.Response = append([]byte(nil), response...)
+		row.UpdatedAt = time.Now()
+		s.data.idempotency[id] = row
+	}
+	return nil
+}
+
+func (rep IdempotencyRepo) Delete(id uint) error {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	delete(rep.store.data.idempotency, id)
+	return nil
+}
+
+func (rep IdempotencyRepo) DeleteExpired(now time.Time) error {
+	s := rep.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, row := range s.data.idempotency {
+		if row.ExpiresAt.Before(now) {
+			delete(s.data.idempotency, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package memory
+
+import (
+	"money-tracker/internal/entity"
+	"sort"
+	"time"
+)
+
+// /--------------------------------- loans ---------------------------------
+
+type LoanRepo struct {
+	store *Store
+}
+
+func NewLoanRepo(store *Store) *LoanRepo {
+	return &LoanRepo{store: store}
+}
+
+func (rep LoanRepo) Insert(loan *entity.Loan) error {
+	s := rep.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastLoan++
+	loan.ID = s.lastLoan
+	if loan.CreatedAt.IsZero() {
+		loan.CreatedAt = time.Now()
+	}
+	s.data.loans[loan.ID] = *loan
+	return nil
+}
+
+func (rep LoanRepo) Update(loan *entity.Loan) error {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	row, ok := rep.store.data.loans[loan.ID]
+	if !ok {
+		return notFound()
+	}
+	row.Name = loan.Name
+	row.Direction = loan.Direction
+	row.Principal = loan.Principal
+	row.InterestRate = loan.InterestRate
+	row.Term = loan.Term
+	row.Frequency = loan.Frequency
+	row.StartDate = loan.StartDate
+	row.Note = loan.Note
+	row.UpdatedAt = loan.UpdatedAt
+	rep.store.data.loans[loan.ID] = row
+	return nil
+}
+
+func (rep LoanRepo) Delete(id uint) error {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	data := &rep.store.data
+	if _, ok := data.loans[id]; !ok {
+		return notFound()
+	}
+	delete(data.loans, id)
+	for pid, p := range data.payments {
+		if p.LoanID == id {
+			delete(data.payments, pid)
+		}
+	}
+	return nil
+}
+
+func (rep LoanRepo) FindById(id uint) (*entity.Loan, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	row, ok := rep.store.data.loans[id]
+	if !ok {
+		return nil, notFound()
+	}
+	return &row, nil
+}
+
+func (rep LoanRepo) FindAll() ([]entity.Loan, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	loans := byID(rep.store.data.loans)
+	sort.SliceStable(loans, func(i, j int) bool { return loans[i].Name < loans[j].Name })
+	return loans, nil
+}
+
+// /-------------------------------- payments --------------------------------
+
+type LoanPaymentRepo struct {
+	store *Store
+}
+
+func NewLoanPaymentRepo(store *Store) *LoanPaymentRepo {
+	return &LoanPaymentRepo{store: store}
+}
+
+func (rep LoanPaymentRepo) Insert(p *entity.LoanPayment) error {
+	s := rep.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastPayment++
+	p.ID = s.lastPayment
+	if p.CreatedAt.IsZero() {
+		p.CreatedAt = time.Now()
+	}
+	s.data.payments[p.ID] = *p
+	return nil
+}
+
+func (rep LoanPaymentRepo) Delete(loan_id uint, id uint) error {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	row, ok := rep.store.data.payments[id]
+	if !ok || row.LoanID != loan_id {
+		return notFound()
+	}
+	delete(rep.store.data.payments, id)
+	return nil
+}
+
+func (rep LoanPaymentRepo) FindByLoan(loan_id uint) ([]entity.LoanPayment, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	payments := []entity.LoanPayment{}
+	for _, row := range byID(rep.store.data.payments) {
+		if row.LoanID == loan_id {
+			payments = append(payments, row)
+		}
+	}
+	sort.SliceStable(payments, func(i, j int) bool { return payments[i].Date.Before(payments[j].Date) })
+	return payments, nil
+}
@@ -0,0 +1,34 @@
+package memory_test
+
+import (
+	"money-tracker/internal/repository/memory"
+	"money-tracker/internal/repository/repotest"
+	"testing"
+)
+
+func TestMemoryRepositories(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repos {
+		store := memory.NewStore()
+		return repotest.Repos{
+			Category:      memory.NewCategoryRepo(store),
+			Tag:           memory.NewTagRepo(store),
+			Purchase:      memory.NewPurchaseRepo(store),
+			User:          memory.NewUserRepo(store),
+			UserToken:     memory.NewUserTokenRepo(store),
+			Idempotency:   memory.NewIdempotencyRepo(store),
+			RecoveryCode:  memory.NewRecoveryCodeRepo(store),
+			PasswordReset: memory.NewPasswordResetRepo(store),
+			APIKey:        memory.NewAPIKeyRepo(store),
+			Webhook:       memory.NewWebhookRepo(store),
+			Outbox:        memory.NewOutboxRepo(store),
+			Delivery:      memory.NewWebhookDeliveryRepo(store),
+			Goal:          memory.NewGoalRepo(store),
+			Contribution:  memory.NewGoalContributionRepo(store),
+			Loan:          memory.NewLoanRepo(store),
+			LoanPayment:   memory.NewLoanPaymentRepo(store),
+			RecurringItem: memory.NewRecurringItemRepo(store),
+			Notification:  memory.NewNotificationRepo(store),
+			Tx:            memory.NewTransactor(store),
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"money-tracker/internal/entity"
+	"time"
+)
+
+type NotificationRepo struct {
+	store *Store
+}
+
+func NewNotificationRepo(store *Store) *NotificationRepo {
+	return &NotificationRepo{store: store}
+}
+
+func (rep NotificationRepo) Insert(n *entity.Notification) (bool, error) {
+	s := rep.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, row := range s.data.notices {
+		if row.Key == n.Key {
+			return false, nil
+		}
+	}
+
+	s.lastNotice++
+	n.ID = s.lastNotice
+	if n.CreatedAt.IsZero() {
+		n.CreatedAt = time.Now()
+	}
+	s.data.notices[n.ID] = *n
+	return true, nil
+}
+
+func (rep NotificationRepo) FindAll(unread bool, start int, limit int) ([]entity.Notification, int, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	matched := []entity.Notification{}
+	rows := byID(rep.store.data.notices)
+	for i := len(rows) - 1; i >= 0; i-- {
+		if !unread || rows[i].ReadAt == nil {
+			matched = append(matched, rows[i])
+		}
+	}
+	return page(matched, start, limit), len(matched), nil
+}
+
+func (rep NotificationRepo) MarkRead(id uint, at time.Time) error {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	row, ok := rep.store.data.notices[id]
+	if !ok {
+		return notFound()
+	}
+	row.ReadAt = &at
+	rep.store.data.notices[id] = row
+	return nil
+}
+
+func (rep NotificationRepo) Delete(id uint) error {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	if _, ok := rep.store.data.notices[id]; !ok {
+		return notFound()
+	}
+	delete(rep.store.data.notices, id)
+	return nil
+}
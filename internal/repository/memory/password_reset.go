@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"money-tracker/internal/entity"
+	"time"
+)
+
+type PasswordResetRepo struct {
+	store *Store
+}
+
+func NewPasswordResetRepo(store *Store) *PasswordResetRepo {
+	return &PasswordResetRepo{store: store}
+}
+
+func (rep PasswordResetRepo) Insert(token *entity.PasswordResetToken) error {
+	s := rep.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastReset++
+	token.ID = s.lastReset
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = time.Now()
+	}
+	s.data.resets[token.ID] = *token
+	return nil
+}
+
+func (rep PasswordResetRepo) Find(token_hash string, now time.Time) (*entity.PasswordResetToken, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	for _, row := range rep.store.data.resets {
+		if row.TokenHash == token_hash && row.UsedAt == nil && row.ExpiresAt.After(now) {
+			return &row, nil
+		}
+	}
+	return nil, notFound()
+}
+
+func (rep PasswordResetRepo) Use(token_hash string, now time.Time) (*entity.PasswordResetToken, error) {
+	s := rep.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, row := range s.data.resets {
+		if row.TokenHash == token_hash && row.UsedAt == nil && row.ExpiresAt.After(now) {
+			row.UsedAt = &now
+			s.data.resets[id] = row
+			return &row, nil
+		}
+	}
+	return nil, notFound()
+}
+
+func (rep PasswordResetRepo) DeleteByUser(user_id uint) error {
+	s := rep.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, row := range s.data.resets {
+		if row.UserID == user_id {
+			delete(s.data.resets, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,292 @@
+package memory
+
+import (
+	"encoding/json"
+	"money-tracker/internal/constants"
+	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var purchaseColumns = map[string]column[entity.Purchase]{
+	"id":              func(p entity.Purchase) interface{} { return p.ID },
+	"date":            func(p entity.Purchase) interface{} { return p.Date },
+	"amount":          func(p entity.Purchase) interface{} { return p.Amount },
+	"reason":          func(p entity.Purchase) interface{} { return p.Reason },
+	"status_id":       func(p entity.Purchase) interface{} { return p.StatusID },
+	"color":           func(p entity.Purchase) interface{} { return p.Color },
+	"method":          func(p entity.Purchase) interface{} { return p.Method },
+	"tag_ids":         func(p entity.Purchase) interface{} { return p.TagIDs },
+	"note":            func(p entity.Purchase) interface{} { return p.Note },
+	"category_id":     func(p entity.Purchase) interface{} { return p.CategoryId },
+	"sub_category_id": func(p entity.Purchase) interface{} { return p.SubCategoryId },
+	"external_id":     func(p entity.Purchase) interface{} { return p.ExternalID },
+	"version":         func(p entity.Purchase) interface{} { return p.Version },
+	"created_at":      func(p entity.Purchase) interface{} { return p.CreatedAt },
+	"updated_at":      func(p entity.Purchase) interface{} { return p.UpdatedAt },
+	"deleted_at":      func(p entity.Purchase) interface{} { return p.DeletedAt },
+}
+
+type PurchaseRepo struct {
+	store *Store
+}
+
+func NewPurchaseRepo(store *Store) *PurchaseRepo {
+	return &PurchaseRepo{store: store}
+}
+
+func (rep PurchaseRepo) Insert(purchase *entity.Purchase) error {
+	s := rep.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastPurchase++
+	row := toStoredPurchase(purchase)
+	row.ID = s.lastPurchase
+	// the columns default to 1
+	if row.StatusID == 0 {
+		row.StatusID = 1
+	}
+	if row.Version == 0 {
+		row.Version = 1
+	}
+	stamp(&row.CreatedAt, &row.UpdatedAt)
+	s.data.purchases[row.ID] = row
+
+	purchase.ID = row.ID
+	return nil
+}
+
+func (rep PurchaseRepo) FindById(id uint, status_id []uint) (*entity.Purchase, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	row, ok := rep.store.data.purchases[id]
+	if !ok || !containsUint(status_id, row.StatusID) {
+		return nil, notFound()
+	}
+	return fromStoredPurchase(row), nil
+}
+
+func (rep PurchaseRepo) Delete(id uint) error {
+	s := rep.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if row, ok := s.data.purchases[id]; ok {
+		now := time.Now()
+		row.StatusID = 0
+		row.DeletedAt = now
+		row.UpdatedAt = now
+		s.data.purchases[id] = row
+	}
+	return nil
+}
+
+// Update saves the purchase if it is still at purchase.Version and bumps
+// the version, otherwise it returns entity.ErrVersionConflict.
+func (rep PurchaseRepo) Update(purchase *entity.Purchase) (*entity.Purchase, error) {
+	s := rep.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	purchase.UpdatedAt = time.Now()
+
+	old, ok := s.data.purchases[purchase.ID]
+	if !ok {
+		return nil, notFound()
+	}
+	if old.Version != purchase.Version {
+		return nil, entity.ErrVersionConflict
+	}
+
+	row := toStoredPurchase(purchase)
+	row.Version = purchase.Version + 1
+	row.CreatedAt = old.CreatedAt
+	s.data.purchases[row.ID] = row
+
+	purchase.Version = row.Version
+	return purchase, nil
+}
+
+// FindByExternalIDs looks up imported purchases in any status, so that a
+// removed purchase is not imported again. Only the id, external id and
+// status are loaded.
+func (rep PurchaseRepo) FindByExternalIDs(ids []string) ([]entity.Purchase, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	var purchases []entity.Purchase
+	for _, row := range byID(rep.store.data.purchases) {
+		for _, id := range ids {
+			if row.ExternalID == id {
+				purchases = append(purchases, entity.Purchase{
+					ID:         row.ID,
+					ExternalID: row.ExternalID,
+					StatusID:   row.StatusID,
+				})
+				break
+			}
+		}
+	}
+	return purchases, nil
+}
+
+func (rep PurchaseRepo) HasMerchant(merchant string, date time.Time, except uint) (bool, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	for _, row := range rep.store.data.purchases {
+		if row.ID != except && row.StatusID == constants.StatusActive && !row.Date.After(date) && entity.MerchantKey(row.Reason) == merchant {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// FindAll loads the listed columns only: created_at and updated_at come
+// with input.OtherFields, and Category and SubCategory carry just the id,
+// slug and color of an active category.
+func (rep PurchaseRepo) FindAll(input dto.PurchaseFindAll) ([]entity.Purchase, int, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	status := input.StatusID
+	if status == 0 {
+		status = 1
+	}
+
+	var rows []entity.Purchase
+	for _, row := range byID(rep.store.data.purchases) {
+		if input.CategoryID != nil && *input.CategoryID > 0 && (row.CategoryId == nil || *row.CategoryId != *input.CategoryID) {
+			continue
+		}
+		if input.SubCategoryID != nil && *input.SubCategoryID > 0 && (row.SubCategoryId == nil || *row.SubCategoryId != *input.SubCategoryID) {
+			continue
+		}
+		if input.Reason != "" && !ilike(row.Reason, input.Reason) {
+			continue
+		}
+		if input.Note != "" && !ilike(row.Note, input.Note) {
+			continue
+		}
+		if input.Color != "" && row.Color != input.Color {
+			continue
+		}
+		if input.Method != 0 && row.Method != input.Method {
+			continue
+		}
+		if input.ID > 0 && row.ID != input.ID {
+			continue
+		}
+		if input.Amount > 0 && row.Amount != input.Amount {
+			continue
+		}
+		if input.DateFrom != nil && row.Date.Before(*input.DateFrom) {
+			continue
+		}
+		if input.DateTo != nil && !row.Date.Before(*input.DateTo) {
+			continue
+		}
+		if input.UpdatedFrom != nil && row.UpdatedAt.Before(*input.UpdatedFrom) {
+			continue
+		}
+		if input.AfterID != 0 && (input.Sort == "DESC" && row.ID >= input.AfterID || input.Sort != "DESC" && row.ID <= input.AfterID) {
+			continue
+		}
+		if !input.AnyStatus && row.StatusID != status {
+			continue
+		}
+		if !hasTags(row.TagIDs, input.TagIDs) {
+			continue
+		}
+		rows = append(rows, row)
+	}
+
+	if err := orderRows(rows, purchaseColumns, input.OrderBy, input.Sort); err != nil {
+		return nil, 0, err
+	}
+
+	var purchases []entity.Purchase
+	for _, row := range page(rows, input.Start, input.Limit) {
+		p := fromStoredPurchase(row)
+		if !input.OtherFields {
+			p.CreatedAt = time.Time{}
+			p.UpdatedAt = time.Time{}
+		}
+		p.DeletedAt = time.Time{}
+		p.Category = rep.preloadCategory(p.CategoryId)
+		p.SubCategory = rep.preloadCategory(p.SubCategoryId)
+		purchases = append(purchases, *p)
+	}
+
+	return purchases, len(rows), nil
+}
+
+func (rep PurchaseRepo) preloadCategory(id *uint) *entity.Category {
+	if id == nil {
+		return nil
+	}
+	c, ok := rep.store.data.categories[*id]
+	if !ok || c.StatusID != 1 {
+		return nil
+	}
+	return &entity.Category{ID: c.ID, Slug: c.Slug, Color: c.Color}
+}
+
+// hasTags reports whether the comma separated tag_ids hold every tag.
+func hasTags(tagIDs string, tags []uint) bool {
+	have := strings.Split(tagIDs, ",")
+	for _, id := range tags {
+		found := false
+		for _, h := range have {
+			if h == strconv.FormatUint(uint64(id), 10) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// toStoredPurchase copies p without its associations. Details go through
+// JSON like they do in the jsonb column.
+func toStoredPurchase(p *entity.Purchase) entity.Purchase {
+	row := *p
+	row.Category = nil
+	row.SubCategory = nil
+	row.CategoryId = copyUint(p.CategoryId)
+	row.SubCategoryId = copyUint(p.SubCategoryId)
+	row.Details = copyDetails(p.Details)
+	return row
+}
+
+func fromStoredPurchase(row entity.Purchase) *entity.Purchase {
+	p := row
+	p.CategoryId = copyUint(row.CategoryId)
+	p.SubCategoryId = copyUint(row.SubCategoryId)
+	p.Details = copyDetails(row.Details)
+	return &p
+}
+
+func copyDetails(details constants.JSONMap) constants.JSONMap {
+	if details == nil {
+		return nil
+	}
+	b, err := json.Marshal(details)
+	if err != nil {
+		return nil
+	}
+	var out constants.JSONMap
+	_ = json.Unmarshal(b, &out)
+	return out
+}
@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"money-tracker/internal/entity"
+	"time"
+)
+
+type RecoveryCodeRepo struct {
+	store *Store
+}
+
+func NewRecoveryCodeRepo(store *Store) *RecoveryCodeRepo {
+	return &RecoveryCodeRepo{store: store}
+}
+
+func (rep RecoveryCodeRepo) Replace(user_id uint, hashes []string) error {
+	s := rep.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, row := range s.data.recovery {
+		if row.UserID == user_id {
+			delete(s.data.recovery, id)
+		}
+	}
+	for _, hash := range hashes {
+		s.lastRecovery++
+		s.data.recovery[s.lastRecovery] = entity.RecoveryCode{
+			ID:        s.lastRecovery,
+			UserID:    user_id,
+			CodeHash:  hash,
+			CreatedAt: time.Now(),
+		}
+	}
+	return nil
+}
+
+func (rep RecoveryCodeRepo) Use(user_id uint, hash string) (bool, error) {
+	s := rep.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, row := range s.data.recovery {
+		if row.UserID == user_id && row.CodeHash == hash && row.UsedAt == nil {
+			now := time.Now()
+			row.UsedAt = &now
+			s.data.recovery[id] = row
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (rep RecoveryCodeRepo) CountUnused(user_id uint) (int, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	count := 0
+	for _, row := range rep.store.data.recovery {
+		if row.UserID == user_id && row.UsedAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (rep RecoveryCodeRepo) DeleteAll(user_id uint) error {
+	s := rep.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, row := range s.data.recovery {
+		if row.UserID == user_id {
+			delete(s.data.recovery, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"money-tracker/internal/entity"
+	"sort"
+	"time"
+)
+
+type RecurringItemRepo struct {
+	store *Store
+}
+
+func NewRecurringItemRepo(store *Store) *RecurringItemRepo {
+	return &RecurringItemRepo{store: store}
+}
+
+func (rep RecurringItemRepo) Insert(item *entity.RecurringItem) error {
+	s := rep.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastRecurring++
+	item.ID = s.lastRecurring
+	if item.CreatedAt.IsZero() {
+		item.CreatedAt = time.Now()
+	}
+	s.data.recurring[item.ID] = *item
+	return nil
+}
+
+func (rep RecurringItemRepo) Update(item *entity.RecurringItem) error {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	row, ok := rep.store.data.recurring[item.ID]
+	if !ok {
+		return notFound()
+	}
+	row.Name = item.Name
+	row.Amount = item.Amount
+	row.Frequency = item.Frequency
+	row.StartDate = item.StartDate
+	row.EndDate = item.EndDate
+	row.Account = item.Account
+	row.CategoryID = item.CategoryID
+	row.Note = item.Note
+	row.UpdatedAt = item.UpdatedAt
+	rep.store.data.recurring[item.ID] = row
+	return nil
+}
+
+func (rep RecurringItemRepo) Delete(id uint) error {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	if _, ok := rep.store.data.recurring[id]; !ok {
+		return notFound()
+	}
+	delete(rep.store.data.recurring, id)
+	return nil
+}
+
+func (rep RecurringItemRepo) FindById(id uint) (*entity.RecurringItem, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	row, ok := rep.store.data.recurring[id]
+	if !ok {
+		return nil, notFound()
+	}
+	return &row, nil
+}
+
+func (rep RecurringItemRepo) FindAll() ([]entity.RecurringItem, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	items := byID(rep.store.data.recurring)
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+	return items, nil
+}
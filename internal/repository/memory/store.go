@@ -0,0 +1,336 @@
+// Package memory keeps the repositories in process memory. It follows the
+// same contract as the GORM repositories (see package repotest) and is meant
+// for tests that should not need a database.
+package memory
+
+import (
+	"fmt"
+	"money-tracker/internal/entity"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Store holds the rows of all the repositories built on it, like one
+// database.
+type Store struct {
+	mu sync.Mutex
+	// txMu lets one transaction run at a time.
+	txMu sync.Mutex
+	data tables
+
+	// like Postgres sequences, ids are not reused after a rollback
+	lastCategory    uint
+	lastTag         uint
+	lastPurchase    uint
+	lastUser        uint
+	lastToken       uint
+	lastIdempotency uint
+	lastRecovery    uint
+	lastReset       uint
+	lastAPIKey      uint
+	lastWebhook     uint
+	lastOutbox      uint
+	lastDelivery    uint
+	lastGoal        uint
+	lastContrib     uint
+	lastLoan        uint
+	lastPayment     uint
+	lastRecurring   uint
+	lastNotice      uint
+}
+
+type tables struct {
+	categories  map[uint]entity.Category
+	tags        map[uint]entity.Tag
+	purchases   map[uint]entity.Purchase
+	users       map[uint]entity.User
+	tokens      map[uint]entity.UserToken
+	idempotency map[uint]entity.IdempotencyKey
+	recovery    map[uint]entity.RecoveryCode
+	resets      map[uint]entity.PasswordResetToken
+	apiKeys     map[uint]entity.APIKey
+	webhooks    map[uint]entity.Webhook
+	outbox      map[uint]entity.OutboxMessage
+	deliveries  map[uint]entity.WebhookDelivery
+	goals       map[uint]entity.Goal
+	contribs    map[uint]entity.GoalContribution
+	loans       map[uint]entity.Loan
+	payments    map[uint]entity.LoanPayment
+	recurring   map[uint]entity.RecurringItem
+	notices     map[uint]entity.Notification
+}
+
+func NewStore() *Store {
+	return &Store{data: tables{
+		categories:  map[uint]entity.Category{},
+		tags:        map[uint]entity.Tag{},
+		purchases:   map[uint]entity.Purchase{},
+		users:       map[uint]entity.User{},
+		tokens:      map[uint]entity.UserToken{},
+		idempotency: map[uint]entity.IdempotencyKey{},
+		recovery:    map[uint]entity.RecoveryCode{},
+		resets:      map[uint]entity.PasswordResetToken{},
+		apiKeys:     map[uint]entity.APIKey{},
+		webhooks:    map[uint]entity.Webhook{},
+		outbox:      map[uint]entity.OutboxMessage{},
+		deliveries:  map[uint]entity.WebhookDelivery{},
+		goals:       map[uint]entity.Goal{},
+		contribs:    map[uint]entity.GoalContribution{},
+		loans:       map[uint]entity.Loan{},
+		payments:    map[uint]entity.LoanPayment{},
+		recurring:   map[uint]entity.RecurringItem{},
+		notices:     map[uint]entity.Notification{},
+	}}
+}
+
+// snapshot copies the tables. Stored rows are replaced, never changed in
+// place, so copying the maps is enough.
+func (s *Store) snapshot() tables {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return tables{
+		categories:  copyMap(s.data.categories),
+		tags:        copyMap(s.data.tags),
+		purchases:   copyMap(s.data.purchases),
+		users:       copyMap(s.data.users),
+		tokens:      copyMap(s.data.tokens),
+		idempotency: copyMap(s.data.idempotency),
+		recovery:    copyMap(s.data.recovery),
+		resets:      copyMap(s.data.resets),
+		apiKeys:     copyMap(s.data.apiKeys),
+		webhooks:    copyMap(s.data.webhooks),
+		outbox:      copyMap(s.data.outbox),
+		deliveries:  copyMap(s.data.deliveries),
+		goals:       copyMap(s.data.goals),
+		contribs:    copyMap(s.data.contribs),
+		loans:       copyMap(s.data.loans),
+		payments:    copyMap(s.data.payments),
+		recurring:   copyMap(s.data.recurring),
+		notices:     copyMap(s.data.notices),
+	}
+}
+
+func (s *Store) restore(t tables) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data = t
+}
+
+// /------------------------------- transactor -------------------------------
+
+// Transactor runs transactions on a Store. Transactions run one at a time;
+// a rollback restores the whole store, so writes made outside the
+// transaction while it runs are lost with it.
+type Transactor struct {
+	store  *Store
+	nested bool
+}
+
+func NewTransactor(store *Store) *Transactor {
+	return &Transactor{store: store}
+}
+
+func (t Transactor) Transaction(fn func(repos entity.Repositories) error) (err error) {
+	if !t.nested {
+		t.store.txMu.Lock()
+		defer t.store.txMu.Unlock()
+	}
+
+	saved := t.store.snapshot()
+	defer func() {
+		if r := recover(); r != nil {
+			t.store.restore(saved)
+			panic(r)
+		}
+		if err != nil {
+			t.store.restore(saved)
+		}
+	}()
+
+	return fn(entity.Repositories{
+		Category: NewCategoryRepo(t.store),
+		Tag:      NewTagRepo(t.store),
+		Purchase: NewPurchaseRepo(t.store),
+		User:     NewUserRepo(t.store),
+		Outbox:   NewOutboxRepo(t.store),
+		Tx:       &Transactor{store: t.store, nested: true},
+	})
+}
+
+// Snapshot runs fn on a copy of the store, which later writes do not
+// change.
+func (t Transactor) Snapshot(fn func(repos entity.Repositories) error) error {
+	frozen := &Store{data: t.store.snapshot()}
+	return fn(entity.Repositories{
+		Category: NewCategoryRepo(frozen),
+		Tag:      NewTagRepo(frozen),
+		Purchase: NewPurchaseRepo(frozen),
+		User:     NewUserRepo(frozen),
+		Outbox:   NewOutboxRepo(frozen),
+		Tx:       &Transactor{store: frozen, nested: true},
+	})
+}
+
+// /------------------------------- helpers -------------------------------
+
+func copyMap[K comparable, V any](m map[K]V) map[K]V {
+	out := make(map[K]V, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+// byID returns the rows ordered by id, which is also how ties are broken
+// when sorting.
+func byID[T any](m map[uint]T) []T {
+	ids := make([]uint, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	rows := make([]T, 0, len(ids))
+	for _, id := range ids {
+		rows = append(rows, m[id])
+	}
+	return rows
+}
+
+// ilike matches like "column ILIKE '%pattern%'".
+func ilike(s string, pattern string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(pattern))
+}
+
+func containsUint(list []uint, v uint) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+func copyUint(p *uint) *uint {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+// column reads a sortable column of a row.
+type column[T any] func(row T) interface{}
+
+// orderRows sorts rows like "ORDER BY orderBy sort". Unknown columns are an
+// error, like they are in SQL.
+func orderRows[T any](rows []T, columns map[string]column[T], orderBy string, sortDir string) error {
+	get, ok := columns[strings.ToLower(orderBy)]
+	if !ok {
+		return fmt.Errorf("column %q does not exist", orderBy)
+	}
+
+	desc := false
+	switch strings.ToUpper(sortDir) {
+	case "", "ASC":
+	case "DESC":
+		desc = true
+	default:
+		return fmt.Errorf("invalid sort direction %q", sortDir)
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		c := compare(get(rows[i]), get(rows[j]))
+		if desc {
+			return c > 0
+		}
+		return c < 0
+	})
+	return nil
+}
+
+// compare orders column values. NULL sorts after every value, as in
+// Postgres.
+func compare(a, b interface{}) int {
+	if p, ok := a.(*uint); ok {
+		q := b.(*uint)
+		switch {
+		case p == nil && q == nil:
+			return 0
+		case p == nil:
+			return 1
+		case q == nil:
+			return -1
+		}
+		a, b = *p, *q
+	}
+
+	switch x := a.(type) {
+	case uint:
+		return cmpOrdered(x, b.(uint))
+	case int:
+		return cmpOrdered(x, b.(int))
+	case int8:
+		return cmpOrdered(x, b.(int8))
+	case int64:
+		return cmpOrdered(x, b.(int64))
+	case string:
+		return cmpOrdered(x, b.(string))
+	case bool:
+		y := b.(bool)
+		switch {
+		case x == y:
+			return 0
+		case !x:
+			return -1
+		}
+		return 1
+	case time.Time:
+		return x.Compare(b.(time.Time))
+	}
+	panic(fmt.Sprintf("memory: cannot compare %T", a))
+}
+
+func cmpOrdered[T int | int8 | int64 | uint | string](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// page applies "OFFSET start LIMIT limit"; a negative limit means none.
+func page[T any](rows []T, start int, limit int) []T {
+	if start > 0 {
+		if start >= len(rows) {
+			return nil
+		}
+		rows = rows[start:]
+	}
+	if limit >= 0 && limit < len(rows) {
+		rows = rows[:limit]
+	}
+	return rows
+}
+
+func notFound() error {
+	return gorm.ErrRecordNotFound
+}
+
+// stamp fills the timestamps GORM sets on create.
+func stamp(createdAt *time.Time, updatedAt *time.Time) {
+	now := time.Now()
+	if createdAt.IsZero() {
+		*createdAt = now
+	}
+	if updatedAt.IsZero() {
+		*updatedAt = now
+	}
+}
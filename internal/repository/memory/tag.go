@@ -0,0 +1,153 @@
+package memory
+
+import (
+	"money-tracker/internal/entity"
+	"time"
+)
+
+var tagColumns = map[string]column[entity.Tag]{
+	"id":         func(t entity.Tag) interface{} { return t.ID },
+	"title":      func(t entity.Tag) interface{} { return t.Title },
+	"status_id":  func(t entity.Tag) interface{} { return t.StatusID },
+	"version":    func(t entity.Tag) interface{} { return t.Version },
+	"created_at": func(t entity.Tag) interface{} { return t.CreatedAt },
+	"updated_at": func(t entity.Tag) interface{} { return t.UpdatedAt },
+	"deleted_at": func(t entity.Tag) interface{} { return t.DeletedAt },
+}
+
+type TagRepo struct {
+	store *Store
+}
+
+func NewTagRepo(store *Store) *TagRepo {
+	return &TagRepo{store: store}
+}
+
+func (rep TagRepo) Insert(tag *entity.Tag) error {
+	s := rep.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastTag++
+	tag.ID = s.lastTag
+	stamp(&tag.CreatedAt, &tag.UpdatedAt)
+	s.data.tags[tag.ID] = *tag
+	return nil
+}
+
+func (rep TagRepo) Delete(id uint) error {
+	s := rep.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if row, ok := s.data.tags[id]; ok {
+		row.StatusID = 0
+		row.UpdatedAt = time.Now()
+		s.data.tags[id] = row
+	}
+	return nil
+}
+
+// Update saves the tag if it is still at tag.Version and bumps the version,
+// otherwise it returns entity.ErrVersionConflict.
+func (rep TagRepo) Update(tag *entity.Tag) (*entity.Tag, error) {
+	s := rep.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tag.UpdatedAt = time.Now()
+
+	row, ok := s.data.tags[tag.ID]
+	if !ok {
+		return nil, notFound()
+	}
+	if row.Version != tag.Version {
+		return nil, entity.ErrVersionConflict
+	}
+
+	row.Title = tag.Title
+	row.StatusID = tag.StatusID
+	row.UpdatedAt = tag.UpdatedAt
+	row.Version = tag.Version + 1
+	s.data.tags[row.ID] = row
+
+	tag.Version++
+	return tag, nil
+}
+
+func (rep TagRepo) FindById(id uint) (*entity.Tag, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	row, ok := rep.store.data.tags[id]
+	if !ok || row.StatusID != 1 {
+		return nil, notFound()
+	}
+	return &row, nil
+}
+
+func (rep TagRepo) FindByTitle(title string) (*entity.Tag, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	for _, row := range byID(rep.store.data.tags) {
+		if row.StatusID == 1 && row.Title == title {
+			return &row, nil
+		}
+	}
+	return nil, notFound()
+}
+
+func (rep TagRepo) FindAll(start int, limit int, orderBy string, sort string, id uint, status_id uint, title string) ([]entity.Tag, int, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	if status_id == 0 {
+		status_id = 1
+	}
+
+	var rows []entity.Tag
+	for _, row := range byID(rep.store.data.tags) {
+		if title != "" && !ilike(row.Title, title) {
+			continue
+		}
+		if id > 0 && row.ID != id {
+			continue
+		}
+		if row.StatusID != status_id {
+			continue
+		}
+		rows = append(rows, row)
+	}
+
+	if err := orderRows(rows, tagColumns, orderBy, sort); err != nil {
+		return nil, 0, err
+	}
+
+	var items []entity.Tag
+	items = append(items, page(rows, start, limit)...)
+	return items, len(rows), nil
+}
+
+// FindByIDs returns the tags in any status.
+func (rep TagRepo) FindByIDs(ids []uint) ([]entity.Tag, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	var tags []entity.Tag
+	for _, row := range byID(rep.store.data.tags) {
+		if containsUint(ids, row.ID) {
+			tags = append(tags, row)
+		}
+	}
+	return tags, nil
+}
+
+func (rep TagRepo) FindAllStatuses(start int, limit int) ([]entity.Tag, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	var tags []entity.Tag
+	tags = append(tags, page(byID(rep.store.data.tags), start, limit)...)
+	return tags, nil
+}
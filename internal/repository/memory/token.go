@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"money-tracker/internal/entity"
+)
+
+type UserTokenRepo struct {
+	store *Store
+}
+
+func NewUserTokenRepo(store *Store) *UserTokenRepo {
+	return &UserTokenRepo{store: store}
+}
+
+func (rep UserTokenRepo) Insert(user_token *entity.UserToken) error {
+	s := rep.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastToken++
+	user_token.ID = s.lastToken
+	stamp(&user_token.CreatedAt, &user_token.UpdatedAt)
+	s.data.tokens[user_token.ID] = *user_token
+	return nil
+}
+
+func (rep UserTokenRepo) Delete(id uint) error {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	delete(rep.store.data.tokens, id)
+	return nil
+}
+
+func (rep UserTokenRepo) DeleteByUser(user_id uint) error {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	for id, row := range rep.store.data.tokens {
+		if row.UserID == user_id {
+			delete(rep.store.data.tokens, id)
+		}
+	}
+	return nil
+}
+
+// FindByToken ignores status_id: tokens have no status, a deleted token is
+// gone.
+func (rep UserTokenRepo) FindByToken(token string, status_id []uint) (*entity.UserToken, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	for _, row := range byID(rep.store.data.tokens) {
+		if row.Token == token {
+			return &row, nil
+		}
+	}
+	return nil, notFound()
+}
@@ -0,0 +1,134 @@
+package memory
+
+import (
+	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
+	"time"
+)
+
+var userColumns = map[string]column[entity.User]{
+	"id":           func(u entity.User) interface{} { return u.ID },
+	"user_name":    func(u entity.User) interface{} { return u.UserName },
+	"level_manage": func(u entity.User) interface{} { return u.LevelManage },
+	"status_id":    func(u entity.User) interface{} { return u.StatusID },
+	"created_at":   func(u entity.User) interface{} { return u.CreatedAt },
+	"updated_at":   func(u entity.User) interface{} { return u.UpdatedAt },
+	"deleted_at":   func(u entity.User) interface{} { return u.DeletedAt },
+}
+
+type UserRepo struct {
+	store *Store
+}
+
+func NewUserRepo(store *Store) *UserRepo {
+	return &UserRepo{store: store}
+}
+
+func (rep UserRepo) Insert(user *entity.User) error {
+	s := rep.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastUser++
+	user.ID = s.lastUser
+	stamp(&user.CreatedAt, &user.UpdatedAt)
+	s.data.users[user.ID] = *user
+	return nil
+}
+
+func (rep UserRepo) FindById(id uint) (*entity.User, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	row, ok := rep.store.data.users[id]
+	if !ok || row.StatusID != 1 {
+		return nil, notFound()
+	}
+	return &row, nil
+}
+
+func (rep UserRepo) Delete(id uint) error {
+	s := rep.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if row, ok := s.data.users[id]; ok {
+		row.StatusID = 0
+		row.UpdatedAt = time.Now()
+		s.data.users[id] = row
+	}
+	return nil
+}
+
+// Update saves every field of the user, inserting it when it is new.
+func (rep UserRepo) Update(user *entity.User) (*entity.User, error) {
+	if user.ID == 0 {
+		if err := rep.Insert(user); err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+
+	s := rep.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user.UpdatedAt = time.Now()
+	if user.ID > s.lastUser {
+		s.lastUser = user.ID
+	}
+	s.data.users[user.ID] = *user
+	return user, nil
+}
+
+// FindAll lists users in any status and loads only the id, user name,
+// level and status.
+func (rep UserRepo) FindAll(input dto.ListUsersInput) ([]entity.User, int, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	var rows []entity.User
+	for _, row := range byID(rep.store.data.users) {
+		if input.UserName != "" && !ilike(row.UserName, input.UserName) {
+			continue
+		}
+		if input.ID > 0 && row.ID != input.ID {
+			continue
+		}
+		if input.LevelManage > 0 && row.LevelManage != input.LevelManage {
+			continue
+		}
+		rows = append(rows, row)
+	}
+
+	orderBy := input.OrderBy
+	if orderBy == "" {
+		orderBy = "id"
+	}
+	if err := orderRows(rows, userColumns, orderBy, input.Sort); err != nil {
+		return nil, 0, err
+	}
+
+	var users []entity.User
+	for _, row := range page(rows, input.Start, input.Limit) {
+		users = append(users, entity.User{
+			ID:          row.ID,
+			UserName:    row.UserName,
+			LevelManage: row.LevelManage,
+			StatusID:    row.StatusID,
+		})
+	}
+	return users, len(rows), nil
+}
+
+func (rep UserRepo) FindByUserName(username string) (*entity.User, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	for _, row := range byID(rep.store.data.users) {
+		if row.StatusID == 1 && row.UserName == username {
+			return &row, nil
+		}
+	}
+	return nil, notFound()
+}
@@ -0,0 +1,202 @@
+package memory
+
+import (
+	"money-tracker/internal/entity"
+	"sort"
+	"time"
+)
+
+// /------------------------------- webhooks -------------------------------
+
+type WebhookRepo struct {
+	store *Store
+}
+
+func NewWebhookRepo(store *Store) *WebhookRepo {
+	return &WebhookRepo{store: store}
+}
+
+func (rep WebhookRepo) Insert(webhook *entity.Webhook) error {
+	s := rep.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastWebhook++
+	webhook.ID = s.lastWebhook
+	if webhook.CreatedAt.IsZero() {
+		webhook.CreatedAt = time.Now()
+	}
+	s.data.webhooks[webhook.ID] = *webhook
+	return nil
+}
+
+func (rep WebhookRepo) Update(webhook *entity.Webhook) error {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	row, ok := rep.store.data.webhooks[webhook.ID]
+	if !ok {
+		return notFound()
+	}
+	row.URL = webhook.URL
+	row.Events = webhook.Events
+	row.Description = webhook.Description
+	row.Active = webhook.Active
+	row.UpdatedAt = webhook.UpdatedAt
+	rep.store.data.webhooks[webhook.ID] = row
+	return nil
+}
+
+func (rep WebhookRepo) Delete(id uint) error {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	data := &rep.store.data
+	if _, ok := data.webhooks[id]; !ok {
+		return notFound()
+	}
+	delete(data.webhooks, id)
+	for msgID, msg := range data.outbox {
+		if msg.WebhookID == id {
+			delete(data.outbox, msgID)
+		}
+	}
+	for deliveryID, delivery := range data.deliveries {
+		if delivery.WebhookID == id {
+			delete(data.deliveries, deliveryID)
+		}
+	}
+	return nil
+}
+
+func (rep WebhookRepo) FindById(id uint) (*entity.Webhook, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	row, ok := rep.store.data.webhooks[id]
+	if !ok {
+		return nil, notFound()
+	}
+	return &row, nil
+}
+
+func (rep WebhookRepo) FindAll() ([]entity.Webhook, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	return byID(rep.store.data.webhooks), nil
+}
+
+func (rep WebhookRepo) FindActive() ([]entity.Webhook, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	webhooks := []entity.Webhook{}
+	for _, row := range byID(rep.store.data.webhooks) {
+		if row.Active {
+			webhooks = append(webhooks, row)
+		}
+	}
+	return webhooks, nil
+}
+
+// /-------------------------------- outbox --------------------------------
+
+type OutboxRepo struct {
+	store *Store
+}
+
+func NewOutboxRepo(store *Store) *OutboxRepo {
+	return &OutboxRepo{store: store}
+}
+
+func (rep OutboxRepo) Insert(msg *entity.OutboxMessage) error {
+	s := rep.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastOutbox++
+	msg.ID = s.lastOutbox
+	if msg.CreatedAt.IsZero() {
+		msg.CreatedAt = time.Now()
+	}
+	s.data.outbox[msg.ID] = *msg
+	return nil
+}
+
+func (rep OutboxRepo) Claim(now time.Time, lease time.Duration, limit int) ([]entity.OutboxMessage, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	due := []entity.OutboxMessage{}
+	for _, row := range byID(rep.store.data.outbox) {
+		if row.DeliveredAt == nil && row.FailedAt == nil && !row.NextAttemptAt.After(now) {
+			due = append(due, row)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool { return due[i].NextAttemptAt.Before(due[j].NextAttemptAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	for i := range due {
+		due[i].NextAttemptAt = now.Add(lease)
+		rep.store.data.outbox[due[i].ID] = due[i]
+	}
+	return due, nil
+}
+
+func (rep OutboxRepo) Update(msg *entity.OutboxMessage) error {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	row, ok := rep.store.data.outbox[msg.ID]
+	if !ok {
+		return nil
+	}
+	row.Attempts = msg.Attempts
+	row.NextAttemptAt = msg.NextAttemptAt
+	row.DeliveredAt = msg.DeliveredAt
+	row.FailedAt = msg.FailedAt
+	row.LastError = msg.LastError
+	rep.store.data.outbox[msg.ID] = row
+	return nil
+}
+
+// /------------------------------ deliveries ------------------------------
+
+type WebhookDeliveryRepo struct {
+	store *Store
+}
+
+func NewWebhookDeliveryRepo(store *Store) *WebhookDeliveryRepo {
+	return &WebhookDeliveryRepo{store: store}
+}
+
+func (rep WebhookDeliveryRepo) Insert(delivery *entity.WebhookDelivery) error {
+	s := rep.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastDelivery++
+	delivery.ID = s.lastDelivery
+	if delivery.CreatedAt.IsZero() {
+		delivery.CreatedAt = time.Now()
+	}
+	s.data.deliveries[delivery.ID] = *delivery
+	return nil
+}
+
+func (rep WebhookDeliveryRepo) FindByWebhook(webhook_id uint, start int, limit int) ([]entity.WebhookDelivery, int, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	matched := []entity.WebhookDelivery{}
+	rows := byID(rep.store.data.deliveries)
+	for i := len(rows) - 1; i >= 0; i-- {
+		if rows[i].WebhookID == webhook_id {
+			matched = append(matched, rows[i])
+		}
+	}
+	return page(matched, start, limit), len(matched), nil
+}
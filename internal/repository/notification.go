@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"money-tracker/internal/entity"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Notification struct {
+	ID         uint   `gorm:"primaryKey"`
+	Kind       string `gorm:"size:32;not null"`
+	Key        string `gorm:"size:255;not null;uniqueIndex"`
+	Title      string `gorm:"size:255;not null"`
+	Body       string `gorm:"size:1000"`
+	PurchaseID *uint
+	CategoryID *uint
+	Amount     int64
+	Baseline   int64
+	Score      float64
+	ReadAt     *time.Time `gorm:"index"`
+	CreatedAt  time.Time
+}
+
+type NotificationRepo struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepo(db *gorm.DB) *NotificationRepo {
+	return &NotificationRepo{db: db}
+}
+
+func (rep NotificationRepo) Insert(n *entity.Notification) (bool, error) {
+	result := rep.db.Clauses(clause.OnConflict{DoNothing: true}).Create(n)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (rep NotificationRepo) FindAll(unread bool, start int, limit int) ([]entity.Notification, int, error) {
+	query := rep.db.Model(&entity.Notification{})
+	if unread {
+		query = query.Where("read_at IS NULL")
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var notifications []entity.Notification
+	err := query.Order("id DESC").Offset(start).Limit(limit).Find(&notifications).Error
+	return notifications, int(count), err
+}
+
+func (rep NotificationRepo) MarkRead(id uint, at time.Time) error {
+	result := rep.db.Model(&entity.Notification{}).Where("id = ?", id).Update("read_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (rep NotificationRepo) Delete(id uint) error {
+	result := rep.db.Where("id = ?", id).Delete(&entity.Notification{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
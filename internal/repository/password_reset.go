@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"money-tracker/internal/entity"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PasswordResetToken struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;index"`
+	TokenHash string    `gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+type PasswordResetRepo struct {
+	db *gorm.DB
+}
+
+func NewPasswordResetRepo(db *gorm.DB) *PasswordResetRepo {
+	return &PasswordResetRepo{db: db}
+}
+
+func (rep PasswordResetRepo) Insert(token *entity.PasswordResetToken) error {
+	return rep.db.Create(token).Error
+}
+
+// Use finds the token and then marks it with an UPDATE that only matches an
+// unused token, so two requests racing with the same token cannot both use
+// it.
+func (rep PasswordResetRepo) Find(token_hash string, now time.Time) (*entity.PasswordResetToken, error) {
+	var t entity.PasswordResetToken
+	err := rep.db.
+		Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", token_hash, now).
+		First(&t).Error
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (rep PasswordResetRepo) Use(token_hash string, now time.Time) (*entity.PasswordResetToken, error) {
+	var t entity.PasswordResetToken
+	err := rep.db.
+		Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", token_hash, now).
+		First(&t).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := rep.db.Model(&entity.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", t.ID).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	t.UsedAt = &now
+	return &t, nil
+}
+
+func (rep PasswordResetRepo) DeleteByUser(user_id uint) error {
+	return rep.db.Where("user_id = ?", user_id).Delete(&PasswordResetToken{}).Error
+}
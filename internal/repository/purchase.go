@@ -10,6 +10,7 @@ import (
 
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Purchase struct {
@@ -27,6 +28,8 @@ type Purchase struct {
 	SubCategoryId *uint          `gorm:"index"`
 	SubCategory   *Category      `gorm:"foreignKey:SubCategoryId;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
 	Details       datatypes.JSON `gorm:"type:jsonb"`
+	ExternalID    string         `gorm:"size:255;index"`
+	Version       uint           `gorm:"default:1;not null"`
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
 	DeletedAt     time.Time `gorm:"index"`
@@ -44,7 +47,11 @@ func NewPurchaseRepo(db *gorm.DB) *PurchaseRepo {
 
 func (rep PurchaseRepo) Insert(purchase *entity.Purchase) error {
 	p := ToRepoPurchase(purchase)
-	return rep.db.Create(p).Error
+	if err := rep.db.Create(p).Error; err != nil {
+		return err
+	}
+	purchase.ID = p.ID
+	return nil
 }
 
 func (rep PurchaseRepo) FindById(id uint, status_id []uint) (*entity.Purchase, error) {
@@ -65,15 +72,58 @@ func (rep PurchaseRepo) Delete(id uint) error {
 			"deleted_at": now,
 		}).Error
 }
+// Update saves the purchase if it is still at purchase.Version and bumps
+// the version, otherwise it returns entity.ErrVersionConflict.
 func (rep PurchaseRepo) Update(purchase *entity.Purchase) (*entity.Purchase, error) {
 	purchase.UpdatedAt = time.Now()
 	dbQ := ToRepoPurchase(purchase)
-	if err := rep.db.Save(dbQ).Error; err != nil {
-		return nil, err
+	dbQ.Version = purchase.Version + 1
+
+	result := rep.db.Model(&Purchase{}).
+		Where("id = ? AND version = ?", purchase.ID, purchase.Version).
+		Select("*").
+		Omit("id", "created_at", clause.Associations).
+		Updates(dbQ)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, versionConflictOrNotFound(rep.db, &Purchase{}, purchase.ID)
 	}
+
+	purchase.Version = dbQ.Version
 	return purchase, nil
 }
 
+// FindByExternalIDs looks up imported purchases in any status, so that a
+// removed purchase is not imported again.
+func (rep PurchaseRepo) FindByExternalIDs(ids []string) ([]entity.Purchase, error) {
+	var items []Purchase
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	if err := rep.db.Select("id", "external_id", "status_id").Where("external_id IN ?", ids).Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	var purchases []entity.Purchase
+	for _, dbF := range items {
+		purchases = append(purchases, *dbF.ToEntityPurchase())
+	}
+	return purchases, nil
+}
+
+// HasMerchant uses the index on lower(btrim(reason)) and stops at the
+// first match.
+func (rep PurchaseRepo) HasMerchant(merchant string, date time.Time, except uint) (bool, error) {
+	var ids []uint
+	err := rep.db.Model(&Purchase{}).
+		Where("lower(btrim(reason)) = ? AND date <= ? AND id <> ? AND status_id = ?", merchant, date, except, constants.StatusActive).
+		Limit(1).
+		Pluck("id", &ids).Error
+	return len(ids) > 0, err
+}
+
 func (rep PurchaseRepo) FindAll(input dto.PurchaseFindAll) ([]entity.Purchase, int, error) {
 	query := rep.db.Model(&Purchase{})
 
@@ -102,12 +152,30 @@ func (rep PurchaseRepo) FindAll(input dto.PurchaseFindAll) ([]entity.Purchase, i
 	if input.Amount > 0 {
 		query = query.Where("amount = ?", input.Amount)
 	}
+	if input.DateFrom != nil {
+		query = query.Where("date >= ?", *input.DateFrom)
+	}
+	if input.DateTo != nil {
+		query = query.Where("date < ?", *input.DateTo)
+	}
+	if input.UpdatedFrom != nil {
+		query = query.Where("updated_at >= ?", *input.UpdatedFrom)
+	}
+	if input.AfterID != 0 {
+		if input.Sort == "DESC" {
+			query = query.Where("id < ?", input.AfterID)
+		} else {
+			query = query.Where("id > ?", input.AfterID)
+		}
+	}
 
-	// --- Default active status ---
-	if input.StatusID == 0 {
-		query = query.Where("status_id = ?", 1)
-	} else {
-		query = query.Where("status_id = ?", input.StatusID)
+	// --- Default active status, unless every status is asked for ---
+	if !input.AnyStatus {
+		if input.StatusID == 0 {
+			query = query.Where("status_id = ?", 1)
+		} else {
+			query = query.Where("status_id = ?", input.StatusID)
+		}
 	}
 
 	// --- Tags filtering (if applicable) ---
@@ -125,6 +193,7 @@ func (rep PurchaseRepo) FindAll(input dto.PurchaseFindAll) ([]entity.Purchase, i
 	columns := []string{
 		"id", "date", "amount", "reason", "status_id", "color",
 		"method", "tag_ids", "note", "category_id", "sub_category_id", "details",
+		"external_id", "version",
 	}
 
 	if input.OtherFields {
@@ -192,6 +261,8 @@ func (m *Purchase) ToEntityPurchase() *entity.Purchase {
 		SubCategory:   subcat,
 		Details:       det,
 		TagIDs:        m.TagIDs,
+		ExternalID:    m.ExternalID,
+		Version:       m.Version,
 		CreatedAt:     m.CreatedAt,
 		UpdatedAt:     m.UpdatedAt,
 		DeletedAt:     m.DeletedAt,
@@ -229,6 +300,8 @@ func ToRepoPurchase(e *entity.Purchase) *Purchase {
 		SubCategory:   subcat,
 		SubCategoryId: e.SubCategoryId,
 		Details:       det,
+		ExternalID:    e.ExternalID,
+		Version:       e.Version,
 		CreatedAt:     e.CreatedAt,
 		UpdatedAt:     e.UpdatedAt,
 		DeletedAt:     e.DeletedAt,
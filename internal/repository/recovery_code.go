@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type RecoveryCode struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null;index"`
+	CodeHash  string `gorm:"size:64;not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+type RecoveryCodeRepo struct {
+	db *gorm.DB
+}
+
+func NewRecoveryCodeRepo(db *gorm.DB) *RecoveryCodeRepo {
+	return &RecoveryCodeRepo{db: db}
+}
+
+func (rep RecoveryCodeRepo) Replace(user_id uint, hashes []string) error {
+	return rep.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", user_id).Delete(&RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if len(hashes) == 0 {
+			return nil
+		}
+
+		codes := make([]RecoveryCode, 0, len(hashes))
+		for _, hash := range hashes {
+			codes = append(codes, RecoveryCode{UserID: user_id, CodeHash: hash})
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// Use marks the code in a single UPDATE, so two requests racing with the
+// same code cannot both use it.
+func (rep RecoveryCodeRepo) Use(user_id uint, hash string) (bool, error) {
+	result := rep.db.Model(&RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user_id, hash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (rep RecoveryCodeRepo) CountUnused(user_id uint) (int, error) {
+	var count int64
+	err := rep.db.Model(&RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", user_id).Count(&count).Error
+	return int(count), err
+}
+
+func (rep RecoveryCodeRepo) DeleteAll(user_id uint) error {
+	return rep.db.Where("user_id = ?", user_id).Delete(&RecoveryCode{}).Error
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"money-tracker/internal/entity"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type RecurringItem struct {
+	ID         uint      `gorm:"primaryKey"`
+	Name       string    `gorm:"size:255;not null"`
+	Amount     int64     `gorm:"not null"`
+	Frequency  string    `gorm:"size:16;not null"`
+	StartDate  time.Time `gorm:"not null"`
+	EndDate    *time.Time
+	Account    string `gorm:"size:255;not null"`
+	CategoryID *uint
+	Note       string `gorm:"size:1000"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+type RecurringItemRepo struct {
+	db *gorm.DB
+}
+
+func NewRecurringItemRepo(db *gorm.DB) *RecurringItemRepo {
+	return &RecurringItemRepo{db: db}
+}
+
+func (rep RecurringItemRepo) Insert(item *entity.RecurringItem) error {
+	return rep.db.Create(item).Error
+}
+
+func (rep RecurringItemRepo) Update(item *entity.RecurringItem) error {
+	result := rep.db.Model(&entity.RecurringItem{}).Where("id = ?", item.ID).Updates(map[string]interface{}{
+		"name":        item.Name,
+		"amount":      item.Amount,
+		"frequency":   item.Frequency,
+		"start_date":  item.StartDate,
+		"end_date":    item.EndDate,
+		"account":     item.Account,
+		"category_id": item.CategoryID,
+		"note":        item.Note,
+		"updated_at":  item.UpdatedAt,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (rep RecurringItemRepo) Delete(id uint) error {
+	result := rep.db.Where("id = ?", id).Delete(&entity.RecurringItem{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (rep RecurringItemRepo) FindById(id uint) (*entity.RecurringItem, error) {
+	var item entity.RecurringItem
+	if err := rep.db.Where("id = ?", id).First(&item).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (rep RecurringItemRepo) FindAll() ([]entity.RecurringItem, error) {
+	var items []entity.RecurringItem
+	err := rep.db.Order("name, id").Find(&items).Error
+	return items, err
+}
@@ -0,0 +1,42 @@
+package repository_test
+
+import (
+	"money-tracker/internal/repository"
+	"money-tracker/internal/repository/repotest"
+	"money-tracker/internal/testdb"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	testdb.Main(m)
+}
+
+// TestGormRepositories runs the contract against Postgres, see testdb.
+func TestGormRepositories(t *testing.T) {
+	db := testdb.Open(t)
+
+	repotest.Run(t, func(t *testing.T) repotest.Repos {
+		testdb.Truncate(t, db)
+		return repotest.Repos{
+			Category:      repository.NewRepositoryGorm(db),
+			Tag:           repository.NewTagRepoGorm(db),
+			Purchase:      repository.NewPurchaseRepo(db),
+			User:          repository.NewUserRepositoryGorm(db),
+			UserToken:     repository.NewUserTokenRepositoryGorm(db),
+			Idempotency:   repository.NewIdempotencyRepo(db),
+			RecoveryCode:  repository.NewRecoveryCodeRepo(db),
+			PasswordReset: repository.NewPasswordResetRepo(db),
+			APIKey:        repository.NewAPIKeyRepo(db),
+			Webhook:       repository.NewWebhookRepo(db),
+			Outbox:        repository.NewOutboxRepo(db),
+			Delivery:      repository.NewWebhookDeliveryRepo(db),
+			Goal:          repository.NewGoalRepo(db),
+			Contribution:  repository.NewGoalContributionRepo(db),
+			Loan:          repository.NewLoanRepo(db),
+			LoanPayment:   repository.NewLoanPaymentRepo(db),
+			RecurringItem: repository.NewRecurringItemRepo(db),
+			Notification:  repository.NewNotificationRepo(db),
+			Tx:            repository.NewGormTransactor(db),
+		}
+	})
+}
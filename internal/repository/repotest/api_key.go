@@ -0,0 +1,57 @@
+package repotest
+
+import (
+	"money-tracker/internal/constants"
+	"money-tracker/internal/entity"
+	"testing"
+	"time"
+)
+
+func testAPIKey(t *testing.T, newRepos Factory) {
+	r := newRepos(t)
+	alice := addUser(t, r, "alice", 1)
+	bob := addUser(t, r, "bob", 2)
+
+	add := func(user uint, name string, hash string) *entity.APIKey {
+		t.Helper()
+		k, err := entity.NewAPIKey(user, name, constants.ScopeRead, "mt_"+hash, hash, nil)
+		must(t, err)
+		must(t, r.APIKey.Insert(k))
+		if k.ID == 0 {
+			t.Fatal("Insert did not set the id")
+		}
+		return k
+	}
+	first := add(alice.ID, "backup script", "h1")
+	second := add(alice.ID, "importer", "h2")
+	add(bob.ID, "bob's", "h3")
+
+	got, err := r.APIKey.FindByHash("h2")
+	must(t, err)
+	if got.ID != second.ID || got.UserID != alice.ID || got.Name != "importer" || got.Scope != constants.ScopeRead {
+		t.Fatalf("FindByHash returned %+v", got)
+	}
+	_, err = r.APIKey.FindByHash("nope")
+	wantNotFound(t, err)
+
+	keys, err := r.APIKey.FindByUser(alice.ID)
+	must(t, err)
+	if len(keys) != 2 || keys[0].ID != second.ID || keys[1].ID != first.ID {
+		t.Fatalf("FindByUser returned %+v", keys)
+	}
+
+	used := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	must(t, r.APIKey.Touch(first.ID, used))
+	got, err = r.APIKey.FindByHash("h1")
+	must(t, err)
+	if got.LastUsedAt == nil || !got.LastUsedAt.Equal(used) {
+		t.Fatalf("LastUsedAt %v, want %v", got.LastUsedAt, used)
+	}
+
+	// a user can only delete their own keys
+	wantNotFound(t, r.APIKey.Delete(bob.ID, first.ID))
+	must(t, r.APIKey.Delete(alice.ID, first.ID))
+	_, err = r.APIKey.FindByHash("h1")
+	wantNotFound(t, err)
+	wantNotFound(t, r.APIKey.Delete(alice.ID, first.ID))
+}
@@ -0,0 +1,141 @@
+package repotest
+
+import (
+	"money-tracker/internal/dto"
+	"testing"
+)
+
+func testCategory(t *testing.T, newRepos Factory) {
+	t.Run("InsertAndFind", func(t *testing.T) {
+		r := newRepos(t)
+		c := addCategory(t, r, "Food", "food")
+		if c.ID == 0 {
+			t.Fatal("Insert did not set the id")
+		}
+
+		got, err := r.Category.FindById(c.ID)
+		must(t, err)
+		if got.Title != "Food" || got.Slug != "food" || got.Color != "#food" || got.StatusID != 1 || got.Version != 1 {
+			t.Fatalf("FindById returned %+v", got)
+		}
+
+		_, err = r.Category.FindById(c.ID + 100)
+		wantNotFound(t, err)
+	})
+
+	t.Run("StatusDefaultsToActive", func(t *testing.T) {
+		r := newRepos(t)
+		c := addCategory(t, r, "Food", "food")
+		c2 := *c
+		c2.ID = 0
+		c2.StatusID = 0
+		c2.Slug = "food-2"
+		must(t, r.Category.Insert(&c2))
+
+		got, err := r.Category.FindById(c2.ID)
+		must(t, err)
+		if got.StatusID != 1 {
+			t.Fatalf("status %d, want 1", got.StatusID)
+		}
+	})
+
+	t.Run("FindBySlug", func(t *testing.T) {
+		r := newRepos(t)
+		c := addCategory(t, r, "Food", "food")
+		must(t, r.Category.Delete(c.ID))
+
+		_, err := r.Category.FindBySlug("food", []uint{1})
+		wantNotFound(t, err)
+
+		got, err := r.Category.FindBySlug("food", []uint{0, 1})
+		must(t, err)
+		if got.ID != c.ID || got.StatusID != 0 {
+			t.Fatalf("FindBySlug returned %+v", got)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		r := newRepos(t)
+		c := addCategory(t, r, "Food", "food")
+		must(t, r.Category.Delete(c.ID))
+
+		_, err := r.Category.FindById(c.ID)
+		wantNotFound(t, err)
+
+		// deleting a missing category is not an error
+		must(t, r.Category.Delete(c.ID+100))
+	})
+
+	t.Run("FindAll", func(t *testing.T) {
+		r := newRepos(t)
+		food := addCategory(t, r, "Food", "food")
+		fast := addCategory(t, r, "Fast food", "fast-food")
+		rent := addCategory(t, r, "Rent", "rent")
+		gone := addCategory(t, r, "Old food", "old-food")
+		must(t, r.Category.Delete(gone.ID))
+
+		items, count, err := r.Category.FindAll(dto.CategoryFindAll{Limit: 10})
+		must(t, err)
+		if count != 3 {
+			t.Fatalf("count %d, want 3", count)
+		}
+		sameIDs(t, "active", ids(items, categoryID), food.ID, fast.ID, rent.ID)
+
+		items, count, err = r.Category.FindAll(dto.CategoryFindAll{Title: "FOOD", Limit: 10, OrderBy: "id", Sort: "DESC"})
+		must(t, err)
+		if count != 2 {
+			t.Fatalf("title count %d, want 2", count)
+		}
+		sameIDs(t, "title", ids(items, categoryID), fast.ID, food.ID)
+
+		items, count, err = r.Category.FindAll(dto.CategoryFindAll{Start: 1, Limit: 1})
+		must(t, err)
+		if count != 3 {
+			t.Fatalf("page count %d, want 3", count)
+		}
+		sameIDs(t, "page", ids(items, categoryID), fast.ID)
+
+		items, _, err = r.Category.FindAll(dto.CategoryFindAll{Slug: "rent", Limit: 10})
+		must(t, err)
+		sameIDs(t, "slug", ids(items, categoryID), rent.ID)
+
+		items, _, err = r.Category.FindAll(dto.CategoryFindAll{ID: food.ID, Limit: 10})
+		must(t, err)
+		sameIDs(t, "id", ids(items, categoryID), food.ID)
+
+		items, count, err = r.Category.FindAll(dto.CategoryFindAll{Title: "nothing", Limit: 10})
+		must(t, err)
+		if count != 0 || len(items) != 0 {
+			t.Fatalf("no match returned %d items, count %d", len(items), count)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		r := newRepos(t)
+		c := addCategory(t, r, "Food", "food")
+
+		c.Title = "Groceries"
+		c.Color = "#00ff00"
+		updated, err := r.Category.Update(c)
+		must(t, err)
+		if updated.Version != 2 {
+			t.Fatalf("version %d, want 2", updated.Version)
+		}
+
+		got, err := r.Category.FindById(c.ID)
+		must(t, err)
+		if got.Title != "Groceries" || got.Color != "#00ff00" || got.Version != 2 {
+			t.Fatalf("FindById after Update returned %+v", got)
+		}
+
+		stale := *got
+		stale.Version = 1
+		_, err = r.Category.Update(&stale)
+		wantConflict(t, err)
+
+		missing := *got
+		missing.ID += 100
+		_, err = r.Category.Update(&missing)
+		wantNotFound(t, err)
+	})
+}
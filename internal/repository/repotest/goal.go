@@ -0,0 +1,83 @@
+package repotest
+
+import (
+	"money-tracker/internal/entity"
+	"testing"
+	"time"
+)
+
+func goalID(g entity.Goal) uint                     { return g.ID }
+func contributionID(c entity.GoalContribution) uint { return c.ID }
+
+func testGoal(t *testing.T, newRepos Factory) {
+	r := newRepos(t)
+
+	add := func(title string, deadline time.Time) *entity.Goal {
+		t.Helper()
+		g, err := entity.NewGoal(title, 2_000_000, deadline, "")
+		must(t, err)
+		must(t, r.Goal.Insert(g))
+		if g.ID == 0 {
+			t.Fatal("Insert did not set the id")
+		}
+		return g
+	}
+	vacation := add("Vacation", day(30))
+	laptop := add("Laptop", day(20))
+
+	goals, err := r.Goal.FindAll()
+	must(t, err)
+	sameIDs(t, "FindAll", ids(goals, goalID), laptop.ID, vacation.ID)
+
+	must(t, vacation.Set("Vacation in June", 2_500_000, day(31)))
+	vacation.Account = "savings"
+	vacation.UpdatedAt = time.Now()
+	must(t, r.Goal.Update(vacation))
+	got, err := r.Goal.FindById(vacation.ID)
+	must(t, err)
+	if got.Title != "Vacation in June" || got.TargetAmount != 2_500_000 || !got.Deadline.Equal(day(31)) || got.Account != "savings" {
+		t.Fatalf("Update did not save %+v", got)
+	}
+	wantNotFound(t, r.Goal.Update(&entity.Goal{ID: 999}))
+	_, err = r.Goal.FindById(999)
+	wantNotFound(t, err)
+
+	contribute := func(goal *entity.Goal, amount int64, date time.Time) *entity.GoalContribution {
+		t.Helper()
+		c, err := entity.NewGoalContribution(goal.ID, amount, date, "")
+		must(t, err)
+		must(t, r.Contribution.Insert(c))
+		if c.ID == 0 {
+			t.Fatal("Insert did not set the id")
+		}
+		return c
+	}
+	late := contribute(vacation, 300, day(10))
+	early := contribute(vacation, 200, day(5))
+	withdrawal := contribute(vacation, -100, day(12))
+	other := contribute(laptop, 50, day(1))
+
+	contributions, err := r.Contribution.FindByGoal(vacation.ID)
+	must(t, err)
+	sameIDs(t, "FindByGoal", ids(contributions, contributionID), early.ID, late.ID, withdrawal.ID)
+	if contributions[2].Amount != -100 {
+		t.Fatalf("FindByGoal returned %+v", contributions[2])
+	}
+
+	// a contribution is only deleted through its own goal
+	wantNotFound(t, r.Contribution.Delete(laptop.ID, late.ID))
+	must(t, r.Contribution.Delete(vacation.ID, late.ID))
+	wantNotFound(t, r.Contribution.Delete(vacation.ID, late.ID))
+
+	// deleting a goal deletes its contributions
+	must(t, r.Goal.Delete(vacation.ID))
+	wantNotFound(t, r.Goal.Delete(vacation.ID))
+	contributions, err = r.Contribution.FindByGoal(vacation.ID)
+	must(t, err)
+	if len(contributions) != 0 {
+		t.Fatalf("%d contributions left after Delete", len(contributions))
+	}
+	contributions, err = r.Contribution.FindByGoal(laptop.ID)
+	must(t, err)
+	sameIDs(t, "other goal", ids(contributions, contributionID), other.ID)
+}
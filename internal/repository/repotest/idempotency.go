@@ -0,0 +1,85 @@
+package repotest
+
+import (
+	"money-tracker/internal/entity"
+	"testing"
+	"time"
+)
+
+func testIdempotency(t *testing.T, newRepos Factory) {
+	t.Run("Reserve", func(t *testing.T) {
+		r := newRepos(t)
+		key, err := entity.NewIdempotencyKey("k1", "POST /purchase", "hash", time.Hour)
+		must(t, err)
+
+		ok, err := r.Idempotency.Reserve(key)
+		must(t, err)
+		if !ok || key.ID == 0 {
+			t.Fatalf("first Reserve: %v, id %d", ok, key.ID)
+		}
+
+		again, err := entity.NewIdempotencyKey("k1", "POST /purchase", "other", time.Hour)
+		must(t, err)
+		ok, err = r.Idempotency.Reserve(again)
+		must(t, err)
+		if ok {
+			t.Fatal("second Reserve of the same key succeeded")
+		}
+
+		// the same key in another scope is another key
+		other, err := entity.NewIdempotencyKey("k1", "POST /tag", "hash", time.Hour)
+		must(t, err)
+		ok, err = r.Idempotency.Reserve(other)
+		must(t, err)
+		if !ok {
+			t.Fatal("Reserve in another scope failed")
+		}
+	})
+
+	t.Run("CompleteAndFind", func(t *testing.T) {
+		r := newRepos(t)
+		key, err := entity.NewIdempotencyKey("k1", "POST /purchase", "hash", time.Hour)
+		must(t, err)
+		_, err = r.Idempotency.Reserve(key)
+		must(t, err)
+
+		got, err := r.Idempotency.Find("k1", "POST /purchase")
+		must(t, err)
+		if got.ID != key.ID || got.Completed || got.RequestHash != "hash" {
+			t.Fatalf("Find before Complete returned %+v", got)
+		}
+
+		must(t, r.Idempotency.Complete(key.ID, 201, []byte(`{"id":1}`)))
+		got, err = r.Idempotency.Find("k1", "POST /purchase")
+		must(t, err)
+		if !got.Completed || got.StatusCode != 201 || string(got.Response) != `{"id":1}` {
+			t.Fatalf("Find after Complete returned %+v", got)
+		}
+
+		_, err = r.Idempotency.Find("k1", "POST /tag")
+		wantNotFound(t, err)
+
+		must(t, r.Idempotency.Delete(key.ID))
+		_, err = r.Idempotency.Find("k1", "POST /purchase")
+		wantNotFound(t, err)
+	})
+
+	t.Run("DeleteExpired", func(t *testing.T) {
+		r := newRepos(t)
+		old, err := entity.NewIdempotencyKey("old", "POST /purchase", "hash", time.Minute)
+		must(t, err)
+		fresh, err := entity.NewIdempotencyKey("fresh", "POST /purchase", "hash", time.Hour)
+		must(t, err)
+		_, err = r.Idempotency.Reserve(old)
+		must(t, err)
+		_, err = r.Idempotency.Reserve(fresh)
+		must(t, err)
+
+		must(t, r.Idempotency.DeleteExpired(time.Now().Add(10*time.Minute)))
+
+		_, err = r.Idempotency.Find("old", "POST /purchase")
+		wantNotFound(t, err)
+		_, err = r.Idempotency.Find("fresh", "POST /purchase")
+		must(t, err)
+	})
+}
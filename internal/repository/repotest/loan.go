@@ -0,0 +1,82 @@
+package repotest
+
+import (
+	"money-tracker/internal/entity"
+	"testing"
+	"time"
+)
+
+func loanID(l entity.Loan) uint               { return l.ID }
+func loanPaymentID(p entity.LoanPayment) uint { return p.ID }
+
+func testLoan(t *testing.T, newRepos Factory) {
+	r := newRepos(t)
+
+	add := func(name string) *entity.Loan {
+		t.Helper()
+		l, err := entity.NewLoan(name, "", 1_500_000, 6.5, 48, entity.FrequencyMonthly, day(1))
+		must(t, err)
+		must(t, r.Loan.Insert(l))
+		if l.ID == 0 {
+			t.Fatal("Insert did not set the id")
+		}
+		return l
+	}
+	car := add("Car")
+	iou := add("Alex")
+
+	loans, err := r.Loan.FindAll()
+	must(t, err)
+	sameIDs(t, "FindAll", ids(loans, loanID), iou.ID, car.ID)
+
+	must(t, iou.SetName("Alex", entity.LoanLent))
+	must(t, iou.SetTerms(20_000, 0, 4, entity.FrequencyWeekly, day(2)))
+	iou.UpdatedAt = time.Now()
+	must(t, r.Loan.Update(iou))
+	got, err := r.Loan.FindById(iou.ID)
+	must(t, err)
+	if got.Direction != entity.LoanLent || got.Principal != 20_000 || got.InterestRate != 0 || got.Term != 4 ||
+		got.Frequency != entity.FrequencyWeekly || !got.StartDate.Equal(day(2)) {
+		t.Fatalf("Update did not save %+v", got)
+	}
+	wantNotFound(t, r.Loan.Update(&entity.Loan{ID: 999}))
+	_, err = r.Loan.FindById(999)
+	wantNotFound(t, err)
+
+	pay := func(loan *entity.Loan, amount int64, date time.Time) *entity.LoanPayment {
+		t.Helper()
+		p := &entity.LoanPayment{LoanID: loan.ID, Date: date, Amount: amount, Principal: amount - 100, Interest: 100}
+		must(t, r.LoanPayment.Insert(p))
+		if p.ID == 0 {
+			t.Fatal("Insert did not set the id")
+		}
+		return p
+	}
+	late := pay(car, 35_000, day(10))
+	early := pay(car, 35_000, day(5))
+	other := pay(iou, 5_000, day(3))
+
+	payments, err := r.LoanPayment.FindByLoan(car.ID)
+	must(t, err)
+	sameIDs(t, "FindByLoan", ids(payments, loanPaymentID), early.ID, late.ID)
+	if payments[0].Principal != 34_900 || payments[0].Interest != 100 {
+		t.Fatalf("FindByLoan returned %+v", payments[0])
+	}
+
+	// a payment is only deleted through its own loan
+	wantNotFound(t, r.LoanPayment.Delete(iou.ID, late.ID))
+	must(t, r.LoanPayment.Delete(car.ID, late.ID))
+	wantNotFound(t, r.LoanPayment.Delete(car.ID, late.ID))
+
+	// deleting a loan deletes its payments
+	must(t, r.Loan.Delete(car.ID))
+	wantNotFound(t, r.Loan.Delete(car.ID))
+	payments, err = r.LoanPayment.FindByLoan(car.ID)
+	must(t, err)
+	if len(payments) != 0 {
+		t.Fatalf("%d payments left after Delete", len(payments))
+	}
+	payments, err = r.LoanPayment.FindByLoan(iou.ID)
+	must(t, err)
+	sameIDs(t, "other loan", ids(payments, loanPaymentID), other.ID)
+}
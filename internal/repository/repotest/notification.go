@@ -0,0 +1,51 @@
+package repotest
+
+import (
+	"money-tracker/internal/entity"
+	"testing"
+	"time"
+)
+
+func notificationID(n entity.Notification) uint { return n.ID }
+
+func testNotification(t *testing.T, newRepos Factory) {
+	r := newRepos(t)
+
+	add := func(key string) (*entity.Notification, bool) {
+		t.Helper()
+		n := &entity.Notification{Kind: entity.AnomalyLargePurchase, Key: key, Title: "Unusually large purchase"}
+		created, err := r.Notification.Insert(n)
+		must(t, err)
+		if created && n.ID == 0 {
+			t.Fatal("Insert did not set the id")
+		}
+		return n, created
+	}
+	first, _ := add("large_purchase:1")
+	second, _ := add("large_purchase:2")
+	if _, created := add("large_purchase:1"); created {
+		t.Fatal("Insert stored a key twice")
+	}
+
+	all, count, err := r.Notification.FindAll(false, 0, 10)
+	must(t, err)
+	sameIDs(t, "FindAll", ids(all, notificationID), second.ID, first.ID)
+	if count != 2 {
+		t.Fatalf("FindAll counted %d", count)
+	}
+
+	must(t, r.Notification.MarkRead(second.ID, time.Now()))
+	wantNotFound(t, r.Notification.MarkRead(999, time.Now()))
+	unread, count, err := r.Notification.FindAll(true, 0, 10)
+	must(t, err)
+	sameIDs(t, "FindAll unread", ids(unread, notificationID), first.ID)
+	if count != 1 {
+		t.Fatalf("FindAll unread counted %d", count)
+	}
+	all, _, err = r.Notification.FindAll(false, 1, 1)
+	must(t, err)
+	sameIDs(t, "FindAll page", ids(all, notificationID), first.ID)
+
+	must(t, r.Notification.Delete(first.ID))
+	wantNotFound(t, r.Notification.Delete(first.ID))
+}
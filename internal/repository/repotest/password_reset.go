@@ -0,0 +1,69 @@
+package repotest
+
+import (
+	"money-tracker/internal/entity"
+	"testing"
+	"time"
+)
+
+func testPasswordReset(t *testing.T, newRepos Factory) {
+	t.Run("UseOnceBeforeExpiry", func(t *testing.T) {
+		r := newRepos(t)
+		alice := addUser(t, r, "alice", 1)
+		now := time.Now().UTC().Truncate(time.Second)
+
+		fresh, err := entity.NewPasswordResetToken(alice.ID, "fresh", now.Add(time.Hour))
+		must(t, err)
+		must(t, r.PasswordReset.Insert(fresh))
+		if fresh.ID == 0 {
+			t.Fatal("Insert did not set the id")
+		}
+		stale, err := entity.NewPasswordResetToken(alice.ID, "stale", now.Add(-time.Second))
+		must(t, err)
+		must(t, r.PasswordReset.Insert(stale))
+
+		found, err := r.PasswordReset.Find("fresh", now)
+		must(t, err)
+		if found.ID != fresh.ID || found.UsedAt != nil {
+			t.Fatalf("Find returned %+v", found)
+		}
+		_, err = r.PasswordReset.Find("stale", now)
+		wantNotFound(t, err)
+
+		got, err := r.PasswordReset.Use("fresh", now)
+		must(t, err)
+		if got.ID != fresh.ID || got.UserID != alice.ID || got.UsedAt == nil {
+			t.Fatalf("Use returned %+v", got)
+		}
+		_, err = r.PasswordReset.Use("fresh", now)
+		wantNotFound(t, err)
+		_, err = r.PasswordReset.Find("fresh", now)
+		wantNotFound(t, err)
+		_, err = r.PasswordReset.Use("stale", now)
+		wantNotFound(t, err)
+		_, err = r.PasswordReset.Use("unknown", now)
+		wantNotFound(t, err)
+	})
+
+	t.Run("DeleteByUser", func(t *testing.T) {
+		r := newRepos(t)
+		alice := addUser(t, r, "alice", 1)
+		bob := addUser(t, r, "bob", 2)
+		expires := time.Now().Add(time.Hour)
+		for _, tok := range []struct {
+			hash string
+			user uint
+		}{{"a", alice.ID}, {"b", bob.ID}} {
+			row, err := entity.NewPasswordResetToken(tok.user, tok.hash, expires)
+			must(t, err)
+			must(t, r.PasswordReset.Insert(row))
+		}
+
+		must(t, r.PasswordReset.DeleteByUser(alice.ID))
+		_, err := r.PasswordReset.Use("a", time.Now())
+		wantNotFound(t, err)
+		if _, err := r.PasswordReset.Use("b", time.Now()); err != nil {
+			t.Fatalf("DeleteByUser removed another user's token: %v", err)
+		}
+	})
+}
@@ -0,0 +1,271 @@
+package repotest
+
+import (
+	"fmt"
+	"money-tracker/internal/constants"
+	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
+	"testing"
+)
+
+func addPurchase(t *testing.T, r Repos, amount int64, date int, category *entity.Category, edit func(p *entity.Purchase)) *entity.Purchase {
+	t.Helper()
+	p, err := entity.NewPurchase(amount, day(date), &category.ID, 1)
+	must(t, err)
+	if edit != nil {
+		edit(p)
+	}
+	must(t, r.Purchase.Insert(p))
+	return p
+}
+
+func testPurchase(t *testing.T, newRepos Factory) {
+	t.Run("InsertAndFind", func(t *testing.T) {
+		r := newRepos(t)
+		food := addCategory(t, r, "Food", "food")
+		p := addPurchase(t, r, 1250, 3, food, func(p *entity.Purchase) {
+			p.Reason = "lunch"
+			p.Method = 2
+			p.Details = constants.JSONMap{"shop": "corner", "items": 2}
+		})
+		if p.ID == 0 {
+			t.Fatal("Insert did not set the id")
+		}
+
+		got, err := r.Purchase.FindById(p.ID, []uint{1})
+		must(t, err)
+		if got.Amount != 1250 || got.Reason != "lunch" || got.Method != 2 || got.StatusID != 1 || got.Version != 1 {
+			t.Fatalf("FindById returned %+v", got)
+		}
+		if !got.Date.Equal(day(3)) {
+			t.Fatalf("date %v, want %v", got.Date, day(3))
+		}
+		if got.CategoryId == nil || *got.CategoryId != food.ID || got.SubCategoryId != nil {
+			t.Fatalf("category ids %v %v", got.CategoryId, got.SubCategoryId)
+		}
+		// details are stored as JSON, so numbers come back as float64
+		if got.Details["shop"] != "corner" || got.Details["items"] != float64(2) {
+			t.Fatalf("details %v", got.Details)
+		}
+		if got.CreatedAt.IsZero() {
+			t.Fatal("created_at is not set")
+		}
+
+		_, err = r.Purchase.FindById(p.ID, []uint{0})
+		wantNotFound(t, err)
+		_, err = r.Purchase.FindById(p.ID+100, []uint{0, 1})
+		wantNotFound(t, err)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		r := newRepos(t)
+		food := addCategory(t, r, "Food", "food")
+		p := addPurchase(t, r, 100, 1, food, nil)
+		must(t, r.Purchase.Delete(p.ID))
+
+		_, err := r.Purchase.FindById(p.ID, []uint{1})
+		wantNotFound(t, err)
+
+		got, err := r.Purchase.FindById(p.ID, []uint{0, 1})
+		must(t, err)
+		if got.StatusID != 0 || got.DeletedAt.IsZero() {
+			t.Fatalf("deleted purchase has status %d, deleted_at %v", got.StatusID, got.DeletedAt)
+		}
+	})
+
+	t.Run("FindAll", func(t *testing.T) {
+		r := newRepos(t)
+		food := addCategory(t, r, "Food", "food")
+		rent := addCategory(t, r, "Rent", "rent")
+		old := addCategory(t, r, "Old", "old")
+		coffee := addTag(t, r, "coffee")
+		work := addTag(t, r, "work")
+
+		lunch := addPurchase(t, r, 1500, 1, food, func(p *entity.Purchase) {
+			p.Reason = "Lunch at work"
+			p.TagIDs = fmt.Sprintf("%d,%d", coffee.ID, work.ID)
+			p.Method = 1
+		})
+		flat := addPurchase(t, r, 90000, 2, rent, func(p *entity.Purchase) {
+			p.Reason = "October rent"
+			p.Method = 2
+		})
+		latte := addPurchase(t, r, 400, 3, food, func(p *entity.Purchase) {
+			p.Reason = "latte"
+			p.TagIDs = fmt.Sprintf("%d", coffee.ID)
+			p.Method = 1
+		})
+		stale := addPurchase(t, r, 700, 4, old, nil)
+		gone := addPurchase(t, r, 300, 5, food, nil)
+		must(t, r.Purchase.Delete(gone.ID))
+		must(t, r.Category.Delete(old.ID))
+
+		list := func(input dto.PurchaseFindAll) ([]entity.Purchase, int) {
+			t.Helper()
+			if input.OrderBy == "" {
+				input.OrderBy, input.Sort = "id", "ASC"
+			}
+			if input.Limit == 0 {
+				input.Limit = 10
+			}
+			items, count, err := r.Purchase.FindAll(input)
+			must(t, err)
+			return items, count
+		}
+
+		items, count := list(dto.PurchaseFindAll{})
+		if count != 4 {
+			t.Fatalf("count %d, want 4", count)
+		}
+		sameIDs(t, "active", ids(items, purchaseID), lunch.ID, flat.ID, latte.ID, stale.ID)
+
+		items, _ = list(dto.PurchaseFindAll{ID: gone.ID})
+		sameIDs(t, "deleted by id", ids(items, purchaseID))
+
+		items, _ = list(dto.PurchaseFindAll{CategoryID: &food.ID})
+		sameIDs(t, "category", ids(items, purchaseID), lunch.ID, latte.ID)
+
+		items, _ = list(dto.PurchaseFindAll{TagIDs: []uint{coffee.ID}})
+		sameIDs(t, "tag", ids(items, purchaseID), lunch.ID, latte.ID)
+
+		items, _ = list(dto.PurchaseFindAll{TagIDs: []uint{coffee.ID, work.ID}})
+		sameIDs(t, "all tags", ids(items, purchaseID), lunch.ID)
+
+		items, _ = list(dto.PurchaseFindAll{Reason: "RENT"})
+		sameIDs(t, "reason", ids(items, purchaseID), flat.ID)
+
+		items, _ = list(dto.PurchaseFindAll{Method: 1, Amount: 400})
+		sameIDs(t, "method and amount", ids(items, purchaseID), latte.ID)
+
+		from, to := day(2), day(4)
+		items, _ = list(dto.PurchaseFindAll{DateFrom: &from, DateTo: &to})
+		sameIDs(t, "dates", ids(items, purchaseID), flat.ID, latte.ID)
+
+		items, _ = list(dto.PurchaseFindAll{AfterID: flat.ID})
+		sameIDs(t, "after id", ids(items, purchaseID), latte.ID, stale.ID)
+		items, _ = list(dto.PurchaseFindAll{OrderBy: "id", Sort: "DESC", AfterID: latte.ID})
+		sameIDs(t, "after id descending", ids(items, purchaseID), flat.ID, lunch.ID)
+
+		items, _ = list(dto.PurchaseFindAll{OrderBy: "amount", Sort: "DESC"})
+		sameIDs(t, "by amount", ids(items, purchaseID), flat.ID, lunch.ID, stale.ID, latte.ID)
+
+		items, count = list(dto.PurchaseFindAll{OrderBy: "date", Sort: "DESC", Start: 1, Limit: 2})
+		if count != 4 {
+			t.Fatalf("page count %d, want 4", count)
+		}
+		sameIDs(t, "page", ids(items, purchaseID), latte.ID, flat.ID)
+
+		// categories are loaded while active, with id, slug and color only
+		items, _ = list(dto.PurchaseFindAll{})
+		if c := items[0].Category; c == nil || c.ID != food.ID || c.Slug != "food" || c.Color != "#food" || c.Title != "" {
+			t.Fatalf("category of %d: %+v", items[0].ID, c)
+		}
+		if items[3].Category != nil || items[3].CategoryId == nil {
+			t.Fatalf("inactive category of %d: %+v %v", items[3].ID, items[3].Category, items[3].CategoryId)
+		}
+
+		// created_at and updated_at come with other_fields only
+		if !items[0].CreatedAt.IsZero() {
+			t.Fatal("created_at loaded without other_fields")
+		}
+		items, _ = list(dto.PurchaseFindAll{OtherFields: true})
+		if items[0].CreatedAt.IsZero() || items[0].UpdatedAt.IsZero() {
+			t.Fatal("created_at not loaded with other_fields")
+		}
+	})
+
+	t.Run("HasMerchant", func(t *testing.T) {
+		r := newRepos(t)
+		food := addCategory(t, r, "Food", "food")
+		market := addPurchase(t, r, 100, 3, food, func(p *entity.Purchase) { p.Reason = " Corner Market " })
+		gone := addPurchase(t, r, 100, 1, food, func(p *entity.Purchase) { p.Reason = "Old Shop" })
+		must(t, r.Purchase.Delete(gone.ID))
+
+		has := func(merchant string, date int, except uint) bool {
+			t.Helper()
+			ok, err := r.Purchase.HasMerchant(merchant, day(date), except)
+			must(t, err)
+			return ok
+		}
+		if !has("corner market", 3, 0) {
+			t.Fatal("merchant not found by its key")
+		}
+		if has("corner market", 2, 0) || has("corner market", 3, market.ID) {
+			t.Fatal("found a later purchase or the excluded one")
+		}
+		if has("old shop", 5, 0) || has("corner", 5, 0) {
+			t.Fatal("found a deleted purchase or a part of a reason")
+		}
+	})
+
+	t.Run("FindByExternalIDs", func(t *testing.T) {
+		r := newRepos(t)
+		food := addCategory(t, r, "Food", "food")
+		a := addPurchase(t, r, 100, 1, food, func(p *entity.Purchase) { p.ExternalID = "ext-a" })
+		b := addPurchase(t, r, 200, 2, food, func(p *entity.Purchase) { p.ExternalID = "ext-b" })
+		addPurchase(t, r, 300, 3, food, nil)
+		must(t, r.Purchase.Delete(b.ID))
+
+		found, err := r.Purchase.FindByExternalIDs([]string{"ext-a", "ext-b", "ext-c"})
+		must(t, err)
+		if len(found) != 2 {
+			t.Fatalf("found %d purchases, want 2", len(found))
+		}
+		byExt := map[string]entity.Purchase{}
+		for _, p := range found {
+			byExt[p.ExternalID] = p
+		}
+		if byExt["ext-a"].ID != a.ID || byExt["ext-a"].StatusID != 1 {
+			t.Fatalf("ext-a: %+v", byExt["ext-a"])
+		}
+		if byExt["ext-b"].ID != b.ID || byExt["ext-b"].StatusID != 0 {
+			t.Fatalf("ext-b: %+v", byExt["ext-b"])
+		}
+
+		found, err = r.Purchase.FindByExternalIDs(nil)
+		must(t, err)
+		if len(found) != 0 {
+			t.Fatalf("FindByExternalIDs(nil) returned %d purchases", len(found))
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		r := newRepos(t)
+		food := addCategory(t, r, "Food", "food")
+		rent := addCategory(t, r, "Rent", "rent")
+		p := addPurchase(t, r, 100, 1, food, func(p *entity.Purchase) { p.Note = "first" })
+
+		current, err := r.Purchase.FindById(p.ID, []uint{1})
+		must(t, err)
+		createdAt := current.CreatedAt
+
+		current.Amount = 250
+		current.Note = ""
+		current.CategoryId = &rent.ID
+		current.Details = constants.JSONMap{"edited": true}
+		updated, err := r.Purchase.Update(current)
+		must(t, err)
+		if updated.Version != 2 {
+			t.Fatalf("version %d, want 2", updated.Version)
+		}
+
+		got, err := r.Purchase.FindById(p.ID, []uint{1})
+		must(t, err)
+		if got.Amount != 250 || got.Note != "" || *got.CategoryId != rent.ID || got.Details["edited"] != true || got.Version != 2 {
+			t.Fatalf("FindById after Update returned %+v", got)
+		}
+		if !got.CreatedAt.Equal(createdAt) {
+			t.Fatalf("created_at changed from %v to %v", createdAt, got.CreatedAt)
+		}
+
+		stale := *got
+		stale.Version = 1
+		_, err = r.Purchase.Update(&stale)
+		wantConflict(t, err)
+
+		missing := *got
+		missing.ID += 100
+		_, err = r.Purchase.Update(&missing)
+		wantNotFound(t, err)
+	})
+}
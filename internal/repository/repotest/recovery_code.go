@@ -0,0 +1,66 @@
+package repotest
+
+import "testing"
+
+func testRecoveryCode(t *testing.T, newRepos Factory) {
+	t.Run("UseOnce", func(t *testing.T) {
+		r := newRepos(t)
+		alice := addUser(t, r, "alice", 1)
+		bob := addUser(t, r, "bob", 2)
+		must(t, r.RecoveryCode.Replace(alice.ID, []string{"h1", "h2", "h3"}))
+		must(t, r.RecoveryCode.Replace(bob.ID, []string{"h1"}))
+
+		ok, err := r.RecoveryCode.Use(alice.ID, "h2")
+		must(t, err)
+		if !ok {
+			t.Fatal("Use of a fresh code failed")
+		}
+		ok, err = r.RecoveryCode.Use(alice.ID, "h2")
+		must(t, err)
+		if ok {
+			t.Fatal("a code was used twice")
+		}
+		ok, err = r.RecoveryCode.Use(alice.ID, "nope")
+		must(t, err)
+		if ok {
+			t.Fatal("an unknown code was used")
+		}
+
+		n, err := r.RecoveryCode.CountUnused(alice.ID)
+		must(t, err)
+		if n != 2 {
+			t.Fatalf("alice has %d unused codes, want 2", n)
+		}
+		// codes belong to their user
+		n, err = r.RecoveryCode.CountUnused(bob.ID)
+		must(t, err)
+		if n != 1 {
+			t.Fatalf("bob has %d unused codes, want 1", n)
+		}
+	})
+
+	t.Run("ReplaceAndDelete", func(t *testing.T) {
+		r := newRepos(t)
+		alice := addUser(t, r, "alice", 1)
+		must(t, r.RecoveryCode.Replace(alice.ID, []string{"old1", "old2"}))
+		must(t, r.RecoveryCode.Replace(alice.ID, []string{"new1"}))
+
+		ok, err := r.RecoveryCode.Use(alice.ID, "old1")
+		must(t, err)
+		if ok {
+			t.Fatal("a replaced code still works")
+		}
+		n, err := r.RecoveryCode.CountUnused(alice.ID)
+		must(t, err)
+		if n != 1 {
+			t.Fatalf("%d unused codes after Replace, want 1", n)
+		}
+
+		must(t, r.RecoveryCode.DeleteAll(alice.ID))
+		n, err = r.RecoveryCode.CountUnused(alice.ID)
+		must(t, err)
+		if n != 0 {
+			t.Fatalf("%d unused codes after DeleteAll", n)
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package repotest
+
+import (
+	"money-tracker/internal/entity"
+	"testing"
+	"time"
+)
+
+func recurringItemID(r entity.RecurringItem) uint { return r.ID }
+
+func testRecurringItem(t *testing.T, newRepos Factory) {
+	r := newRepos(t)
+
+	add := func(name string, amount int64) *entity.RecurringItem {
+		t.Helper()
+		item, err := entity.NewRecurringItem(name, amount, entity.FrequencyMonthly, day(1), nil, "")
+		must(t, err)
+		must(t, r.RecurringItem.Insert(item))
+		if item.ID == 0 {
+			t.Fatal("Insert did not set the id")
+		}
+		return item
+	}
+	salary := add("Salary", 300_000)
+	rent := add("Rent", -120_000)
+
+	items, err := r.RecurringItem.FindAll()
+	must(t, err)
+	sameIDs(t, "FindAll", ids(items, recurringItemID), rent.ID, salary.ID)
+
+	end := day(20)
+	must(t, rent.Set("Rent", -125_000, entity.FrequencyMonthly, day(2), &end, "checking"))
+	category := uint(7)
+	rent.CategoryID = &category
+	rent.UpdatedAt = time.Now()
+	must(t, r.RecurringItem.Update(rent))
+	got, err := r.RecurringItem.FindById(rent.ID)
+	must(t, err)
+	if got.Amount != -125_000 || !got.StartDate.Equal(day(2)) || got.EndDate == nil || !got.EndDate.Equal(end) ||
+		got.Account != "checking" || got.CategoryID == nil || *got.CategoryID != 7 {
+		t.Fatalf("Update did not save %+v", got)
+	}
+
+	// the end date and category can be cleared
+	rent.EndDate, rent.CategoryID = nil, nil
+	must(t, r.RecurringItem.Update(rent))
+	got, err = r.RecurringItem.FindById(rent.ID)
+	must(t, err)
+	if got.EndDate != nil || got.CategoryID != nil {
+		t.Fatalf("Update did not clear %+v", got)
+	}
+	wantNotFound(t, r.RecurringItem.Update(&entity.RecurringItem{ID: 999}))
+
+	must(t, r.RecurringItem.Delete(rent.ID))
+	wantNotFound(t, r.RecurringItem.Delete(rent.ID))
+	_, err = r.RecurringItem.FindById(rent.ID)
+	wantNotFound(t, err)
+}
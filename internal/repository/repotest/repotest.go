@@ -0,0 +1,128 @@
+// Package repotest is the contract of the repositories: one test suite that
+// every implementation (GORM on Postgres, memory) has to pass.
+package repotest
+
+import (
+	"errors"
+	"money-tracker/internal/entity"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Repos is one implementation of all the repositories, sharing one store.
+type Repos struct {
+	Category      entity.CategoryRepository
+	Tag           entity.TagRepository
+	Purchase      entity.PurchaseRepository
+	User          entity.UserRepository
+	UserToken     entity.UserTokenRepository
+	Idempotency   entity.IdempotencyRepository
+	RecoveryCode  entity.RecoveryCodeRepository
+	PasswordReset entity.PasswordResetRepository
+	APIKey        entity.APIKeyRepository
+	Webhook       entity.WebhookRepository
+	Outbox        entity.OutboxRepository
+	Delivery      entity.WebhookDeliveryRepository
+	Goal          entity.GoalRepository
+	Contribution  entity.GoalContributionRepository
+	Loan          entity.LoanRepository
+	LoanPayment   entity.LoanPaymentRepository
+	RecurringItem entity.RecurringItemRepository
+	Notification  entity.NotificationRepository
+	Tx            entity.Transactor
+}
+
+// Factory returns repositories on an empty store. It is called once per
+// test.
+type Factory func(t *testing.T) Repos
+
+// Run runs the whole contract against the repositories of newRepos.
+func Run(t *testing.T, newRepos Factory) {
+	t.Run("Category", func(t *testing.T) { testCategory(t, newRepos) })
+	t.Run("Tag", func(t *testing.T) { testTag(t, newRepos) })
+	t.Run("Purchase", func(t *testing.T) { testPurchase(t, newRepos) })
+	t.Run("User", func(t *testing.T) { testUser(t, newRepos) })
+	t.Run("UserToken", func(t *testing.T) { testUserToken(t, newRepos) })
+	t.Run("Idempotency", func(t *testing.T) { testIdempotency(t, newRepos) })
+	t.Run("RecoveryCode", func(t *testing.T) { testRecoveryCode(t, newRepos) })
+	t.Run("PasswordReset", func(t *testing.T) { testPasswordReset(t, newRepos) })
+	t.Run("APIKey", func(t *testing.T) { testAPIKey(t, newRepos) })
+	t.Run("Webhook", func(t *testing.T) { testWebhook(t, newRepos) })
+	t.Run("Outbox", func(t *testing.T) { testOutbox(t, newRepos) })
+	t.Run("Goal", func(t *testing.T) { testGoal(t, newRepos) })
+	t.Run("Loan", func(t *testing.T) { testLoan(t, newRepos) })
+	t.Run("RecurringItem", func(t *testing.T) { testRecurringItem(t, newRepos) })
+	t.Run("Notification", func(t *testing.T) { testNotification(t, newRepos) })
+	t.Run("Transactor", func(t *testing.T) { testTransactor(t, newRepos) })
+}
+
+// /------------------------------- helpers -------------------------------
+
+func must(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func wantNotFound(t *testing.T, err error) {
+	t.Helper()
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("want gorm.ErrRecordNotFound, got %v", err)
+	}
+}
+
+func wantConflict(t *testing.T, err error) {
+	t.Helper()
+	if !errors.Is(err, entity.ErrVersionConflict) {
+		t.Fatalf("want entity.ErrVersionConflict, got %v", err)
+	}
+}
+
+func addCategory(t *testing.T, r Repos, title string, slug string) *entity.Category {
+	t.Helper()
+	c, err := entity.NewCategory(title, slug, 1, "#"+slug)
+	must(t, err)
+	must(t, r.Category.Insert(c))
+	return c
+}
+
+func addTag(t *testing.T, r Repos, title string) *entity.Tag {
+	t.Helper()
+	tag, err := entity.NewTag(title, 1)
+	must(t, err)
+	must(t, r.Tag.Insert(tag))
+	return tag
+}
+
+// day is a date the database stores without losing precision.
+func day(d int) time.Time {
+	return time.Date(2026, 10, d, 12, 0, 0, 0, time.UTC)
+}
+
+func ids[T any](rows []T, id func(T) uint) []uint {
+	var out []uint
+	for _, row := range rows {
+		out = append(out, id(row))
+	}
+	return out
+}
+
+func sameIDs(t *testing.T, what string, got []uint, want ...uint) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: got ids %v, want %v", what, got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("%s: got ids %v, want %v", what, got, want)
+		}
+	}
+}
+
+func categoryID(c entity.Category) uint { return c.ID }
+func tagID(tag entity.Tag) uint         { return tag.ID }
+func purchaseID(p entity.Purchase) uint { return p.ID }
+func userID(u entity.User) uint         { return u.ID }
@@ -0,0 +1,126 @@
+package repotest
+
+import (
+	"testing"
+)
+
+func testTag(t *testing.T, newRepos Factory) {
+	t.Run("InsertAndFind", func(t *testing.T) {
+		r := newRepos(t)
+		tag := addTag(t, r, "coffee")
+		if tag.ID == 0 {
+			t.Fatal("Insert did not set the id")
+		}
+
+		got, err := r.Tag.FindById(tag.ID)
+		must(t, err)
+		if got.Title != "coffee" || got.StatusID != 1 || got.Version != 1 {
+			t.Fatalf("FindById returned %+v", got)
+		}
+
+		got, err = r.Tag.FindByTitle("coffee")
+		must(t, err)
+		if got.ID != tag.ID {
+			t.Fatalf("FindByTitle returned %+v", got)
+		}
+
+		_, err = r.Tag.FindByTitle("tea")
+		wantNotFound(t, err)
+		_, err = r.Tag.FindById(tag.ID + 100)
+		wantNotFound(t, err)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		r := newRepos(t)
+		tag := addTag(t, r, "coffee")
+		must(t, r.Tag.Delete(tag.ID))
+
+		_, err := r.Tag.FindById(tag.ID)
+		wantNotFound(t, err)
+		_, err = r.Tag.FindByTitle("coffee")
+		wantNotFound(t, err)
+	})
+
+	t.Run("FindByIDs", func(t *testing.T) {
+		r := newRepos(t)
+		a := addTag(t, r, "a")
+		b := addTag(t, r, "b")
+		addTag(t, r, "c")
+		must(t, r.Tag.Delete(b.ID))
+
+		// deleted tags are found too
+		tags, err := r.Tag.FindByIDs([]uint{b.ID, a.ID, b.ID + 100})
+		must(t, err)
+		if len(tags) != 2 {
+			t.Fatalf("FindByIDs returned %d tags, want 2", len(tags))
+		}
+
+		tags, err = r.Tag.FindByIDs(nil)
+		must(t, err)
+		if len(tags) != 0 {
+			t.Fatalf("FindByIDs(nil) returned %d tags", len(tags))
+		}
+	})
+
+	t.Run("FindAll", func(t *testing.T) {
+		r := newRepos(t)
+		coffee := addTag(t, r, "Coffee")
+		iced := addTag(t, r, "iced coffee")
+		tea := addTag(t, r, "tea")
+		gone := addTag(t, r, "old coffee")
+		must(t, r.Tag.Delete(gone.ID))
+
+		items, count, err := r.Tag.FindAll(0, 10, "id", "ASC", 0, 0, "")
+		must(t, err)
+		if count != 3 {
+			t.Fatalf("count %d, want 3", count)
+		}
+		sameIDs(t, "active", ids(items, tagID), coffee.ID, iced.ID, tea.ID)
+
+		items, count, err = r.Tag.FindAll(0, 10, "id", "DESC", 0, 0, "coffee")
+		must(t, err)
+		if count != 2 {
+			t.Fatalf("title count %d, want 2", count)
+		}
+		sameIDs(t, "title", ids(items, tagID), iced.ID, coffee.ID)
+
+		items, count, err = r.Tag.FindAll(2, 1, "id", "ASC", 0, 0, "")
+		must(t, err)
+		if count != 3 {
+			t.Fatalf("page count %d, want 3", count)
+		}
+		sameIDs(t, "page", ids(items, tagID), tea.ID)
+
+		items, _, err = r.Tag.FindAll(0, 10, "id", "ASC", tea.ID, 0, "")
+		must(t, err)
+		sameIDs(t, "id", ids(items, tagID), tea.ID)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		r := newRepos(t)
+		tag := addTag(t, r, "coffee")
+
+		tag.Title = "tea"
+		updated, err := r.Tag.Update(tag)
+		must(t, err)
+		if updated.Version != 2 {
+			t.Fatalf("version %d, want 2", updated.Version)
+		}
+
+		got, err := r.Tag.FindById(tag.ID)
+		must(t, err)
+		if got.Title != "tea" || got.Version != 2 {
+			t.Fatalf("FindById after Update returned %+v", got)
+		}
+
+		stale := *got
+		stale.Version = 1
+		_, err = r.Tag.Update(&stale)
+		wantConflict(t, err)
+
+		missing := *got
+		missing.ID += 100
+		_, err = r.Tag.Update(&missing)
+		wantNotFound(t, err)
+	})
+}
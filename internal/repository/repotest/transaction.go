@@ -0,0 +1,131 @@
+package repotest
+
+import (
+	"errors"
+	"money-tracker/internal/entity"
+	"testing"
+)
+
+func testTransactor(t *testing.T, newRepos Factory) {
+	errRollback := errors.New("rollback")
+
+	t.Run("Commit", func(t *testing.T) {
+		r := newRepos(t)
+		var id uint
+		err := r.Tx.Transaction(func(repos entity.Repositories) error {
+			tag, err := entity.NewTag("coffee", 1)
+			if err != nil {
+				return err
+			}
+			if err := repos.Tag.Insert(tag); err != nil {
+				return err
+			}
+			id = tag.ID
+			return nil
+		})
+		must(t, err)
+
+		_, err = r.Tag.FindById(id)
+		must(t, err)
+	})
+
+	t.Run("Rollback", func(t *testing.T) {
+		r := newRepos(t)
+		food := addCategory(t, r, "Food", "food")
+
+		var id uint
+		err := r.Tx.Transaction(func(repos entity.Repositories) error {
+			tag, err := entity.NewTag("coffee", 1)
+			if err != nil {
+				return err
+			}
+			if err := repos.Tag.Insert(tag); err != nil {
+				return err
+			}
+			id = tag.ID
+
+			food.Title = "Groceries"
+			if _, err := repos.Category.Update(food); err != nil {
+				return err
+			}
+			return errRollback
+		})
+		if !errors.Is(err, errRollback) {
+			t.Fatalf("Transaction returned %v, want the error of fn", err)
+		}
+
+		_, err = r.Tag.FindById(id)
+		wantNotFound(t, err)
+		got, err := r.Category.FindById(food.ID)
+		must(t, err)
+		if got.Title != "Food" || got.Version != 1 {
+			t.Fatalf("rolled back category is %+v", got)
+		}
+	})
+
+	t.Run("Savepoint", func(t *testing.T) {
+		r := newRepos(t)
+		var kept, dropped uint
+		err := r.Tx.Transaction(func(repos entity.Repositories) error {
+			tag, err := entity.NewTag("kept", 1)
+			if err != nil {
+				return err
+			}
+			if err := repos.Tag.Insert(tag); err != nil {
+				return err
+			}
+			kept = tag.ID
+
+			err = repos.Tx.Transaction(func(inner entity.Repositories) error {
+				tag, err := entity.NewTag("dropped", 1)
+				if err != nil {
+					return err
+				}
+				if err := inner.Tag.Insert(tag); err != nil {
+					return err
+				}
+				dropped = tag.ID
+				return errRollback
+			})
+			if !errors.Is(err, errRollback) {
+				t.Errorf("nested Transaction returned %v", err)
+			}
+			return nil
+		})
+		must(t, err)
+
+		_, err = r.Tag.FindById(kept)
+		must(t, err)
+		_, err = r.Tag.FindById(dropped)
+		wantNotFound(t, err)
+	})
+
+	t.Run("Snapshot", func(t *testing.T) {
+		r := newRepos(t)
+		addTag(t, r, "coffee")
+
+		err := r.Tx.Snapshot(func(repos entity.Repositories) error {
+			before, err := repos.Tag.FindAllStatuses(0, 10)
+			if err != nil {
+				return err
+			}
+			// committed while the snapshot reads
+			addTag(t, r, "tea")
+			after, err := repos.Tag.FindAllStatuses(0, 10)
+			if err != nil {
+				return err
+			}
+			if len(before) != 1 || len(after) != 1 {
+				t.Errorf("snapshot saw %d then %d tags, want 1", len(before), len(after))
+			}
+			return nil
+		})
+		must(t, err)
+
+		tags, err := r.Tag.FindAllStatuses(0, 10)
+		must(t, err)
+		if len(tags) != 2 {
+			t.Fatalf("%d tags after the snapshot, want 2", len(tags))
+		}
+	})
+}
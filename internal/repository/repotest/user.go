@@ -0,0 +1,144 @@
+package repotest
+
+import (
+	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
+	"testing"
+)
+
+func addUser(t *testing.T, r Repos, name string, level int8) *entity.User {
+	t.Helper()
+	// a known hash keeps bcrypt out of the tests
+	u := &entity.User{UserName: name, Password: "hash-" + name, LevelManage: level, StatusID: 1}
+	must(t, r.User.Insert(u))
+	return u
+}
+
+func testUser(t *testing.T, newRepos Factory) {
+	t.Run("InsertAndFind", func(t *testing.T) {
+		r := newRepos(t)
+		u := addUser(t, r, "alice", 1)
+		if u.ID == 0 || u.CreatedAt.IsZero() {
+			t.Fatalf("Insert returned %+v", u)
+		}
+
+		got, err := r.User.FindById(u.ID)
+		must(t, err)
+		if got.UserName != "alice" || got.Password != "hash-alice" || got.LevelManage != 1 {
+			t.Fatalf("FindById returned %+v", got)
+		}
+
+		got, err = r.User.FindByUserName("alice")
+		must(t, err)
+		if got.ID != u.ID {
+			t.Fatalf("FindByUserName returned %+v", got)
+		}
+
+		_, err = r.User.FindByUserName("bob")
+		wantNotFound(t, err)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		r := newRepos(t)
+		u := addUser(t, r, "alice", 1)
+		must(t, r.User.Delete(u.ID))
+
+		_, err := r.User.FindById(u.ID)
+		wantNotFound(t, err)
+		_, err = r.User.FindByUserName("alice")
+		wantNotFound(t, err)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		r := newRepos(t)
+		u := addUser(t, r, "alice", 1)
+
+		u.UserName = "alice2"
+		u.LevelManage = 2
+		u.TOTPSecret = "JBSWY3DPEHPK3PXP"
+		u.TOTPEnabled = true
+		u.TOTPLastStep = 12345
+		_, err := r.User.Update(u)
+		must(t, err)
+
+		got, err := r.User.FindById(u.ID)
+		must(t, err)
+		if got.UserName != "alice2" || got.LevelManage != 2 || got.Password != "hash-alice" {
+			t.Fatalf("FindById after Update returned %+v", got)
+		}
+		if got.TOTPSecret != "JBSWY3DPEHPK3PXP" || !got.TOTPEnabled || got.TOTPLastStep != 12345 {
+			t.Fatalf("TOTP fields after Update: %+v", got)
+		}
+	})
+
+	t.Run("FindAll", func(t *testing.T) {
+		r := newRepos(t)
+		alice := addUser(t, r, "alice", 1)
+		bob := addUser(t, r, "bob", 2)
+		alina := addUser(t, r, "Alina", 2)
+		must(t, r.User.Delete(bob.ID))
+
+		// users are listed in any status
+		items, count, err := r.User.FindAll(dto.ListUsersInput{Limit: 10})
+		must(t, err)
+		if count != 3 {
+			t.Fatalf("count %d, want 3", count)
+		}
+		sameIDs(t, "all", ids(items, userID), alice.ID, bob.ID, alina.ID)
+		if items[1].StatusID != 0 {
+			t.Fatalf("deleted user has status %d", items[1].StatusID)
+		}
+		// the password is not loaded
+		if items[0].Password != "" {
+			t.Fatal("FindAll loaded the password")
+		}
+
+		items, _, err = r.User.FindAll(dto.ListUsersInput{UserName: "ALI", Limit: 10, Sort: "DESC"})
+		must(t, err)
+		sameIDs(t, "username", ids(items, userID), alina.ID, alice.ID)
+
+		items, _, err = r.User.FindAll(dto.ListUsersInput{LevelManage: 2, Limit: 1})
+		must(t, err)
+		sameIDs(t, "level", ids(items, userID), bob.ID)
+	})
+}
+
+func testUserToken(t *testing.T, newRepos Factory) {
+	r := newRepos(t)
+	u := addUser(t, r, "alice", 1)
+
+	token, err := entity.NewUserToken("token-1", u.ID)
+	must(t, err)
+	must(t, r.UserToken.Insert(token))
+	if token.ID == 0 {
+		t.Fatal("Insert did not set the id")
+	}
+
+	got, err := r.UserToken.FindByToken("token-1", []uint{1})
+	must(t, err)
+	if got.ID != token.ID || got.UserID != u.ID {
+		t.Fatalf("FindByToken returned %+v", got)
+	}
+
+	must(t, r.UserToken.Delete(token.ID))
+	_, err = r.UserToken.FindByToken("token-1", []uint{0, 1})
+	wantNotFound(t, err)
+
+	bob := addUser(t, r, "bob", 2)
+	for _, tok := range []struct {
+		token string
+		user  uint
+	}{{"a-1", u.ID}, {"a-2", u.ID}, {"b-1", bob.ID}} {
+		ut, err := entity.NewUserToken(tok.token, tok.user)
+		must(t, err)
+		must(t, r.UserToken.Insert(ut))
+	}
+	must(t, r.UserToken.DeleteByUser(u.ID))
+	for _, tok := range []string{"a-1", "a-2"} {
+		_, err = r.UserToken.FindByToken(tok, []uint{1})
+		wantNotFound(t, err)
+	}
+	if _, err := r.UserToken.FindByToken("b-1", []uint{1}); err != nil {
+		t.Fatalf("DeleteByUser removed another user's token: %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package repotest
+
+import (
+	"money-tracker/internal/entity"
+	"testing"
+	"time"
+)
+
+func addWebhook(t *testing.T, r Repos, target string, events ...string) *entity.Webhook {
+	t.Helper()
+	w, err := entity.NewWebhook(target, "whsec_test", events, "")
+	must(t, err)
+	must(t, r.Webhook.Insert(w))
+	if w.ID == 0 {
+		t.Fatal("Insert did not set the id")
+	}
+	return w
+}
+
+func webhookID(w entity.Webhook) uint { return w.ID }
+
+func testWebhook(t *testing.T, newRepos Factory) {
+	r := newRepos(t)
+	first := addWebhook(t, r, "https://example.com/a", "purchase.created")
+	second := addWebhook(t, r, "https://example.com/b", "purchase.created", "purchase.deleted")
+
+	got, err := r.Webhook.FindById(second.ID)
+	must(t, err)
+	if got.URL != "https://example.com/b" || got.Secret != "whsec_test" || !got.Wants("purchase.deleted") || !got.Active {
+		t.Fatalf("FindById returned %+v", got)
+	}
+	_, err = r.Webhook.FindById(999)
+	wantNotFound(t, err)
+
+	first.Active = false
+	first.Description = "paused"
+	first.UpdatedAt = time.Now()
+	must(t, r.Webhook.Update(first))
+	got, err = r.Webhook.FindById(first.ID)
+	must(t, err)
+	if got.Active || got.Description != "paused" {
+		t.Fatalf("Update did not save %+v", got)
+	}
+	wantNotFound(t, r.Webhook.Update(&entity.Webhook{ID: 999}))
+
+	all, err := r.Webhook.FindAll()
+	must(t, err)
+	sameIDs(t, "FindAll", ids(all, webhookID), first.ID, second.ID)
+	active, err := r.Webhook.FindActive()
+	must(t, err)
+	sameIDs(t, "FindActive", ids(active, webhookID), second.ID)
+
+	// deliveries, newest first
+	for attempt := 1; attempt <= 3; attempt++ {
+		must(t, r.Delivery.Insert(&entity.WebhookDelivery{
+			WebhookID: second.ID, MessageID: 1, EventID: "evt", EventType: "purchase.created", Attempt: attempt,
+		}))
+	}
+	must(t, r.Delivery.Insert(&entity.WebhookDelivery{WebhookID: first.ID, MessageID: 2, EventID: "evt", EventType: "purchase.created", Attempt: 1}))
+	deliveries, total, err := r.Delivery.FindByWebhook(second.ID, 0, 2)
+	must(t, err)
+	if total != 3 || len(deliveries) != 2 || deliveries[0].Attempt != 3 || deliveries[1].Attempt != 2 {
+		t.Fatalf("FindByWebhook returned %d %+v", total, deliveries)
+	}
+
+	// deleting a webhook takes its delivery log with it
+	must(t, r.Webhook.Delete(second.ID))
+	_, err = r.Webhook.FindById(second.ID)
+	wantNotFound(t, err)
+	wantNotFound(t, r.Webhook.Delete(second.ID))
+	_, total, err = r.Delivery.FindByWebhook(second.ID, 0, 10)
+	must(t, err)
+	if total != 0 {
+		t.Fatalf("%d deliveries left after Delete", total)
+	}
+	_, total, err = r.Delivery.FindByWebhook(first.ID, 0, 10)
+	must(t, err)
+	if total != 1 {
+		t.Fatalf("Delete removed the deliveries of another webhook")
+	}
+}
+
+func testOutbox(t *testing.T, newRepos Factory) {
+	r := newRepos(t)
+	w := addWebhook(t, r, "https://example.com/a", "purchase.created")
+	now := day(19)
+
+	add := func(due time.Time) *entity.OutboxMessage {
+		t.Helper()
+		msg := &entity.OutboxMessage{
+			WebhookID: w.ID, EventID: "evt", EventType: "purchase.created",
+			Payload: []byte(`{"a":1}`), NextAttemptAt: due,
+		}
+		must(t, r.Outbox.Insert(msg))
+		if msg.ID == 0 {
+			t.Fatal("Insert did not set the id")
+		}
+		return msg
+	}
+	later := add(now.Add(-time.Minute))
+	earlier := add(now.Add(-time.Hour))
+	add(now.Add(time.Hour))
+	third := add(now)
+
+	claimed, err := r.Outbox.Claim(now, time.Minute, 2)
+	must(t, err)
+	sameIDs(t, "Claim", ids(claimed, outboxID), earlier.ID, later.ID)
+	if string(claimed[0].Payload) != `{"a":1}` || !claimed[0].NextAttemptAt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("Claim returned %+v", claimed[0])
+	}
+
+	// claimed messages are leased, the rest are still due
+	claimed, err = r.Outbox.Claim(now, time.Minute, 10)
+	must(t, err)
+	sameIDs(t, "second Claim", ids(claimed, outboxID), third.ID)
+
+	// delivered and failed messages are never claimed again
+	delivered := now
+	earlier.Attempts = 1
+	earlier.DeliveredAt = &delivered
+	must(t, r.Outbox.Update(earlier))
+	later.Attempts = 5
+	later.FailedAt = &delivered
+	later.LastError = "HTTP 500"
+	must(t, r.Outbox.Update(later))
+	third.Attempts = 1
+	third.NextAttemptAt = now.Add(2 * time.Minute)
+	must(t, r.Outbox.Update(third))
+
+	claimed, err = r.Outbox.Claim(now.Add(2*time.Minute), time.Minute, 10)
+	must(t, err)
+	sameIDs(t, "Claim after Update", ids(claimed, outboxID), third.ID)
+	if claimed[0].Attempts != 1 {
+		t.Fatalf("Update did not save the attempts: %+v", claimed[0])
+	}
+}
+
+func outboxID(m entity.OutboxMessage) uint { return m.ID }
@@ -11,6 +11,7 @@ type Tag struct {
 	ID        uint   `gorm:"primaryKey"`
 	Title     string `gorm:"size:100"`
 	StatusID  uint   `gorm:"default:1;not null"`
+	Version   uint   `gorm:"default:1;not null"`
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	DeletedAt gorm.DeletedAt `gorm:"index"`
@@ -32,11 +33,27 @@ func (rep TagRepoGorm) Delete(id uint) error {
 	return rep.db.Model(&Tag{}).Where("id = ?", id).Update("status_id", 0).Error
 }
 
+// Update saves the tag if it is still at tag.Version and bumps the version,
+// otherwise it returns entity.ErrVersionConflict.
 func (rep *TagRepoGorm) Update(tag *entity.Tag) (*entity.Tag, error) {
 	tag.UpdatedAt = time.Now()
-	if err := rep.db.Save(tag).Error; err != nil {
-		return nil, err
+
+	result := rep.db.Model(&entity.Tag{}).
+		Where("id = ? AND version = ?", tag.ID, tag.Version).
+		Updates(map[string]interface{}{
+			"title":      tag.Title,
+			"status_id":  tag.StatusID,
+			"updated_at": tag.UpdatedAt,
+			"version":    tag.Version + 1,
+		})
+	if result.Error != nil {
+		return nil, result.Error
 	}
+	if result.RowsAffected == 0 {
+		return nil, versionConflictOrNotFound(rep.db, &entity.Tag{}, tag.ID)
+	}
+
+	tag.Version++
 	return tag, nil
 }
 
@@ -101,4 +118,9 @@ func (rep TagRepoGorm) FindByIDs(ids []uint) ([]entity.Tag, error) {
 	var tags []entity.Tag
 	err := rep.db.Where("id IN ?", ids).Find(&tags).Error
 	return tags, err
-}
\ No newline at end of file
+}
+func (rep TagRepoGorm) FindAllStatuses(start int, limit int) ([]entity.Tag, error) {
+	var tags []entity.Tag
+	err := rep.db.Order("id ASC").Offset(start).Limit(limit).Find(&tags).Error
+	return tags, err
+}
@@ -28,13 +28,22 @@ func (rep UserTokenRepoGormPostgres) Insert(user_token *entity.UserToken) error
 	return rep.db.Create(user_token).Error
 }
 
+// Delete removes the token for good: tokens are written through
+// entity.UserToken with a non-NULL deleted_at, which a soft delete never
+// matches.
 func (rep UserTokenRepoGormPostgres) Delete(id uint) error {
-	return rep.db.Where("id = ?", id).Delete(&UserToken{}).Error
+	return rep.db.Where("id = ?", id).Delete(&entity.UserToken{}).Error
 }
 
+func (rep UserTokenRepoGormPostgres) DeleteByUser(user_id uint) error {
+	return rep.db.Where("user_id = ?", user_id).Delete(&entity.UserToken{}).Error
+}
+
+// FindByToken ignores status_id: tokens have no status, a deleted token is
+// gone.
 func (rep UserTokenRepoGormPostgres) FindByToken(token string, status_id []uint) (*entity.UserToken, error) {
 	var t entity.UserToken
-	if err := rep.db.Where("token = ? AND status_id IN ?", token, status_id).First(&t).Error; err != nil {
+	if err := rep.db.Where("token = ?", token).First(&t).Error; err != nil {
 		return nil, err
 	}
 	return &t, nil
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"database/sql"
+	"money-tracker/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+type GormTransactor struct {
+	db *gorm.DB
+}
+
+func NewGormTransactor(db *gorm.DB) *GormTransactor {
+	return &GormTransactor{db: db}
+}
+
+func (t GormTransactor) Transaction(fn func(repos entity.Repositories) error) error {
+	return t.db.Transaction(func(tx *gorm.DB) error {
+		return fn(gormRepositories(tx))
+	})
+}
+
+// Snapshot runs fn in a read-only REPEATABLE READ transaction: every query
+// sees the rows committed before the first one.
+func (t GormTransactor) Snapshot(fn func(repos entity.Repositories) error) error {
+	return t.db.Transaction(func(tx *gorm.DB) error {
+		return fn(gormRepositories(tx))
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+}
+
+func gormRepositories(tx *gorm.DB) entity.Repositories {
+	return entity.Repositories{
+		Category: NewRepositoryGorm(tx),
+		Tag:      NewTagRepoGorm(tx),
+		Purchase: NewPurchaseRepo(tx),
+		User:     NewUserRepositoryGorm(tx),
+		Outbox:   NewOutboxRepo(tx),
+		Tx:       NewGormTransactor(tx),
+	}
+}
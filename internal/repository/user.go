@@ -9,17 +9,20 @@ import (
 )
 
 type User struct {
-	ID          uint   `gorm:"primaryKey"`
-	Name        string `gorm:"size:255"`
-	UserName    string `gorm:"size:255"`
-	Email       string `gorm:"size:255"`
-	Password    string `gorm:"size:255"`
-	Mobile      string `gorm:"size:255"`
-	LevelManage int8
-	StatusID    uint `gorm:"default:1;not null"`
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	DeletedAt   time.Time `gorm:"index"`
+	ID           uint   `gorm:"primaryKey"`
+	Name         string `gorm:"size:255"`
+	UserName     string `gorm:"size:255"`
+	Email        string `gorm:"size:255"`
+	Password     string `gorm:"size:255"`
+	Mobile       string `gorm:"size:255"`
+	LevelManage  int8
+	StatusID     uint   `gorm:"default:1;not null"`
+	TOTPSecret   string `gorm:"size:64"`
+	TOTPEnabled  bool   `gorm:"default:false;not null"`
+	TOTPLastStep int64  `gorm:"default:0;not null"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	DeletedAt    time.Time `gorm:"index"`
 }
 
 type UserRepoGormPostgres struct {
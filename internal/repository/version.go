@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"money-tracker/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+// versionConflictOrNotFound tells why a versioned update matched no row.
+func versionConflictOrNotFound(db *gorm.DB, model interface{}, id uint) error {
+	var count int64
+	if err := db.Model(model).Where("id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return entity.ErrVersionConflict
+}
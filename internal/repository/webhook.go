@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"money-tracker/internal/entity"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Webhook struct {
+	ID          uint   `gorm:"primaryKey"`
+	URL         string `gorm:"size:2048;not null"`
+	Secret      string `gorm:"size:100;not null"`
+	Events      string `gorm:"size:1000;not null"`
+	Description string `gorm:"size:255"`
+	Active      bool   `gorm:"default:true;not null"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type OutboxMessage struct {
+	ID            uint      `gorm:"primaryKey"`
+	WebhookID     uint      `gorm:"not null;index"`
+	EventID       string    `gorm:"size:64;not null"`
+	EventType     string    `gorm:"size:100;not null"`
+	Payload       []byte    `gorm:"not null"`
+	Attempts      int       `gorm:"default:0;not null"`
+	NextAttemptAt time.Time `gorm:"not null;index:idx_outbox_due,where:delivered_at IS NULL AND failed_at IS NULL"`
+	DeliveredAt   *time.Time
+	FailedAt      *time.Time
+	LastError     string `gorm:"size:1000"`
+	CreatedAt     time.Time
+}
+
+type WebhookDelivery struct {
+	ID         uint   `gorm:"primaryKey"`
+	WebhookID  uint   `gorm:"not null;index"`
+	MessageID  uint   `gorm:"not null"`
+	EventID    string `gorm:"size:64;not null"`
+	EventType  string `gorm:"size:100;not null"`
+	Attempt    int    `gorm:"not null"`
+	StatusCode int
+	Error      string `gorm:"size:1000"`
+	Response   string `gorm:"size:1024"`
+	DurationMs int64
+	CreatedAt  time.Time
+}
+
+// /------------------------------- webhooks -------------------------------
+
+type WebhookRepo struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepo(db *gorm.DB) *WebhookRepo {
+	return &WebhookRepo{db: db}
+}
+
+func (rep WebhookRepo) Insert(webhook *entity.Webhook) error {
+	return rep.db.Create(webhook).Error
+}
+
+// Update writes every column, so Active can be set to false.
+func (rep WebhookRepo) Update(webhook *entity.Webhook) error {
+	result := rep.db.Model(&entity.Webhook{}).Where("id = ?", webhook.ID).Updates(map[string]interface{}{
+		"url":         webhook.URL,
+		"events":      webhook.Events,
+		"description": webhook.Description,
+		"active":      webhook.Active,
+		"updated_at":  webhook.UpdatedAt,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Delete removes the webhook with its outbox messages and delivery log.
+func (rep WebhookRepo) Delete(id uint) error {
+	return rep.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ?", id).Delete(&entity.Webhook{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := tx.Where("webhook_id = ?", id).Delete(&entity.OutboxMessage{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("webhook_id = ?", id).Delete(&entity.WebhookDelivery{}).Error
+	})
+}
+
+func (rep WebhookRepo) FindById(id uint) (*entity.Webhook, error) {
+	var w entity.Webhook
+	if err := rep.db.Where("id = ?", id).First(&w).Error; err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+func (rep WebhookRepo) FindAll() ([]entity.Webhook, error) {
+	var webhooks []entity.Webhook
+	err := rep.db.Order("id").Find(&webhooks).Error
+	return webhooks, err
+}
+
+func (rep WebhookRepo) FindActive() ([]entity.Webhook, error) {
+	var webhooks []entity.Webhook
+	err := rep.db.Where("active = ?", true).Order("id").Find(&webhooks).Error
+	return webhooks, err
+}
+
+// /-------------------------------- outbox --------------------------------
+
+type OutboxRepo struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepo(db *gorm.DB) *OutboxRepo {
+	return &OutboxRepo{db: db}
+}
+
+func (rep OutboxRepo) Insert(msg *entity.OutboxMessage) error {
+	return rep.db.Create(msg).Error
+}
+
+// Claim locks the due rows with SKIP LOCKED, so workers of several servers
+// never claim the same message.
+func (rep OutboxRepo) Claim(now time.Time, lease time.Duration, limit int) ([]entity.OutboxMessage, error) {
+	var msgs []entity.OutboxMessage
+	err := rep.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("delivered_at IS NULL AND failed_at IS NULL AND next_attempt_at <= ?", now).
+			Order("next_attempt_at, id").
+			Limit(limit).
+			Find(&msgs).Error
+		if err != nil || len(msgs) == 0 {
+			return err
+		}
+
+		ids := make([]uint, len(msgs))
+		for i := range msgs {
+			ids[i] = msgs[i].ID
+			msgs[i].NextAttemptAt = now.Add(lease)
+		}
+		return tx.Model(&entity.OutboxMessage{}).Where("id IN ?", ids).Update("next_attempt_at", now.Add(lease)).Error
+	})
+	return msgs, err
+}
+
+func (rep OutboxRepo) Update(msg *entity.OutboxMessage) error {
+	return rep.db.Model(&entity.OutboxMessage{}).Where("id = ?", msg.ID).Updates(map[string]interface{}{
+		"attempts":        msg.Attempts,
+		"next_attempt_at": msg.NextAttemptAt,
+		"delivered_at":    msg.DeliveredAt,
+		"failed_at":       msg.FailedAt,
+		"last_error":      msg.LastError,
+	}).Error
+}
+
+// /------------------------------ deliveries ------------------------------
+
+type WebhookDeliveryRepo struct {
+	db *gorm.DB
+}
+
+func NewWebhookDeliveryRepo(db *gorm.DB) *WebhookDeliveryRepo {
+	return &WebhookDeliveryRepo{db: db}
+}
+
+func (rep WebhookDeliveryRepo) Insert(delivery *entity.WebhookDelivery) error {
+	return rep.db.Create(delivery).Error
+}
+
+func (rep WebhookDeliveryRepo) FindByWebhook(webhook_id uint, start int, limit int) ([]entity.WebhookDelivery, int, error) {
+	var count int64
+	query := rep.db.Model(&entity.WebhookDelivery{}).Where("webhook_id = ?", webhook_id)
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var deliveries []entity.WebhookDelivery
+	err := query.Order("id DESC").Offset(start).Limit(limit).Find(&deliveries).Error
+	return deliveries, int(count), err
+}
@@ -1,36 +1,59 @@
 package routes
 
 import (
-	"money-tracker/internal/middleware"
-
 	"github.com/gin-gonic/gin"
 )
 
-func AuthRoutes(base string, router *gin.Engine) {
-	h := buildHandlers()
-
+func AuthRoutes(base string, router *gin.Engine, h *Handlers) {
 	auth := router.Group(base + "/auth")
 	{
 		auth.POST("/login", h.User.LoginHandler)
+		auth.POST("/login/2fa", h.User.LoginTwoFactorHandler)
 		auth.POST("/signup", h.User.RegisterHandler)
 		auth.GET("/signup-admin", h.User.SignAdminHandler)
+		auth.POST("/password/forgot", h.Password.ForgotHandler)
+		auth.POST("/password/reset", h.Password.ResetHandler)
 	}
 }
 
-func AdminRoutes(base string, router *gin.Engine) {
-
-	h := buildHandlers()
+// AccountRoutes are the settings of the logged in user.
+func AccountRoutes(base string, router *gin.Engine, h *Handlers) {
+	account := router.Group(base)
+	account.Use(h.Auth, h.SessionOnly)
+	{
+		account.GET("/api-keys", h.APIKey.ListHandler)
+		account.POST("/api-keys", h.APIKey.CreateHandler)
+		account.DELETE("/api-keys/:id", h.APIKey.RevokeHandler)
+
+		account.POST("/password", h.Password.ChangeHandler)
+		account.POST("/2fa/enroll", h.User.EnrollTOTPHandler)
+		account.POST("/2fa/activate", h.User.ActivateTOTPHandler)
+		account.POST("/2fa/disable", h.User.DisableTOTPHandler)
+		account.POST("/2fa/recovery-codes", h.User.RegenerateRecoveryCodesHandler)
+	}
+}
 
+func AdminRoutes(base string, router *gin.Engine, h *Handlers) {
 	admin := router.Group(base)
-	admin.Use(
-		middleware.AuthMiddleware([]int8{1}))
+	admin.Use(h.Admin)
 	{
-		admin.POST("/category", h.Category.CreateCategoryHandler)
+		admin.POST("/category", h.Idempotency, h.Category.CreateCategoryHandler)
 		admin.GET("/category", h.Category.GetAllCategoryHandler)
 		admin.PUT("/category", h.Category.UpdateCategoryHandler)
 		admin.DELETE("/category/:id", h.Category.DeleteHandler)
 
 		admin.GET("/users", h.User.GetAllUsers)
+		admin.POST("/users/:id/unlock", h.User.UnlockUserHandler)
+
+		admin.GET("/backup", h.Backup.BackupHandler)
+		admin.POST("/restore", h.Backup.RestoreHandler)
+
+		admin.POST("/webhooks", h.Webhook.CreateHandler)
+		admin.GET("/webhooks", h.Webhook.ListHandler)
+		admin.PUT("/webhooks/:id", h.Webhook.UpdateHandler)
+		admin.DELETE("/webhooks/:id", h.Webhook.DeleteHandler)
+		admin.GET("/webhooks/:id/deliveries", h.Webhook.DeliveriesHandler)
+		admin.POST("/webhooks/:id/ping", h.Webhook.PingHandler)
 
 	}
 }
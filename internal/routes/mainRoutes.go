@@ -1,68 +1,94 @@
 package routes
 
 import (
-	"money-tracker/internal/config"
 	"money-tracker/internal/handler"
-	// "money-tracker/internal/middleware"
-	"money-tracker/internal/repository"
-	"money-tracker/internal/usecase"
 
 	"github.com/gin-gonic/gin"
 )
 
+// Handlers is built once by the app container and shared by all route
+// groups.
 type Handlers struct {
 	Category *handler.CategoryHandler
 	User     *handler.UserHandler
+	Password *handler.PasswordHandler
+	APIKey   *handler.APIKeyHandler
 	Tag      *handler.TagHandler
 	Purchase *handler.PurchaseHandler
-}
-
-func buildHandlers() *Handlers {
-	// repositories
-
-	repoCat := repository.NewRepositoryGorm(config.DB)
-	repoUser := repository.NewUserRepositoryGorm(config.DB)
-	repoToken := repository.NewUserTokenRepositoryGorm(config.DB)
-	repoTag := repository.NewTagRepoGorm(config.DB)
-	repoPurchase := repository.NewPurchaseRepo(config.DB)
-	// use cases
-
-	ucCategory := usecase.NewCategoryUseCase(repoCat)
-	ucUser := usecase.NewUserUseCase(repoUser, repoToken)
-	ucTag := usecase.NewTagUseCase(repoTag)
-	ucPurchase := usecase.NewPurchaseUseCase(repoPurchase, repoTag, repoCat)
-
-	// handlers
-	return &Handlers{
-		Category: handler.NewCategoryHandler(ucCategory),
-		User:     handler.NewUserHandler(ucUser),
-		Tag:      handler.NewTagHandler(ucTag),
-		Purchase: handler.NewPurchaseHandler(ucPurchase),
-	}
+	Backup   *handler.BackupHandler
+	Webhook  *handler.WebhookHandler
+	Events   *handler.EventsHandler
+	Goal     *handler.GoalHandler
+	Loan     *handler.LoanHandler
+	Forecast *handler.ForecastHandler
+	Anomaly  *handler.AnomalyHandler
 
+	Admin gin.HandlerFunc
+	// Auth lets in any logged in user
+	Auth gin.HandlerFunc
+	// SessionOnly, after Auth, keeps API keys out
+	SessionOnly gin.HandlerFunc
+	Idempotency gin.HandlerFunc
 }
 
-func MainRoutes(base string, router *gin.Engine) {
-	h := buildHandlers()
-
+func MainRoutes(base string, router *gin.Engine, h *Handlers) {
 	api := router.Group(base)
 	// api.Use(middleware.AuthMiddleware([]int8{2}))
 	{
-		api.POST("/tag", h.Tag.CreateTagHandler)
+		api.POST("/tag", h.Idempotency, h.Tag.CreateTagHandler)
 		api.GET("/tag", h.Tag.GetAllTagsHandler)
 		api.PUT("/tag", h.Tag.UpdateTagHandler)
 		api.DELETE("/tag/:id", h.Tag.DeleteHandler)
 
 		api.GET("/category", h.Category.GetAllPublicCategoryHandler)
-		api.POST("/category", h.Category.CreateCategoryHandler)
+		api.POST("/category", h.Idempotency, h.Category.CreateCategoryHandler)
 		api.PUT("/category", h.Category.UpdateCategoryHandler)
 		api.DELETE("/category/:id", h.Category.DeleteHandler)
 
 		api.GET("/purchase", h.Purchase.GetAllPurchaseHandler)
-		api.POST("/purchase", h.Purchase.CreatepurchaseHandler)
+		api.GET("/purchase/suggest", h.Auth, h.Purchase.SuggestHandler)
+		api.POST("/purchase/import", h.Auth, h.Purchase.ImportHandler)
+		api.GET("/purchase/export", h.Auth, h.Purchase.ExportHandler)
+		api.POST("/purchase", h.Idempotency, h.Purchase.CreatepurchaseHandler)
+		api.POST("/purchase/batch", h.Auth, h.Idempotency, h.Purchase.BatchCreateHandler)
+		api.POST("/purchase/bulk", h.Auth, h.Idempotency, h.Purchase.BulkHandler)
 		api.PUT("/purchase", h.Purchase.UpdatePurchaseHandler)
+		api.PATCH("/purchase/:id", h.Auth, h.Purchase.PatchPurchaseHandler)
 		api.DELETE("/purchase/:id", h.Purchase.DeleteHandler)
 
+		api.GET("/events", h.Auth, h.Events.StreamHandler)
+
+		api.POST("/goal", h.Auth, h.Goal.CreateHandler)
+		api.GET("/goal", h.Auth, h.Goal.ListHandler)
+		api.GET("/goal/:id", h.Auth, h.Goal.GetHandler)
+		api.PUT("/goal/:id", h.Auth, h.Goal.UpdateHandler)
+		api.DELETE("/goal/:id", h.Auth, h.Goal.DeleteHandler)
+		api.GET("/goal/:id/progress", h.Auth, h.Goal.ProgressHandler)
+		api.POST("/goal/:id/contribution", h.Auth, h.Goal.ContributeHandler)
+		api.GET("/goal/:id/contribution", h.Auth, h.Goal.ContributionsHandler)
+		api.DELETE("/goal/:id/contribution/:contribution_id", h.Auth, h.Goal.DeleteContributionHandler)
+
+		api.POST("/loan", h.Auth, h.Loan.CreateHandler)
+		api.GET("/loan", h.Auth, h.Loan.ListHandler)
+		api.GET("/loan/:id", h.Auth, h.Loan.GetHandler)
+		api.PUT("/loan/:id", h.Auth, h.Loan.UpdateHandler)
+		api.DELETE("/loan/:id", h.Auth, h.Loan.DeleteHandler)
+		api.GET("/loan/:id/schedule", h.Auth, h.Loan.ScheduleHandler)
+		api.GET("/loan/:id/status", h.Auth, h.Loan.StatusHandler)
+		api.POST("/loan/:id/payment", h.Auth, h.Loan.PayHandler)
+		api.GET("/loan/:id/payment", h.Auth, h.Loan.PaymentsHandler)
+		api.DELETE("/loan/:id/payment/:payment_id", h.Auth, h.Loan.DeletePaymentHandler)
+
+		api.POST("/recurring", h.Auth, h.Forecast.CreateItemHandler)
+		api.GET("/recurring", h.Auth, h.Forecast.ListItemsHandler)
+		api.PUT("/recurring/:id", h.Auth, h.Forecast.UpdateItemHandler)
+		api.DELETE("/recurring/:id", h.Auth, h.Forecast.DeleteItemHandler)
+		api.GET("/forecast", h.Auth, h.Forecast.ForecastHandler)
+
+		api.GET("/notification", h.Auth, h.Anomaly.ListHandler)
+		api.POST("/notification/:id/read", h.Auth, h.Anomaly.ReadHandler)
+		api.DELETE("/notification/:id", h.Auth, h.Anomaly.DeleteHandler)
+
 	}
 
 }
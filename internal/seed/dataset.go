@@ -0,0 +1,80 @@
+package seed
+
+import "time"
+
+// CategoryTree is a top level category and the titles of its sub categories,
+// which share its color.
+type CategoryTree struct {
+	Title    string
+	Color    string
+	Children []string
+}
+
+// Spending describes how often and how much is spent on one sub category.
+// The monthly rate swings around PerMonth over the year: it is highest in
+// Peak, by Swing (0 to 1), and lowest six months later.
+type Spending struct {
+	Category    string
+	SubCategory string
+	PerMonth    float64
+	Min, Max    int64
+	// Day of the month the purchase falls on; random when zero
+	Day     int
+	Peak    time.Month
+	Swing   float64
+	Reasons []string
+	Tags    []string
+}
+
+var Categories = []CategoryTree{
+	{Title: "Food", Color: "#e67e22", Children: []string{"Groceries", "Restaurants", "Coffee"}},
+	{Title: "Home", Color: "#3498db", Children: []string{"Rent", "Utilities", "Furniture"}},
+	{Title: "Transport", Color: "#2ecc71", Children: []string{"Fuel", "Public Transport", "Taxi"}},
+	{Title: "Health", Color: "#e74c3c", Children: []string{"Pharmacy", "Doctor"}},
+	{Title: "Leisure", Color: "#9b59b6", Children: []string{"Travel", "Entertainment", "Sport"}},
+	{Title: "Shopping", Color: "#f1c40f", Children: []string{"Clothes", "Electronics", "Gifts"}},
+}
+
+var Tags = []string{"essential", "recurring", "family", "work", "weekend", "online", "cash"}
+
+var Spendings = []Spending{
+	{Category: "Food", SubCategory: "Groceries", PerMonth: 10, Min: 15, Max: 120,
+		Reasons: []string{"Supermarket", "Bakery", "Greengrocer", "Butcher"}, Tags: []string{"essential", "family"}},
+	{Category: "Food", SubCategory: "Restaurants", PerMonth: 4, Min: 20, Max: 90, Peak: time.December, Swing: 0.3,
+		Reasons: []string{"Dinner out", "Lunch", "Pizza", "Sushi"}, Tags: []string{"weekend"}},
+	{Category: "Food", SubCategory: "Coffee", PerMonth: 8, Min: 3, Max: 8, Peak: time.January, Swing: 0.2,
+		Reasons: []string{"Coffee", "Espresso", "Cappuccino"}, Tags: []string{"work", "cash"}},
+
+	{Category: "Home", SubCategory: "Rent", PerMonth: 1, Min: 900, Max: 900, Day: 1,
+		Reasons: []string{"Monthly rent"}, Tags: []string{"essential", "recurring"}},
+	{Category: "Home", SubCategory: "Utilities", PerMonth: 2, Min: 40, Max: 160, Peak: time.January, Swing: 0.5,
+		Reasons: []string{"Electricity", "Gas", "Water", "Internet"}, Tags: []string{"essential", "recurring"}},
+	{Category: "Home", SubCategory: "Furniture", PerMonth: 0.2, Min: 80, Max: 600,
+		Reasons: []string{"Chair", "Lamp", "Shelf", "Rug"}, Tags: []string{"online"}},
+
+	{Category: "Transport", SubCategory: "Fuel", PerMonth: 3, Min: 30, Max: 70, Peak: time.July, Swing: 0.3,
+		Reasons: []string{"Gas station"}, Tags: []string{"essential"}},
+	{Category: "Transport", SubCategory: "Public Transport", PerMonth: 1, Min: 45, Max: 45, Day: 2,
+		Reasons: []string{"Monthly pass"}, Tags: []string{"work", "recurring"}},
+	{Category: "Transport", SubCategory: "Taxi", PerMonth: 2, Min: 8, Max: 30, Peak: time.December, Swing: 0.4,
+		Reasons: []string{"Taxi", "Ride to the airport", "Late ride home"}, Tags: []string{"weekend"}},
+
+	{Category: "Health", SubCategory: "Pharmacy", PerMonth: 1, Min: 5, Max: 40, Peak: time.February, Swing: 0.5,
+		Reasons: []string{"Pharmacy", "Vitamins", "Cold medicine"}, Tags: []string{"essential"}},
+	{Category: "Health", SubCategory: "Doctor", PerMonth: 0.3, Min: 40, Max: 150,
+		Reasons: []string{"Doctor visit", "Dentist"}, Tags: []string{"family"}},
+
+	{Category: "Leisure", SubCategory: "Travel", PerMonth: 0.3, Min: 200, Max: 1200, Peak: time.August, Swing: 0.9,
+		Reasons: []string{"Flights", "Hotel", "Train tickets"}, Tags: []string{"family", "online"}},
+	{Category: "Leisure", SubCategory: "Entertainment", PerMonth: 2, Min: 10, Max: 60,
+		Reasons: []string{"Cinema", "Concert", "Books", "Streaming"}, Tags: []string{"weekend"}},
+	{Category: "Leisure", SubCategory: "Sport", PerMonth: 1, Min: 30, Max: 30, Day: 5,
+		Reasons: []string{"Gym membership"}, Tags: []string{"recurring"}},
+
+	{Category: "Shopping", SubCategory: "Clothes", PerMonth: 1, Min: 25, Max: 150, Peak: time.November, Swing: 0.4,
+		Reasons: []string{"Shoes", "Jacket", "Shirt", "Jeans"}, Tags: []string{"online"}},
+	{Category: "Shopping", SubCategory: "Electronics", PerMonth: 0.2, Min: 50, Max: 900, Peak: time.November, Swing: 0.7,
+		Reasons: []string{"Headphones", "Phone case", "Laptop", "Charger"}, Tags: []string{"online"}},
+	{Category: "Shopping", SubCategory: "Gifts", PerMonth: 0.4, Min: 15, Max: 120, Peak: time.December, Swing: 0.9,
+		Reasons: []string{"Birthday present", "Flowers", "Holiday gifts"}, Tags: []string{"family"}},
+}
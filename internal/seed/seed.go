@@ -0,0 +1,306 @@
+// Package seed fills a database with a demo dataset: an admin, the category
+// trees and tags of dataset.go and, optionally, months of purchases with a
+// seasonal pattern.
+//
+// The purchases are random but depend on nothing but Options, so the same
+// options always give the same data, and seeding again only adds what is not
+// there yet. Tests can seed memory repositories with it as well as Postgres.
+package seed
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+
+	"money-tracker/internal/constants"
+	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
+	"money-tracker/internal/password"
+	"money-tracker/internal/usecase"
+	"money-tracker/internal/utils"
+)
+
+const lookupBatch = 500
+
+type Options struct {
+	// Seed picks the random purchases.
+	Seed int64
+	// Months of purchases to generate, the month of Until included. None
+	// when zero.
+	Months int
+	// Until is the day of the last purchases, today when zero.
+	Until time.Time
+	// AdminName is the admin to create unless it exists; no admin when
+	// empty.
+	AdminName     string
+	AdminPassword string
+}
+
+type Result struct {
+	Admin *entity.User
+	// AdminCreated is false when the admin was already there.
+	AdminCreated bool
+	// Categories and Tags are keyed by title and hold the existing rows
+	// that were reused as well as the new ones.
+	Categories map[string]*entity.Category
+	Tags       map[string]*entity.Tag
+	// Purchases were inserted, PurchasesSkipped were seeded before.
+	Purchases        int
+	PurchasesSkipped int
+}
+
+type Seeder struct {
+	Tx entity.Transactor
+	// Policy checks the password of a new admin; nil accepts any.
+	Policy *password.Policy
+}
+
+func New(tx entity.Transactor, policy *password.Policy) *Seeder {
+	return &Seeder{
+		Tx:     tx,
+		Policy: policy,
+	}
+}
+
+// Run seeds the admin, the categories, tags and purchases in one
+// transaction, so a refused admin password leaves nothing behind.
+func (s *Seeder) Run(opts Options) (*Result, error) {
+	if opts.Months < 0 {
+		return nil, fmt.Errorf("months must not be negative, got %d", opts.Months)
+	}
+	if opts.Until.IsZero() {
+		opts.Until = time.Now()
+	}
+
+	result := &Result{}
+	err := s.Tx.Transaction(func(repos entity.Repositories) error {
+		var err error
+		if opts.AdminName != "" {
+			if result.Admin, result.AdminCreated, err = s.admin(repos.User, opts.AdminName, opts.AdminPassword); err != nil {
+				return err
+			}
+		}
+		if result.Categories, err = categories(repos.Category); err != nil {
+			return err
+		}
+		if result.Tags, err = tags(repos.Tag); err != nil {
+			return err
+		}
+		purchases := Purchases(opts, result.Categories, result.Tags)
+		result.Purchases, result.PurchasesSkipped, err = insertPurchases(repos.Purchase, purchases)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// admin adds the admin through the user use case, which applies the
+// password policy, unless a user of that name exists.
+func (s *Seeder) admin(repo entity.UserRepository, name string, password string) (*entity.User, bool, error) {
+	existing, e_err := repo.FindByUserName(name)
+	if e_err == nil && existing != nil {
+		return existing, false, nil
+	}
+
+	users := usecase.NewUserUseCase(repo, nil, nil, s.Policy, nil, "", 0)
+	admin, err := users.Add(dto.AddUserInput{
+		UserName:    name,
+		Password:    password,
+		LevelManage: constants.LevelManageAdmin,
+		StatusID:    constants.StatusActive,
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("admin: %w", err)
+	}
+	return admin, true, nil
+}
+
+func categories(repo entity.CategoryRepository) (map[string]*entity.Category, error) {
+	byTitle := map[string]*entity.Category{}
+	add := func(title string, color string) error {
+		slug := utils.GenerateSlugUnicode(title)
+		existing, e_err := repo.FindBySlug(slug, []uint{constants.StatusActive})
+		if e_err == nil && existing != nil {
+			byTitle[title] = existing
+			return nil
+		}
+
+		category, err := entity.NewCategory(title, slug, constants.StatusActive, color)
+		if err != nil {
+			return err
+		}
+		if err := repo.Insert(category); err != nil {
+			return fmt.Errorf("category %q: %w", title, err)
+		}
+		byTitle[title] = category
+		return nil
+	}
+
+	for _, tree := range Categories {
+		if err := add(tree.Title, tree.Color); err != nil {
+			return nil, err
+		}
+		for _, child := range tree.Children {
+			if err := add(child, tree.Color); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return byTitle, nil
+}
+
+func tags(repo entity.TagRepository) (map[string]*entity.Tag, error) {
+	byTitle := map[string]*entity.Tag{}
+	for _, title := range Tags {
+		existing, e_err := repo.FindByTitle(title)
+		if e_err == nil && existing != nil {
+			byTitle[title] = existing
+			continue
+		}
+
+		tag, err := entity.NewTag(title, constants.StatusActive)
+		if err != nil {
+			return nil, err
+		}
+		if err := repo.Insert(tag); err != nil {
+			return nil, fmt.Errorf("tag %q: %w", title, err)
+		}
+		byTitle[title] = tag
+	}
+	return byTitle, nil
+}
+
+// insertPurchases skips the purchases whose external id is already taken,
+// i.e. that an earlier run seeded.
+func insertPurchases(repo entity.PurchaseRepository, purchases []*entity.Purchase) (int, int, error) {
+	seeded := map[string]bool{}
+	for start := 0; start < len(purchases); start += lookupBatch {
+		end := min(start+lookupBatch, len(purchases))
+		ids := make([]string, 0, end-start)
+		for _, p := range purchases[start:end] {
+			ids = append(ids, p.ExternalID)
+		}
+		existing, err := repo.FindByExternalIDs(ids)
+		if err != nil {
+			return 0, 0, err
+		}
+		for _, p := range existing {
+			seeded[p.ExternalID] = true
+		}
+	}
+
+	inserted := 0
+	for _, p := range purchases {
+		if seeded[p.ExternalID] {
+			continue
+		}
+		if err := repo.Insert(p); err != nil {
+			return 0, 0, fmt.Errorf("purchase %s: %w", p.ExternalID, err)
+		}
+		inserted++
+	}
+	return inserted, len(purchases) - inserted, nil
+}
+
+// Purchases generates the purchases of opts against the seeded categories
+// and tags, oldest month first. The external id of each purchase names the
+// seed, the month and its place in the month, so it is the same every time.
+func Purchases(opts Options, categories map[string]*entity.Category, tags map[string]*entity.Tag) []*entity.Purchase {
+	until := opts.Until
+	if until.IsZero() {
+		until = time.Now()
+	}
+
+	var purchases []*entity.Purchase
+	first := time.Date(until.Year(), until.Month(), 1, 0, 0, 0, 0, until.Location()).AddDate(0, 1-opts.Months, 0)
+	for m := 0; m < opts.Months; m++ {
+		month := first.AddDate(0, m, 0)
+		// each month has its own stream, so a month is the same whatever
+		// the number of months around it
+		rng := rand.New(rand.NewPCG(uint64(opts.Seed), uint64(month.Year()*12+int(month.Month()))))
+		lastDay := month.AddDate(0, 1, -1).Day()
+		// the month of until is drawn whole and cut, so that seeding again
+		// on a later day keeps the purchases drawn before
+		cutoff := lastDay
+		if m == opts.Months-1 {
+			cutoff = until.Day()
+		}
+
+		for row, s := range Spendings {
+			rate := s.PerMonth * (1 + s.Swing*math.Cos(2*math.Pi*float64(month.Month()-s.Peak)/12))
+			if s.Peak == 0 {
+				rate = s.PerMonth
+			}
+
+			n := poisson(rng, rate)
+			if s.Day != 0 {
+				// fixed costs are paid once a month
+				n = 1
+			}
+			for k := 0; k < n; k++ {
+				// draw everything first, so that skipping a day never
+				// shifts the purchases that follow
+				day := 1 + rng.IntN(lastDay)
+				hour, minute := 8+rng.IntN(14), rng.IntN(60)
+				amount := logUniform(rng, s.Min, s.Max)
+				reason := s.Reasons[rng.IntN(len(s.Reasons))]
+				var tagIDs []string
+				for _, title := range s.Tags {
+					if tag, ok := tags[title]; rng.Float64() < 0.8 && ok {
+						tagIDs = append(tagIDs, strconv.Itoa(int(tag.ID)))
+					}
+				}
+
+				if s.Day != 0 {
+					day = s.Day
+				}
+				if day > cutoff {
+					continue
+				}
+				parent, child := categories[s.Category], categories[s.SubCategory]
+				if parent == nil || child == nil {
+					continue
+				}
+
+				date := time.Date(month.Year(), month.Month(), day, hour, minute, 0, 0, month.Location())
+				p, err := entity.NewPurchase(amount, date, &parent.ID, constants.StatusActive)
+				if err != nil {
+					continue
+				}
+				p.SubCategoryId = &child.ID
+				p.Reason = reason
+				p.TagIDs = strings.Join(tagIDs, ",")
+				p.ExternalID = fmt.Sprintf("seed-%d-%s-%d-%d", opts.Seed, month.Format("200601"), row, k)
+				purchases = append(purchases, p)
+			}
+		}
+	}
+	return purchases
+}
+
+// poisson draws how many times something with the given mean happens.
+func poisson(rng *rand.Rand, mean float64) int {
+	limit, p, n := math.Exp(-mean), 1.0, 0
+	for {
+		p *= rng.Float64()
+		if p <= limit {
+			return n
+		}
+		n++
+	}
+}
+
+// logUniform draws an amount between lo and hi, small amounts being as
+// likely as large ones on a log scale, like real prices.
+func logUniform(rng *rand.Rand, lo int64, hi int64) int64 {
+	if hi <= lo {
+		return lo
+	}
+	f := float64(lo) * math.Exp(rng.Float64()*math.Log(float64(hi)/float64(lo)))
+	return int64(math.Round(f))
+}
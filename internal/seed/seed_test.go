@@ -0,0 +1,129 @@
+package seed_test
+
+import (
+	"fmt"
+	"money-tracker/internal/dto"
+	"money-tracker/internal/password"
+	"money-tracker/internal/repository/memory"
+	"money-tracker/internal/seed"
+	"testing"
+	"time"
+)
+
+var until = time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+
+func newSeeder() (*seed.Seeder, *memory.Store) {
+	store := memory.NewStore()
+	return seed.New(memory.NewTransactor(store), nil), store
+}
+
+// purchases describes every stored purchase, in id order.
+func purchases(t *testing.T, store *memory.Store) []string {
+	t.Helper()
+	items, _, err := memory.NewPurchaseRepo(store).FindAll(dto.PurchaseFindAll{Limit: 10000, OrderBy: "id", Sort: "ASC"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []string
+	for _, p := range items {
+		out = append(out, fmt.Sprintf("%s %d %s [%s] %s", p.Date.Format(time.DateTime), p.Amount, p.Reason, p.TagIDs, p.ExternalID))
+	}
+	return out
+}
+
+func TestSeedIsDeterministic(t *testing.T) {
+	opts := seed.Options{Seed: 42, Months: 12, Until: until, AdminName: "admin", AdminPassword: "admin-password"}
+
+	s1, store1 := newSeeder()
+	res, err := s1.Run(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.AdminCreated || res.Admin.LevelManage != 1 || !res.Admin.CheckPassword("admin-password") {
+		t.Fatalf("admin %+v", res.Admin)
+	}
+	categories := 0
+	for _, tree := range seed.Categories {
+		categories += 1 + len(tree.Children)
+	}
+	if len(res.Categories) != categories || len(res.Tags) != len(seed.Tags) {
+		t.Fatalf("%d categories, %d tags", len(res.Categories), len(res.Tags))
+	}
+	if res.Purchases < 300 {
+		t.Fatalf("only %d purchases in a year", res.Purchases)
+	}
+
+	s2, store2 := newSeeder()
+	if _, err := s2.Run(opts); err != nil {
+		t.Fatal(err)
+	}
+	first, second := purchases(t, store1), purchases(t, store2)
+	if len(first) != len(second) {
+		t.Fatalf("%d purchases, then %d", len(first), len(second))
+	}
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("purchase %d differs: %q and %q", i, first[i], second[i])
+		}
+	}
+
+	for _, p := range first {
+		if p[:10] > until.Format(time.DateOnly) {
+			t.Fatalf("purchase after until: %s", p)
+		}
+	}
+}
+
+func TestSeedAgainAddsNothing(t *testing.T) {
+	s, store := newSeeder()
+	opts := seed.Options{Seed: 7, Months: 3, Until: until, AdminName: "admin", AdminPassword: "admin-password"}
+	first, err := s.Run(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := s.Run(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.AdminCreated || again.Purchases != 0 || again.PurchasesSkipped != first.Purchases {
+		t.Fatalf("second run %+v after %d purchases", again, first.Purchases)
+	}
+	if again.Categories["Food"].ID != first.Categories["Food"].ID {
+		t.Fatal("the categories were not reused")
+	}
+
+	// a month more adds that month only
+	opts.Months = 4
+	more, err := s.Run(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if more.Purchases == 0 || more.PurchasesSkipped != first.Purchases {
+		t.Fatalf("with one month more: %+v", more)
+	}
+	if got := len(purchases(t, store)); got != first.Purchases+more.Purchases {
+		t.Fatalf("%d purchases stored", got)
+	}
+}
+
+func TestSeedAppliesThePasswordPolicy(t *testing.T) {
+	s, store := newSeeder()
+	policy, err := password.NewPolicy(8, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Policy = policy
+
+	_, err = s.Run(seed.Options{Seed: 42, Months: 1, Until: until, AdminName: "admin", AdminPassword: "admin"})
+	if err == nil {
+		t.Fatal("seeded an admin with a weak password")
+	}
+	// the categories, tags and purchases went with the admin
+	if got := purchases(t, store); len(got) != 0 {
+		t.Fatalf("%d purchases left behind", len(got))
+	}
+	if _, err := memory.NewUserRepo(store).FindByUserName("admin"); err == nil {
+		t.Fatal("the admin was created")
+	}
+}
@@ -0,0 +1,133 @@
+// Package testdb provides the Postgres database of the tests: the one named
+// by TEST_DATABASE_URL, or else an embedded Postgres started once per test
+// binary. The binaries of the embedded Postgres are downloaded on first use
+// and cached in ~/.embedded-postgres-go.
+//
+// The tests empty the tables they use. Never point TEST_DATABASE_URL at a
+// database with real data, and run packages one at a time against it
+// (go test -p 1), as they share it.
+package testdb
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"money-tracker/migrations"
+
+	embeddedpostgres "github.com/fergusstrange/embedded-postgres"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var (
+	startOnce sync.Once
+	dsn       string
+	// startErr is why no database is available; tests skip on it
+	startErr error
+
+	embedded    *embeddedpostgres.EmbeddedPostgres
+	embeddedDir string
+
+	migrateOnce sync.Once
+	migrateErr  error
+)
+
+// Main runs the tests of a package and stops the embedded Postgres after
+// them. Packages that use Open call it from TestMain.
+func Main(m *testing.M) {
+	code := m.Run()
+	if embedded != nil {
+		if err := embedded.Stop(); err != nil {
+			fmt.Fprintln(os.Stderr, "testdb: stopping postgres:", err)
+		}
+		os.RemoveAll(embeddedDir)
+	}
+	os.Exit(code)
+}
+
+// Open connects to the test database and migrates it. The test is skipped
+// when no database can be had: TEST_DATABASE_URL is not set and the
+// embedded Postgres does not start, e.g. offline.
+func Open(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	startOnce.Do(start)
+	if startErr != nil {
+		t.Skipf("no Postgres for the test: %v", startErr)
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("testdb: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	migrateOnce.Do(func() {
+		migrateErr = migrations.RunMigration(db).Migrate()
+	})
+	if migrateErr != nil {
+		t.Fatalf("testdb: migrating: %v", migrateErr)
+	}
+	return db
+}
+
+// Truncate empties every table and restarts the ids.
+func Truncate(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	err := db.Exec("TRUNCATE categories, tags, purchases, users, user_tokens, idempotency_keys, recovery_codes, password_reset_tokens, api_keys, webhooks, outbox_messages, webhook_deliveries, goals, goal_contributions, loans, loan_payments, recurring_items, notifications RESTART IDENTITY CASCADE").Error
+	if err != nil {
+		t.Fatalf("testdb: truncating: %v", err)
+	}
+}
+
+func start() {
+	if url := os.Getenv("TEST_DATABASE_URL"); url != "" {
+		dsn = url
+		return
+	}
+
+	port, err := freePort()
+	if err != nil {
+		startErr = err
+		return
+	}
+	embeddedDir, err = os.MkdirTemp("", "money-tracker-postgres-")
+	if err != nil {
+		startErr = err
+		return
+	}
+
+	pg := embeddedpostgres.NewDatabase(embeddedpostgres.DefaultConfig().
+		Port(port).
+		Database("money_test").
+		RuntimePath(embeddedDir).
+		StartTimeout(time.Minute).
+		Logger(io.Discard))
+	if err := pg.Start(); err != nil {
+		startErr = fmt.Errorf("TEST_DATABASE_URL is not set and the embedded postgres did not start: %w", err)
+		os.RemoveAll(embeddedDir)
+		return
+	}
+
+	embedded = pg
+	dsn = fmt.Sprintf("host=localhost port=%d user=postgres password=postgres dbname=money_test sslmode=disable", port)
+}
+
+func freePort() (uint32, error) {
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return uint32(l.Addr().(*net.TCPAddr).Port), nil
+}
@@ -0,0 +1,56 @@
+package totp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+// The SHA1 test vectors of RFC 6238, appendix B.
+func TestRFC6238(t *testing.T) {
+	key := []byte("12345678901234567890")
+	vectors := []struct {
+		unix int64
+		code string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+	for _, v := range vectors {
+		if got := hotp(key, Step(time.Unix(v.unix, 0)), 8); got != v.code {
+			t.Errorf("at %d: got %s, want %s", v.unix, got, v.code)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+	now := time.Unix(1111111111, 0)
+
+	current, err := Code(secret, Step(now))
+	if err != nil {
+		t.Fatal(err)
+	}
+	step, ok := Verify(secret, current, now)
+	if !ok || step != Step(now) {
+		t.Fatalf("current code: step %d, ok %v", step, ok)
+	}
+
+	// one period of drift either way is fine, two are not
+	previous, _ := Code(secret, Step(now)-1)
+	if _, ok := Verify(secret, previous, now); !ok {
+		t.Fatal("previous code refused")
+	}
+	old, _ := Code(secret, Step(now)-2)
+	if _, ok := Verify(secret, old, now); ok {
+		t.Fatal("code of two periods ago accepted")
+	}
+
+	if _, ok := Verify(secret, "12345", now); ok {
+		t.Fatal("short code accepted")
+	}
+}
@@ -0,0 +1,469 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"money-tracker/internal/constants"
+	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
+)
+
+// EventNotificationCreated is published on the change feed for every new
+// notification.
+const EventNotificationCreated = "notification.created"
+
+const (
+	anomalyBatch = 500
+	// anomalyQueue purchases wait for a check; when it is full the nightly
+	// scan checks the rest
+	anomalyQueue = 256
+	// minSpikeMonths of history are needed before a month can spike
+	minSpikeMonths = 3
+	// scanOverlap is how far back the nightly scan looks, a little more
+	// than a day so a late run misses nothing
+	scanOverlap = 25 * time.Hour
+)
+
+// AnomalyOptions configure an AnomalyUseCase; see config.AnomalyConfig.
+type AnomalyOptions struct {
+	Threshold     float64
+	HistoryMonths int
+	MinSamples    int
+	ScanAt        time.Duration
+}
+
+// AnomalyUseCase flags unusual spending and keeps the notifications about
+// it. A purchase is compared with the other purchases of its category:
+// the median and the median absolute deviation (MAD) stand in for the mean
+// and the standard deviation, so one earlier outlier does not hide the next.
+type AnomalyUseCase struct {
+	Purchases     entity.PurchaseRepository
+	CatRepo       entity.CategoryRepository
+	Notifications entity.NotificationRepository
+	Changes       ChangeFeed
+	Options       AnomalyOptions
+
+	queue chan entity.Purchase
+	now   func() time.Time
+}
+
+func NewAnomalyUseCase(purchases entity.PurchaseRepository, catRepo entity.CategoryRepository, notifications entity.NotificationRepository, changes ChangeFeed, opts AnomalyOptions) *AnomalyUseCase {
+	return &AnomalyUseCase{
+		Purchases:     purchases,
+		CatRepo:       catRepo,
+		Notifications: notifications,
+		Changes:       changes,
+		Options:       opts,
+		queue:         make(chan entity.Purchase, anomalyQueue),
+		now:           time.Now,
+	}
+}
+
+// /------------------------------ notifications ------------------------------
+
+// List returns the notifications newest first, only the unread ones when
+// unread is set.
+func (uc *AnomalyUseCase) List(unread bool, start int, limit int) ([]entity.Notification, int, error) {
+	if start < 0 {
+		start = 0
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 30
+	}
+	return uc.Notifications.FindAll(unread, start, limit)
+}
+
+func (uc *AnomalyUseCase) MarkRead(id uint) error {
+	if err := uc.Notifications.MarkRead(id, uc.now()); err != nil {
+		return ErrNoticeNotFound
+	}
+	return nil
+}
+
+func (uc *AnomalyUseCase) Remove(id uint) error {
+	if err := uc.Notifications.Delete(id); err != nil {
+		return ErrNoticeNotFound
+	}
+	return nil
+}
+
+// /-------------------------------- detection --------------------------------
+
+// Queue has the purchase checked by the worker, off the request path. It
+// never blocks; a purchase that does not fit is left to the nightly scan.
+func (uc *AnomalyUseCase) Queue(p entity.Purchase) {
+	select {
+	case uc.queue <- p:
+	default:
+	}
+}
+
+// Check compares the purchase with its category and stores a notification
+// for every anomaly found. A purchase is flagged at most once per kind, and
+// a category spike once per month, so checking again is harmless. It
+// returns the notifications that are new.
+func (uc *AnomalyUseCase) Check(p *entity.Purchase) ([]entity.Notification, error) {
+	if !checkable(p) {
+		return nil, nil
+	}
+	history, err := uc.history(*p.CategoryId, p.Date)
+	if err != nil {
+		return nil, err
+	}
+	return uc.check(p, history)
+}
+
+func checkable(p *entity.Purchase) bool {
+	return p.CategoryId != nil && p.Amount > 0 && p.StatusID == constants.StatusActive
+}
+
+// check compares the purchase with history, the purchases of its category
+// around its month.
+func (uc *AnomalyUseCase) check(p *entity.Purchase, history []entity.Purchase) ([]entity.Notification, error) {
+	category := uc.categoryTitle(*p.CategoryId)
+
+	var found []entity.Notification
+	if n := uc.largePurchase(p, category, history); n != nil {
+		found = append(found, *n)
+	}
+	if n := uc.categorySpike(p, category, history); n != nil {
+		found = append(found, *n)
+	}
+	n, err := uc.newMerchant(p, category, history)
+	if err != nil {
+		return nil, err
+	}
+	if n != nil {
+		found = append(found, *n)
+	}
+
+	created := []entity.Notification{}
+	for i := range found {
+		n := &found[i]
+		n.PurchaseID = &p.ID
+		n.CategoryID = p.CategoryId
+		n.CreatedAt = uc.now()
+		ok, err := uc.Notifications.Insert(n)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			created = append(created, *n)
+			notifyChange(uc.Changes, EventNotificationCreated, *n)
+		}
+	}
+	return created, nil
+}
+
+// historyKey is a category and a month; purchases with the same key are
+// compared with the same history.
+type historyKey struct {
+	category uint
+	month    time.Time
+}
+
+// Scan checks the active purchases created or changed since then and
+// returns how many notifications it stored. The history of a category and
+// month is loaded once for all its purchases.
+func (uc *AnomalyUseCase) Scan(ctx context.Context, since time.Time) (int, error) {
+	created := 0
+	histories := map[historyKey][]entity.Purchase{}
+	for start := 0; ctx.Err() == nil; start += anomalyBatch {
+		purchases, _, err := uc.Purchases.FindAll(dto.PurchaseFindAll{
+			UpdatedFrom: &since,
+			Start:       start,
+			Limit:       anomalyBatch,
+			OrderBy:     "id",
+			Sort:        "ASC",
+		})
+		if err != nil {
+			return created, err
+		}
+
+		for i := range purchases {
+			p := &purchases[i]
+			if !checkable(p) {
+				continue
+			}
+			key := historyKey{*p.CategoryId, monthOf(p.Date)}
+			history, ok := histories[key]
+			if !ok {
+				if history, err = uc.history(*p.CategoryId, p.Date); err != nil {
+					return created, err
+				}
+				histories[key] = history
+			}
+			found, err := uc.check(p, history)
+			if err != nil {
+				return created, err
+			}
+			created += len(found)
+		}
+
+		if len(purchases) < anomalyBatch {
+			break
+		}
+	}
+	return created, nil
+}
+
+// drain checks the queued purchases until the queue is empty.
+func (uc *AnomalyUseCase) drain() {
+	for {
+		select {
+		case p := <-uc.queue:
+			uc.checkQueued(p)
+		default:
+			return
+		}
+	}
+}
+
+func (uc *AnomalyUseCase) checkQueued(p entity.Purchase) {
+	if _, err := uc.Check(&p); err != nil {
+		log.Printf("anomalies: check of purchase %d failed: %v", p.ID, err)
+	}
+}
+
+// Run checks the queued purchases as they come, and scans the purchases of
+// the last day every night at ScanAt after midnight UTC, until ctx is
+// done. It runs as a background worker of the server; the scan catches the
+// purchases that did not come in through Add, e.g. imports and edits.
+func (uc *AnomalyUseCase) Run(ctx context.Context) {
+	for {
+		now := uc.now()
+		next := nextScan(now, uc.Options.ScanAt)
+		timer := time.NewTimer(next.Sub(now))
+	wait:
+		for {
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case p := <-uc.queue:
+				uc.checkQueued(p)
+			case <-timer.C:
+				break wait
+			}
+		}
+
+		if _, err := uc.Scan(ctx, next.Add(-scanOverlap)); err != nil {
+			log.Printf("anomalies: scan failed: %v", err)
+		}
+	}
+}
+
+// nextScan is the first time after now that is at after a midnight UTC.
+func nextScan(now time.Time, at time.Duration) time.Time {
+	y, m, d := now.UTC().Date()
+	next := time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Add(at)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// history returns the active purchases of the category from
+// HistoryMonths before the month of date to the end of that month.
+func (uc *AnomalyUseCase) history(categoryID uint, date time.Time) ([]entity.Purchase, error) {
+	month := monthOf(date)
+	from := month.AddDate(0, -uc.Options.HistoryMonths, 0)
+	to := month.AddDate(0, 1, 0)
+
+	var history []entity.Purchase
+	for start := 0; ; start += anomalyBatch {
+		purchases, _, err := uc.Purchases.FindAll(dto.PurchaseFindAll{
+			CategoryID: &categoryID,
+			DateFrom:   &from,
+			DateTo:     &to,
+			Start:      start,
+			Limit:      anomalyBatch,
+			OrderBy:    "id",
+			Sort:       "ASC",
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range purchases {
+			if p.Amount > 0 {
+				history = append(history, p)
+			}
+		}
+		if len(purchases) < anomalyBatch {
+			return history, nil
+		}
+	}
+}
+
+// largePurchase flags a purchase far above the typical amount of its
+// category.
+func (uc *AnomalyUseCase) largePurchase(p *entity.Purchase, category string, history []entity.Purchase) *entity.Notification {
+	amounts := otherAmounts(p, history)
+	if len(amounts) < uc.Options.MinSamples {
+		return nil
+	}
+	median, score := robustScore(float64(p.Amount), amounts)
+	if score <= uc.Options.Threshold {
+		return nil
+	}
+	return &entity.Notification{
+		Kind:     entity.AnomalyLargePurchase,
+		Key:      fmt.Sprintf("%s:%d", entity.AnomalyLargePurchase, p.ID),
+		Title:    "Unusually large purchase in " + category,
+		Body:     fmt.Sprintf("%s of %d is %.1f times the usual %d.", purchaseLabel(p), p.Amount, float64(p.Amount)/median, int64(math.Round(median))),
+		Amount:   p.Amount,
+		Baseline: int64(math.Round(median)),
+		Score:    round2(score),
+	}
+}
+
+// categorySpike flags the month of the purchase when the category's total
+// for it is far above its monthly totals before. The months count from the
+// first one with a purchase, months without any count as zero.
+func (uc *AnomalyUseCase) categorySpike(p *entity.Purchase, category string, history []entity.Purchase) *entity.Notification {
+	month := monthOf(p.Date)
+	totals := map[time.Time]int64{month: p.Amount}
+	first := month
+	for _, h := range history {
+		if h.ID == p.ID {
+			continue
+		}
+		m := monthOf(h.Date)
+		totals[m] += h.Amount
+		if m.Before(first) {
+			first = m
+		}
+	}
+
+	var before []float64
+	for m := first; m.Before(month); m = m.AddDate(0, 1, 0) {
+		before = append(before, float64(totals[m]))
+	}
+	if len(before) < minSpikeMonths {
+		return nil
+	}
+	median, score := robustScore(float64(totals[month]), before)
+	if score <= uc.Options.Threshold {
+		return nil
+	}
+	label := month.Format("2006-01")
+	return &entity.Notification{
+		Kind:     entity.AnomalyCategorySpike,
+		Key:      fmt.Sprintf("%s:%d:%s", entity.AnomalyCategorySpike, *p.CategoryId, label),
+		Title:    "Spending on " + category + " spiked in " + label,
+		Body:     fmt.Sprintf("%d spent so far in %s against a usual %d a month.", totals[month], label, int64(math.Round(median))),
+		Amount:   totals[month],
+		Baseline: int64(math.Round(median)),
+		Score:    round2(score),
+	}
+}
+
+// newMerchant flags a large charge from a merchant, by the reason of the
+// purchase, never seen before in any category. Large is at least the 90th
+// percentile of the category.
+func (uc *AnomalyUseCase) newMerchant(p *entity.Purchase, category string, history []entity.Purchase) (*entity.Notification, error) {
+	merchant := entity.MerchantKey(p.Reason)
+	if merchant == "" {
+		return nil, nil
+	}
+	amounts := otherAmounts(p, history)
+	if len(amounts) < uc.Options.MinSamples {
+		return nil, nil
+	}
+	sort.Float64s(amounts)
+	p90 := amounts[int(math.Ceil(0.9*float64(len(amounts))))-1]
+	if float64(p.Amount) < p90 {
+		return nil, nil
+	}
+
+	seen, err := uc.Purchases.HasMerchant(merchant, p.Date, p.ID)
+	if err != nil || seen {
+		return nil, err
+	}
+
+	return &entity.Notification{
+		Kind:     entity.AnomalyNewMerchant,
+		Key:      fmt.Sprintf("%s:%d", entity.AnomalyNewMerchant, p.ID),
+		Title:    "Large charge from a new merchant in " + category,
+		Body:     fmt.Sprintf("First purchase from %q, for %d.", strings.TrimSpace(p.Reason), p.Amount),
+		Amount:   p.Amount,
+		Baseline: int64(math.Round(p90)),
+	}, nil
+}
+
+func (uc *AnomalyUseCase) categoryTitle(id uint) string {
+	if cat, err := uc.CatRepo.FindById(id); err == nil && cat != nil {
+		return cat.Title
+	}
+	return fmt.Sprintf("category %d", id)
+}
+
+// robustScore is the median of samples and how far x is above it, in
+// robust standard deviations: MAD / 0.6745, or 1.2533 times the mean
+// absolute deviation when more than half the samples are equal. The
+// spread is never less than a tenth of the median, so a category of
+// nearly equal amounts does not flag every small change.
+func robustScore(x float64, samples []float64) (float64, float64) {
+	median := medianOf(samples)
+	deviations := make([]float64, len(samples))
+	var sum float64
+	for i, s := range samples {
+		deviations[i] = math.Abs(s - median)
+		sum += deviations[i]
+	}
+
+	spread := medianOf(deviations) / 0.6745
+	if spread == 0 {
+		spread = 1.2533 * sum / float64(len(samples))
+	}
+	spread = math.Max(spread, median/10)
+	if spread == 0 {
+		return median, 0
+	}
+	return median, (x - median) / spread
+}
+
+func medianOf(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n == 0 {
+		return 0
+	}
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+func otherAmounts(p *entity.Purchase, history []entity.Purchase) []float64 {
+	var amounts []float64
+	for _, h := range history {
+		if h.ID != p.ID {
+			amounts = append(amounts, float64(h.Amount))
+		}
+	}
+	return amounts
+}
+
+func purchaseLabel(p *entity.Purchase) string {
+	if reason := strings.TrimSpace(p.Reason); reason != "" {
+		return fmt.Sprintf("%q", reason)
+	}
+	return fmt.Sprintf("Purchase %d", p.ID)
+}
+
+func monthOf(t time.Time) time.Time {
+	y, m, _ := t.UTC().Date()
+	return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func round2(x float64) float64 {
+	return math.Round(x*100) / 100
+}
@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"money-tracker/internal/constants"
+	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
+	"money-tracker/internal/repository/memory"
+	"testing"
+	"time"
+)
+
+func TestAnomaliesOnAdd(t *testing.T) {
+	store := memory.NewStore()
+	feed := &recordedFeed{}
+	anomalies := NewAnomalyUseCase(memory.NewPurchaseRepo(store), memory.NewCategoryRepo(store), memory.NewNotificationRepo(store), feed, AnomalyOptions{Threshold: 3.5, HistoryMonths: 12, MinSamples: 8})
+	purchases := NewPurchaseUseCase(memory.NewPurchaseRepo(store), memory.NewTagRepo(store), memory.NewCategoryRepo(store), memory.NewTransactor(store), nil, nil, nil, anomalies)
+
+	food, err := entity.NewCategory("Groceries", "groceries", 1, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := purchases.CatRepo.Insert(food); err != nil {
+		t.Fatal(err)
+	}
+	add := func(reason string, amount int64, on time.Time) []entity.Notification {
+		t.Helper()
+		before, _, _ := anomalies.List(false, 0, 100)
+		if _, err := purchases.Add(dto.AddPurchaseInput{CategoryId: &food.ID, Reason: reason, Amount: amount, Date: on, StatusID: constants.StatusActive}); err != nil {
+			t.Fatal(err)
+		}
+		anomalies.drain()
+		after, _, err := anomalies.List(false, 0, 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return after[:len(after)-len(before)]
+	}
+	kinds := func(notifications []entity.Notification) map[string]entity.Notification {
+		byKind := map[string]entity.Notification{}
+		for _, n := range notifications {
+			byKind[n.Kind] = n
+		}
+		return byKind
+	}
+
+	// one purchase a month, January to September, around 5,000
+	for i, amount := range []int64{5_000, 5_200, 4_800, 5_100, 4_900, 5_300, 4_700, 5_000, 5_050} {
+		if got := add("Market", amount, date(2026, time.Month(i+1), 10)); len(got) != 0 {
+			t.Fatalf("usual purchase %d flagged: %+v", i, got)
+		}
+	}
+
+	// median 5,000; the MAD of 100 is below the floor of a tenth of the median
+	got := kinds(add("Market", 40_000, date(2026, 10, 19)))
+	if len(got) != 2 {
+		t.Fatalf("large purchase gave %+v", got)
+	}
+	large := got[entity.AnomalyLargePurchase]
+	if large.Baseline != 5_000 || large.Score != 70 || large.Title != "Unusually large purchase in Groceries" || large.ReadAt != nil {
+		t.Fatalf("large purchase = %+v", large)
+	}
+	spike := got[entity.AnomalyCategorySpike]
+	if spike.Key != "category_spike:1:2026-10" || spike.Amount != 40_000 || spike.Baseline != 5_000 {
+		t.Fatalf("category spike = %+v", spike)
+	}
+
+	// the month has spiked already; 6,000 is at the 90th percentile, from a
+	// merchant never seen before
+	got = kinds(add("  new shop ", 6_000, date(2026, 10, 19)))
+	if n, ok := got[entity.AnomalyNewMerchant]; len(got) != 1 || !ok || n.Baseline != 5_300 {
+		t.Fatalf("new merchant gave %+v", got)
+	}
+	if got := add("New Shop", 6_000, date(2026, 10, 19)); len(got) != 0 {
+		t.Fatalf("known merchant flagged: %+v", got)
+	}
+	if got := add("Corner Shop", 5_000, date(2026, 10, 19)); len(got) != 0 {
+		t.Fatalf("small charge of a new merchant flagged: %+v", got)
+	}
+
+	notified := 0
+	for _, e := range *feed {
+		if e == EventNotificationCreated {
+			notified++
+		}
+	}
+	if notified != 3 {
+		t.Fatalf("published %d notifications", notified)
+	}
+
+	// checking the same purchases again stores nothing new
+	anomalies.now = func() time.Time { return date(2026, 10, 20) }
+	created, err := anomalies.Scan(context.Background(), time.Time{})
+	if err != nil || created != 0 {
+		t.Fatalf("Scan = %d, %v", created, err)
+	}
+
+	if err := anomalies.MarkRead(large.ID); err != nil {
+		t.Fatal(err)
+	}
+	if unread, count, _ := anomalies.List(true, 0, 10); count != 2 || len(unread) != 2 {
+		t.Fatalf("unread = %d %+v", count, unread)
+	}
+	if err := anomalies.Remove(large.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := anomalies.MarkRead(large.ID); err != ErrNoticeNotFound {
+		t.Fatalf("MarkRead of a deleted notification = %v", err)
+	}
+}
+
+func TestAnomaliesNeedHistory(t *testing.T) {
+	store := memory.NewStore()
+	anomalies := NewAnomalyUseCase(memory.NewPurchaseRepo(store), memory.NewCategoryRepo(store), memory.NewNotificationRepo(store), nil, AnomalyOptions{Threshold: 3.5, HistoryMonths: 12, MinSamples: 8})
+
+	food := uint(1)
+	for i := 0; i < 3; i++ {
+		p, _ := entity.NewPurchase(1_000, date(2026, 10, 1+i), &food, constants.StatusActive)
+		if err := anomalies.Purchases.Insert(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p, _ := entity.NewPurchase(100_000, date(2026, 10, 19), &food, constants.StatusActive)
+	p.Reason = "Somewhere new"
+	if err := anomalies.Purchases.Insert(p); err != nil {
+		t.Fatal(err)
+	}
+	got, err := anomalies.Check(p)
+	if err != nil || len(got) != 0 {
+		t.Fatalf("Check with 3 samples and one month = %+v, %v", got, err)
+	}
+}
+
+func TestRobustScore(t *testing.T) {
+	cases := []struct {
+		x       float64
+		samples []float64
+		median  float64
+		score   float64
+	}{
+		// MAD 10, spread 14.83
+		{x: 200, samples: []float64{80, 90, 100, 110, 120}, median: 100, score: 6.745},
+		// MAD 0, 1.2533 times the mean deviation of 60
+		{x: 400, samples: []float64{100, 100, 100, 100, 400}, median: 100, score: 3.99},
+		// equal amounts fall back to a tenth of the median
+		{x: 150, samples: []float64{100, 100, 100}, median: 100, score: 5},
+		{x: 50, samples: []float64{100, 100, 100}, median: 100, score: -5},
+	}
+	for _, c := range cases {
+		median, score := robustScore(c.x, c.samples)
+		if median != c.median || math.Abs(score-c.score) > 0.01 {
+			t.Errorf("robustScore(%v, %v) = %v, %v; want %v, %v", c.x, c.samples, median, score, c.median, c.score)
+		}
+	}
+}
+
+func TestNextScan(t *testing.T) {
+	at := 3 * time.Hour
+	if got := nextScan(time.Date(2026, 10, 19, 1, 0, 0, 0, time.UTC), at); !got.Equal(time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC)) {
+		t.Errorf("before the scan time = %v", got)
+	}
+	if got := nextScan(time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC), at); !got.Equal(time.Date(2026, 10, 20, 3, 0, 0, 0, time.UTC)) {
+		t.Errorf("at the scan time = %v", got)
+	}
+}
@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"errors"
+	"money-tracker/internal/constants"
+	"money-tracker/internal/dto"
+	"money-tracker/internal/repository/memory"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAPIKeys(t *testing.T) {
+	uc := NewAPIKeyUseCase(memory.NewAPIKeyRepo(memory.NewStore()))
+	now := time.Now()
+	uc.now = func() time.Time { return now }
+
+	expires := now.Add(24 * time.Hour)
+	created, err := uc.Create(1, dto.CreateAPIKeyRequest{Name: " backups ", Scope: constants.ScopeWrite, ExpiresAt: &expires})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(created.Key, APIKeyPrefix) || !strings.HasPrefix(created.Key, created.Prefix) || created.Name != "backups" {
+		t.Fatalf("created %+v", created)
+	}
+	if _, err := uc.Create(1, dto.CreateAPIKeyRequest{Name: "past", ExpiresAt: &now}); !errors.Is(err, Validation("")) {
+		t.Fatalf("expiry in the past: %v", err)
+	}
+
+	key, err := uc.Authenticate(created.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.UserID != 1 || !key.CanWrite() || key.LastUsedAt == nil || !key.LastUsedAt.Equal(now) {
+		t.Fatalf("authenticated %+v", key)
+	}
+	// last use is only written once a minute
+	now = now.Add(30 * time.Second)
+	key, _ = uc.Authenticate(created.Key)
+	if key.LastUsedAt.Equal(now) {
+		t.Fatal("last use written twice within a minute")
+	}
+
+	for _, bad := range []string{"", "mt_made-up", created.Key[len(APIKeyPrefix):]} {
+		if _, err := uc.Authenticate(bad); !errors.Is(err, ErrInvalidAPIKey) {
+			t.Fatalf("key %q: %v", bad, err)
+		}
+	}
+	now = expires
+	if _, err := uc.Authenticate(created.Key); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Fatalf("expired key: %v", err)
+	}
+
+	// keys belong to their user
+	if err := uc.Revoke(2, created.ID); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Fatalf("revoke another user's key: %v", err)
+	}
+	if err := uc.Revoke(1, created.ID); err != nil {
+		t.Fatal(err)
+	}
+	if keys, _ := uc.List(1); len(keys) != 0 {
+		t.Fatalf("listed %+v after revoking", keys)
+	}
+}
+
+func TestAPIKeyLimit(t *testing.T) {
+	uc := NewAPIKeyUseCase(memory.NewAPIKeyRepo(memory.NewStore()))
+	for i := 0; i < maxAPIKeys; i++ {
+		created, err := uc.Create(1, dto.CreateAPIKeyRequest{Name: "script"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if created.Scope != constants.ScopeRead {
+			t.Fatalf("default scope %q", created.Scope)
+		}
+	}
+	if _, err := uc.Create(1, dto.CreateAPIKeyRequest{Name: "one more"}); !errors.Is(err, ErrTooManyAPIKeys) {
+		t.Fatalf("over the limit: %v", err)
+	}
+}
@@ -0,0 +1,298 @@
+package usecase
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"money-tracker/internal/backup"
+	"money-tracker/internal/constants"
+	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
+)
+
+const backupBatch = 500
+
+type BackupUseCase struct {
+	Repo      entity.PurchaseRepository
+	TagRepo   entity.TagRepository
+	CatRepo   entity.CategoryRepository
+	Tx        entity.Transactor
+	Suggester *SuggestionUseCase
+}
+
+func NewBackupUseCase(repo entity.PurchaseRepository, tag entity.TagRepository, cat entity.CategoryRepository, tx entity.Transactor, suggester *SuggestionUseCase) *BackupUseCase {
+	return &BackupUseCase{
+		Repo:      repo,
+		TagRepo:   tag,
+		CatRepo:   cat,
+		Tx:        tx,
+		Suggester: suggester,
+	}
+}
+
+// /----------------------------- backup -----------------------------
+// Backup writes the categories, tags and purchases to w as a zip archive,
+// deleted ones included.
+func (uc *BackupUseCase) Backup(w io.Writer) error {
+	bw := backup.NewWriter(w)
+
+	if err := bw.Begin(backup.CategoriesFile); err != nil {
+		return err
+	}
+	for start := 0; ; start += backupBatch {
+		categories, _, err := uc.CatRepo.FindAll(dto.CategoryFindAll{
+			Start:     start,
+			Limit:     backupBatch,
+			OrderBy:   "id",
+			Sort:      "ASC",
+			AnyStatus: true,
+		})
+		if err != nil {
+			return err
+		}
+		for _, c := range categories {
+			err := bw.Write(backup.Category{
+				ID:        c.ID,
+				Title:     c.Title,
+				Slug:      c.Slug,
+				Color:     c.Color,
+				StatusID:  c.StatusID,
+				CreatedAt: c.CreatedAt,
+				UpdatedAt: c.UpdatedAt,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if len(categories) < backupBatch {
+			break
+		}
+	}
+
+	if err := bw.Begin(backup.TagsFile); err != nil {
+		return err
+	}
+	for start := 0; ; start += backupBatch {
+		tags, err := uc.TagRepo.FindAllStatuses(start, backupBatch)
+		if err != nil {
+			return err
+		}
+		for _, t := range tags {
+			err := bw.Write(backup.Tag{
+				ID:        t.ID,
+				Title:     t.Title,
+				StatusID:  t.StatusID,
+				CreatedAt: t.CreatedAt,
+				UpdatedAt: t.UpdatedAt,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if len(tags) < backupBatch {
+			break
+		}
+	}
+
+	if err := bw.Begin(backup.PurchasesFile); err != nil {
+		return err
+	}
+	for start := 0; ; start += backupBatch {
+		purchases, _, err := uc.Repo.FindAll(dto.PurchaseFindAll{
+			Start:       start,
+			Limit:       backupBatch,
+			OrderBy:     "id",
+			Sort:        "ASC",
+			OtherFields: true,
+			AnyStatus:   true,
+		})
+		if err != nil {
+			return err
+		}
+		for _, p := range purchases {
+			err := bw.Write(backup.Purchase{
+				ID:            p.ID,
+				Date:          p.Date,
+				Amount:        p.Amount,
+				Reason:        p.Reason,
+				Note:          p.Note,
+				Color:         p.Color,
+				Method:        p.Method,
+				StatusID:      p.StatusID,
+				CategoryID:    p.CategoryId,
+				SubCategoryID: p.SubCategoryId,
+				TagIDs:        parseTagIDs(p.TagIDs),
+				Details:       p.Details,
+				ExternalID:    p.ExternalID,
+				CreatedAt:     p.CreatedAt,
+				UpdatedAt:     p.UpdatedAt,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if len(purchases) < backupBatch {
+			break
+		}
+	}
+
+	return bw.Close()
+}
+
+// /----------------------------- restore -----------------------------
+// Restore imports an archive written by Backup in a single transaction.
+// Records get new ids, so nothing tells a restored purchase from one that
+// is already there: the target must not have any purchases, deleted ones
+// included, or ErrRestoreNotEmpty is returned. Categories are matched to
+// existing ones by slug and tags by title. Deleted records are restored
+// deleted.
+func (uc *BackupUseCase) Restore(r io.ReaderAt, size int64) (*dto.RestoreResult, error) {
+	br, err := backup.NewReader(r, size)
+	if err != nil {
+		return nil, InvalidField("file", CodeInvalidFile, err.Error())
+	}
+
+	result := &dto.RestoreResult{}
+	err = uc.Tx.Transaction(func(repos entity.Repositories) error {
+		*result = dto.RestoreResult{}
+		categoryIDs := map[uint]uint{}
+		tagIDs := map[uint]uint{}
+
+		_, count, err := repos.Purchase.FindAll(dto.PurchaseFindAll{Limit: 1, OrderBy: "id", Sort: "ASC", AnyStatus: true})
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrRestoreNotEmpty
+		}
+
+		err = backup.Each(br, backup.CategoriesFile, func(c *backup.Category) error {
+			existing, e_err := repos.Category.FindBySlug(c.Slug, []uint{constants.StatusInactive, constants.StatusActive})
+			if e_err == nil && existing != nil {
+				categoryIDs[c.ID] = existing.ID
+				result.CategoriesReused++
+				return nil
+			}
+
+			category, err := entity.NewCategory(c.Title, c.Slug, c.StatusID, c.Color)
+			if err != nil {
+				return Validation("category " + strconv.Itoa(int(c.ID)) + ": " + err.Error()).Wrap(err)
+			}
+			category.CreatedAt = c.CreatedAt
+			if err := repos.Category.Insert(category); err != nil {
+				return err
+			}
+			// the status column defaults to active
+			if c.StatusID == constants.StatusInactive {
+				if err := repos.Category.Delete(category.ID); err != nil {
+					return err
+				}
+			}
+			categoryIDs[c.ID] = category.ID
+			result.Categories++
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		err = backup.Each(br, backup.TagsFile, func(t *backup.Tag) error {
+			existing, e_err := repos.Tag.FindByTitle(t.Title)
+			if e_err == nil && existing != nil {
+				tagIDs[t.ID] = existing.ID
+				result.TagsReused++
+				return nil
+			}
+
+			tag, err := entity.NewTag(t.Title, t.StatusID)
+			if err != nil {
+				return Validation("tag " + strconv.Itoa(int(t.ID)) + ": " + err.Error()).Wrap(err)
+			}
+			tag.CreatedAt = t.CreatedAt
+			if err := repos.Tag.Insert(tag); err != nil {
+				return err
+			}
+			if t.StatusID == constants.StatusInactive {
+				if err := repos.Tag.Delete(tag.ID); err != nil {
+					return err
+				}
+			}
+			tagIDs[t.ID] = tag.ID
+			result.Tags++
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		return backup.Each(br, backup.PurchasesFile, func(p *backup.Purchase) error {
+			categoryID, err := remapID(categoryIDs, p.CategoryID)
+			if err != nil {
+				return Validation("purchase " + strconv.Itoa(int(p.ID)) + ": category " + err.Error())
+			}
+			subCategoryID, err := remapID(categoryIDs, p.SubCategoryID)
+			if err != nil {
+				return Validation("purchase " + strconv.Itoa(int(p.ID)) + ": sub category " + err.Error())
+			}
+
+			var tags []string
+			for _, id := range p.TagIDs {
+				newID, ok := tagIDs[id]
+				if !ok {
+					return Validation("purchase " + strconv.Itoa(int(p.ID)) + ": tag " + strconv.Itoa(int(id)) + " not in archive")
+				}
+				tags = append(tags, strconv.Itoa(int(newID)))
+			}
+
+			purchase, err := entity.NewPurchase(p.Amount, p.Date, categoryID, p.StatusID)
+			if err != nil {
+				return Validation("purchase " + strconv.Itoa(int(p.ID)) + ": " + err.Error()).Wrap(err)
+			}
+			purchase.SubCategoryId = subCategoryID
+			purchase.TagIDs = strings.Join(tags, ",")
+			purchase.Reason = p.Reason
+			purchase.Note = p.Note
+			purchase.Color = p.Color
+			purchase.Method = p.Method
+			purchase.Details = p.Details
+			purchase.ExternalID = p.ExternalID
+			purchase.CreatedAt = p.CreatedAt
+
+			if err := repos.Purchase.Insert(purchase); err != nil {
+				return err
+			}
+			if p.StatusID == constants.StatusInactive {
+				if err := repos.Purchase.Delete(purchase.ID); err != nil {
+					return err
+				}
+			}
+			result.Purchases++
+			return nil
+		})
+	})
+	if errors.Is(err, backup.ErrInvalidRecord) {
+		return nil, InvalidField("file", CodeInvalidFile, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if uc.Suggester != nil && result.Purchases > 0 {
+		uc.Suggester.Reset()
+	}
+
+	return result, nil
+}
+
+func remapID(ids map[uint]uint, id *uint) (*uint, error) {
+	if id == nil || *id == 0 {
+		return nil, nil
+	}
+	newID, ok := ids[*id]
+	if !ok {
+		return nil, errors.New(strconv.Itoa(int(*id)) + " not in archive")
+	}
+	return &newID, nil
+}
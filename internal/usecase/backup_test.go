@@ -0,0 +1,82 @@
+package usecase
+
+import (
+	"bytes"
+	"money-tracker/internal/constants"
+	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
+	"money-tracker/internal/repository/memory"
+	"testing"
+)
+
+func newBackupUseCase() *BackupUseCase {
+	store := memory.NewStore()
+	return NewBackupUseCase(memory.NewPurchaseRepo(store), memory.NewTagRepo(store), memory.NewCategoryRepo(store), memory.NewTransactor(store), nil)
+}
+
+func TestBackupRestoresEveryStatus(t *testing.T) {
+	source := newBackupUseCase()
+	old, _ := entity.NewCategory("Old", "old", constants.StatusActive, "")
+	if err := source.CatRepo.Insert(old); err != nil {
+		t.Fatal(err)
+	}
+	tag, _ := entity.NewTag("archived", constants.StatusActive)
+	if err := source.TagRepo.Insert(tag); err != nil {
+		t.Fatal(err)
+	}
+	kept, _ := entity.NewPurchase(1_000, date(2026, 10, 1), &old.ID, constants.StatusActive)
+	kept.Reason = "Market"
+	deleted, _ := entity.NewPurchase(3_000, date(2026, 10, 3), &old.ID, constants.StatusActive)
+	for _, p := range []*entity.Purchase{kept, deleted} {
+		if err := source.Repo.Insert(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := source.Repo.Delete(deleted.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := source.CatRepo.Delete(old.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := source.TagRepo.Delete(tag.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	var archive bytes.Buffer
+	if err := source.Backup(&archive); err != nil {
+		t.Fatal(err)
+	}
+
+	target := newBackupUseCase()
+	result, err := target.Restore(bytes.NewReader(archive.Bytes()), int64(archive.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Categories != 1 || result.Tags != 1 || result.Purchases != 2 {
+		t.Fatalf("restored %+v", result)
+	}
+	restored, _, err := target.Repo.FindAll(dto.PurchaseFindAll{Limit: 10, OrderBy: "id", Sort: "ASC", AnyStatus: true})
+	if err != nil || len(restored) != 2 {
+		t.Fatalf("restored purchases %+v, %v", restored, err)
+	}
+	if restored[0].StatusID != constants.StatusActive || restored[0].Reason != "Market" {
+		t.Fatalf("active purchase = %+v", restored[0])
+	}
+	if gone, err := target.Repo.FindById(restored[1].ID, []uint{constants.StatusInactive}); err != nil || gone.DeletedAt.IsZero() {
+		t.Fatalf("deleted purchase = %+v, %v", gone, err)
+	}
+	if _, err := target.CatRepo.FindBySlug("old", []uint{constants.StatusInactive}); err != nil {
+		t.Fatalf("deleted category: %v", err)
+	}
+	if tags, _ := target.TagRepo.FindAllStatuses(0, 10); len(tags) != 1 || tags[0].StatusID != constants.StatusInactive {
+		t.Fatalf("tags = %+v", tags)
+	}
+
+	// restoring again would add the same purchases twice
+	if _, err := target.Restore(bytes.NewReader(archive.Bytes()), int64(archive.Len())); err != ErrRestoreNotEmpty {
+		t.Fatalf("second restore = %v", err)
+	}
+	if _, count, _ := target.Repo.FindAll(dto.PurchaseFindAll{Limit: 10, OrderBy: "id", Sort: "ASC", AnyStatus: true}); count != 2 {
+		t.Fatalf("%d purchases after the refused restore", count)
+	}
+}
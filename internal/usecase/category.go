@@ -1,7 +1,6 @@
 package usecase
 
 import (
-	"errors"
 	"fmt"
 	"money-tracker/internal/constants"
 	"money-tracker/internal/dto"
@@ -12,11 +11,12 @@ import (
 )
 
 type CategoryUseCase struct {
-	Repo entity.CategoryRepository
+	Repo    entity.CategoryRepository
+	Changes ChangeFeed
 }
 
-func NewCategoryUseCase(repo entity.CategoryRepository) *CategoryUseCase {
-	return &CategoryUseCase{Repo: repo}
+func NewCategoryUseCase(repo entity.CategoryRepository, changes ChangeFeed) *CategoryUseCase {
+	return &CategoryUseCase{Repo: repo, Changes: changes}
 }
 
 // /----------------------------------------------------
@@ -24,7 +24,7 @@ func (uc *CategoryUseCase) Add(input dto.AddCategoryInput) (*entity.Category, er
 	// check slug duplication
 	existing_category, e_err := uc.Repo.FindBySlug(input.Slug, []uint{constants.ArticleActive})
 	if e_err == nil && existing_category != nil {
-		return nil, errors.New("slug(title) duplicate")
+		return nil, ErrCategoryDuplicate
 	}
 
 	// default status
@@ -35,7 +35,7 @@ func (uc *CategoryUseCase) Add(input dto.AddCategoryInput) (*entity.Category, er
 	// create new category
 	category, err := entity.NewCategory(input.Title, input.Slug, input.StatusID, input.Color)
 	if err != nil {
-		return nil, err
+		return nil, invalid(err)
 	}
 	fmt.Printf("$$$$#-------------%v ", category)
 
@@ -66,6 +66,7 @@ func (uc *CategoryUseCase) Add(input dto.AddCategoryInput) (*entity.Category, er
 	if res != nil {
 		return nil, res
 	}
+	notifyChange(uc.Changes, EventCategoryCreated, *category)
 
 	return category, nil
 }
@@ -151,19 +152,31 @@ func (uc *CategoryUseCase) Get(input dto.ListCategoriesInput) ([]dto.CategoryRes
 
 // /-----------------------------------------------
 func (uc *CategoryUseCase) Remove(id uint) error {
-	_, err := uc.Repo.FindById(id)
+	category, err := uc.Repo.FindById(id)
 	if err != nil {
-		return errors.New("category not found")
+		return ErrCategoryNotFound
 	}
 
-	return uc.Repo.Delete(id)
+	if err := uc.Repo.Delete(id); err != nil {
+		return err
+	}
+	notifyChange(uc.Changes, EventCategoryDeleted, *category)
+	return nil
+}
+
+// ---------------------------------------------------
+func (uc *CategoryUseCase) GetByID(id uint) (*entity.Category, error) {
+	return uc.Repo.FindById(id)
 }
 
 // ---------------------------------------------------
 func (uc *CategoryUseCase) Update(input dto.UpdateCategoryInput) (*entity.Category, error) {
+	if input.Version == nil {
+		return nil, ErrVersionRequired
+	}
 	category, err := uc.Repo.FindById(input.ID)
 	if err != nil {
-		return nil, errors.New("category not found")
+		return nil, ErrCategoryNotFound
 	}
 
 	if input.Title != "" {
@@ -178,7 +191,7 @@ func (uc *CategoryUseCase) Update(input dto.UpdateCategoryInput) (*entity.Catego
 		existing_cat, e_err := uc.Repo.FindBySlug(input.Slug, []uint{constants.StatusActive})
 		if e_err == nil && existing_cat != nil {
 			if existing_cat.ID != input.ID {
-				return nil, errors.New("slug(title) duplicate")
+				return nil, ErrCategoryDuplicate
 			}
 		}
 		category.Slug = input.Slug
@@ -212,10 +225,18 @@ func (uc *CategoryUseCase) Update(input dto.UpdateCategoryInput) (*entity.Catego
 	// }
 
 	// category.TagIDs = input.TagIDs
+	if input.Version != nil {
+		category.Version = *input.Version
+	}
 	category.UpdatedAt = time.Now()
 
 	// Save changes
-	return uc.Repo.Update(category)
+	updated, err := uc.Repo.Update(category)
+	if err != nil {
+		return nil, err
+	}
+	notifyChange(uc.Changes, EventCategoryUpdated, *updated)
+	return updated, nil
 }
 
 //----------------------------------------
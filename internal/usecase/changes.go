@@ -0,0 +1,26 @@
+package usecase
+
+// Change events of categories and tags; the purchase ones are shared with
+// the webhooks.
+const (
+	EventCategoryCreated = "category.created"
+	EventCategoryUpdated = "category.updated"
+	EventCategoryDeleted = "category.deleted"
+	EventTagCreated      = "tag.created"
+	EventTagUpdated      = "tag.updated"
+	EventTagDeleted      = "tag.deleted"
+)
+
+// ChangeFeed is told about changes once they are committed, e.g. to push
+// them to open dashboards. Publish must not block.
+type ChangeFeed interface {
+	Publish(eventType string, data interface{})
+}
+
+// notifyChange publishes to feed if there is one. data should be a copy
+// the caller does not change afterwards.
+func notifyChange(feed ChangeFeed, eventType string, data interface{}) {
+	if feed != nil {
+		feed.Publish(eventType, data)
+	}
+}
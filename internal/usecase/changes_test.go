@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"money-tracker/internal/dto"
+	"money-tracker/internal/repository/memory"
+	"testing"
+	"time"
+)
+
+// recordedFeed keeps the types of the published events.
+type recordedFeed []string
+
+func (f *recordedFeed) Publish(eventType string, data interface{}) {
+	*f = append(*f, eventType)
+}
+
+func TestChangesArePublishedAfterCommit(t *testing.T) {
+	feed := &recordedFeed{}
+	store := memory.NewStore()
+	categories := NewCategoryUseCase(memory.NewCategoryRepo(store), feed)
+	tags := NewTagUseCase(memory.NewTagRepo(store), feed)
+	purchases := NewPurchaseUseCase(memory.NewPurchaseRepo(store), memory.NewTagRepo(store), memory.NewCategoryRepo(store), memory.NewTransactor(store), nil, nil, feed, nil)
+
+	food, err := categories.Add(dto.AddCategoryInput{Title: "Food", Slug: "food"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tags.Add("coffee", 1); err != nil {
+		t.Fatal(err)
+	}
+	p, err := purchases.Add(dto.AddPurchaseInput{CategoryId: &food.ID, Amount: 100, Date: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a failed or rolled back write publishes nothing
+	stale := uint(99)
+	if _, err := purchases.Update(dto.UpdatePurchaseInput{ID: p.ID, Amount: 1, Date: p.Date, Version: &stale}); err == nil {
+		t.Fatal("stale update succeeded")
+	}
+	missing := uint(999)
+	_, err = purchases.BatchCreate(dto.BatchCreatePurchaseInput{
+		AllOrNothing: true,
+		Items: []dto.AddPurchaseInput{
+			{CategoryId: &food.ID, Amount: 100, Date: time.Now()},
+			{CategoryId: &missing, Amount: 200, Date: time.Now()},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := purchases.Bulk(dto.BulkPurchaseInput{IDs: []uint{p.ID, missing}, Action: BulkDelete})
+	if err != nil || result.Succeeded != 1 {
+		t.Fatalf("Bulk: %+v, %v", result, err)
+	}
+
+	want := []string{EventCategoryCreated, EventTagCreated, EventPurchaseCreated, EventPurchaseDeleted}
+	if len(*feed) != len(want) {
+		t.Fatalf("published %v, want %v", *feed, want)
+	}
+	for i := range want {
+		if (*feed)[i] != want[i] {
+			t.Fatalf("published %v, want %v", *feed, want)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+
+	"money-tracker/internal/dto"
+)
+
+// ErrorKind tells the transport layer what went wrong, e.g. which HTTP
+// status to answer with.
+type ErrorKind string
+
+const (
+	KindValidation   ErrorKind = "validation"
+	KindNotFound     ErrorKind = "not_found"
+	KindConflict     ErrorKind = "conflict"
+	KindForbidden    ErrorKind = "forbidden"
+	KindUnauthorized ErrorKind = "unauthorized"
+	KindRateLimited  ErrorKind = "rate_limited"
+	KindPrecondition ErrorKind = "precondition_required"
+)
+
+// Error codes are part of the API: clients may switch on them, so they
+// must not change once released.
+const (
+	CodeValidation         = "validation_failed"
+	CodeInvalidRequest     = "invalid_request"
+	CodeInvalidFile        = "invalid_file"
+	CodeNotFound           = "not_found"
+	CodePurchaseNotFound   = "purchase_not_found"
+	CodeCategoryNotFound   = "category_not_found"
+	CodeTagNotFound        = "tag_not_found"
+	CodeUserNotFound       = "user_not_found"
+	CodeTokenNotFound      = "token_not_found"
+	CodeCategoryDuplicate  = "category_duplicate"
+	CodeTagDuplicate       = "tag_duplicate"
+	CodeUserDuplicate      = "user_duplicate"
+	CodeVersionConflict    = "version_conflict"
+	CodeVersionRequired    = "version_required"
+	CodeInvalidCredentials = "invalid_credentials"
+	CodeUnauthorized       = "unauthorized"
+	CodeForbidden          = "forbidden"
+	CodeTooManyAttempts    = "too_many_attempts"
+	CodeAccountLocked      = "account_locked"
+	CodeTwoFactorEnabled   = "two_factor_enabled"
+	CodeTwoFactorDisabled  = "two_factor_not_enabled"
+	CodeTwoFactorEnroll    = "two_factor_not_enrolled"
+	CodeInvalidTwoFactor   = "invalid_two_factor_code"
+	CodeInvalidChallenge   = "invalid_challenge"
+	CodeWeakPassword       = "weak_password"
+	CodeWrongPassword      = "wrong_password"
+	CodeInvalidResetToken  = "invalid_reset_token"
+	CodeInvalidAPIKey      = "invalid_api_key"
+	CodeAPIKeyNotFound     = "api_key_not_found"
+	CodeTooManyAPIKeys     = "too_many_api_keys"
+	CodeWebhookNotFound    = "webhook_not_found"
+	CodeGoalNotFound       = "goal_not_found"
+	CodeContribNotFound    = "contribution_not_found"
+	CodeLoanNotFound       = "loan_not_found"
+	CodePaymentNotFound    = "payment_not_found"
+	CodeLoanHasPayments    = "loan_has_payments"
+	CodePaymentNotLatest   = "payment_not_latest"
+	CodeRecurringNotFound  = "recurring_item_not_found"
+	CodeNoticeNotFound     = "notification_not_found"
+	CodeRestoreNotEmpty    = "restore_target_not_empty"
+	CodeIdempotencyBusy    = "idempotency_in_progress"
+	CodeIdempotencyReused  = "idempotency_key_reused"
+	CodeInternal           = "internal_error"
+)
+
+var (
+	ErrPurchaseNotFound   = NotFound(CodePurchaseNotFound, "purchase not found")
+	ErrCategoryNotFound   = NotFound(CodeCategoryNotFound, "category not found")
+	ErrTagNotFound        = NotFound(CodeTagNotFound, "tag not found")
+	ErrUserNotFound       = NotFound(CodeUserNotFound, "user not found")
+	ErrTokenNotFound      = NotFound(CodeTokenNotFound, "token not found")
+	ErrCategoryDuplicate  = Conflict(CodeCategoryDuplicate, "slug(title) duplicate")
+	ErrTagDuplicate       = Conflict(CodeTagDuplicate, "tag duplicate")
+	ErrUserDuplicate      = Conflict(CodeUserDuplicate, "user duplicate")
+	ErrVersionRequired    = PreconditionRequired(CodeVersionRequired, "send the version you read in the body or its ETag in If-Match")
+	ErrInvalidCredentials = Unauthorized(CodeInvalidCredentials, "invalid user name or password")
+
+	ErrTwoFactorEnabled     = Conflict(CodeTwoFactorEnabled, "two-factor authentication is already on")
+	ErrTwoFactorNotEnabled  = Conflict(CodeTwoFactorDisabled, "two-factor authentication is off")
+	ErrTwoFactorNotEnrolled = Conflict(CodeTwoFactorEnroll, "enroll in two-factor authentication first")
+	ErrInvalidTwoFactorCode = Unauthorized(CodeInvalidTwoFactor, "invalid two-factor code")
+	ErrInvalidChallenge     = Unauthorized(CodeInvalidChallenge, "the login challenge is invalid or expired")
+
+	ErrInvalidAPIKey     = Unauthorized(CodeInvalidAPIKey, "API key is invalid or expired")
+	ErrAPIKeyNotFound    = NotFound(CodeAPIKeyNotFound, "API key not found")
+	ErrTooManyAPIKeys    = Conflict(CodeTooManyAPIKeys, "too many API keys, revoke one first")
+	ErrWebhookNotFound   = NotFound(CodeWebhookNotFound, "webhook not found")
+	ErrGoalNotFound      = NotFound(CodeGoalNotFound, "goal not found")
+	ErrContribNotFound   = NotFound(CodeContribNotFound, "contribution not found")
+	ErrLoanNotFound      = NotFound(CodeLoanNotFound, "loan not found")
+	ErrPaymentNotFound   = NotFound(CodePaymentNotFound, "payment not found")
+	ErrLoanHasPayments   = Conflict(CodeLoanHasPayments, "the terms of a loan are fixed once payments are recorded")
+	ErrPaymentNotLatest  = Conflict(CodePaymentNotLatest, "only the latest payment can be deleted")
+	ErrRecurringNotFound = NotFound(CodeRecurringNotFound, "recurring item not found")
+	ErrNoticeNotFound    = NotFound(CodeNoticeNotFound, "notification not found")
+	ErrRestoreNotEmpty   = Conflict(CodeRestoreNotEmpty, "restore needs an instance without purchases, or they would be duplicated")
+	// a validation error of its own code, so that errors.Is does not take
+	// any other invalid field for it
+	ErrInvalidResetToken = &Error{Kind: KindValidation, Code: CodeInvalidResetToken, Message: "the reset token is invalid, used or expired",
+		Fields: []dto.FieldError{{Field: "token", Code: CodeInvalidResetToken, Message: "the reset token is invalid, used or expired"}}}
+)
+
+// Error is a domain error with a stable code. Errors that are not an
+// *Error are treated as internal by the transport layer.
+type Error struct {
+	Kind    ErrorKind
+	Code    string
+	Message string
+	Fields  []dto.FieldError
+	// Details is returned to the client with the error, e.g. the current
+	// state of a resource after a version conflict.
+	Details interface{}
+	// RetryAfter tells a rate limited client when to try again.
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches errors of the same kind and code, so errors.Is works with the
+// Err* values even after WithDetails or Wrap. Each Err* value therefore has
+// a code of its own.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Kind == e.Kind && t.Code == e.Code
+}
+
+// WithDetails returns a copy of e carrying details.
+func (e *Error) WithDetails(details interface{}) *Error {
+	out := *e
+	out.Details = details
+	return &out
+}
+
+// Wrap returns a copy of e with err as its cause.
+func (e *Error) Wrap(err error) *Error {
+	out := *e
+	out.Err = err
+	return &out
+}
+
+func NotFound(code string, message string) *Error {
+	return &Error{Kind: KindNotFound, Code: code, Message: message}
+}
+
+func Conflict(code string, message string) *Error {
+	return &Error{Kind: KindConflict, Code: code, Message: message}
+}
+
+func Forbidden(code string, message string) *Error {
+	return &Error{Kind: KindForbidden, Code: code, Message: message}
+}
+
+func Unauthorized(code string, message string) *Error {
+	return &Error{Kind: KindUnauthorized, Code: code, Message: message}
+}
+
+// RateLimited asks the client to wait retryAfter before trying again.
+func RateLimited(code string, message string, retryAfter time.Duration) *Error {
+	return &Error{Kind: KindRateLimited, Code: code, Message: message, RetryAfter: retryAfter}
+}
+
+// PreconditionRequired rejects an update that does not say which version
+// of the record it was based on.
+func PreconditionRequired(code string, message string) *Error {
+	return &Error{Kind: KindPrecondition, Code: code, Message: message}
+}
+
+func Validation(message string, fields ...dto.FieldError) *Error {
+	return &Error{Kind: KindValidation, Code: CodeValidation, Message: message, Fields: fields}
+}
+
+// InvalidField is a validation error for a single field.
+func InvalidField(field string, code string, message string) *Error {
+	return Validation(message, dto.FieldError{Field: field, Code: code, Message: message})
+}
+
+// invalid turns an error of an entity constructor into a validation error.
+func invalid(err error) error {
+	var e *Error
+	if err == nil || errors.As(err, &e) {
+		return err
+	}
+	return Validation(err.Error()).Wrap(err)
+}
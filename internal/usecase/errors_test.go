@@ -0,0 +1,43 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestErrorValuesAreDistinct(t *testing.T) {
+	sentinels := []*Error{
+		ErrPurchaseNotFound, ErrCategoryNotFound, ErrTagNotFound, ErrUserNotFound, ErrTokenNotFound,
+		ErrCategoryDuplicate, ErrTagDuplicate, ErrUserDuplicate, ErrVersionRequired, ErrInvalidCredentials,
+		ErrTwoFactorEnabled, ErrTwoFactorNotEnabled, ErrTwoFactorNotEnrolled, ErrInvalidTwoFactorCode, ErrInvalidChallenge,
+		ErrInvalidAPIKey, ErrAPIKeyNotFound, ErrTooManyAPIKeys, ErrWebhookNotFound, ErrGoalNotFound,
+		ErrContribNotFound, ErrLoanNotFound, ErrPaymentNotFound, ErrLoanHasPayments, ErrPaymentNotLatest,
+		ErrRecurringNotFound, ErrNoticeNotFound, ErrRestoreNotEmpty, ErrInvalidResetToken,
+	}
+	for i, a := range sentinels {
+		for j, b := range sentinels {
+			if i != j && errors.Is(a, b) {
+				t.Errorf("%q (%s) matches %q (%s)", a.Message, a.Code, b.Message, b.Code)
+			}
+		}
+	}
+
+	// nor do the errors built on the fly take one of them
+	for _, err := range []error{
+		InvalidField("token", "required", "token is required"),
+		Validation("bad input"),
+		Unauthorized(CodeUnauthorized, "Token is invalid or expired"),
+	} {
+		for _, s := range sentinels {
+			if errors.Is(err, s) {
+				t.Errorf("%v matches %q", err, s.Message)
+			}
+		}
+	}
+
+	// while a sentinel still matches after WithDetails and wrapping
+	if err := fmt.Errorf("reset: %w", ErrInvalidResetToken.WithDetails("x")); !errors.Is(err, ErrInvalidResetToken) {
+		t.Error("a wrapped ErrInvalidResetToken does not match")
+	}
+}
@@ -0,0 +1,208 @@
+package usecase
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
+	"money-tracker/internal/exporter"
+)
+
+const exportBatch = 500
+
+var exportColumns = []string{
+	"id", "date", "amount", "reason", "note", "color", "method", "status_id",
+	"category_id", "category", "sub_category_id", "sub_category", "tag_ids", "tags",
+	"created_at", "updated_at",
+}
+
+// Export writes every purchase matching the filters of input, a batch at a
+// time. The purchases are read twice: once to collect the keys of the
+// Details column, which become "details.<key>" columns, and once to write
+// the rows. Both reads run in one snapshot, so they see the same rows.
+func (uc *PurchaseUseCase) Export(input dto.PurchaseFindAll, w exporter.Writer) error {
+	input.OrderBy = "id"
+	if input.Sort != "DESC" {
+		input.Sort = "ASC"
+	}
+	input.OtherFields = true
+
+	if uc.Tx == nil {
+		return uc.export(entity.Repositories{Category: uc.CatRepo, Tag: uc.TagRepo, Purchase: uc.Repo}, input, w)
+	}
+	return uc.Tx.Snapshot(func(repos entity.Repositories) error {
+		return uc.export(repos, input, w)
+	})
+}
+
+func (uc *PurchaseUseCase) export(repos entity.Repositories, input dto.PurchaseFindAll, w exporter.Writer) error {
+	detailKeys := map[string]bool{}
+	err := eachPurchase(repos.Purchase, input, func(p *entity.Purchase) error {
+		for key := range flattenDetails(p.Details) {
+			detailKeys[key] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(detailKeys))
+	for key := range detailKeys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	header := append([]string{}, exportColumns...)
+	for _, key := range keys {
+		header = append(header, "details."+key)
+	}
+	if err := w.WriteHeader(header); err != nil {
+		return err
+	}
+
+	titles := newTitleCache(repos.Category, repos.Tag)
+	err = eachPurchase(repos.Purchase, input, func(p *entity.Purchase) error {
+		tagIDs := parseTagIDs(p.TagIDs)
+
+		row := []any{
+			p.ID, p.Date, p.Amount, p.Reason, p.Note, p.Color, p.Method, p.StatusID,
+			optionalID(p.CategoryId), titles.category(p.CategoryId),
+			optionalID(p.SubCategoryId), titles.category(p.SubCategoryId),
+			p.TagIDs, strings.Join(titles.tags(tagIDs), ","),
+			p.CreatedAt, p.UpdatedAt,
+		}
+
+		details := flattenDetails(p.Details)
+		for _, key := range keys {
+			row = append(row, details[key])
+		}
+		return w.WriteRow(row)
+	})
+	if err != nil {
+		return err
+	}
+
+	return w.Close()
+}
+
+// eachPurchase pages through the purchases by id, each page starting after
+// the last id of the one before.
+func eachPurchase(repo entity.PurchaseRepository, input dto.PurchaseFindAll, fn func(p *entity.Purchase) error) error {
+	input.Start = 0
+	input.Limit = exportBatch
+	input.AfterID = 0
+	for {
+		purchases, _, err := repo.FindAll(input)
+		if err != nil {
+			return err
+		}
+		for i := range purchases {
+			if err := fn(&purchases[i]); err != nil {
+				return err
+			}
+		}
+
+		if len(purchases) < exportBatch {
+			return nil
+		}
+		input.AfterID = purchases[len(purchases)-1].ID
+	}
+}
+
+// flattenDetails turns nested objects into dotted keys; arrays are kept as
+// JSON text.
+func flattenDetails(details map[string]interface{}) map[string]any {
+	flat := map[string]any{}
+
+	var walk func(prefix string, v interface{})
+	walk = func(prefix string, v interface{}) {
+		switch val := v.(type) {
+		case map[string]interface{}:
+			for k, child := range val {
+				walk(prefix+"."+k, child)
+			}
+		case []interface{}:
+			b, _ := json.Marshal(val)
+			flat[prefix] = string(b)
+		default:
+			flat[prefix] = val
+		}
+	}
+
+	for k, v := range details {
+		walk(k, v)
+	}
+	return flat
+}
+
+func optionalID(id *uint) any {
+	if id == nil {
+		return nil
+	}
+	return *id
+}
+
+// titleCache resolves category and tag titles, hitting the repositories
+// once per id.
+type titleCache struct {
+	catRepo    entity.CategoryRepository
+	tagRepo    entity.TagRepository
+	categories map[uint]string
+	tagTitles  map[uint]string
+}
+
+func newTitleCache(cat entity.CategoryRepository, tag entity.TagRepository) *titleCache {
+	return &titleCache{
+		catRepo:    cat,
+		tagRepo:    tag,
+		categories: map[uint]string{},
+		tagTitles:  map[uint]string{},
+	}
+}
+
+func (tc *titleCache) category(id *uint) string {
+	if id == nil || *id == 0 {
+		return ""
+	}
+	if title, ok := tc.categories[*id]; ok {
+		return title
+	}
+
+	title := ""
+	if category, err := tc.catRepo.FindById(*id); err == nil && category != nil {
+		title = category.Title
+	}
+	tc.categories[*id] = title
+	return title
+}
+
+func (tc *titleCache) tags(ids []uint) []string {
+	var missing []uint
+	for _, id := range ids {
+		if _, ok := tc.tagTitles[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+
+	if len(missing) > 0 {
+		for _, id := range missing {
+			tc.tagTitles[id] = ""
+		}
+		if tags, err := tc.tagRepo.FindByIDs(missing); err == nil {
+			for _, tag := range tags {
+				tc.tagTitles[tag.ID] = tag.Title
+			}
+		}
+	}
+
+	titles := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if title := tc.tagTitles[id]; title != "" {
+			titles = append(titles, title)
+		}
+	}
+	return titles
+}
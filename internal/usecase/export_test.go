@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"money-tracker/internal/constants"
+	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
+	"money-tracker/internal/exporter"
+	"strings"
+	"testing"
+	"time"
+)
+
+type xlsxCell struct {
+	Ref   string `xml:"r,attr"`
+	Type  string `xml:"t,attr"`
+	Value string `xml:"v"`
+	Text  string `xml:"is>t"`
+}
+
+// readSheet reads the cells of the single sheet of an exported workbook,
+// by row and column name.
+func readSheet(t *testing.T, workbook []byte) []map[string]xlsxCell {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(workbook), int64(len(workbook)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := zr.Open("xl/worksheets/sheet1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sheet struct {
+		Rows []struct {
+			Cells []xlsxCell `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := xml.Unmarshal(data, &sheet); err != nil {
+		t.Fatal(err)
+	}
+	rows := make([]map[string]xlsxCell, len(sheet.Rows))
+	for i, row := range sheet.Rows {
+		rows[i] = map[string]xlsxCell{}
+		for _, c := range row.Cells {
+			rows[i][strings.TrimRight(c.Ref, "0123456789")] = c
+		}
+	}
+	return rows
+}
+
+func TestExportXLSXRoundTrip(t *testing.T) {
+	uc, food := newTestPurchaseUseCase(t)
+	coffee, _ := entity.NewTag("coffee & cake", constants.StatusActive)
+	if err := uc.TagRepo.Insert(coffee); err != nil {
+		t.Fatal(err)
+	}
+
+	add := func(amount int64, on time.Time, reason string, details constants.JSONMap) *entity.Purchase {
+		t.Helper()
+		p, err := entity.NewPurchase(amount, on, &food.ID, constants.StatusActive)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.Reason = reason
+		p.TagIDs = fmt.Sprint(coffee.ID)
+		p.Details = details
+		if err := uc.Repo.Insert(p); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	add(100, date(2026, 9, 30), "before the range", nil)
+	big := add(1_234_567_890_123, date(2026, 10, 1), "<first> & last", nil)
+	latte := add(-450, date(2026, 10, 31), "refund", constants.JSONMap{"shop": map[string]interface{}{"city": "Berlin"}})
+	add(100, date(2026, 11, 1), "after the range", nil)
+
+	var out bytes.Buffer
+	from, to := date(2026, 10, 1), date(2026, 11, 1)
+	if err := uc.Export(dto.PurchaseFindAll{DateFrom: &from, DateTo: &to}, exporter.NewXLSXWriter(&out)); err != nil {
+		t.Fatal(err)
+	}
+
+	rows := readSheet(t, out.Bytes())
+	if len(rows) != 3 {
+		t.Fatalf("%d rows, want a header and the 2 purchases of October", len(rows))
+	}
+
+	header := rows[0]
+	for column, want := range map[string]string{"A": "id", "B": "date", "C": "amount", "D": "reason", "J": "category", "N": "tags", "Q": "details.shop.city"} {
+		if got := header[column]; got.Type != "inlineStr" || got.Text != want {
+			t.Errorf("header %s = %+v, want %q", column, got, want)
+		}
+	}
+	if len(header) != len(exportColumns)+1 {
+		t.Errorf("%d header cells", len(header))
+	}
+
+	first, second := rows[1], rows[2]
+	if first["A"].Value != fmt.Sprint(big.ID) || second["A"].Value != fmt.Sprint(latte.ID) {
+		t.Fatalf("ids %q, %q", first["A"].Value, second["A"].Value)
+	}
+	// amounts are numbers, whole and exact
+	if c := first["C"]; c.Type != "" || c.Value != "1234567890123" {
+		t.Errorf("amount cell %+v", c)
+	}
+	if c := second["C"]; c.Type != "" || c.Value != "-450" {
+		t.Errorf("negative amount cell %+v", c)
+	}
+	if c := first["B"]; c.Text != "2026-10-01T00:00:00Z" {
+		t.Errorf("date cell %+v", c)
+	}
+	if first["D"].Text != "<first> & last" || first["J"].Text != "Food" || first["N"].Text != "coffee & cake" {
+		t.Errorf("first row %+v", first)
+	}
+	if _, ok := first["Q"]; ok || second["Q"].Text != "Berlin" {
+		t.Errorf("details %+v, %+v", first["Q"], second["Q"])
+	}
+	// the sub category is empty: no cell at all
+	if _, ok := first["K"]; ok {
+		t.Errorf("sub_category_id cell %+v", first["K"])
+	}
+}
@@ -0,0 +1,296 @@
+package usecase
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
+)
+
+const (
+	defaultForecastDays = 90
+	defaultLookback     = 90
+	forecastBatch       = 500
+	oneDay              = 24 * time.Hour
+)
+
+// ForecastUseCase keeps the recurring items and projects balances from
+// them and the purchase history.
+type ForecastUseCase struct {
+	Repo    entity.RecurringItemRepository
+	CatRepo entity.CategoryRepository
+	// Purchases are read for the average spending per category
+	Purchases entity.PurchaseRepository
+
+	now func() time.Time
+}
+
+func NewForecastUseCase(repo entity.RecurringItemRepository, catRepo entity.CategoryRepository, purchases entity.PurchaseRepository) *ForecastUseCase {
+	return &ForecastUseCase{Repo: repo, CatRepo: catRepo, Purchases: purchases, now: time.Now}
+}
+
+// /----------------------------- recurring items -----------------------------
+
+func (uc *ForecastUseCase) CreateItem(req dto.RecurringItemRequest) (*entity.RecurringItem, error) {
+	item, err := entity.NewRecurringItem(req.Name, req.Amount, req.Frequency, req.StartDate, req.EndDate, req.Account)
+	if err != nil {
+		return nil, invalid(err)
+	}
+	if err := uc.setCategory(item, req.CategoryID); err != nil {
+		return nil, err
+	}
+	item.Note = strings.TrimSpace(req.Note)
+
+	if err := uc.Repo.Insert(item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (uc *ForecastUseCase) ListItems() ([]entity.RecurringItem, error) {
+	return uc.Repo.FindAll()
+}
+
+// UpdateItem replaces the item with req.
+func (uc *ForecastUseCase) UpdateItem(id uint, req dto.RecurringItemRequest) (*entity.RecurringItem, error) {
+	item, err := uc.Repo.FindById(id)
+	if err != nil || item == nil {
+		return nil, ErrRecurringNotFound
+	}
+	if err := item.Set(req.Name, req.Amount, req.Frequency, req.StartDate, req.EndDate, req.Account); err != nil {
+		return nil, invalid(err)
+	}
+	if err := uc.setCategory(item, req.CategoryID); err != nil {
+		return nil, err
+	}
+	item.Note = strings.TrimSpace(req.Note)
+
+	item.UpdatedAt = uc.now()
+	if err := uc.Repo.Update(item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (uc *ForecastUseCase) RemoveItem(id uint) error {
+	if err := uc.Repo.Delete(id); err != nil {
+		return ErrRecurringNotFound
+	}
+	return nil
+}
+
+func (uc *ForecastUseCase) setCategory(item *entity.RecurringItem, id *uint) error {
+	if id != nil && *id == 0 {
+		id = nil
+	}
+	if id != nil {
+		if _, err := uc.CatRepo.FindById(*id); err != nil {
+			return InvalidField("category_id", "not_found", "category not found")
+		}
+	}
+	item.CategoryID = id
+	return nil
+}
+
+// /-------------------------------- forecast --------------------------------
+
+// Forecast projects the balance of every account for the days after today.
+// Recurring items land on their dates; purchases are not scheduled, so the
+// spending account is charged the daily average of every category over the
+// lookback days instead, leaving out the categories a recurring item pays.
+func (uc *ForecastUseCase) Forecast(in dto.ForecastInput) (*dto.Forecast, error) {
+	if in.Days == 0 {
+		in.Days = defaultForecastDays
+	}
+	if in.Lookback == 0 {
+		in.Lookback = defaultLookback
+	}
+	y, m, d := uc.now().UTC().Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+
+	items, err := uc.Repo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	scheduled := map[uint]bool{}
+	for _, item := range items {
+		if item.CategoryID != nil {
+			scheduled[*item.CategoryID] = true
+		}
+	}
+
+	averages, err := uc.averages(today.AddDate(0, 0, 1-in.Lookback), today.AddDate(0, 0, 1), in.Lookback, scheduled)
+	if err != nil {
+		return nil, err
+	}
+	return forecast(today, in, items, averages), nil
+}
+
+// averages sums the active purchases in [from, to) per category.
+func (uc *ForecastUseCase) averages(from time.Time, to time.Time, days int, skip map[uint]bool) ([]dto.CategoryAverage, error) {
+	totals := map[uint]*dto.CategoryAverage{}
+	for start := 0; ; start += forecastBatch {
+		purchases, _, err := uc.Purchases.FindAll(dto.PurchaseFindAll{
+			DateFrom: &from,
+			DateTo:   &to,
+			Start:    start,
+			Limit:    forecastBatch,
+			OrderBy:  "id",
+			Sort:     "ASC",
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range purchases {
+			if p.CategoryId == nil {
+				continue
+			}
+			if skip[*p.CategoryId] || (p.SubCategoryId != nil && skip[*p.SubCategoryId]) {
+				continue
+			}
+			a, ok := totals[*p.CategoryId]
+			if !ok {
+				a = &dto.CategoryAverage{CategoryID: *p.CategoryId}
+				if p.Category != nil {
+					a.Category = p.Category.Slug
+				}
+				totals[*p.CategoryId] = a
+			}
+			a.Total += p.Amount
+		}
+
+		if len(purchases) < forecastBatch {
+			break
+		}
+	}
+
+	averages := []dto.CategoryAverage{}
+	for _, a := range totals {
+		a.Daily = math.Round(float64(a.Total)/float64(days)*100) / 100
+		averages = append(averages, *a)
+	}
+	sort.Slice(averages, func(i, j int) bool {
+		if averages[i].Total != averages[j].Total {
+			return averages[i].Total > averages[j].Total
+		}
+		return averages[i].CategoryID < averages[j].CategoryID
+	})
+	return averages, nil
+}
+
+// flows are the money coming in and going out of one account per forecast
+// day, indexed from 1.
+type flows struct {
+	income  []int64
+	expense []int64
+}
+
+func forecast(today time.Time, in dto.ForecastInput, items []entity.RecurringItem, averages []dto.CategoryAverage) *dto.Forecast {
+	spending := strings.TrimSpace(in.SpendingAccount)
+	if spending == "" {
+		spending = entity.DefaultAccount
+	}
+	f := &dto.Forecast{
+		From:            today.AddDate(0, 0, 1),
+		To:              today.AddDate(0, 0, in.Days),
+		Lookback:        in.Lookback,
+		SpendingAccount: spending,
+		Averages:        averages,
+		Accounts:        []dto.AccountForecast{},
+	}
+
+	opening := map[string]int64{}
+	for account, balance := range in.Balances {
+		account = strings.TrimSpace(account)
+		if account == "" {
+			account = entity.DefaultAccount
+		}
+		opening[account] += balance
+	}
+
+	accounts := map[string]*flows{}
+	account := func(name string) *flows {
+		if accounts[name] == nil {
+			accounts[name] = &flows{income: make([]int64, in.Days+1), expense: make([]int64, in.Days+1)}
+		}
+		return accounts[name]
+	}
+	for name := range opening {
+		account(name)
+	}
+
+	// items are scheduled by the day, whatever the time of their start
+	end := f.To.Add(oneDay - time.Nanosecond)
+	for _, item := range items {
+		a := account(item.Account)
+		item.Occurrences(f.From, end, func(date time.Time) {
+			y, m, d := date.UTC().Date()
+			i := int(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Sub(today) / oneDay)
+			if i < 1 || i > in.Days {
+				return
+			}
+			if item.Amount > 0 {
+				a.income[i] += item.Amount
+			} else {
+				a.expense[i] -= item.Amount
+			}
+		})
+	}
+
+	// spread the averages over the days without losing the rounding
+	var daily float64
+	for _, avg := range averages {
+		daily += float64(avg.Total) / float64(in.Lookback)
+	}
+	a := account(spending)
+	for i := 1; i <= in.Days; i++ {
+		a.expense[i] += int64(math.Round(daily*float64(i))) - int64(math.Round(daily*float64(i-1)))
+	}
+
+	names := make([]string, 0, len(accounts))
+	for name := range accounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f.Accounts = append(f.Accounts, accountForecast(name, opening[name], f.From, accounts[name]))
+	}
+	return f
+}
+
+func accountForecast(name string, opening int64, from time.Time, flows *flows) dto.AccountForecast {
+	af := dto.AccountForecast{
+		Account:      name,
+		Opening:      opening,
+		NegativeFrom: []time.Time{},
+	}
+
+	balance := opening
+	for i := 1; i < len(flows.income); i++ {
+		before := balance
+		balance += flows.income[i] - flows.expense[i]
+		fd := dto.ForecastDay{
+			Date:    from.AddDate(0, 0, i-1),
+			Income:  flows.income[i],
+			Expense: flows.expense[i],
+			Balance: balance,
+		}
+		af.Days = append(af.Days, fd)
+
+		if i == 1 || balance < af.Low.Balance {
+			af.Low = fd
+		}
+		if balance < 0 {
+			af.NegativeDays++
+			if before >= 0 {
+				af.NegativeFrom = append(af.NegativeFrom, fd.Date)
+			}
+		}
+	}
+	af.Closing = balance
+	return af
+}
@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"money-tracker/internal/constants"
+	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
+	"money-tracker/internal/repository/memory"
+	"testing"
+	"time"
+)
+
+func TestForecast(t *testing.T) {
+	store := memory.NewStore()
+	uc := NewForecastUseCase(memory.NewRecurringItemRepo(store), memory.NewCategoryRepo(store), memory.NewPurchaseRepo(store))
+	now := time.Date(2026, 10, 19, 15, 0, 0, 0, time.UTC)
+	uc.now = func() time.Time { return now }
+
+	category := func(title string) *entity.Category {
+		c, err := entity.NewCategory(title, title, 1, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := uc.CatRepo.Insert(c); err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	food, rent := category("food"), category("rent")
+	buy := func(c *entity.Category, amount int64, daysAgo int) {
+		p, err := entity.NewPurchase(amount, now.AddDate(0, 0, -daysAgo), &c.ID, constants.StatusActive)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := uc.Purchases.Insert(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 9,000 on food in the last 90 days, 100 a day
+	buy(food, 3_000, 1)
+	buy(food, 3_000, 30)
+	buy(food, 3_000, 60)
+	buy(food, 50_000, 100)
+	// rent is a recurring item, its purchases are not averaged
+	buy(rent, 120_000, 20)
+	buy(rent, 120_000, 50)
+
+	item := func(req dto.RecurringItemRequest) {
+		if _, err := uc.CreateItem(req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	item(dto.RecurringItemRequest{Name: "Salary", Amount: 300_000, Frequency: entity.FrequencyMonthly, StartDate: date(2026, 11, 1)})
+	item(dto.RecurringItemRequest{Name: "Rent", Amount: -120_000, Frequency: entity.FrequencyMonthly, StartDate: date(2026, 10, 25), CategoryID: &rent.ID})
+	last := date(2026, 11, 30)
+	item(dto.RecurringItemRequest{Name: "Saving", Amount: 10_000, Frequency: entity.FrequencyMonthly, StartDate: date(2026, 10, 20), EndDate: &last, Account: "savings"})
+
+	f, err := uc.Forecast(dto.ForecastInput{Days: 30, Balances: map[string]int64{"main": 50_000}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.From.Equal(date(2026, 10, 20)) || !f.To.Equal(date(2026, 11, 18)) || f.Lookback != 90 {
+		t.Fatalf("forecast %v to %v over %d days", f.From, f.To, f.Lookback)
+	}
+	if len(f.Averages) != 1 || f.Averages[0].Category != "food" || f.Averages[0].Total != 9_000 || f.Averages[0].Daily != 100 {
+		t.Fatalf("averages %+v", f.Averages)
+	}
+	if len(f.Accounts) != 2 || f.Accounts[0].Account != "main" || f.Accounts[1].Account != "savings" {
+		t.Fatalf("accounts %+v", f.Accounts)
+	}
+
+	main := f.Accounts[0]
+	if len(main.Days) != 30 || main.Days[0].Balance != 49_900 || main.Closing != 227_000 {
+		t.Fatalf("main: first day %+v, closing %d", main.Days[0], main.Closing)
+	}
+	if !main.Low.Date.Equal(date(2026, 10, 31)) || main.Low.Balance != -71_200 {
+		t.Fatalf("main low %+v", main.Low)
+	}
+	if len(main.NegativeFrom) != 1 || !main.NegativeFrom[0].Equal(date(2026, 10, 25)) || main.NegativeDays != 7 {
+		t.Fatalf("main negative from %v for %d days", main.NegativeFrom, main.NegativeDays)
+	}
+
+	savings := f.Accounts[1]
+	if savings.Opening != 0 || savings.Closing != 10_000 || savings.NegativeDays != 0 || savings.Days[0].Income != 10_000 {
+		t.Fatalf("savings %+v", savings)
+	}
+}
+
+func TestRecurringItems(t *testing.T) {
+	store := memory.NewStore()
+	uc := NewForecastUseCase(memory.NewRecurringItemRepo(store), memory.NewCategoryRepo(store), memory.NewPurchaseRepo(store))
+
+	missing := uint(42)
+	_, err := uc.CreateItem(dto.RecurringItemRequest{Name: "Gym", Amount: -3_000, Frequency: entity.FrequencyMonthly, StartDate: date(2026, 1, 1), CategoryID: &missing})
+	if !hasFieldCode(err, "category_id", "not_found") {
+		t.Fatalf("unknown category: %v", err)
+	}
+
+	item, err := uc.CreateItem(dto.RecurringItemRequest{Name: "Gym", Amount: -3_000, Frequency: entity.FrequencyMonthly, StartDate: date(2026, 1, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Account != entity.DefaultAccount {
+		t.Fatalf("account %q", item.Account)
+	}
+
+	end := date(2025, 12, 1)
+	_, err = uc.UpdateItem(item.ID, dto.RecurringItemRequest{Name: "Gym", Amount: -3_000, Frequency: entity.FrequencyMonthly, StartDate: date(2026, 1, 1), EndDate: &end})
+	if err == nil {
+		t.Fatal("an item ended before it started")
+	}
+	if err := uc.RemoveItem(item.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := uc.RemoveItem(item.ID); err != ErrRecurringNotFound {
+		t.Fatalf("want ErrRecurringNotFound, got %v", err)
+	}
+}
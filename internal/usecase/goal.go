@@ -0,0 +1,191 @@
+package usecase
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
+)
+
+// daysPerMonth is the length of an average Gregorian month.
+const daysPerMonth = 365.2425 / 12
+
+type GoalUseCase struct {
+	Repo        entity.GoalRepository
+	ContribRepo entity.GoalContributionRepository
+
+	now func() time.Time
+}
+
+func NewGoalUseCase(repo entity.GoalRepository, contributions entity.GoalContributionRepository) *GoalUseCase {
+	return &GoalUseCase{Repo: repo, ContribRepo: contributions, now: time.Now}
+}
+
+func (uc *GoalUseCase) Create(req dto.CreateGoalRequest) (*entity.Goal, error) {
+	goal, err := entity.NewGoal(req.Title, req.TargetAmount, req.Deadline, req.Account)
+	if err != nil {
+		return nil, invalid(err)
+	}
+	goal.Note = strings.TrimSpace(req.Note)
+
+	if err := uc.Repo.Insert(goal); err != nil {
+		return nil, err
+	}
+	return goal, nil
+}
+
+func (uc *GoalUseCase) List() ([]entity.Goal, error) {
+	return uc.Repo.FindAll()
+}
+
+func (uc *GoalUseCase) Get(id uint) (*entity.Goal, error) {
+	goal, err := uc.Repo.FindById(id)
+	if err != nil || goal == nil {
+		return nil, ErrGoalNotFound
+	}
+	return goal, nil
+}
+
+func (uc *GoalUseCase) Update(id uint, req dto.UpdateGoalRequest) (*entity.Goal, error) {
+	goal, err := uc.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	title, target, deadline := goal.Title, goal.TargetAmount, goal.Deadline
+	if req.Title != nil {
+		title = *req.Title
+	}
+	if req.TargetAmount != nil {
+		target = *req.TargetAmount
+	}
+	if req.Deadline != nil {
+		deadline = *req.Deadline
+	}
+	if err := goal.Set(title, target, deadline); err != nil {
+		return nil, invalid(err)
+	}
+	if req.Account != nil {
+		goal.Account = strings.TrimSpace(*req.Account)
+	}
+	if req.Note != nil {
+		goal.Note = strings.TrimSpace(*req.Note)
+	}
+
+	goal.UpdatedAt = uc.now()
+	if err := uc.Repo.Update(goal); err != nil {
+		return nil, err
+	}
+	return goal, nil
+}
+
+// Remove deletes the goal with its contributions.
+func (uc *GoalUseCase) Remove(id uint) error {
+	if err := uc.Repo.Delete(id); err != nil {
+		return ErrGoalNotFound
+	}
+	return nil
+}
+
+// /----------------------------- contributions -----------------------------
+
+func (uc *GoalUseCase) Contribute(goalID uint, req dto.AddContributionRequest) (*entity.GoalContribution, error) {
+	if _, err := uc.Get(goalID); err != nil {
+		return nil, err
+	}
+
+	date := uc.now()
+	if req.Date != nil {
+		date = *req.Date
+	}
+	c, err := entity.NewGoalContribution(goalID, req.Amount, date, req.Note)
+	if err != nil {
+		return nil, invalid(err)
+	}
+	if err := uc.ContribRepo.Insert(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (uc *GoalUseCase) Contributions(goalID uint) ([]entity.GoalContribution, error) {
+	if _, err := uc.Get(goalID); err != nil {
+		return nil, err
+	}
+	return uc.ContribRepo.FindByGoal(goalID)
+}
+
+func (uc *GoalUseCase) RemoveContribution(goalID uint, id uint) error {
+	if err := uc.ContribRepo.Delete(goalID, id); err != nil {
+		return ErrContribNotFound
+	}
+	return nil
+}
+
+// /-------------------------------- progress --------------------------------
+
+func (uc *GoalUseCase) Progress(id uint) (*dto.GoalProgress, error) {
+	goal, err := uc.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	contributions, err := uc.ContribRepo.FindByGoal(id)
+	if err != nil {
+		return nil, err
+	}
+	return goalProgress(goal, contributions, uc.now()), nil
+}
+
+// goalProgress works out where a goal stands at now from its contributions,
+// which are sorted by date.
+func goalProgress(goal *entity.Goal, contributions []entity.GoalContribution, now time.Time) *dto.GoalProgress {
+	p := &dto.GoalProgress{
+		GoalID:       goal.ID,
+		TargetAmount: goal.TargetAmount,
+		Deadline:     goal.Deadline,
+	}
+
+	// reachedAt is when the balance last climbed to the target
+	var reachedAt *time.Time
+	for _, c := range contributions {
+		p.Saved += c.Amount
+		if p.Saved < goal.TargetAmount {
+			reachedAt = nil
+		} else if reachedAt == nil {
+			date := c.Date
+			reachedAt = &date
+		}
+	}
+
+	p.Remaining = max(goal.TargetAmount-p.Saved, 0)
+	p.Percent = math.Max(math.Round(float64(p.Saved)*1000/float64(goal.TargetAmount))/10, 0)
+	p.Completed = p.Remaining == 0
+	p.Overdue = !p.Completed && now.After(goal.Deadline)
+
+	monthsLeft := goal.Deadline.Sub(now).Hours() / 24 / daysPerMonth
+	p.MonthsLeft = math.Max(math.Round(monthsLeft*10)/10, 0)
+	if !p.Completed {
+		// less than a month left means all of it is due now
+		p.RequiredMonthly = int64(math.Ceil(float64(p.Remaining) / math.Max(monthsLeft, 1)))
+	}
+
+	if len(contributions) > 0 {
+		months := math.Max(now.Sub(contributions[0].Date).Hours()/24/daysPerMonth, 1)
+		p.AverageMonthly = int64(math.Round(float64(p.Saved) / months))
+	}
+
+	switch {
+	case p.Completed:
+		p.ProjectedCompletion = reachedAt
+		p.OnTrack = true
+	case p.AverageMonthly > 0:
+		days := math.Ceil(float64(p.Remaining) / float64(p.AverageMonthly) * daysPerMonth)
+		y, m, d := now.AddDate(0, 0, int(days)).Date()
+		projected := time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+		p.ProjectedCompletion = &projected
+		p.OnTrack = !projected.After(goal.Deadline)
+	}
+	return p
+}
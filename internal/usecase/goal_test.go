@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
+	"money-tracker/internal/repository/memory"
+	"testing"
+	"time"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestGoalProgress(t *testing.T) {
+	now := date(2026, 10, 1)
+	goal := &entity.Goal{ID: 1, TargetAmount: 1_200_000, Deadline: date(2027, 6, 1)}
+	contrib := func(amount int64, d time.Time) entity.GoalContribution {
+		return entity.GoalContribution{Amount: amount, Date: d}
+	}
+
+	t.Run("no contributions", func(t *testing.T) {
+		p := goalProgress(goal, nil, now)
+		if p.Saved != 0 || p.Remaining != 1_200_000 || p.ProjectedCompletion != nil || p.OnTrack {
+			t.Fatalf("%+v", p)
+		}
+		// just under eight months left
+		if p.MonthsLeft != 8 || p.RequiredMonthly != 150_306 {
+			t.Fatalf("months left %v, required %d", p.MonthsLeft, p.RequiredMonthly)
+		}
+	})
+
+	t.Run("on track", func(t *testing.T) {
+		// 200,000 a month for four months
+		p := goalProgress(goal, []entity.GoalContribution{
+			contrib(200_000, date(2026, 6, 1)),
+			contrib(200_000, date(2026, 7, 1)),
+			contrib(200_000, date(2026, 8, 1)),
+			contrib(200_000, date(2026, 9, 1)),
+		}, now)
+		if p.Saved != 800_000 || p.Remaining != 400_000 || p.Percent != 66.7 || p.Completed {
+			t.Fatalf("%+v", p)
+		}
+		if p.AverageMonthly != 199_586 || p.ProjectedCompletion == nil || !p.OnTrack {
+			t.Fatalf("average %d, projected %v, on track %v", p.AverageMonthly, p.ProjectedCompletion, p.OnTrack)
+		}
+		if want := date(2026, 12, 2); !p.ProjectedCompletion.Equal(want) {
+			t.Fatalf("projected %v, want %v", p.ProjectedCompletion, want)
+		}
+	})
+
+	t.Run("behind", func(t *testing.T) {
+		p := goalProgress(goal, []entity.GoalContribution{
+			contrib(100_000, date(2025, 10, 1)),
+		}, now)
+		if p.AverageMonthly != 8_339 || p.OnTrack || p.ProjectedCompletion == nil || p.ProjectedCompletion.Before(goal.Deadline) {
+			t.Fatalf("%+v", p)
+		}
+	})
+
+	t.Run("withdrawn below zero pace", func(t *testing.T) {
+		p := goalProgress(goal, []entity.GoalContribution{
+			contrib(100_000, date(2026, 9, 1)),
+			contrib(-150_000, date(2026, 9, 15)),
+		}, now)
+		if p.Saved != -50_000 || p.Percent != 0 || p.ProjectedCompletion != nil || p.OnTrack {
+			t.Fatalf("%+v", p)
+		}
+	})
+
+	t.Run("completed", func(t *testing.T) {
+		p := goalProgress(goal, []entity.GoalContribution{
+			contrib(1_000_000, date(2026, 3, 1)),
+			contrib(300_000, date(2026, 4, 1)),
+			contrib(-200_000, date(2026, 5, 1)),
+			contrib(200_000, date(2026, 6, 1)),
+		}, now)
+		if !p.Completed || p.Remaining != 0 || p.RequiredMonthly != 0 || !p.OnTrack {
+			t.Fatalf("%+v", p)
+		}
+		// reached in April, dropped below in May, reached again in June
+		if p.ProjectedCompletion == nil || !p.ProjectedCompletion.Equal(date(2026, 6, 1)) {
+			t.Fatalf("completed at %v", p.ProjectedCompletion)
+		}
+	})
+
+	t.Run("overdue", func(t *testing.T) {
+		p := goalProgress(goal, []entity.GoalContribution{contrib(600_000, date(2026, 1, 1))}, date(2027, 7, 1))
+		if !p.Overdue || p.MonthsLeft != 0 || p.RequiredMonthly != 600_000 {
+			t.Fatalf("%+v", p)
+		}
+	})
+}
+
+func TestGoalContributions(t *testing.T) {
+	store := memory.NewStore()
+	uc := NewGoalUseCase(memory.NewGoalRepo(store), memory.NewGoalContributionRepo(store))
+	uc.now = func() time.Time { return date(2026, 10, 1) }
+
+	goal, err := uc.Create(dto.CreateGoalRequest{Title: " Vacation ", TargetAmount: 2_000_000, Deadline: date(2027, 6, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if goal.Title != "Vacation" {
+		t.Fatalf("created %+v", goal)
+	}
+	if _, err := uc.Create(dto.CreateGoalRequest{Title: "Nothing", Deadline: date(2027, 6, 1)}); err == nil {
+		t.Fatal("created a goal without a target")
+	}
+
+	c, err := uc.Contribute(goal.ID, dto.AddContributionRequest{Amount: 500_000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.Date.Equal(date(2026, 10, 1)) {
+		t.Fatalf("contribution dated %v, want today", c.Date)
+	}
+	if _, err := uc.Contribute(goal.ID+1, dto.AddContributionRequest{Amount: 1}); err != ErrGoalNotFound {
+		t.Fatalf("want ErrGoalNotFound, got %v", err)
+	}
+
+	p, err := uc.Progress(goal.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Saved != 500_000 || p.Percent != 25 {
+		t.Fatalf("progress %+v", p)
+	}
+
+	if err := uc.RemoveContribution(goal.ID, c.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := uc.RemoveContribution(goal.ID, c.ID); err != ErrContribNotFound {
+		t.Fatalf("want ErrContribNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,371 @@
+package usecase
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
+)
+
+// maxInstallments bounds a projection whose payments barely cover the
+// interest.
+const maxInstallments = 10_000
+
+type LoanUseCase struct {
+	Repo        entity.LoanRepository
+	PaymentRepo entity.LoanPaymentRepository
+
+	now func() time.Time
+}
+
+func NewLoanUseCase(repo entity.LoanRepository, payments entity.LoanPaymentRepository) *LoanUseCase {
+	return &LoanUseCase{Repo: repo, PaymentRepo: payments, now: time.Now}
+}
+
+func (uc *LoanUseCase) Create(req dto.CreateLoanRequest) (*entity.Loan, error) {
+	loan, err := entity.NewLoan(req.Name, req.Direction, req.Principal, req.InterestRate, req.Term, req.Frequency, req.StartDate)
+	if err != nil {
+		return nil, invalid(err)
+	}
+	loan.Note = strings.TrimSpace(req.Note)
+
+	if err := uc.Repo.Insert(loan); err != nil {
+		return nil, err
+	}
+	return loan, nil
+}
+
+func (uc *LoanUseCase) List() ([]entity.Loan, error) {
+	return uc.Repo.FindAll()
+}
+
+func (uc *LoanUseCase) Get(id uint) (*entity.Loan, error) {
+	loan, err := uc.Repo.FindById(id)
+	if err != nil || loan == nil {
+		return nil, ErrLoanNotFound
+	}
+	return loan, nil
+}
+
+// Update changes the loan. The recorded payments were split on the terms
+// they were made under, so the terms cannot change once there are any.
+func (uc *LoanUseCase) Update(id uint, req dto.UpdateLoanRequest) (*entity.Loan, error) {
+	loan, err := uc.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	name, direction := loan.Name, loan.Direction
+	if req.Name != nil {
+		name = *req.Name
+	}
+	if req.Direction != nil {
+		direction = *req.Direction
+	}
+	if err := loan.SetName(name, direction); err != nil {
+		return nil, invalid(err)
+	}
+
+	if req.Principal != nil || req.InterestRate != nil || req.Term != nil || req.Frequency != nil || req.StartDate != nil {
+		payments, err := uc.PaymentRepo.FindByLoan(id)
+		if err != nil {
+			return nil, err
+		}
+		if len(payments) > 0 {
+			return nil, ErrLoanHasPayments
+		}
+
+		principal, rate, term, frequency, start := loan.Principal, loan.InterestRate, loan.Term, loan.Frequency, loan.StartDate
+		if req.Principal != nil {
+			principal = *req.Principal
+		}
+		if req.InterestRate != nil {
+			rate = *req.InterestRate
+		}
+		if req.Term != nil {
+			term = *req.Term
+		}
+		if req.Frequency != nil {
+			frequency = *req.Frequency
+		}
+		if req.StartDate != nil {
+			start = *req.StartDate
+		}
+		if err := loan.SetTerms(principal, rate, term, frequency, start); err != nil {
+			return nil, invalid(err)
+		}
+	}
+	if req.Note != nil {
+		loan.Note = strings.TrimSpace(*req.Note)
+	}
+
+	loan.UpdatedAt = uc.now()
+	if err := uc.Repo.Update(loan); err != nil {
+		return nil, err
+	}
+	return loan, nil
+}
+
+// Remove deletes the loan with its payments.
+func (uc *LoanUseCase) Remove(id uint) error {
+	if err := uc.Repo.Delete(id); err != nil {
+		return ErrLoanNotFound
+	}
+	return nil
+}
+
+// /-------------------------------- payments --------------------------------
+
+// Pay records a payment and splits it into the interest charged since the
+// payment before and principal. Payments are recorded in date order.
+func (uc *LoanUseCase) Pay(loanID uint, req dto.AddLoanPaymentRequest) (*entity.LoanPayment, error) {
+	loan, err := uc.Get(loanID)
+	if err != nil {
+		return nil, err
+	}
+	payments, err := uc.PaymentRepo.FindByLoan(loanID)
+	if err != nil {
+		return nil, err
+	}
+
+	date := uc.now()
+	if req.Date != nil {
+		date = *req.Date
+	}
+	if date.Before(loan.StartDate) {
+		return nil, InvalidField("date", "invalid", "date is before the start of the loan")
+	}
+	if n := len(payments); n > 0 && date.Before(payments[n-1].Date) {
+		return nil, InvalidField("date", "invalid", "date is before the latest payment")
+	}
+
+	payment := splitPayment(loan, payments, req.Amount, date)
+	if payment.Principal > loanBalance(loan, payments) {
+		payoff := loanBalance(loan, payments) + payment.Interest
+		return nil, InvalidField("amount", "max", "amount is more than the "+strconv.FormatInt(payoff, 10)+" left to pay")
+	}
+	payment.Note = strings.TrimSpace(req.Note)
+	payment.CreatedAt = uc.now()
+
+	if err := uc.PaymentRepo.Insert(payment); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+func (uc *LoanUseCase) Payments(loanID uint) ([]entity.LoanPayment, error) {
+	if _, err := uc.Get(loanID); err != nil {
+		return nil, err
+	}
+	return uc.PaymentRepo.FindByLoan(loanID)
+}
+
+// RemovePayment deletes the latest payment; the splits of later payments
+// depend on the earlier ones, so those stay.
+func (uc *LoanUseCase) RemovePayment(loanID uint, id uint) error {
+	payments, err := uc.PaymentRepo.FindByLoan(loanID)
+	if err != nil {
+		return err
+	}
+	for i, p := range payments {
+		if p.ID != id {
+			continue
+		}
+		if i != len(payments)-1 {
+			return ErrPaymentNotLatest
+		}
+		if err := uc.PaymentRepo.Delete(loanID, id); err != nil {
+			return ErrPaymentNotFound
+		}
+		return nil
+	}
+	return ErrPaymentNotFound
+}
+
+// /------------------------------ amortization ------------------------------
+
+// Schedule is the amortization schedule of the loan from its start, with
+// extra paid on top of every payment.
+func (uc *LoanUseCase) Schedule(id uint, extra int64) (*dto.LoanSchedule, error) {
+	loan, err := uc.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	payment := regularPayment(loan)
+	installments, payoff := amortize(loan, loan.Principal, 0, 1, payment+extra)
+	return &dto.LoanSchedule{
+		LoanID:         loan.ID,
+		RegularPayment: payment,
+		Extra:          extra,
+		Installments:   installments,
+		Payoff:         payoff,
+	}, nil
+}
+
+// Status sums up the payments made and projects the rest of the loan. With
+// extra or lumpSum it also projects paying more.
+func (uc *LoanUseCase) Status(id uint, in dto.LoanStatusInput) (*dto.LoanStatus, error) {
+	loan, err := uc.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	payments, err := uc.PaymentRepo.FindByLoan(id)
+	if err != nil {
+		return nil, err
+	}
+	return loanStatus(loan, payments, in, uc.now()), nil
+}
+
+func loanStatus(loan *entity.Loan, payments []entity.LoanPayment, in dto.LoanStatusInput, now time.Time) *dto.LoanStatus {
+	s := &dto.LoanStatus{
+		LoanID:         loan.ID,
+		Principal:      loan.Principal,
+		Balance:        loanBalance(loan, payments),
+		InterestOwed:   interestOwed(loan, payments),
+		PaymentsMade:   len(payments),
+		RegularPayment: regularPayment(loan),
+	}
+	for _, p := range payments {
+		s.PrincipalPaid += p.Principal
+		s.InterestPaid += p.Interest
+	}
+	s.PaidOff = s.Balance == 0 && s.InterestOwed == 0
+	if s.PaidOff {
+		return s
+	}
+
+	// the next payment due is the first after the period of the latest
+	// payment, even when payments were missed
+	last := loan.StartDate
+	if n := len(payments); n > 0 {
+		last = payments[n-1].Date
+	}
+	next := loan.DuesBy(last) + 1
+	due := loan.DueDate(next)
+	s.NextDueDate = &due
+	_, s.Remaining = amortize(loan, s.Balance, s.InterestOwed, next, s.RegularPayment)
+
+	if in.Extra > 0 || in.LumpSum > 0 {
+		// a lump sum pays the interest owed first
+		lump := min(in.LumpSum, s.Balance+s.InterestOwed)
+		owed := max(s.InterestOwed-lump, 0)
+		balance := s.Balance - (lump - (s.InterestOwed - owed))
+		w := &dto.LoanWhatIf{Extra: in.Extra, LumpSum: lump}
+		_, w.Remaining = amortize(loan, balance, owed, next, s.RegularPayment+in.Extra)
+		w.Remaining.TotalPaid += lump
+		if lump == s.Balance+s.InterestOwed {
+			// the lump sum pays it all off today
+			w.Remaining.PayoffDate = &now
+		}
+		w.InterestSaved = s.Remaining.TotalInterest - w.Remaining.TotalInterest
+		w.PaymentsSaved = s.Remaining.Payments - w.Remaining.Payments
+		s.WhatIf = w
+	}
+	return s
+}
+
+// regularPayment is the fixed payment that pays the loan off in its term,
+// rounded up to the minor unit so the last payment is the smallest.
+func regularPayment(loan *entity.Loan) int64 {
+	r := loan.PeriodRate()
+	n := float64(loan.Term)
+	principal := float64(loan.Principal)
+	if r == 0 {
+		return int64(math.Ceil(principal / n))
+	}
+	// leave out float error, a payment of 100.0000001 is 100
+	return int64(math.Ceil(principal*r/(1-math.Pow(1+r, -n)) - 1e-6))
+}
+
+// amortize pays balance and the interest owed off with payment every
+// period, starting with the payment due as the first-th. Each period
+// charges interest on the balance, rounded to the minor unit; the rest of
+// the payment pays off principal.
+func amortize(loan *entity.Loan, balance int64, owed int64, first int, payment int64) ([]dto.LoanInstallment, dto.LoanPayoff) {
+	r := loan.PeriodRate()
+	installments := []dto.LoanInstallment{}
+	var payoff dto.LoanPayoff
+
+	for n := first; (balance > 0 || owed > 0) && len(installments) < maxInstallments; n++ {
+		interest := owed + int64(math.Round(float64(balance)*r))
+		owed = 0
+		pay := min(payment, balance+interest)
+		if pay <= interest {
+			// the payment does not even cover the interest
+			break
+		}
+		balance -= pay - interest
+		installments = append(installments, dto.LoanInstallment{
+			Number:    n,
+			Date:      loan.DueDate(n),
+			Payment:   pay,
+			Interest:  interest,
+			Principal: pay - interest,
+			Balance:   balance,
+		})
+		payoff.TotalInterest += interest
+		payoff.TotalPaid += pay
+	}
+
+	payoff.Payments = len(installments)
+	if balance == 0 && owed == 0 && len(installments) > 0 {
+		date := installments[len(installments)-1].Date
+		payoff.PayoffDate = &date
+	}
+	return installments, payoff
+}
+
+func loanBalance(loan *entity.Loan, payments []entity.LoanPayment) int64 {
+	balance := loan.Principal
+	for _, p := range payments {
+		balance -= p.Principal
+	}
+	return balance
+}
+
+// interestOwed replays the payments and returns the interest they were
+// charged but did not cover.
+func interestOwed(loan *entity.Loan, payments []entity.LoanPayment) int64 {
+	balance := loan.Principal
+	last := loan.StartDate
+	var owed int64
+	for _, p := range payments {
+		owed += periodInterest(loan, balance, last, p.Date) - p.Interest
+		balance -= p.Principal
+		last = p.Date
+	}
+	return max(owed, 0)
+}
+
+// periodInterest is the interest on balance for the periods that came due
+// after from up to to.
+func periodInterest(loan *entity.Loan, balance int64, from time.Time, to time.Time) int64 {
+	periods := loan.DuesBy(to) - loan.DuesBy(from)
+	return int64(math.Round(float64(balance) * loan.PeriodRate() * float64(periods)))
+}
+
+// splitPayment charges the interest of every period that came due since
+// the payment before, or the start, and puts the rest towards principal.
+// A payment between due dates charges no interest. Interest that a payment
+// does not cover is owed and charged again with the next payment.
+func splitPayment(loan *entity.Loan, payments []entity.LoanPayment, amount int64, date time.Time) *entity.LoanPayment {
+	last := loan.StartDate
+	if n := len(payments); n > 0 {
+		last = payments[n-1].Date
+	}
+
+	balance := loanBalance(loan, payments)
+	interest := interestOwed(loan, payments) + periodInterest(loan, balance, last, date)
+	interest = min(interest, amount)
+	return &entity.LoanPayment{
+		LoanID:    loan.ID,
+		Date:      date,
+		Amount:    amount,
+		Principal: amount - interest,
+		Interest:  interest,
+	}
+}
@@ -0,0 +1,251 @@
+package usecase
+
+import (
+	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
+	"money-tracker/internal/repository/memory"
+	"testing"
+	"time"
+)
+
+func newLoanUseCase(t *testing.T, now time.Time) *LoanUseCase {
+	t.Helper()
+	store := memory.NewStore()
+	uc := NewLoanUseCase(memory.NewLoanRepo(store), memory.NewLoanPaymentRepo(store))
+	uc.now = func() time.Time { return now }
+	return uc
+}
+
+// carLoan is 10,000.00 at 12% a year over 12 monthly payments.
+func carLoan(t *testing.T, uc *LoanUseCase) *entity.Loan {
+	t.Helper()
+	loan, err := uc.Create(dto.CreateLoanRequest{
+		Name: "Car", Principal: 1_000_000, InterestRate: 12, Term: 12,
+		Frequency: entity.FrequencyMonthly, StartDate: date(2026, 1, 31),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loan
+}
+
+func TestLoanSchedule(t *testing.T) {
+	uc := newLoanUseCase(t, date(2026, 1, 31))
+	loan := carLoan(t, uc)
+
+	s, err := uc.Schedule(loan.ID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.RegularPayment != 88_849 || len(s.Installments) != 12 {
+		t.Fatalf("payment %d over %d installments", s.RegularPayment, len(s.Installments))
+	}
+	first, last := s.Installments[0], s.Installments[11]
+	if first.Interest != 10_000 || first.Principal != 78_849 || first.Balance != 921_151 {
+		t.Fatalf("first installment %+v", first)
+	}
+	if last.Balance != 0 || last.Payment > s.RegularPayment || s.Payoff.TotalPaid != 1_000_000+s.Payoff.TotalInterest {
+		t.Fatalf("last installment %+v, payoff %+v", last, s.Payoff)
+	}
+	if s.Payoff.TotalInterest != 66_186 {
+		t.Fatalf("total interest %d", s.Payoff.TotalInterest)
+	}
+	// monthly due dates keep the day or the last day of the month
+	for i, want := range []time.Time{date(2026, 2, 28), date(2026, 3, 31), date(2026, 4, 30)} {
+		if !s.Installments[i].Date.Equal(want) {
+			t.Fatalf("installment %d due %v, want %v", i+1, s.Installments[i].Date, want)
+		}
+	}
+	if !s.Payoff.PayoffDate.Equal(date(2027, 1, 31)) {
+		t.Fatalf("paid off %v", s.Payoff.PayoffDate)
+	}
+
+	s, err = uc.Schedule(loan.ID, 50_000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Installments) != 8 || s.Payoff.TotalInterest >= 66_186 {
+		t.Fatalf("with extra: %d installments, payoff %+v", len(s.Installments), s.Payoff)
+	}
+}
+
+func TestLoanScheduleWithoutInterest(t *testing.T) {
+	uc := newLoanUseCase(t, date(2026, 10, 1))
+	iou, err := uc.Create(dto.CreateLoanRequest{
+		Name: "Alex", Direction: entity.LoanLent, Principal: 100_000, Term: 3,
+		Frequency: entity.FrequencyWeekly, StartDate: date(2026, 10, 1),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := uc.Schedule(iou.ID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paid []int64
+	for _, i := range s.Installments {
+		paid = append(paid, i.Payment)
+	}
+	if len(paid) != 3 || paid[0] != 33_334 || paid[2] != 33_332 || s.Payoff.TotalInterest != 0 {
+		t.Fatalf("paid %v, payoff %+v", paid, s.Payoff)
+	}
+	if !s.Payoff.PayoffDate.Equal(date(2026, 10, 22)) {
+		t.Fatalf("paid off %v", s.Payoff.PayoffDate)
+	}
+}
+
+func TestLoanPayments(t *testing.T) {
+	uc := newLoanUseCase(t, date(2026, 2, 28))
+	loan := carLoan(t, uc)
+	pay := func(amount int64, d time.Time) (*entity.LoanPayment, error) {
+		return uc.Pay(loan.ID, dto.AddLoanPaymentRequest{Amount: amount, Date: &d})
+	}
+
+	// on time: one period of interest
+	first, err := uc.Pay(loan.ID, dto.AddLoanPaymentRequest{Amount: 88_849})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Interest != 10_000 || first.Principal != 78_849 || !first.Date.Equal(date(2026, 2, 28)) {
+		t.Fatalf("first payment %+v", first)
+	}
+
+	// between due dates: all principal
+	extra, err := pay(21_151, date(2026, 3, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if extra.Interest != 0 || extra.Principal != 21_151 {
+		t.Fatalf("extra payment %+v", extra)
+	}
+
+	// the March and April payments are missed, May pays three periods of
+	// interest
+	late, err := pay(100_000, date(2026, 5, 31))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if late.Interest != 27_000 || late.Principal != 73_000 {
+		t.Fatalf("late payment %+v", late)
+	}
+
+	if _, err := pay(1, date(2026, 5, 1)); !hasFieldCode(err, "date", "invalid") {
+		t.Fatalf("backdated payment: %v", err)
+	}
+	if _, err := pay(900_000, date(2026, 6, 30)); !hasFieldCode(err, "amount", "max") {
+		t.Fatalf("overpayment: %v", err)
+	}
+
+	if err := uc.RemovePayment(loan.ID, extra.ID); err != ErrPaymentNotLatest {
+		t.Fatalf("want ErrPaymentNotLatest, got %v", err)
+	}
+	term := 24
+	if _, err := uc.Update(loan.ID, dto.UpdateLoanRequest{Term: &term}); err != ErrLoanHasPayments {
+		t.Fatalf("want ErrLoanHasPayments, got %v", err)
+	}
+	note := "paid from savings"
+	if _, err := uc.Update(loan.ID, dto.UpdateLoanRequest{Note: &note}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := uc.RemovePayment(loan.ID, late.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := uc.RemovePayment(loan.ID, late.ID); err != ErrPaymentNotFound {
+		t.Fatalf("want ErrPaymentNotFound, got %v", err)
+	}
+}
+
+func TestLoanStatus(t *testing.T) {
+	uc := newLoanUseCase(t, date(2026, 3, 5))
+	loan := carLoan(t, uc)
+	d := date(2026, 2, 28)
+	if _, err := uc.Pay(loan.ID, dto.AddLoanPaymentRequest{Amount: 88_849, Date: &d}); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := uc.Status(loan.ID, dto.LoanStatusInput{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Balance != 921_151 || s.PrincipalPaid != 78_849 || s.InterestPaid != 10_000 || s.PaymentsMade != 1 || s.PaidOff {
+		t.Fatalf("status %+v", s)
+	}
+	if !s.NextDueDate.Equal(date(2026, 3, 31)) || s.Remaining.Payments != 11 || !s.Remaining.PayoffDate.Equal(date(2027, 1, 31)) {
+		t.Fatalf("next due %v, remaining %+v", s.NextDueDate, s.Remaining)
+	}
+	if s.WhatIf != nil {
+		t.Fatalf("what-if without extra: %+v", s.WhatIf)
+	}
+
+	s, err = uc.Status(loan.ID, dto.LoanStatusInput{Extra: 50_000, LumpSum: 100_000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := s.WhatIf
+	if w.Remaining.Payments != 7 || w.PaymentsSaved != 4 || w.InterestSaved <= 0 || !w.Remaining.PayoffDate.Before(*s.Remaining.PayoffDate) {
+		t.Fatalf("what-if %+v, remaining %+v", w, w.Remaining)
+	}
+
+	// a lump sum over the balance pays off today
+	s, err = uc.Status(loan.ID, dto.LoanStatusInput{LumpSum: 2_000_000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := s.WhatIf; w.LumpSum != 921_151 || w.Remaining.Payments != 0 || !w.Remaining.PayoffDate.Equal(date(2026, 3, 5)) {
+		t.Fatalf("paid off at once: %+v", w)
+	}
+}
+
+func TestLoanInterestOwed(t *testing.T) {
+	uc := newLoanUseCase(t, date(2026, 3, 31))
+	loan := carLoan(t, uc)
+	pay := func(amount int64, d time.Time) *entity.LoanPayment {
+		t.Helper()
+		p, err := uc.Pay(loan.ID, dto.AddLoanPaymentRequest{Amount: amount, Date: &d})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	// half the interest of February is paid, the rest is owed
+	if p := pay(5_000, date(2026, 2, 28)); p.Interest != 5_000 || p.Principal != 0 {
+		t.Fatalf("short payment %+v", p)
+	}
+	s, err := uc.Status(loan.ID, dto.LoanStatusInput{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.InterestOwed != 5_000 || s.Balance != 1_000_000 || s.PaidOff {
+		t.Fatalf("status %+v", s)
+	}
+	if p := pay(88_849, date(2026, 3, 31)); p.Interest != 15_000 || p.Principal != 73_849 {
+		t.Fatalf("payment after a short one %+v", p)
+	}
+	if s, _ := uc.Status(loan.ID, dto.LoanStatusInput{}); s.InterestOwed != 0 {
+		t.Fatalf("interest still owed: %+v", s)
+	}
+}
+
+func TestLoanInterestEndsWithTerm(t *testing.T) {
+	uc := newLoanUseCase(t, date(2027, 1, 1))
+	loan, err := uc.Create(dto.CreateLoanRequest{
+		Name: "Phone", Principal: 100_000, InterestRate: 12, Term: 3,
+		Frequency: entity.FrequencyMonthly, StartDate: date(2026, 1, 31),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// paid late in the year, interest is charged for the 3 periods only
+	d := date(2026, 12, 31)
+	p, err := uc.Pay(loan.ID, dto.AddLoanPaymentRequest{Amount: 103_000, Date: &d})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Interest != 3_000 || p.Principal != 100_000 {
+		t.Fatalf("late payment %+v", p)
+	}
+}
@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"strings"
+	"time"
+
+	"money-tracker/internal/ratelimit"
+)
+
+// failureMemory is how long failed logins of a user name are remembered.
+const failureMemory = 24 * time.Hour
+
+// LoginLimits configures LoginGuard. A zero attempt limit turns that limit
+// off.
+type LoginLimits struct {
+	// IPAttempts logins per IPWindow from one address
+	IPAttempts int
+	IPWindow   time.Duration
+	// UserAttempts logins per UserWindow for one user name
+	UserAttempts int
+	UserWindow   time.Duration
+	// LockoutAfter failures lock the user name for LockoutBase, doubled for
+	// every failure after that, up to LockoutMax.
+	LockoutAfter int
+	LockoutBase  time.Duration
+	LockoutMax   time.Duration
+}
+
+// LoginGuard rate limits logins per address and per user name and locks a
+// user name out after repeated failures. Its keys are the names that were
+// tried, whether a user has them or not, so its answers tell nothing about
+// which users exist.
+type LoginGuard struct {
+	Store  ratelimit.Store
+	Limits LoginLimits
+
+	now func() time.Time
+}
+
+func NewLoginGuard(store ratelimit.Store, limits LoginLimits) *LoginGuard {
+	return &LoginGuard{
+		Store:  store,
+		Limits: limits,
+		now:    time.Now,
+	}
+}
+
+func ipKey(ip string) string         { return "login:ip:" + ip }
+func attemptsKey(user string) string { return "login:user:" + user }
+func failuresKey(user string) string { return "login:failures:" + user }
+func lockKey(user string) string     { return "login:lock:" + user }
+
+func normalizeUserName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// CheckLocked refuses a user name that is locked out.
+func (g *LoginGuard) CheckLocked(userName string) error {
+	now := g.now()
+	_, lockedUntil, err := g.Store.Get(lockKey(normalizeUserName(userName)))
+	if err != nil {
+		return err
+	}
+	if lockedUntil.After(now) {
+		return RateLimited(CodeAccountLocked, "too many failed logins, try again later", lockedUntil.Sub(now))
+	}
+	return nil
+}
+
+// Check counts a login attempt and refuses it when the address or the user
+// name is over its limit or the user name is locked out.
+func (g *LoginGuard) Check(ip string, userName string) error {
+	if err := g.CheckLocked(userName); err != nil {
+		return err
+	}
+	user := normalizeUserName(userName)
+	now := g.now()
+
+	limits := []struct {
+		key      string
+		attempts int
+		window   time.Duration
+	}{
+		{ipKey(ip), g.Limits.IPAttempts, g.Limits.IPWindow},
+		{attemptsKey(user), g.Limits.UserAttempts, g.Limits.UserWindow},
+	}
+	for _, l := range limits {
+		if l.attempts <= 0 {
+			continue
+		}
+		n, reset, err := g.Store.Incr(l.key, l.window)
+		if err != nil {
+			return err
+		}
+		if n > l.attempts {
+			return RateLimited(CodeTooManyAttempts, "too many login attempts, try again later", reset.Sub(now))
+		}
+	}
+	return nil
+}
+
+// Failed records a failed login and locks the user name out once it has
+// failed LockoutAfter times.
+func (g *LoginGuard) Failed(userName string) error {
+	if g.Limits.LockoutAfter <= 0 {
+		return nil
+	}
+	user := normalizeUserName(userName)
+
+	n, _, err := g.Store.Incr(failuresKey(user), failureMemory)
+	if err != nil {
+		return err
+	}
+	if n < g.Limits.LockoutAfter {
+		return nil
+	}
+
+	lockout := g.Limits.LockoutBase
+	for i := g.Limits.LockoutAfter; i < n && lockout < g.Limits.LockoutMax; i++ {
+		lockout *= 2
+	}
+	lockout = min(lockout, g.Limits.LockoutMax)
+	return g.Store.Set(lockKey(user), n, g.now().Add(lockout))
+}
+
+// Succeeded forgets the failures of the user name.
+func (g *LoginGuard) Succeeded(userName string) error {
+	user := normalizeUserName(userName)
+	return g.Store.Delete(failuresKey(user), lockKey(user))
+}
+
+// Unlock lifts the lockout and the attempt limit of the user name.
+func (g *LoginGuard) Unlock(userName string) error {
+	user := normalizeUserName(userName)
+	return g.Store.Delete(failuresKey(user), lockKey(user), attemptsKey(user))
+}
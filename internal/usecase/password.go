@@ -0,0 +1,195 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"money-tracker/internal/constants"
+	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
+	"money-tracker/internal/notify"
+	"money-tracker/internal/password"
+)
+
+// PasswordUseCase changes and resets passwords. Both end every session of
+// the user, so a leaked password or token stops working everywhere.
+type PasswordUseCase struct {
+	Repo      entity.UserRepository
+	TokenRepo entity.UserTokenRepository
+	ResetRepo entity.PasswordResetRepository
+	Policy    *password.Policy
+	Notifier  notify.Notifier
+	// Guard limits reset requests like logins and counts wrong current
+	// passwords as failed logins; nil turns that off.
+	Guard    *LoginGuard
+	ResetTTL time.Duration
+
+	now func() time.Time
+}
+
+func NewPasswordUseCase(repo entity.UserRepository, tokenRepo entity.UserTokenRepository, resetRepo entity.PasswordResetRepository, policy *password.Policy, notifier notify.Notifier, guard *LoginGuard, resetTTL time.Duration) *PasswordUseCase {
+	return &PasswordUseCase{
+		Repo:      repo,
+		TokenRepo: tokenRepo,
+		ResetRepo: resetRepo,
+		Policy:    policy,
+		Notifier:  notifier,
+		Guard:     guard,
+		ResetTTL:  resetTTL,
+		now:       time.Now,
+	}
+}
+
+// Change sets a new password for a user who knows the current one.
+func (uc *PasswordUseCase) Change(userID uint, req dto.ChangePasswordRequest) error {
+	user, err := uc.Repo.FindById(userID)
+	if err != nil || user == nil {
+		return ErrUserNotFound
+	}
+	if uc.Guard != nil {
+		if err := uc.Guard.CheckLocked(user.UserName); err != nil {
+			return err
+		}
+	}
+
+	if !user.CheckPassword(req.CurrentPassword) {
+		if uc.Guard != nil {
+			if err := uc.Guard.Failed(user.UserName); err != nil {
+				return err
+			}
+		}
+		return InvalidField("current_password", CodeWrongPassword, "the current password is wrong")
+	}
+	if err := checkPassword(uc.Policy, "new_password", req.NewPassword, user.UserName); err != nil {
+		return err
+	}
+
+	return uc.setPassword(user, req.NewPassword)
+}
+
+// Forgot sends a reset token to the user. It answers the same whether the
+// user exists or not; only the rate limit can refuse it.
+func (uc *PasswordUseCase) Forgot(req dto.ForgotPasswordRequest, ip string) error {
+	if uc.Guard != nil {
+		if err := uc.Guard.Check(ip, req.UserName); err != nil {
+			return err
+		}
+	}
+
+	user, err := uc.Repo.FindByUserName(req.UserName)
+	if err != nil || user == nil || user.StatusID != constants.StatusActive {
+		return nil
+	}
+
+	token, err := generateResetToken()
+	if err != nil {
+		return err
+	}
+	expiresAt := uc.now().Add(uc.ResetTTL)
+	row, err := entity.NewPasswordResetToken(user.ID, hashResetToken(token), expiresAt)
+	if err != nil {
+		return err
+	}
+	// only the latest token works
+	if err := uc.ResetRepo.DeleteByUser(user.ID); err != nil {
+		return err
+	}
+	if err := uc.ResetRepo.Insert(row); err != nil {
+		return err
+	}
+
+	return uc.Notifier.Notify(notify.Message{
+		UserID:   user.ID,
+		UserName: user.UserName,
+		Subject:  "Reset your password",
+		Body: fmt.Sprintf("Someone asked to reset the password of %s. If it was you, reset it with this token before %s:\n\n%s\n\nOtherwise ignore this message.",
+			user.UserName, expiresAt.Format(time.RFC1123), token),
+	})
+}
+
+// Reset sets a new password with a token from Forgot and lifts the login
+// lockout of the user. The token is used only once the password passes the
+// policy.
+func (uc *PasswordUseCase) Reset(req dto.ResetPasswordRequest) error {
+	hash := hashResetToken(req.Token)
+	row, err := uc.ResetRepo.Find(hash, uc.now())
+	if err != nil {
+		return ErrInvalidResetToken
+	}
+	user, err := uc.Repo.FindById(row.UserID)
+	if err != nil || user == nil {
+		return ErrInvalidResetToken
+	}
+
+	// a rejected password must not use the token up
+	if err := checkPassword(uc.Policy, "new_password", req.NewPassword, user.UserName); err != nil {
+		return err
+	}
+	if err := user.SetPassword(req.NewPassword); err != nil {
+		return invalid(err)
+	}
+	// Use fails when another request used the token since Find
+	if _, err := uc.ResetRepo.Use(hash, uc.now()); err != nil {
+		return ErrInvalidResetToken
+	}
+
+	if err := uc.savePassword(user); err != nil {
+		return err
+	}
+	if uc.Guard != nil {
+		return uc.Guard.Unlock(user.UserName)
+	}
+	return nil
+}
+
+// setPassword stores the password and ends the sessions and the pending
+// resets of the user.
+func (uc *PasswordUseCase) setPassword(user *entity.User, newPassword string) error {
+	if err := user.SetPassword(newPassword); err != nil {
+		return invalid(err)
+	}
+	return uc.savePassword(user)
+}
+
+// savePassword stores a password set on user, see setPassword.
+func (uc *PasswordUseCase) savePassword(user *entity.User) error {
+	user.UpdatedAt = uc.now()
+	if _, err := uc.Repo.Update(user); err != nil {
+		return err
+	}
+	if err := uc.TokenRepo.DeleteByUser(user.ID); err != nil {
+		return err
+	}
+	return uc.ResetRepo.DeleteByUser(user.ID)
+}
+
+// checkPassword checks a new password against policy, which may be nil.
+func checkPassword(policy *password.Policy, field string, newPassword string, userName string) error {
+	if policy == nil {
+		return nil
+	}
+	if err := policy.Check(newPassword, userName); err != nil {
+		return InvalidField(field, CodeWeakPassword, err.Error())
+	}
+	return nil
+}
+
+// generateResetToken returns 32 random bytes, URL safe.
+func generateResetToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashResetToken is a plain SHA-256: the token is random, there is nothing
+// to guess from the hash.
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
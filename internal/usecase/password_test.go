@@ -0,0 +1,179 @@
+package usecase
+
+import (
+	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
+	"money-tracker/internal/notify"
+	"money-tracker/internal/ratelimit"
+	"money-tracker/internal/repository/memory"
+	"strings"
+	"testing"
+	"time"
+)
+
+// outbox keeps the messages instead of sending them.
+type outbox []notify.Message
+
+func (o *outbox) Notify(msg notify.Message) error {
+	*o = append(*o, msg)
+	return nil
+}
+
+// lastToken reads the reset token, the last line of the last message.
+func (o *outbox) lastToken(t *testing.T) string {
+	t.Helper()
+	if len(*o) == 0 {
+		t.Fatal("nothing was sent")
+	}
+	body := strings.TrimSpace((*o)[len(*o)-1].Body)
+	lines := strings.Split(body, "\n")
+	return strings.TrimSpace(lines[len(lines)-3])
+}
+
+func newTestPasswordUseCase(t *testing.T) (*PasswordUseCase, *entity.User, *outbox, *time.Time) {
+	t.Helper()
+	store := memory.NewStore()
+	guard := NewLoginGuard(ratelimit.NewMemoryStore(), LoginLimits{LockoutAfter: 2, LockoutBase: time.Minute, LockoutMax: time.Minute})
+	sent := &outbox{}
+	uc := NewPasswordUseCase(memory.NewUserRepo(store), memory.NewUserTokenRepo(store), memory.NewPasswordResetRepo(store),
+		testPolicy(t), sent, guard, time.Hour)
+	now := time.Now()
+	uc.now = func() time.Time { return now }
+	guard.now = uc.now
+
+	user, err := entity.NewUser("alice", "old-password", 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := uc.Repo.Insert(user); err != nil {
+		t.Fatal(err)
+	}
+	session, _ := entity.NewUserToken("session", user.ID)
+	if err := uc.TokenRepo.Insert(session); err != nil {
+		t.Fatal(err)
+	}
+	return uc, user, sent, &now
+}
+
+func reloadUser(t *testing.T, uc *PasswordUseCase, id uint) *entity.User {
+	t.Helper()
+	user, err := uc.Repo.FindById(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func TestChangePassword(t *testing.T) {
+	uc, alice, _, _ := newTestPasswordUseCase(t)
+
+	err := uc.Change(alice.ID, dto.ChangePasswordRequest{CurrentPassword: "old-password", NewPassword: "qwertyuiop"})
+	if !hasFieldCode(err, "new_password", CodeWeakPassword) {
+		t.Fatalf("weak password: %v", err)
+	}
+	err = uc.Change(alice.ID, dto.ChangePasswordRequest{CurrentPassword: "wrong", NewPassword: "a much better one"})
+	if !hasFieldCode(err, "current_password", CodeWrongPassword) {
+		t.Fatalf("wrong current password: %v", err)
+	}
+	// wrong current passwords count towards the lockout
+	_ = uc.Change(alice.ID, dto.ChangePasswordRequest{CurrentPassword: "wrong", NewPassword: "a much better one"})
+	err = uc.Change(alice.ID, dto.ChangePasswordRequest{CurrentPassword: "old-password", NewPassword: "a much better one"})
+	wantRateLimited(t, err, CodeAccountLocked)
+
+	if err := uc.Guard.Unlock("alice"); err != nil {
+		t.Fatal(err)
+	}
+	if err := uc.Change(alice.ID, dto.ChangePasswordRequest{CurrentPassword: "old-password", NewPassword: "a much better one"}); err != nil {
+		t.Fatal(err)
+	}
+	if !reloadUser(t, uc, alice.ID).CheckPassword("a much better one") {
+		t.Fatal("the password did not change")
+	}
+	if _, err := uc.TokenRepo.FindByToken("session", nil); err == nil {
+		t.Fatal("the session survived the change")
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	uc, alice, sent, now := newTestPasswordUseCase(t)
+
+	// unknown users get the same answer, and nothing is sent
+	if err := uc.Forgot(dto.ForgotPasswordRequest{UserName: "nobody"}, "1.1.1.1"); err != nil {
+		t.Fatal(err)
+	}
+	if len(*sent) != 0 {
+		t.Fatalf("sent %+v", *sent)
+	}
+
+	if err := uc.Forgot(dto.ForgotPasswordRequest{UserName: "alice"}, "1.1.1.1"); err != nil {
+		t.Fatal(err)
+	}
+	first := sent.lastToken(t)
+	if err := uc.Forgot(dto.ForgotPasswordRequest{UserName: "alice"}, "1.1.1.1"); err != nil {
+		t.Fatal(err)
+	}
+	token := sent.lastToken(t)
+	if (*sent)[1].UserID != alice.ID || token == first {
+		t.Fatalf("sent %+v", *sent)
+	}
+
+	// a weak password does not use the token up
+	err := uc.Reset(dto.ResetPasswordRequest{Token: token, NewPassword: "12345678"})
+	if !hasFieldCode(err, "new_password", CodeWeakPassword) {
+		t.Fatalf("weak password: %v", err)
+	}
+	// only the latest token works
+	err = uc.Reset(dto.ResetPasswordRequest{Token: first, NewPassword: "a brand new password"})
+	if !hasFieldCode(err, "token", CodeInvalidResetToken) {
+		t.Fatalf("replaced token: %v", err)
+	}
+
+	if err := uc.Reset(dto.ResetPasswordRequest{Token: token, NewPassword: "a brand new password"}); err != nil {
+		t.Fatal(err)
+	}
+	if !reloadUser(t, uc, alice.ID).CheckPassword("a brand new password") {
+		t.Fatal("the password did not change")
+	}
+	if _, err := uc.TokenRepo.FindByToken("session", nil); err == nil {
+		t.Fatal("the session survived the reset")
+	}
+	err = uc.Reset(dto.ResetPasswordRequest{Token: token, NewPassword: "yet another password"})
+	if !hasFieldCode(err, "token", CodeInvalidResetToken) {
+		t.Fatalf("used token: %v", err)
+	}
+
+	// tokens expire
+	if err := uc.Forgot(dto.ForgotPasswordRequest{UserName: "alice"}, "1.1.1.1"); err != nil {
+		t.Fatal(err)
+	}
+	*now = now.Add(time.Hour)
+	err = uc.Reset(dto.ResetPasswordRequest{Token: sent.lastToken(t), NewPassword: "yet another password"})
+	if !hasFieldCode(err, "token", CodeInvalidResetToken) {
+		t.Fatalf("expired token: %v", err)
+	}
+}
+
+func TestResetChecksUserName(t *testing.T) {
+	uc, _, sent, _ := newTestPasswordUseCase(t)
+	user, err := entity.NewUser("alice.smith", "old-password", 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := uc.Repo.Insert(user); err != nil {
+		t.Fatal(err)
+	}
+	if err := uc.Forgot(dto.ForgotPasswordRequest{UserName: "alice.smith"}, "1.1.1.1"); err != nil {
+		t.Fatal(err)
+	}
+	token := sent.lastToken(t)
+
+	// the user name is only known once the token is read, and the token
+	// stays usable when it is rejected
+	err = uc.Reset(dto.ResetPasswordRequest{Token: token, NewPassword: "Alice.Smith"})
+	if !hasFieldCode(err, "new_password", CodeWeakPassword) {
+		t.Fatalf("user name as password: %v", err)
+	}
+	if err := uc.Reset(dto.ResetPasswordRequest{Token: token, NewPassword: "a brand new password"}); err != nil {
+		t.Fatal(err)
+	}
+}
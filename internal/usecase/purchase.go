@@ -1,27 +1,43 @@
 package usecase
 
 import (
-	"errors"
+	"bytes"
+	"encoding/json"
 
 	"money-tracker/internal/constants"
 	"money-tracker/internal/dto"
 	"money-tracker/internal/entity"
+	"money-tracker/internal/importer"
+	"money-tracker/internal/utils"
 	"strconv"
 	"strings"
 	"time"
 )
 
 type PurchaseUseCase struct {
-	Repo    entity.PurchaseRepository
-	TagRepo entity.TagRepository
-	CatRepo entity.CategoryRepository
+	Repo      entity.PurchaseRepository
+	TagRepo   entity.TagRepository
+	CatRepo   entity.CategoryRepository
+	Tx        entity.Transactor
+	Suggester *SuggestionUseCase
+	// Events publishes the purchase webhooks; nil publishes nothing
+	Events  *WebhookUseCase
+	Changes ChangeFeed
+	// Anomalies checks every added purchase in the background; nil checks
+	// nothing
+	Anomalies *AnomalyUseCase
 }
 
-func NewPurchaseUseCase(repo entity.PurchaseRepository, tag entity.TagRepository, cat entity.CategoryRepository) *PurchaseUseCase {
+func NewPurchaseUseCase(repo entity.PurchaseRepository, tag entity.TagRepository, cat entity.CategoryRepository, tx entity.Transactor, suggester *SuggestionUseCase, events *WebhookUseCase, changes ChangeFeed, anomalies *AnomalyUseCase) *PurchaseUseCase {
 	return &PurchaseUseCase{
-		Repo:    repo,
-		TagRepo: tag,
-		CatRepo: cat,
+		Repo:      repo,
+		TagRepo:   tag,
+		CatRepo:   cat,
+		Tx:        tx,
+		Suggester: suggester,
+		Events:    events,
+		Changes:   changes,
+		Anomalies: anomalies,
 	}
 }
 
@@ -32,56 +48,41 @@ func (uc *PurchaseUseCase) Add(input dto.AddPurchaseInput) (*entity.Purchase, er
 		input.StatusID = 1
 	}
 
-	category, err := uc.CatRepo.FindById(*input.CategoryId)
-	if err != nil || category == nil {
-		return nil, errors.New("category not found")
-	}
-
-	if input.SubCategoryId != nil {
-		category, err_c := uc.CatRepo.FindById(*input.SubCategoryId)
-		if err_c != nil || category == nil {
-			return nil, errors.New("sub category not found")
-		}
-	}
-
-	// create new category
+	// create new purchase
 	purchase, err := entity.NewPurchase(input.Amount, input.Date, input.CategoryId, input.StatusID)
 	if err != nil {
-		return nil, err
-	}
-
-	// category.Slug = input.Slug
-	// category.CoverId = coverId
-
-	// handle tag IDs if provided
-	if input.TagIDs != "" {
-		idsStr := strings.Split(input.TagIDs, ",")
-		for _, idStr := range idsStr {
-			idStr = strings.TrimSpace(idStr)
-			id, err := strconv.Atoi(idStr)
-			if err != nil {
-				return nil, errors.New("invalid tag id: " + idStr)
-			}
-
-			// Check tag existence
-			tag, err := uc.TagRepo.FindById(uint(id))
-			if err != nil || tag == nil {
-				return nil, errors.New("tag not found: " + strconv.Itoa(int(id)))
-			}
-		}
-		purchase.TagIDs = input.TagIDs
+		return nil, invalid(err)
 	}
 
 	purchase.SubCategoryId = input.SubCategoryId
+	purchase.TagIDs = input.TagIDs
+	purchase.Reason = input.Reason
 	purchase.Note = input.Note
 	purchase.Color = input.Color
 	purchase.Method = input.Method
 
+	if err := uc.validate(purchase); err != nil {
+		return nil, err
+	}
+
 	// insert into repo
-	res := uc.Repo.Insert(purchase)
+	res := uc.write(func(repos entity.Repositories) error {
+		if err := repos.Purchase.Insert(purchase); err != nil {
+			return err
+		}
+		return uc.publish(repos, EventPurchaseCreated, purchase)
+	})
 	if res != nil {
 		return nil, res
 	}
+	notifyChange(uc.Changes, EventPurchaseCreated, *purchase)
+
+	if uc.Suggester != nil {
+		uc.Suggester.Observe(nil, purchase)
+	}
+	if uc.Anomalies != nil {
+		uc.Anomalies.Queue(*purchase)
+	}
 
 	return purchase, nil
 }
@@ -172,20 +173,44 @@ func (uc *PurchaseUseCase) Get(input dto.PurchaseFindAll) ([]dto.PurchaseRespons
 
 // /-----------------------------------------------
 func (uc *PurchaseUseCase) Remove(id uint) error {
-	_, err := uc.Repo.FindById(id, []uint{constants.StatusActive})
+	purchase, err := uc.Repo.FindById(id, []uint{constants.StatusActive})
 	if err != nil {
-		return errors.New("purchase not found")
+		return ErrPurchaseNotFound
+	}
+
+	err = uc.write(func(repos entity.Repositories) error {
+		if err := repos.Purchase.Delete(id); err != nil {
+			return err
+		}
+		return uc.publish(repos, EventPurchaseDeleted, purchase)
+	})
+	if err != nil {
+		return err
+	}
+	notifyChange(uc.Changes, EventPurchaseDeleted, *purchase)
+
+	if uc.Suggester != nil {
+		uc.Suggester.Observe(purchase, nil)
 	}
 
-	return uc.Repo.Delete(id)
+	return nil
+}
+
+// ---------------------------------------------------
+func (uc *PurchaseUseCase) GetByID(id uint) (*entity.Purchase, error) {
+	return uc.Repo.FindById(id, []uint{constants.StatusActive})
 }
 
 // ---------------------------------------------------
 func (uc *PurchaseUseCase) Update(input dto.UpdatePurchaseInput) (*entity.Purchase, error) {
+	if input.Version == nil {
+		return nil, ErrVersionRequired
+	}
 	purchase, err := uc.Repo.FindById(input.ID, []uint{constants.StatusActive})
 	if err != nil {
-		return nil, errors.New("purchase not found")
+		return nil, ErrPurchaseNotFound
 	}
+	old := *purchase
 
 	if input.Reason != "" {
 		purchase.Reason = input.Reason
@@ -196,26 +221,14 @@ func (uc *PurchaseUseCase) Update(input dto.UpdatePurchaseInput) (*entity.Purcha
 	if input.CategoryId != nil {
 		_, err := uc.CatRepo.FindById(*input.CategoryId)
 		if err != nil {
-			return nil, errors.New("category not found")
+			return nil, InvalidField("category_id", "not_found", "category not found")
 		}
 		purchase.CategoryId = input.CategoryId
 	}
 
 	if input.TagIDs != "" {
-		idsStr := strings.Split(input.TagIDs, ",")
-		for _, idStr := range idsStr {
-			idStr = strings.TrimSpace(idStr)
-			id, err := strconv.Atoi(idStr)
-			if err != nil {
-				return nil, errors.New("invalid tag id: " + idStr)
-			}
-
-			// Check tag existence
-			tag, err := uc.TagRepo.FindById(uint(id))
-			if err != nil || tag == nil {
-				return nil, errors.New("tag not found: " + strconv.Itoa(int(id)))
-			}
-
+		if err := uc.checkTagIDs(input.TagIDs); err != nil {
+			return nil, err
 		}
 		purchase.TagIDs = input.TagIDs
 	}
@@ -223,7 +236,7 @@ func (uc *PurchaseUseCase) Update(input dto.UpdatePurchaseInput) (*entity.Purcha
 	if input.SubCategoryId != nil && *input.SubCategoryId > 0 {
 		_, err := uc.CatRepo.FindById(*input.SubCategoryId)
 		if err != nil {
-			return nil, errors.New("subcat not found")
+			return nil, InvalidField("sub_category_id", "not_found", "sub category not found")
 		}
 
 		purchase.SubCategoryId = input.SubCategoryId
@@ -234,15 +247,328 @@ func (uc *PurchaseUseCase) Update(input dto.UpdatePurchaseInput) (*entity.Purcha
 	purchase.Note = input.Note
 	purchase.Date = input.Date
 	purchase.Method = input.Method
+	if input.Version != nil {
+		purchase.Version = *input.Version
+	}
 
 	purchase.UpdatedAt = time.Now()
 
 	// Save changes
-	return uc.Repo.Update(purchase)
+	updated, err := uc.update(purchase)
+	if err != nil {
+		return nil, err
+	}
+
+	if uc.Suggester != nil {
+		uc.Suggester.Observe(&old, updated)
+	}
+
+	return updated, nil
+}
+
+// ---------------------------------------------------
+// Patch applies a JSON Merge Patch (RFC 7396) to a purchase: only the
+// members present in the patch change and null clears a member. The update
+// is conditional on the "version" member of the patch if it has one, else
+// on version (from If-Match); without either it returns ErrVersionRequired.
+// A patch cannot delete the purchase, status_id stays 1.
+func (uc *PurchaseUseCase) Patch(id uint, patch []byte, version *uint) (*entity.Purchase, error) {
+	purchase, err := uc.Repo.FindById(id, []uint{constants.StatusActive})
+	if err != nil {
+		return nil, ErrPurchaseNotFound
+	}
+	old := *purchase
+
+	date := purchase.Date
+	status := purchase.StatusID
+	current := purchase.Version
+	doc, err := json.Marshal(dto.PurchasePatchDocument{
+		CategoryId:    purchase.CategoryId,
+		SubCategoryId: purchase.SubCategoryId,
+		Reason:        purchase.Reason,
+		Date:          &date,
+		Note:          purchase.Note,
+		Color:         purchase.Color,
+		Method:        purchase.Method,
+		Amount:        purchase.Amount,
+		StatusID:      &status,
+		TagIDs:        purchase.TagIDs,
+		Details:       purchase.Details,
+		Version:       &current,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	patched, err := utils.MergePatch(doc, patch)
+	if err != nil {
+		return nil, Validation(err.Error()).Wrap(err)
+	}
+
+	var next dto.PurchasePatchDocument
+	dec := json.NewDecoder(bytes.NewReader(patched))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&next); err != nil {
+		return nil, Validation("invalid merge patch: " + err.Error()).Wrap(err)
+	}
+
+	if next.StatusID == nil {
+		return nil, InvalidField("status_id", "required", "status_id cannot be null")
+	}
+	// deleting also sets deleted_at and announces it, which is Remove's job
+	if *next.StatusID != constants.StatusActive {
+		return nil, InvalidField("status_id", "oneof", "status_id must be 1; use DELETE to delete a purchase")
+	}
+	var member struct {
+		Version *uint `json:"version"`
+	}
+	if err := json.Unmarshal(patch, &member); err != nil || (member.Version == nil && version == nil) {
+		return nil, ErrVersionRequired
+	}
+	if next.SubCategoryId != nil && *next.SubCategoryId == 0 {
+		next.SubCategoryId = nil
+	}
+
+	purchase.CategoryId = next.CategoryId
+	purchase.SubCategoryId = next.SubCategoryId
+	purchase.Reason = next.Reason
+	purchase.Date = time.Time{}
+	if next.Date != nil {
+		purchase.Date = *next.Date
+	}
+	purchase.Note = next.Note
+	purchase.Color = next.Color
+	purchase.Method = next.Method
+	purchase.Amount = next.Amount
+	purchase.StatusID = *next.StatusID
+	purchase.TagIDs = next.TagIDs
+	purchase.Details = next.Details
+
+	if member.Version != nil {
+		purchase.Version = *member.Version
+	} else {
+		purchase.Version = *version
+	}
+
+	if err := uc.validate(purchase); err != nil {
+		return nil, err
+	}
+
+	purchase.UpdatedAt = time.Now()
+
+	updated, err := uc.update(purchase)
+	if err != nil {
+		return nil, err
+	}
+
+	if uc.Suggester != nil {
+		uc.Suggester.Observe(&old, updated)
+	}
+
+	return updated, nil
+}
+
+// ---------------------------------------------------
+// Import stores the outgoing transactions of a bank statement as purchases
+// in a single transaction, so a failure imports nothing. Transactions whose
+// bank id was imported before are skipped, so importing an overlapping
+// statement again never creates duplicates. Deposits are not supported:
+// they are counted in SkippedIncome but not stored, the tracker has no
+// income records.
+func (uc *PurchaseUseCase) Import(input dto.ImportPurchasesInput, transactions []importer.Transaction) (*dto.ImportPurchasesResult, error) {
+	category, err := uc.CatRepo.FindById(*input.CategoryId)
+	if err != nil || category == nil {
+		return nil, InvalidField("category_id", "not_found", "category not found")
+	}
+
+	if input.SubCategoryId != nil {
+		subcat, err_c := uc.CatRepo.FindById(*input.SubCategoryId)
+		if err_c != nil || subcat == nil {
+			return nil, InvalidField("sub_category_id", "not_found", "sub category not found")
+		}
+	}
+
+	if input.TagIDs != "" {
+		if err := uc.checkTagIDs(input.TagIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	externalIDs := make([]string, 0, len(transactions))
+	for _, t := range transactions {
+		externalIDs = append(externalIDs, importExternalID(t))
+	}
+
+	var (
+		result    *dto.ImportPurchasesResult
+		purchases []*entity.Purchase
+	)
+	importAll := func(repos entity.Repositories) error {
+		result = &dto.ImportPurchasesResult{Total: len(transactions), IDs: []uint{}}
+		purchases = nil
+
+		existing, err := repos.Purchase.FindByExternalIDs(externalIDs)
+		if err != nil {
+			return err
+		}
+		imported := make(map[string]bool, len(existing))
+		for _, p := range existing {
+			imported[p.ExternalID] = true
+		}
+
+		for i, t := range transactions {
+			if imported[externalIDs[i]] {
+				result.Duplicates++
+				continue
+			}
+			if t.Amount >= 0 {
+				result.SkippedIncome++
+				continue
+			}
+
+			purchase, err := entity.NewPurchase(-t.Amount, t.Date, input.CategoryId, constants.StatusActive)
+			if err != nil {
+				return invalid(err)
+			}
+			purchase.SubCategoryId = input.SubCategoryId
+			purchase.TagIDs = input.TagIDs
+			purchase.Reason = t.Payee
+			purchase.Note = t.Memo
+			purchase.ExternalID = externalIDs[i]
+
+			if err := repos.Purchase.Insert(purchase); err != nil {
+				return err
+			}
+			if err := uc.publish(repos, EventPurchaseCreated, purchase); err != nil {
+				return err
+			}
+
+			imported[externalIDs[i]] = true
+			purchases = append(purchases, purchase)
+			result.Imported++
+			result.IDs = append(result.IDs, purchase.ID)
+		}
+		return nil
+	}
+
+	if uc.Tx == nil {
+		err = importAll(entity.Repositories{Purchase: uc.Repo})
+	} else {
+		err = uc.Tx.Transaction(importAll)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, purchase := range purchases {
+		notifyChange(uc.Changes, EventPurchaseCreated, *purchase)
+		if uc.Suggester != nil {
+			uc.Suggester.Observe(nil, purchase)
+		}
+		if uc.Anomalies != nil {
+			uc.Anomalies.Queue(*purchase)
+		}
+	}
+
+	return result, nil
 }
 
 //----------------------------------------
 
+// write runs fn with the purchase repository and the outbox. While events
+// are published it runs in a transaction, so the change and its events are
+// committed together.
+func (uc *PurchaseUseCase) write(fn func(repos entity.Repositories) error) error {
+	if uc.Events == nil || uc.Tx == nil {
+		return fn(entity.Repositories{Purchase: uc.Repo})
+	}
+	return uc.Tx.Transaction(fn)
+}
+
+func (uc *PurchaseUseCase) update(p *entity.Purchase) (*entity.Purchase, error) {
+	var updated *entity.Purchase
+	err := uc.write(func(repos entity.Repositories) error {
+		var err error
+		if updated, err = repos.Purchase.Update(p); err != nil {
+			return err
+		}
+		return uc.publish(repos, EventPurchaseUpdated, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	notifyChange(uc.Changes, EventPurchaseUpdated, *updated)
+	return updated, nil
+}
+
+// publish queues a purchase event in the outbox of repos.
+func (uc *PurchaseUseCase) publish(repos entity.Repositories, eventType string, p *entity.Purchase) error {
+	if uc.Events == nil || repos.Outbox == nil {
+		return nil
+	}
+	return uc.Events.Publish(repos.Outbox, eventType, p)
+}
+
+// validate checks a purchase before it is stored; Add and Patch share it.
+func (uc *PurchaseUseCase) validate(p *entity.Purchase) error {
+	if p.Amount == 0 {
+		return InvalidField("amount", "required", "amount is required")
+	}
+	if p.Date.IsZero() {
+		return InvalidField("date", "required", "date is required")
+	}
+	if p.StatusID != constants.StatusInactive && p.StatusID != constants.StatusActive {
+		return InvalidField("status_id", "oneof", "invalid status_id")
+	}
+
+	if p.CategoryId == nil {
+		return InvalidField("category_id", "required", "category is required")
+	}
+	category, err := uc.CatRepo.FindById(*p.CategoryId)
+	if err != nil || category == nil {
+		return InvalidField("category_id", "not_found", "category not found")
+	}
+
+	if p.SubCategoryId != nil {
+		subcat, err_c := uc.CatRepo.FindById(*p.SubCategoryId)
+		if err_c != nil || subcat == nil {
+			return InvalidField("sub_category_id", "not_found", "sub category not found")
+		}
+	}
+
+	if p.TagIDs != "" {
+		if err := uc.checkTagIDs(p.TagIDs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// bank transaction ids are only unique per account; the format is left out
+// so the same statement downloaded as OFX and as QFX is imported once
+func importExternalID(t importer.Transaction) string {
+	return t.Account + ":" + t.ID
+}
+
+func (uc *PurchaseUseCase) checkTagIDs(tagIDs string) error {
+	idsStr := strings.Split(tagIDs, ",")
+	for _, idStr := range idsStr {
+		idStr = strings.TrimSpace(idStr)
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			return InvalidField("tag_ids", "invalid", "invalid tag id: "+idStr)
+		}
+
+		// Check tag existence
+		tag, err := uc.TagRepo.FindById(uint(id))
+		if err != nil || tag == nil {
+			return InvalidField("tag_ids", "not_found", "tag not found: "+strconv.Itoa(int(id)))
+		}
+	}
+	return nil
+}
+
 func parseTagIDs(tagIDs string) []uint {
 	var ids []uint
 	for _, idStr := range strings.Split(tagIDs, ",") {
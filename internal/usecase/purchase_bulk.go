@@ -0,0 +1,316 @@
+package usecase
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"money-tracker/internal/constants"
+	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
+)
+
+const (
+	BulkSetCategory = "set_category"
+	BulkAddTags     = "add_tags"
+	BulkRemoveTags  = "remove_tags"
+	BulkSetStatus   = "set_status"
+	BulkDelete      = "delete"
+
+	maxBulkItems = 5000
+)
+
+// errBulkRollback rolls back an all-or-nothing batch after an item failed.
+var errBulkRollback = errors.New("bulk operation rolled back")
+
+// /----------------------------- batch create -----------------------------
+// BatchCreate inserts several purchases in one transaction. Each item is
+// validated like Add. With AllOrNothing a single failure rolls back the
+// whole batch, otherwise the valid items are kept.
+func (uc *PurchaseUseCase) BatchCreate(input dto.BatchCreatePurchaseInput) (*dto.BulkResult, error) {
+	if len(input.Items) > maxBulkItems {
+		return nil, InvalidField("items", "max", "too many items, the limit is "+strconv.Itoa(maxBulkItems))
+	}
+
+	purchases := make([]*entity.Purchase, len(input.Items))
+	result := &dto.BulkResult{Items: make([]dto.BulkItemResult, len(input.Items))}
+
+	err := uc.runBulk(len(input.Items), input.AllOrNothing, result, func(repos entity.Repositories, i int) (uint, error) {
+		item := input.Items[i]
+		if item.StatusID == 0 {
+			item.StatusID = constants.StatusActive
+		}
+
+		purchase, err := entity.NewPurchase(item.Amount, item.Date, item.CategoryId, item.StatusID)
+		if err != nil {
+			return 0, invalid(err)
+		}
+		purchase.SubCategoryId = item.SubCategoryId
+		purchase.TagIDs = item.TagIDs
+		purchase.Reason = item.Reason
+		purchase.Note = item.Note
+		purchase.Color = item.Color
+		purchase.Method = item.Method
+
+		if err := uc.validate(purchase); err != nil {
+			return 0, err
+		}
+		if err := repos.Purchase.Insert(purchase); err != nil {
+			return 0, err
+		}
+		if err := uc.publish(repos, EventPurchaseCreated, purchase); err != nil {
+			return 0, err
+		}
+		purchases[i] = purchase
+		return purchase.ID, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !result.RolledBack {
+		for i, p := range purchases {
+			if p == nil || !result.Items[i].OK {
+				continue
+			}
+			notifyChange(uc.Changes, EventPurchaseCreated, *p)
+			if uc.Suggester != nil {
+				uc.Suggester.Observe(nil, p)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// /----------------------------- bulk update -----------------------------
+// Bulk applies one action to the purchases selected by IDs or by Filter, in
+// one transaction.
+func (uc *PurchaseUseCase) Bulk(input dto.BulkPurchaseInput) (*dto.BulkResult, error) {
+	ids, err := uc.bulkIDs(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var apply func(p *entity.Purchase)
+	switch input.Action {
+	case BulkSetCategory:
+		if input.CategoryId == nil {
+			return nil, InvalidField("category_id", "required", "category_id is required")
+		}
+		if category, err := uc.CatRepo.FindById(*input.CategoryId); err != nil || category == nil {
+			return nil, InvalidField("category_id", "not_found", "category not found")
+		}
+		if input.SubCategoryId != nil {
+			if subcat, err := uc.CatRepo.FindById(*input.SubCategoryId); err != nil || subcat == nil {
+				return nil, InvalidField("sub_category_id", "not_found", "sub category not found")
+			}
+		}
+		apply = func(p *entity.Purchase) {
+			p.CategoryId = input.CategoryId
+			if input.SubCategoryId != nil {
+				p.SubCategoryId = input.SubCategoryId
+			}
+		}
+
+	case BulkAddTags, BulkRemoveTags:
+		if len(input.TagIDs) == 0 {
+			return nil, InvalidField("tag_ids", "required", "tag_ids is required")
+		}
+		if input.Action == BulkAddTags {
+			if err := uc.checkTagIDs(joinTagIDs(input.TagIDs)); err != nil {
+				return nil, err
+			}
+		}
+		apply = func(p *entity.Purchase) {
+			p.TagIDs = changeTagIDs(p.TagIDs, input.TagIDs, input.Action == BulkAddTags)
+		}
+
+	case BulkSetStatus:
+		// only active purchases are selected, so the one status they can
+		// move to is inactive, which is what a delete does
+		if input.StatusID == nil || *input.StatusID != constants.StatusInactive {
+			return nil, InvalidField("status_id", "oneof", "status_id must be 0; only active purchases are changed")
+		}
+
+	case BulkDelete:
+
+	default:
+		return nil, InvalidField("action", "oneof", "unknown action: "+input.Action)
+	}
+
+	eventType := EventPurchaseUpdated
+	if apply == nil {
+		eventType = EventPurchaseDeleted
+	}
+	changed := make([]*entity.Purchase, len(ids))
+
+	result := &dto.BulkResult{Items: make([]dto.BulkItemResult, len(ids))}
+	err = uc.runBulk(len(ids), input.AllOrNothing, result, func(repos entity.Repositories, i int) (uint, error) {
+		// deleted purchases are inactive; they are reported as not found
+		purchase, err := repos.Purchase.FindById(ids[i], []uint{constants.StatusActive})
+		if err != nil {
+			return ids[i], ErrPurchaseNotFound
+		}
+
+		if apply == nil {
+			if err := repos.Purchase.Delete(ids[i]); err != nil {
+				return ids[i], err
+			}
+			changed[i] = purchase
+			return ids[i], uc.publish(repos, EventPurchaseDeleted, purchase)
+		}
+
+		apply(purchase)
+		purchase.UpdatedAt = time.Now()
+		updated, err := repos.Purchase.Update(purchase)
+		if err != nil {
+			return ids[i], err
+		}
+		changed[i] = updated
+		return ids[i], uc.publish(repos, EventPurchaseUpdated, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !result.RolledBack {
+		for i, p := range changed {
+			if p != nil && result.Items[i].OK {
+				notifyChange(uc.Changes, eventType, *p)
+			}
+		}
+	}
+	if uc.Suggester != nil && result.Succeeded > 0 && !result.RolledBack {
+		uc.Suggester.Reset()
+	}
+
+	return result, nil
+}
+
+// runBulk runs fn for every item in one transaction and records the outcome
+// of each item in result. Without allOrNothing every item runs in its own
+// savepoint, so a failing item is undone without aborting the others.
+func (uc *PurchaseUseCase) runBulk(count int, allOrNothing bool, result *dto.BulkResult, fn func(repos entity.Repositories, i int) (uint, error)) error {
+	ran := 0
+	err := uc.Tx.Transaction(func(repos entity.Repositories) error {
+		for i := 0; i < count; i++ {
+			var id uint
+			var err error
+			if allOrNothing {
+				id, err = fn(repos, i)
+			} else {
+				err = repos.Tx.Transaction(func(item entity.Repositories) error {
+					var e error
+					id, e = fn(item, i)
+					return e
+				})
+			}
+			ran++
+
+			result.Items[i] = dto.BulkItemResult{Index: i, ID: id, OK: err == nil}
+			if err != nil {
+				result.Items[i].Error = err.Error()
+				var e *Error
+				if errors.As(err, &e) {
+					result.Items[i].Code = e.Code
+				}
+				result.Failed++
+				if allOrNothing {
+					return errBulkRollback
+				}
+				continue
+			}
+			result.Succeeded++
+		}
+		return nil
+	})
+	if !errors.Is(err, errBulkRollback) {
+		return err
+	}
+
+	result.RolledBack = true
+	result.Succeeded = 0
+	for i := range result.Items {
+		switch {
+		case i >= ran:
+			result.Items[i] = dto.BulkItemResult{Index: i, Error: "not run"}
+		case result.Items[i].OK:
+			result.Items[i].OK = false
+			result.Items[i].Error = "rolled back"
+		}
+	}
+	return nil
+}
+
+func (uc *PurchaseUseCase) bulkIDs(input dto.BulkPurchaseInput) ([]uint, error) {
+	if len(input.IDs) > 0 {
+		if len(input.IDs) > maxBulkItems {
+			return nil, InvalidField("ids", "max", "too many ids, the limit is "+strconv.Itoa(maxBulkItems))
+		}
+		return input.IDs, nil
+	}
+	if input.Filter == nil {
+		return nil, Validation("ids or filter is required",
+			dto.FieldError{Field: "ids", Code: "required_without", Message: "ids or filter is required"},
+			dto.FieldError{Field: "filter", Code: "required_without", Message: "ids or filter is required"})
+	}
+
+	filter := *input.Filter
+	filter.OrderBy = "id"
+	filter.Sort = "ASC"
+	filter.Start = 0
+	filter.Limit = maxBulkItems + 1
+
+	purchases, _, err := uc.Repo.FindAll(filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(purchases) > maxBulkItems {
+		return nil, InvalidField("filter", "max", "filter matches more than "+strconv.Itoa(maxBulkItems)+" purchases")
+	}
+
+	ids := make([]uint, 0, len(purchases))
+	for _, p := range purchases {
+		ids = append(ids, p.ID)
+	}
+	return ids, nil
+}
+
+func joinTagIDs(ids []uint) string {
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, strconv.Itoa(int(id)))
+	}
+	return strings.Join(parts, ",")
+}
+
+// changeTagIDs adds or removes ids from a comma separated tag list,
+// keeping the order of the existing tags.
+func changeTagIDs(tagIDs string, ids []uint, add bool) string {
+	current := parseTagIDs(tagIDs)
+	change := map[uint]bool{}
+	for _, id := range ids {
+		change[id] = true
+	}
+
+	var next []uint
+	present := map[uint]bool{}
+	for _, id := range current {
+		if present[id] || (!add && change[id]) {
+			continue
+		}
+		present[id] = true
+		next = append(next, id)
+	}
+	if add {
+		for _, id := range ids {
+			if !present[id] {
+				present[id] = true
+				next = append(next, id)
+			}
+		}
+	}
+	return joinTagIDs(next)
+}
@@ -0,0 +1,244 @@
+package usecase
+
+import (
+	"errors"
+	"money-tracker/internal/constants"
+	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
+	"money-tracker/internal/importer"
+	"money-tracker/internal/repository/memory"
+	"testing"
+	"time"
+)
+
+func newTestPurchaseUseCase(t *testing.T) (*PurchaseUseCase, *entity.Category) {
+	t.Helper()
+	store := memory.NewStore()
+	uc := NewPurchaseUseCase(memory.NewPurchaseRepo(store), memory.NewTagRepo(store), memory.NewCategoryRepo(store), memory.NewTransactor(store), nil, nil, nil, nil)
+
+	food, err := entity.NewCategory("Food", "food", 1, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := uc.CatRepo.Insert(food); err != nil {
+		t.Fatal(err)
+	}
+	return uc, food
+}
+
+func countPurchases(t *testing.T, uc *PurchaseUseCase) int {
+	t.Helper()
+	_, count, err := uc.Repo.FindAll(dto.PurchaseFindAll{Limit: 10, OrderBy: "id", Sort: "ASC"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestPurchaseBatchCreate(t *testing.T) {
+	missing := uint(999)
+
+	for _, allOrNothing := range []bool{true, false} {
+		uc, food := newTestPurchaseUseCase(t)
+		result, err := uc.BatchCreate(dto.BatchCreatePurchaseInput{
+			AllOrNothing: allOrNothing,
+			Items: []dto.AddPurchaseInput{
+				{CategoryId: &food.ID, Amount: 100, Date: time.Now()},
+				{CategoryId: &missing, Amount: 200, Date: time.Now()},
+				{CategoryId: &food.ID, Amount: 300, Date: time.Now()},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if allOrNothing {
+			if !result.RolledBack || result.Succeeded != 0 || countPurchases(t, uc) != 0 {
+				t.Fatalf("all or nothing: result %+v, %d stored", result, countPurchases(t, uc))
+			}
+			continue
+		}
+		if result.RolledBack || result.Succeeded != 2 || result.Failed != 1 || countPurchases(t, uc) != 2 {
+			t.Fatalf("partial: result %+v, %d stored", result, countPurchases(t, uc))
+		}
+	}
+}
+
+func TestPurchaseUpdateVersionConflict(t *testing.T) {
+	uc, food := newTestPurchaseUseCase(t)
+	p, err := uc.Add(dto.AddPurchaseInput{CategoryId: &food.ID, Amount: 100, Date: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	version := p.Version
+	_, err = uc.Update(dto.UpdatePurchaseInput{ID: p.ID, Amount: 150, Date: p.Date, Version: &version})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a second update from the same read is stale
+	_, err = uc.Update(dto.UpdatePurchaseInput{ID: p.ID, Amount: 175, Date: p.Date, Version: &version})
+	if !errors.Is(err, entity.ErrVersionConflict) {
+		t.Fatalf("stale update returned %v, want a version conflict", err)
+	}
+
+	// an update must say which version it was based on
+	if _, err := uc.Update(dto.UpdatePurchaseInput{ID: p.ID, Amount: 200, Date: p.Date}); err != ErrVersionRequired {
+		t.Fatalf("update without a version returned %v", err)
+	}
+	if _, err := uc.Patch(p.ID, []byte(`{"amount": 200}`), nil); err != ErrVersionRequired {
+		t.Fatalf("patch without a version returned %v", err)
+	}
+	if _, err := uc.Patch(p.ID, []byte(`{"amount": 200, "version": 2}`), nil); err != nil {
+		t.Fatalf("patch with a version returned %v", err)
+	}
+
+	// the version member of the patch wins over If-Match
+	stale := uint(1)
+	if _, err := uc.Patch(p.ID, []byte(`{"amount": 250, "version": 3}`), &stale); err != nil {
+		t.Fatalf("patch with the current version and a stale If-Match returned %v", err)
+	}
+	current := uint(4)
+	if _, err := uc.Patch(p.ID, []byte(`{"amount": 300, "version": 1}`), &current); !errors.Is(err, entity.ErrVersionConflict) {
+		t.Fatalf("patch with a stale version and a current If-Match returned %v", err)
+	}
+	if _, err := uc.Patch(p.ID, []byte(`{"amount": 300}`), &current); err != nil {
+		t.Fatalf("patch with If-Match only returned %v", err)
+	}
+}
+
+func TestPurchasePatch(t *testing.T) {
+	uc, food := newTestPurchaseUseCase(t)
+	p, err := uc.Add(dto.AddPurchaseInput{CategoryId: &food.ID, SubCategoryId: &food.ID, Amount: 100, Date: time.Now(), Reason: "latte", Note: "oat milk"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// null clears a member, the members not in the patch stay
+	patched, err := uc.Patch(p.ID, []byte(`{"sub_category_id": null, "note": null, "amount": 120, "version": 1}`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if patched.SubCategoryId != nil || patched.Note != "" || patched.Amount != 120 || patched.Reason != "latte" || *patched.CategoryId != food.ID {
+		t.Fatalf("patched %+v", patched)
+	}
+	stored, err := uc.Repo.FindById(p.ID, []uint{constants.StatusActive})
+	if err != nil || stored.SubCategoryId != nil || stored.Version != 2 {
+		t.Fatalf("stored %+v, %v", stored, err)
+	}
+
+	// a required member cannot be cleared, unknown members are refused
+	if _, err := uc.Patch(p.ID, []byte(`{"category_id": null, "version": 2}`), nil); !hasFieldCode(err, "category_id", "required") {
+		t.Fatalf("null category_id = %v", err)
+	}
+	if _, err := uc.Patch(p.ID, []byte(`{"colour": "red", "version": 2}`), nil); err == nil {
+		t.Fatal("unknown member accepted")
+	}
+	if _, err := uc.Patch(p.ID+100, []byte(`{"amount": 1, "version": 2}`), nil); err != ErrPurchaseNotFound {
+		t.Fatalf("missing purchase = %v", err)
+	}
+}
+
+func TestPurchasePatchKeepsStatus(t *testing.T) {
+	uc, food := newTestPurchaseUseCase(t)
+	p, err := uc.Add(dto.AddPurchaseInput{CategoryId: &food.ID, Amount: 100, Date: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, patch := range []string{`{"status_id": 0, "version": 1}`, `{"status_id": 2, "version": 1}`} {
+		if _, err := uc.Patch(p.ID, []byte(patch), nil); !hasFieldCode(err, "status_id", "oneof") {
+			t.Fatalf("Patch(%s) = %v", patch, err)
+		}
+	}
+	if _, err := uc.Patch(p.ID, []byte(`{"status_id": null, "version": 1}`), nil); !hasFieldCode(err, "status_id", "required") {
+		t.Fatalf("null status_id = %v", err)
+	}
+	if n := countPurchases(t, uc); n != 1 {
+		t.Fatalf("%d active purchases after the refused patches", n)
+	}
+}
+
+func TestPurchaseImport(t *testing.T) {
+	uc, food := newTestPurchaseUseCase(t)
+	statement := []importer.Transaction{
+		{ID: "T1", Account: "9876", Date: time.Now(), Amount: -4_590, Payee: "Market"},
+		{ID: "T2", Account: "9876", Date: time.Now(), Amount: 150_000, Payee: "Salary"},
+	}
+
+	result, err := uc.Import(dto.ImportPurchasesInput{Format: "ofx", CategoryId: &food.ID}, statement)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Imported != 1 || result.SkippedIncome != 1 || result.Duplicates != 0 {
+		t.Fatalf("first import = %+v", result)
+	}
+
+	// the same statement downloaded as QFX is not imported twice
+	result, err = uc.Import(dto.ImportPurchasesInput{Format: "qfx", CategoryId: &food.ID}, statement)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Imported != 0 || result.Duplicates != 1 {
+		t.Fatalf("second import = %+v", result)
+	}
+	if n := countPurchases(t, uc); n != 1 {
+		t.Fatalf("%d purchases", n)
+	}
+}
+
+func TestPurchaseBulkSkipsDeleted(t *testing.T) {
+	uc, food := newTestPurchaseUseCase(t)
+	kept, err := uc.Add(dto.AddPurchaseInput{CategoryId: &food.ID, Amount: 100, Date: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gone, err := uc.Add(dto.AddPurchaseInput{CategoryId: &food.ID, Amount: 200, Date: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := uc.Remove(gone.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := uc.Bulk(dto.BulkPurchaseInput{IDs: []uint{kept.ID, gone.ID}, Action: BulkDelete})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Succeeded != 1 || !result.Items[0].OK || result.Items[1].OK || result.Items[1].Code != CodePurchaseNotFound {
+		t.Fatalf("bulk = %+v", result)
+	}
+	if n := countPurchases(t, uc); n != 0 {
+		t.Fatalf("%d active purchases after the bulk delete", n)
+	}
+}
+
+func TestPurchaseBulkSetStatusDeletes(t *testing.T) {
+	uc, food := newTestPurchaseUseCase(t)
+	feed := &recordedFeed{}
+	uc.Changes = feed
+	p, err := uc.Add(dto.AddPurchaseInput{CategoryId: &food.ID, Amount: 100, Date: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	active, inactive := uint(constants.StatusActive), uint(constants.StatusInactive)
+	_, err = uc.Bulk(dto.BulkPurchaseInput{IDs: []uint{p.ID}, Action: BulkSetStatus, StatusID: &active})
+	if !hasFieldCode(err, "status_id", "oneof") {
+		t.Fatalf("set_status 1 = %v", err)
+	}
+
+	*feed = nil
+	result, err := uc.Bulk(dto.BulkPurchaseInput{IDs: []uint{p.ID}, Action: BulkSetStatus, StatusID: &inactive})
+	if err != nil || result.Succeeded != 1 {
+		t.Fatalf("set_status 0 = %+v, %v", result, err)
+	}
+	gone, err := uc.Repo.FindById(p.ID, []uint{constants.StatusInactive})
+	if err != nil || gone.DeletedAt.IsZero() {
+		t.Fatalf("after set_status 0 = %+v, %v", gone, err)
+	}
+	if len(*feed) != 1 || (*feed)[0] != EventPurchaseDeleted {
+		t.Fatalf("published %v", *feed)
+	}
+}
@@ -0,0 +1,328 @@
+package usecase
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"money-tracker/internal/constants"
+	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
+)
+
+const (
+	suggestionDefaultLimit = 5
+	suggestionTrainBatch   = 500
+)
+
+// SuggestionUseCase suggests categories and tags for a draft purchase using
+// naive Bayes models trained on the purchase history. The models are built
+// lazily on first use and then kept up to date by PurchaseUseCase.
+type SuggestionUseCase struct {
+	Repo    entity.PurchaseRepository
+	TagRepo entity.TagRepository
+	CatRepo entity.CategoryRepository
+
+	mu         sync.RWMutex
+	trained    bool
+	categories *naiveBayes
+	tags       *naiveBayes
+	// generation counts the changes the models missed while not trained
+	generation int
+
+	// trainMu lets one train run at a time, without holding mu
+	trainMu sync.Mutex
+}
+
+func NewSuggestionUseCase(repo entity.PurchaseRepository, tag entity.TagRepository, cat entity.CategoryRepository) *SuggestionUseCase {
+	return &SuggestionUseCase{
+		Repo:       repo,
+		TagRepo:    tag,
+		CatRepo:    cat,
+		categories: newNaiveBayes(),
+		tags:       newNaiveBayes(),
+	}
+}
+
+// /----------------------- suggest -----------------------------
+func (uc *SuggestionUseCase) Suggest(input dto.SuggestPurchaseInput) (*dto.PurchaseSuggestionResponse, error) {
+	if err := uc.train(); err != nil {
+		return nil, err
+	}
+
+	if input.Limit <= 0 {
+		input.Limit = suggestionDefaultLimit
+	}
+
+	features := purchaseFeatures(input.Reason, input.Note, input.Amount)
+
+	uc.mu.RLock()
+	catScores := uc.categories.predict(features)
+	tagScores := uc.tags.predict(features)
+	uc.mu.RUnlock()
+
+	response := &dto.PurchaseSuggestionResponse{
+		Categories: []dto.SuggestedCategory{},
+		Tags:       []dto.SuggestedTag{},
+	}
+
+	// labels may point to rows that were removed since they were learned
+	for _, s := range catScores {
+		if len(response.Categories) >= input.Limit {
+			break
+		}
+		category, err := uc.CatRepo.FindById(s.label)
+		if err != nil || category == nil {
+			continue
+		}
+		response.Categories = append(response.Categories, dto.SuggestedCategory{
+			ID:    category.ID,
+			Title: category.Title,
+			Slug:  category.Slug,
+			Color: category.Color,
+			Score: s.score,
+		})
+	}
+
+	for _, s := range tagScores {
+		if len(response.Tags) >= input.Limit {
+			break
+		}
+		tag, err := uc.TagRepo.FindById(s.label)
+		if err != nil || tag == nil {
+			continue
+		}
+		response.Tags = append(response.Tags, dto.SuggestedTag{
+			ID:    tag.ID,
+			Title: tag.Title,
+			Score: s.score,
+		})
+	}
+
+	return response, nil
+}
+
+// Observe updates the models after a purchase was created, updated or
+// removed. old is nil for a new purchase and next is nil for a removed one.
+// Until the models are trained there is nothing to update: training reads
+// the current state of the purchases anyway.
+func (uc *SuggestionUseCase) Observe(old *entity.Purchase, next *entity.Purchase) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	if !uc.trained {
+		uc.generation++
+		return
+	}
+	if old != nil && old.StatusID == constants.StatusActive {
+		learnPurchase(uc.categories, uc.tags, old, -1)
+	}
+	if next != nil && next.StatusID == constants.StatusActive {
+		learnPurchase(uc.categories, uc.tags, next, 1)
+	}
+}
+
+// Reset drops the models after a bulk change of the purchases; they are
+// trained again on next use.
+func (uc *SuggestionUseCase) Reset() {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	uc.trained = false
+	uc.generation++
+	uc.categories, uc.tags = newNaiveBayes(), newNaiveBayes()
+}
+
+// train builds the models from all active purchases, once. It reads the
+// purchases without holding mu, so Suggest and Observe go on meanwhile.
+func (uc *SuggestionUseCase) train() error {
+	uc.mu.RLock()
+	trained := uc.trained
+	uc.mu.RUnlock()
+	if trained {
+		return nil
+	}
+
+	uc.trainMu.Lock()
+	defer uc.trainMu.Unlock()
+	uc.mu.RLock()
+	trained, generation := uc.trained, uc.generation
+	uc.mu.RUnlock()
+	if trained {
+		return nil
+	}
+
+	categories, tags := newNaiveBayes(), newNaiveBayes()
+	for start := 0; ; start += suggestionTrainBatch {
+		purchases, _, err := uc.Repo.FindAll(dto.PurchaseFindAll{
+			Start:   start,
+			Limit:   suggestionTrainBatch,
+			OrderBy: "id",
+			Sort:    "ASC",
+		})
+		if err != nil {
+			return err
+		}
+
+		for i := range purchases {
+			learnPurchase(categories, tags, &purchases[i], 1)
+		}
+
+		if len(purchases) < suggestionTrainBatch {
+			break
+		}
+	}
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	uc.categories, uc.tags = categories, tags
+	// a purchase that changed during the read may be missing from the
+	// models; they are used as they are and trained again on next use
+	uc.trained = uc.generation == generation
+	return nil
+}
+
+func learnPurchase(categories *naiveBayes, tags *naiveBayes, p *entity.Purchase, weight int) {
+	features := purchaseFeatures(p.Reason, p.Note, p.Amount)
+
+	if p.CategoryId != nil && *p.CategoryId > 0 {
+		categories.learn([]uint{*p.CategoryId}, features, weight)
+	}
+	tags.learn(parseTagIDs(p.TagIDs), features, weight)
+}
+
+// purchaseFeatures turns the free text of a purchase into lower-cased word
+// tokens and adds a token for the order of magnitude of the amount.
+func purchaseFeatures(reason string, note string, amount int64) []string {
+	var features []string
+
+	split := func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}
+	for _, word := range strings.FieldsFunc(strings.ToLower(reason+" "+note), split) {
+		if len([]rune(word)) < 2 {
+			continue
+		}
+		features = append(features, word)
+	}
+
+	if amount != 0 {
+		// half-decade buckets: 1-3, 3-10, 10-31, 31-100, ...
+		bucket := int(math.Floor(math.Log10(math.Abs(float64(amount))) * 2))
+		features = append(features, "#amount:"+strconv.Itoa(bucket))
+	}
+
+	return features
+}
+
+// /----------------------- naive bayes -----------------------------
+
+// naiveBayes is a multinomial naive Bayes classifier with Laplace smoothing.
+// A document may belong to several labels (tags), in which case it counts
+// once towards each of them.
+type naiveBayes struct {
+	documents int
+	labelDocs map[uint]int
+	tokens    map[uint]map[string]int
+	totals    map[uint]int
+	vocab     map[string]int
+}
+
+type labelScore struct {
+	label uint
+	score float64
+}
+
+func newNaiveBayes() *naiveBayes {
+	return &naiveBayes{
+		labelDocs: make(map[uint]int),
+		tokens:    make(map[uint]map[string]int),
+		totals:    make(map[uint]int),
+		vocab:     make(map[string]int),
+	}
+}
+
+// learn adds (weight 1) or removes (weight -1) a document.
+func (nb *naiveBayes) learn(labels []uint, features []string, weight int) {
+	if len(labels) == 0 {
+		return
+	}
+
+	nb.documents += weight
+	for _, f := range features {
+		nb.vocab[f] += weight
+		if nb.vocab[f] <= 0 {
+			delete(nb.vocab, f)
+		}
+	}
+
+	for _, label := range labels {
+		nb.labelDocs[label] += weight
+		if nb.labelDocs[label] <= 0 {
+			delete(nb.labelDocs, label)
+			delete(nb.tokens, label)
+			delete(nb.totals, label)
+			continue
+		}
+
+		counts := nb.tokens[label]
+		if counts == nil {
+			counts = make(map[string]int)
+			nb.tokens[label] = counts
+		}
+		for _, f := range features {
+			counts[f] += weight
+			if counts[f] <= 0 {
+				delete(counts, f)
+			}
+		}
+		nb.totals[label] += weight * len(features)
+	}
+}
+
+// predict returns every known label with its posterior probability, best
+// first.
+func (nb *naiveBayes) predict(features []string) []labelScore {
+	if nb.documents <= 0 || len(nb.labelDocs) == 0 {
+		return nil
+	}
+
+	vocabSize := float64(len(nb.vocab) + 1)
+	scores := make([]labelScore, 0, len(nb.labelDocs))
+	best := math.Inf(-1)
+
+	for label, docs := range nb.labelDocs {
+		logP := math.Log(float64(docs) / float64(nb.documents))
+		counts := nb.tokens[label]
+		total := float64(nb.totals[label])
+		for _, f := range features {
+			logP += math.Log((float64(counts[f]) + 1) / (total + vocabSize))
+		}
+		scores = append(scores, labelScore{label: label, score: logP})
+		if logP > best {
+			best = logP
+		}
+	}
+
+	// normalise the log scores into probabilities
+	var sum float64
+	for i := range scores {
+		scores[i].score = math.Exp(scores[i].score - best)
+		sum += scores[i].score
+	}
+	for i := range scores {
+		scores[i].score /= sum
+	}
+
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].score == scores[j].score {
+			return scores[i].label < scores[j].label
+		}
+		return scores[i].score > scores[j].score
+	})
+
+	return scores
+}
@@ -0,0 +1,176 @@
+package usecase
+
+import (
+	"fmt"
+	"money-tracker/internal/constants"
+	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
+	"money-tracker/internal/repository/memory"
+	"testing"
+	"time"
+)
+
+type suggestionFixture struct {
+	uc                *SuggestionUseCase
+	food, rent, books *entity.Category
+	coffee            *entity.Tag
+	purchases         entity.PurchaseRepository
+}
+
+func newSuggestionFixture(t *testing.T) *suggestionFixture {
+	t.Helper()
+	store := memory.NewStore()
+	f := &suggestionFixture{purchases: memory.NewPurchaseRepo(store)}
+	f.uc = NewSuggestionUseCase(f.purchases, memory.NewTagRepo(store), memory.NewCategoryRepo(store))
+
+	category := func(title string, slug string) *entity.Category {
+		c, err := entity.NewCategory(title, slug, constants.StatusActive, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := f.uc.CatRepo.Insert(c); err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	f.food, f.rent, f.books = category("Food", "food"), category("Rent", "rent"), category("Books", "books")
+	coffee, _ := entity.NewTag("coffee", constants.StatusActive)
+	if err := f.uc.TagRepo.Insert(coffee); err != nil {
+		t.Fatal(err)
+	}
+	f.coffee = coffee
+	return f
+}
+
+func (f *suggestionFixture) purchase(t *testing.T, category *entity.Category, amount int64, reason string, tagIDs string) *entity.Purchase {
+	t.Helper()
+	p, err := entity.NewPurchase(amount, time.Now(), &category.ID, constants.StatusActive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Reason = reason
+	p.TagIDs = tagIDs
+	if err := f.purchases.Insert(p); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func (f *suggestionFixture) suggest(t *testing.T, reason string, amount int64) *dto.PurchaseSuggestionResponse {
+	t.Helper()
+	got, err := f.uc.Suggest(dto.SuggestPurchaseInput{Reason: reason, Amount: amount})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+func TestSuggestEmptyModel(t *testing.T) {
+	f := newSuggestionFixture(t)
+	got := f.suggest(t, "latte", 450)
+	if got.Categories == nil || got.Tags == nil || len(got.Categories) != 0 || len(got.Tags) != 0 {
+		t.Fatalf("empty history suggested %+v", got)
+	}
+}
+
+func TestSuggestRanksByHistory(t *testing.T) {
+	f := newSuggestionFixture(t)
+	tag := fmt.Sprint(f.coffee.ID)
+	for i := 0; i < 3; i++ {
+		f.purchase(t, f.food, 450, "Latte at the corner cafe", tag)
+		f.purchase(t, f.rent, 90_000, "Monthly rent", "")
+	}
+	f.purchase(t, f.food, 3_000, "Groceries", "")
+
+	got := f.suggest(t, "latte", 500)
+	if len(got.Categories) != 2 || got.Categories[0].ID != f.food.ID || got.Categories[1].ID != f.rent.ID {
+		t.Fatalf("categories %+v", got.Categories)
+	}
+	if got.Categories[0].Score <= got.Categories[1].Score || got.Categories[0].Score+got.Categories[1].Score < 0.999 {
+		t.Fatalf("scores %+v", got.Categories)
+	}
+	if len(got.Tags) != 1 || got.Tags[0].ID != f.coffee.ID {
+		t.Fatalf("tags %+v", got.Tags)
+	}
+
+	got = f.suggest(t, "rent", 95_000)
+	if got.Categories[0].ID != f.rent.ID {
+		t.Fatalf("rent suggested %+v", got.Categories)
+	}
+}
+
+func TestSuggestObserveAndReset(t *testing.T) {
+	f := newSuggestionFixture(t)
+	f.purchase(t, f.food, 450, "Latte", "")
+	if got := f.suggest(t, "novel", 0); len(got.Categories) != 1 {
+		t.Fatalf("before Observe %+v", got.Categories)
+	}
+
+	// the model is trained; only Observe tells it about new purchases
+	book := f.purchase(t, f.books, 2_000, "Novel", "")
+	f.uc.Observe(nil, book)
+	if got := f.suggest(t, "novel", 0); got.Categories[0].ID != f.books.ID {
+		t.Fatalf("after a new purchase %+v", got.Categories)
+	}
+	if f.uc.categories.labelDocs[f.books.ID] != 1 || f.uc.categories.documents != 2 {
+		t.Fatalf("counts %+v", f.uc.categories)
+	}
+
+	// moving the purchase to another category moves its counts
+	moved := *book
+	moved.CategoryId = &f.rent.ID
+	f.uc.Observe(book, &moved)
+	if _, ok := f.uc.categories.labelDocs[f.books.ID]; ok || f.uc.categories.labelDocs[f.rent.ID] != 1 {
+		t.Fatalf("counts after the move %+v", f.uc.categories.labelDocs)
+	}
+	f.uc.Observe(&moved, nil)
+	if f.uc.categories.documents != 1 || len(f.uc.categories.labelDocs) != 1 {
+		t.Fatalf("counts after the removal %+v", f.uc.categories)
+	}
+
+	// Reset drops the models, the next Suggest trains them from the
+	// purchases again, the book included
+	f.uc.Reset()
+	if f.uc.trained || f.uc.categories.documents != 0 {
+		t.Fatal("Reset kept the models")
+	}
+	if got := f.suggest(t, "novel", 0); got.Categories[0].ID != f.books.ID || !f.uc.trained {
+		t.Fatalf("after Reset %+v", got.Categories)
+	}
+}
+
+// observingRepo calls observe during the first read, like a purchase saved
+// while the models are trained.
+type observingRepo struct {
+	entity.PurchaseRepository
+	observe func()
+}
+
+func (r *observingRepo) FindAll(input dto.PurchaseFindAll) ([]entity.Purchase, int, error) {
+	if r.observe != nil {
+		r.observe()
+		r.observe = nil
+	}
+	return r.PurchaseRepository.FindAll(input)
+}
+
+func TestSuggestTrainsWithoutTheLock(t *testing.T) {
+	f := newSuggestionFixture(t)
+	f.purchase(t, f.food, 450, "Latte", "")
+	repo := &observingRepo{PurchaseRepository: f.purchases}
+	f.uc.Repo = repo
+	repo.observe = func() {
+		// would deadlock if train held the lock while reading
+		f.uc.Observe(nil, f.purchase(t, f.books, 2_000, "Novel", ""))
+	}
+
+	if got := f.suggest(t, "latte", 0); got.Categories[0].ID != f.food.ID {
+		t.Fatalf("suggested %+v", got.Categories)
+	}
+	if f.uc.trained {
+		t.Fatal("models that may miss a change were kept as trained")
+	}
+	if got := f.suggest(t, "novel", 0); got.Categories[0].ID != f.books.ID || !f.uc.trained {
+		t.Fatalf("retrained %+v", got.Categories)
+	}
+}
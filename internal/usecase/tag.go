@@ -1,7 +1,6 @@
 package usecase
 
 import (
-	"errors"
 	"money-tracker/internal/dto"
 	"money-tracker/internal/entity"
 	"strings"
@@ -9,11 +8,12 @@ import (
 )
 
 type TagUseCase struct {
-	Repo entity.TagRepository
+	Repo    entity.TagRepository
+	Changes ChangeFeed
 }
 
-func NewTagUseCase(repo entity.TagRepository) *TagUseCase {
-	return &TagUseCase{Repo: repo}
+func NewTagUseCase(repo entity.TagRepository, changes ChangeFeed) *TagUseCase {
+	return &TagUseCase{Repo: repo, Changes: changes}
 }
 
 func (uc *TagUseCase) Add(title string, status_id uint) (*entity.Tag, error) {
@@ -24,25 +24,29 @@ func (uc *TagUseCase) Add(title string, status_id uint) (*entity.Tag, error) {
 
 	existing, err_e := uc.Repo.FindByTitle(title)
 	if err_e == nil || existing != nil {
-		return nil, errors.New("tag duplicate")
+		return nil, ErrTagDuplicate
 	}
 
 	tag, er := entity.NewTag(title, uint(status_id))
 	if er != nil {
-		return nil, er
+		return nil, invalid(er)
 	}
 
 	err := uc.Repo.Insert(tag)
 	if err != nil {
 		return nil, err
 	}
+	notifyChange(uc.Changes, EventTagCreated, *tag)
 
 	return tag, err
 }
 func (uc *TagUseCase) Update(input dto.UpdateTagRequest) (*entity.Tag, error) {
+	if input.Version == nil {
+		return nil, ErrVersionRequired
+	}
 	tag, err := uc.Repo.FindById(input.ID)
 	if err != nil {
-		return nil, errors.New("tag not found")
+		return nil, ErrTagNotFound
 	}
 	if input.Title != "" {
 		tag.Title = input.Title
@@ -50,18 +54,28 @@ func (uc *TagUseCase) Update(input dto.UpdateTagRequest) (*entity.Tag, error) {
 	if input.StatusID != 0 {
 		tag.StatusID = input.StatusID
 	}
+	tag.Version = *input.Version
 
 	tag.UpdatedAt = time.Now()
 
-	return uc.Repo.Update(tag)
+	updated, err := uc.Repo.Update(tag)
+	if err != nil {
+		return nil, err
+	}
+	notifyChange(uc.Changes, EventTagUpdated, *updated)
+	return updated, nil
 }
 func (uc *TagUseCase) Remove(id uint) error {
-	_, err := uc.Repo.FindById(id)
+	tag, err := uc.Repo.FindById(id)
 	if err != nil {
-		return errors.New("tag not found")
+		return ErrTagNotFound
 	}
 
-	return uc.Repo.Delete(id)
+	if err := uc.Repo.Delete(id); err != nil {
+		return err
+	}
+	notifyChange(uc.Changes, EventTagDeleted, *tag)
+	return nil
 }
 func (uc *TagUseCase) GetByID(id uint) (*entity.Tag, error) {
 	return uc.Repo.FindById(id)
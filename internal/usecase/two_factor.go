@@ -0,0 +1,279 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
+	"money-tracker/internal/totp"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	totpIssuer = "Money Tracker"
+
+	// twoFactorChallengeTTL is how long the password step of a login stays
+	// good for the code step
+	twoFactorChallengeTTL = 5 * time.Minute
+	challengePurpose      = "login_2fa"
+
+	recoveryCodeCount = 10
+	// recoveryAlphabet leaves out the characters that are easy to misread
+	recoveryAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+)
+
+// EnrollTOTP starts the enrollment: it gives the user a new secret, which
+// takes effect once ActivateTOTP gets a valid code for it.
+func (uc *UserUseCase) EnrollTOTP(userID uint) (*dto.TOTPEnrollment, error) {
+	user, err := uc.Repo.FindById(userID)
+	if err != nil || user == nil {
+		return nil, ErrUserNotFound
+	}
+	if user.TOTPEnabled {
+		return nil, ErrTwoFactorEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	user.TOTPSecret = secret
+	user.TOTPLastStep = 0
+	user.UpdatedAt = time.Now()
+	if _, err := uc.Repo.Update(user); err != nil {
+		return nil, err
+	}
+
+	return &dto.TOTPEnrollment{
+		Secret:     secret,
+		OTPAuthURI: totp.URI(totpIssuer, user.UserName, secret),
+	}, nil
+}
+
+// ActivateTOTP turns two-factor authentication on with the first code of
+// the enrolled secret and returns the recovery codes.
+func (uc *UserUseCase) ActivateTOTP(userID uint, code string) (*dto.RecoveryCodes, error) {
+	user, err := uc.Repo.FindById(userID)
+	if err != nil || user == nil {
+		return nil, ErrUserNotFound
+	}
+	if user.TOTPEnabled {
+		return nil, ErrTwoFactorEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+
+	step, ok := totp.Verify(user.TOTPSecret, code, time.Now())
+	if !ok {
+		return nil, invalidTwoFactorCode()
+	}
+	user.TOTPEnabled = true
+	user.TOTPLastStep = step
+	user.UpdatedAt = time.Now()
+	if _, err := uc.Repo.Update(user); err != nil {
+		return nil, err
+	}
+
+	return uc.newRecoveryCodes(user.ID)
+}
+
+// DisableTOTP turns two-factor authentication off; it takes a TOTP or a
+// recovery code.
+func (uc *UserUseCase) DisableTOTP(userID uint, code string) error {
+	user, err := uc.twoFactorUser(userID, code)
+	if err != nil {
+		return err
+	}
+
+	user.TOTPEnabled = false
+	user.TOTPSecret = ""
+	user.TOTPLastStep = 0
+	user.UpdatedAt = time.Now()
+	if _, err := uc.Repo.Update(user); err != nil {
+		return err
+	}
+	return uc.RecoveryRepo.DeleteAll(user.ID)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the user.
+func (uc *UserUseCase) RegenerateRecoveryCodes(userID uint, code string) (*dto.RecoveryCodes, error) {
+	user, err := uc.twoFactorUser(userID, code)
+	if err != nil {
+		return nil, err
+	}
+	return uc.newRecoveryCodes(user.ID)
+}
+
+// twoFactorUser loads a user with two-factor authentication on and checks
+// the code. Wrong codes count towards the login lockout.
+func (uc *UserUseCase) twoFactorUser(userID uint, code string) (*entity.User, error) {
+	user, err := uc.Repo.FindById(userID)
+	if err != nil || user == nil {
+		return nil, ErrUserNotFound
+	}
+	if !user.TOTPEnabled {
+		return nil, ErrTwoFactorNotEnabled
+	}
+	if uc.Guard != nil {
+		if err := uc.Guard.CheckLocked(user.UserName); err != nil {
+			return nil, err
+		}
+	}
+
+	ok, err := uc.checkSecondFactor(user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if uc.Guard != nil {
+			if err := uc.Guard.Failed(user.UserName); err != nil {
+				return nil, err
+			}
+		}
+		return nil, invalidTwoFactorCode()
+	}
+	return user, nil
+}
+
+// LoginTwoFactor is the second step of a login: it exchanges the challenge
+// of Login and a TOTP or recovery code for a session token.
+func (uc *UserUseCase) LoginTwoFactor(req dto.TwoFactorLoginRequest, ip string) (*dto.LoginResult, error) {
+	userID, err := uc.parseChallenge(req.Challenge)
+	if err != nil {
+		return nil, ErrInvalidChallenge
+	}
+	user, err := uc.Repo.FindById(userID)
+	if err != nil || user == nil || !user.TOTPEnabled {
+		return nil, ErrInvalidChallenge
+	}
+
+	if uc.Guard != nil {
+		if err := uc.Guard.Check(ip, user.UserName); err != nil {
+			return nil, err
+		}
+	}
+
+	ok, err := uc.checkSecondFactor(user, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, uc.loginFailed(user.UserName, ErrInvalidTwoFactorCode)
+	}
+
+	if uc.Guard != nil {
+		if err := uc.Guard.Succeeded(user.UserName); err != nil {
+			return nil, err
+		}
+	}
+	return uc.startSession(user)
+}
+
+// checkSecondFactor accepts a TOTP code newer than the last one used, or an
+// unused recovery code, which is used up.
+func (uc *UserUseCase) checkSecondFactor(user *entity.User, code string) (bool, error) {
+	if step, ok := totp.Verify(user.TOTPSecret, code, time.Now()); ok {
+		if step <= user.TOTPLastStep {
+			return false, nil
+		}
+		user.TOTPLastStep = step
+		user.UpdatedAt = time.Now()
+		if _, err := uc.Repo.Update(user); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	return uc.RecoveryRepo.Use(user.ID, hashRecoveryCode(code))
+}
+
+func (uc *UserUseCase) newRecoveryCodes(userID uint) (*dto.RecoveryCodes, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i], hashes[i] = code, hashRecoveryCode(code)
+	}
+
+	if err := uc.RecoveryRepo.Replace(userID, hashes); err != nil {
+		return nil, err
+	}
+	return &dto.RecoveryCodes{Codes: codes}, nil
+}
+
+// generateRecoveryCode returns a code like "k7m2q-xp4ht", about 50 random
+// bits.
+func generateRecoveryCode() (string, error) {
+	// bytes past the last multiple of the alphabet size are dropped, so
+	// every character is as likely
+	limit := byte(256 / len(recoveryAlphabet) * len(recoveryAlphabet))
+	out := make([]byte, 0, 11)
+	b := make([]byte, 1)
+	for len(out) < 11 {
+		if len(out) == 5 {
+			out = append(out, '-')
+			continue
+		}
+		if _, err := rand.Read(b); err != nil {
+			return "", err
+		}
+		if b[0] < limit {
+			out = append(out, recoveryAlphabet[int(b[0])%len(recoveryAlphabet)])
+		}
+	}
+	return string(out), nil
+}
+
+// hashRecoveryCode ignores case, dashes and spaces, the way people type
+// codes back. Recovery codes are random enough for a plain SHA-256.
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// generateChallenge signs a short-lived token that proves the password step
+// of a login. It is not a session token: it is never stored, so the auth
+// middleware refuses it.
+func (uc *UserUseCase) generateChallenge(userID uint) (string, error) {
+	claims := jwt.MapClaims{
+		"user_id": userID,
+		"purpose": challengePurpose,
+		"exp":     time.Now().Add(twoFactorChallengeTTL).Unix(),
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(uc.JWTSecret))
+}
+
+func (uc *UserUseCase) parseChallenge(challenge string) (uint, error) {
+	token, err := jwt.Parse(challenge, func(t *jwt.Token) (interface{}, error) {
+		return []byte(uc.JWTSecret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil || !token.Valid {
+		return 0, errors.New("invalid challenge")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["purpose"] != challengePurpose {
+		return 0, errors.New("invalid challenge")
+	}
+	id, ok := claims["user_id"].(float64)
+	if !ok || id <= 0 {
+		return 0, errors.New("invalid challenge")
+	}
+	return uint(id), nil
+}
+
+func invalidTwoFactorCode() error {
+	return InvalidField("code", CodeInvalidTwoFactor, "invalid two-factor code")
+}
@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"errors"
+	"money-tracker/internal/dto"
+	"money-tracker/internal/totp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// totpCode returns the code of the step offset steps from now; Verify takes
+// the steps next to the current one too.
+func totpCode(t *testing.T, secret string, offset int64) string {
+	t.Helper()
+	code, err := totp.Code(secret, totp.Step(time.Now())+offset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+func enableTwoFactor(t *testing.T, uc *UserUseCase, userID uint) (string, []string) {
+	t.Helper()
+	enrollment, err := uc.EnrollTOTP(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := uc.ActivateTOTP(userID, "not-a-code"); !errors.Is(err, invalidTwoFactorCode()) {
+		t.Fatalf("activate with a wrong code: %v", err)
+	}
+	codes, err := uc.ActivateTOTP(userID, totpCode(t, enrollment.Secret, -1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes.Codes) != recoveryCodeCount {
+		t.Fatalf("got %d recovery codes", len(codes.Codes))
+	}
+	return enrollment.Secret, codes.Codes
+}
+
+func challenge(t *testing.T, uc *UserUseCase) string {
+	t.Helper()
+	result, err := uc.Login(dto.LoginRequest{UserName: "alice", Password: "right-password"}, "1.1.1.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.TwoFactorRequired || result.Challenge == "" || result.Token != "" {
+		t.Fatalf("want a challenge, got %+v", result)
+	}
+	return result.Challenge
+}
+
+func TestTwoFactorLogin(t *testing.T) {
+	uc, alice, _ := newTestUserUseCase(t, LoginLimits{})
+	secret, _ := enableTwoFactor(t, uc, alice.ID)
+
+	if _, err := uc.EnrollTOTP(alice.ID); !errors.Is(err, ErrTwoFactorEnabled) {
+		t.Fatalf("enroll twice: %v", err)
+	}
+
+	code := totpCode(t, secret, 0)
+	result, err := uc.LoginTwoFactor(dto.TwoFactorLoginRequest{Challenge: challenge(t, uc), Code: code}, "1.1.1.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Token == "" || result.UserId != alice.ID {
+		t.Fatalf("login returned %+v", result)
+	}
+
+	// a code works once, and so does anything older
+	for _, c := range []string{code, totpCode(t, secret, -1)} {
+		_, err = uc.LoginTwoFactor(dto.TwoFactorLoginRequest{Challenge: challenge(t, uc), Code: c}, "1.1.1.1")
+		if !errors.Is(err, ErrInvalidTwoFactorCode) {
+			t.Fatalf("replayed code: %v", err)
+		}
+	}
+
+	_, err = uc.LoginTwoFactor(dto.TwoFactorLoginRequest{Challenge: "forged", Code: totpCode(t, secret, 1)}, "1.1.1.1")
+	if !errors.Is(err, ErrInvalidChallenge) {
+		t.Fatalf("forged challenge: %v", err)
+	}
+	// a session token is no challenge
+	_, err = uc.LoginTwoFactor(dto.TwoFactorLoginRequest{Challenge: result.Token, Code: totpCode(t, secret, 1)}, "1.1.1.1")
+	if !errors.Is(err, ErrInvalidChallenge) {
+		t.Fatalf("session token as challenge: %v", err)
+	}
+}
+
+func TestTwoFactorRecoveryCodes(t *testing.T) {
+	uc, alice, _ := newTestUserUseCase(t, LoginLimits{})
+	secret, codes := enableTwoFactor(t, uc, alice.ID)
+
+	// typed back in upper case, the code still works, once
+	req := dto.TwoFactorLoginRequest{Challenge: challenge(t, uc), Code: strings.ToUpper(codes[0])}
+	if _, err := uc.LoginTwoFactor(req, "1.1.1.1"); err != nil {
+		t.Fatal(err)
+	}
+	req.Challenge = challenge(t, uc)
+	if _, err := uc.LoginTwoFactor(req, "1.1.1.1"); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("used recovery code: %v", err)
+	}
+
+	fresh, err := uc.RegenerateRecoveryCodes(alice.ID, codes[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if left, _ := uc.RecoveryRepo.CountUnused(alice.ID); left != recoveryCodeCount {
+		t.Fatalf("%d codes left after regenerating", left)
+	}
+	if err := uc.DisableTOTP(alice.ID, codes[3]); !errors.Is(err, invalidTwoFactorCode()) {
+		t.Fatalf("disable with an old recovery code: %v", err)
+	}
+
+	if err := uc.DisableTOTP(alice.ID, fresh.Codes[0]); err != nil {
+		t.Fatal(err)
+	}
+	if left, _ := uc.RecoveryRepo.CountUnused(alice.ID); left != 0 {
+		t.Fatalf("%d codes left after disabling", left)
+	}
+	if _, err := uc.Login(dto.LoginRequest{UserName: "alice", Password: "right-password"}, "1.1.1.1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := uc.DisableTOTP(alice.ID, totpCode(t, secret, 0)); !errors.Is(err, ErrTwoFactorNotEnabled) {
+		t.Fatalf("disable twice: %v", err)
+	}
+}
+
+func TestTwoFactorCodesCountTowardsLockout(t *testing.T) {
+	uc, alice, _ := newTestUserUseCase(t, LoginLimits{LockoutAfter: 2, LockoutBase: time.Minute, LockoutMax: time.Minute})
+	enableTwoFactor(t, uc, alice.ID)
+
+	for i := 0; i < 2; i++ {
+		_, err := uc.LoginTwoFactor(dto.TwoFactorLoginRequest{Challenge: challenge(t, uc), Code: "nope"}, "1.1.1.1")
+		if !errors.Is(err, ErrInvalidTwoFactorCode) {
+			t.Fatalf("failure %d: %v", i+1, err)
+		}
+	}
+	// the right password does not lift the lockout
+	wantRateLimited(t, login(uc, "alice", "right-password", "1.1.1.1"), CodeAccountLocked)
+}
@@ -1,15 +1,14 @@
 package usecase
 
 import (
-	"errors"
-	"fmt"
 	"money-tracker/internal/constants"
 	"money-tracker/internal/dto"
 	"money-tracker/internal/entity"
+	"money-tracker/internal/password"
 
-	"os"
 	"reflect"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -17,17 +16,36 @@ import (
 )
 
 type UserUseCase struct {
-	Repo      entity.UserRepository
-	TokenRepo entity.UserTokenRepository
+	Repo         entity.UserRepository
+	TokenRepo    entity.UserTokenRepository
+	RecoveryRepo entity.RecoveryCodeRepository
+	// Policy checks new passwords; nil accepts any.
+	Policy *password.Policy
+	// Guard limits logins; nil lets every login through.
+	Guard     *LoginGuard
+	JWTSecret string
+	TokenTTL  time.Duration
 }
 
-func NewUserUseCase(repo entity.UserRepository, tokenRepo entity.UserTokenRepository) *UserUseCase {
+func NewUserUseCase(repo entity.UserRepository, tokenRepo entity.UserTokenRepository, recoveryRepo entity.RecoveryCodeRepository, policy *password.Policy, guard *LoginGuard, jwtSecret string, tokenTTL time.Duration) *UserUseCase {
 	return &UserUseCase{
-		Repo:      repo,
-		TokenRepo: tokenRepo,
+		Repo:         repo,
+		TokenRepo:    tokenRepo,
+		RecoveryRepo: recoveryRepo,
+		Policy:       policy,
+		Guard:        guard,
+		JWTSecret:    jwtSecret,
+		TokenTTL:     tokenTTL,
 	}
 }
 
+// dummyHash is compared against when the user name is unknown, so that a
+// login takes as long whether the user exists or not.
+var dummyHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("not a password"), bcrypt.DefaultCost)
+	return hash
+})
+
 func getModelColumns(model interface{}) map[string]bool {
 	t := reflect.TypeOf(model)
 	columns := make(map[string]bool)
@@ -57,8 +75,8 @@ func toSnakeCase(str string) string {
 	return string(result)
 }
 
-func generateToken(userID uint, user_name string) (string, error) {
-	expirationTime := time.Now().Add(720 * time.Hour) // month
+func (uc *UserUseCase) generateToken(userID uint, user_name string) (string, error) {
+	expirationTime := time.Now().Add(uc.TokenTTL)
 
 	claims := jwt.MapClaims{
 		"user_id":   userID,
@@ -68,8 +86,7 @@ func generateToken(userID uint, user_name string) (string, error) {
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
-	secretKey := os.Getenv("JWT_SECRET")
-	return token.SignedString([]byte(secretKey))
+	return token.SignedString([]byte(uc.JWTSecret))
 }
 
 // /-------------------------- insert -------------------------------
@@ -79,19 +96,20 @@ func (uc *UserUseCase) Add(input dto.AddUserInput) (*entity.User, error) {
 		input.StatusID = 1
 	}
 
-	existedUser, er := uc.Repo.FindByUserName(input.UserName)
-	fmt.Println("-*******--- %v ", existedUser, er)
-
+	existedUser, _ := uc.Repo.FindByUserName(input.UserName)
 	if existedUser != nil {
-		return nil, errors.New("user duplicate!")
+		return nil, ErrUserDuplicate
 	}
 	if input.LevelManage == 0 {
 		input.LevelManage = 2 //user
 	}
+	if err := checkPassword(uc.Policy, "password", input.Password, input.UserName); err != nil {
+		return nil, err
+	}
 
 	user, err := entity.NewUser(input.UserName, input.Password, int8(input.LevelManage), input.StatusID)
 	if err != nil {
-		return nil, err
+		return nil, invalid(err)
 	}
 
 	res := uc.Repo.Insert(user)
@@ -104,33 +122,91 @@ func (uc *UserUseCase) Add(input dto.AddUserInput) (*entity.User, error) {
 }
 
 // ---------------------------------------- Login ----------------------
-func (uc *UserUseCase) Login(req dto.LoginRequest) (string, int8, uint, error) {
+// Login answers ErrInvalidCredentials for an unknown user and a wrong
+// password alike. ip is the address of the client, for the rate limit. A
+// user with two-factor authentication on gets a challenge instead of a
+// token, see LoginTwoFactor.
+func (uc *UserUseCase) Login(req dto.LoginRequest, ip string) (*dto.LoginResult, error) {
+	if uc.Guard != nil {
+		if err := uc.Guard.Check(ip, req.UserName); err != nil {
+			return nil, err
+		}
+	}
+
 	user, err := uc.Repo.FindByUserName(req.UserName)
 	if err != nil {
-		return "", 0, 0, errors.New("user name not found!")
+		_ = bcrypt.CompareHashAndPassword(dummyHash(), []byte(req.Password))
+		return nil, uc.loginFailed(req.UserName, ErrInvalidCredentials)
 	}
 
 	if !user.CheckPassword(req.Password) {
-		return "", 0, 0, errors.New("invalid password")
+		return nil, uc.loginFailed(req.UserName, ErrInvalidCredentials)
 	}
 
+	if user.TOTPEnabled {
+		// the failures are kept until the code is right too, or the
+		// password would reset the lockout between guesses of the code
+		challenge, err := uc.generateChallenge(user.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &dto.LoginResult{
+			TwoFactorRequired: true,
+			Challenge:         challenge,
+			ExpiresIn:         int(twoFactorChallengeTTL.Seconds()),
+		}, nil
+	}
+
+	if uc.Guard != nil {
+		if err := uc.Guard.Succeeded(req.UserName); err != nil {
+			return nil, err
+		}
+	}
+	return uc.startSession(user)
+}
+
+// startSession issues and stores a session token.
+func (uc *UserUseCase) startSession(user *entity.User) (*dto.LoginResult, error) {
+
 	// Generate JWT
-	token, err := generateToken(user.ID, user.UserName)
+	token, err := uc.generateToken(user.ID, user.UserName)
 	if err != nil {
-		return "", 0, 0, err
+		return nil, err
 	}
 
 	userToken, e := entity.NewUserToken(token, user.ID)
 	if e != nil {
-		return "", 0, 0, e
+		return nil, e
 	}
 
 	er := uc.TokenRepo.Insert(userToken)
 	if er != nil {
-		return "", 0, 0, er
+		return nil, er
 	}
 
-	return token, user.LevelManage, user.ID, nil
+	return &dto.LoginResult{Token: token, LevelManage: user.LevelManage, UserId: user.ID}, nil
+}
+
+// loginFailed counts a failed login and returns err.
+func (uc *UserUseCase) loginFailed(userName string, err error) error {
+	if uc.Guard != nil {
+		if gerr := uc.Guard.Failed(userName); gerr != nil {
+			return gerr
+		}
+	}
+	return err
+}
+
+// Unlock lifts the login lockout of a user.
+func (uc *UserUseCase) Unlock(id uint) error {
+	user, err := uc.Repo.FindById(id)
+	if err != nil || user == nil {
+		return ErrUserNotFound
+	}
+	if uc.Guard == nil {
+		return nil
+	}
+	return uc.Guard.Unlock(user.UserName)
 }
 
 // ------------------
@@ -138,7 +214,7 @@ func (uc *UserUseCase) Logout(input dto.LogoutInput) error {
 
 	existedUser, _ := uc.TokenRepo.FindByToken(input.Token, []uint{constants.StatusActive})
 	if existedUser == nil {
-		return errors.New("token not found!")
+		return ErrTokenNotFound
 	}
 
 	return uc.TokenRepo.Delete(existedUser.ID)
@@ -149,7 +225,7 @@ func (uc *UserUseCase) Logout(input dto.LogoutInput) error {
 func (uc *UserUseCase) Remove(id uint) error {
 	_, err := uc.Repo.FindById(id)
 	if err != nil {
-		return errors.New("user not found")
+		return ErrUserNotFound
 	}
 
 	return uc.Repo.Delete(id)
@@ -159,19 +235,20 @@ func (uc *UserUseCase) Remove(id uint) error {
 func (uc *UserUseCase) Update(input dto.UpdateUserRequest) (*entity.User, error) {
 	user, err := uc.Repo.FindById(input.ID)
 	if err != nil || user == nil {
-		return nil, errors.New("user not found")
+		return nil, ErrUserNotFound
 	}
 
 	if input.UserName != "" {
 		user.UserName = input.UserName
 	}
-	if input.Password != "" {
-		// hash the new password
-		hashed, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
-		if err != nil {
+	passwordChanged := input.Password != ""
+	if passwordChanged {
+		if err := checkPassword(uc.Policy, "password", input.Password, user.UserName); err != nil {
 			return nil, err
 		}
-		user.Password = string(hashed)
+		if err := user.SetPassword(input.Password); err != nil {
+			return nil, invalid(err)
+		}
 	}
 	if input.LevelManage != 0 {
 		user.LevelManage = input.LevelManage
@@ -182,7 +259,17 @@ func (uc *UserUseCase) Update(input dto.UpdateUserRequest) (*entity.User, error)
 
 	user.UpdatedAt = time.Now()
 
-	return uc.Repo.Update(user)
+	updated, err := uc.Repo.Update(user)
+	if err != nil {
+		return nil, err
+	}
+	if passwordChanged {
+		// the old password must not keep anyone logged in
+		if err := uc.TokenRepo.DeleteByUser(user.ID); err != nil {
+			return nil, err
+		}
+	}
+	return updated, nil
 }
 
 // /--------------------------------- GET -----------------------
@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"errors"
+	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
+	"money-tracker/internal/password"
+	"money-tracker/internal/ratelimit"
+	"money-tracker/internal/repository/memory"
+	"testing"
+	"time"
+)
+
+func newTestUserUseCase(t *testing.T, limits LoginLimits) (*UserUseCase, *entity.User, *time.Time) {
+	t.Helper()
+	store := memory.NewStore()
+	guard := NewLoginGuard(ratelimit.NewMemoryStore(), limits)
+	now := time.Now()
+	guard.now = func() time.Time { return now }
+	uc := NewUserUseCase(memory.NewUserRepo(store), memory.NewUserTokenRepo(store), memory.NewRecoveryCodeRepo(store), testPolicy(t), guard, "secret", time.Hour)
+
+	user, err := entity.NewUser("alice", "right-password", 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := uc.Repo.Insert(user); err != nil {
+		t.Fatal(err)
+	}
+	return uc, user, &now
+}
+
+func testPolicy(t *testing.T) *password.Policy {
+	t.Helper()
+	policy, err := password.NewPolicy(8, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return policy
+}
+
+func login(uc *UserUseCase, name string, password string, ip string) error {
+	_, err := uc.Login(dto.LoginRequest{UserName: name, Password: password}, ip)
+	return err
+}
+
+func wantRateLimited(t *testing.T, err error, code string) *Error {
+	t.Helper()
+	var e *Error
+	if !errors.As(err, &e) || e.Kind != KindRateLimited || e.Code != code {
+		t.Fatalf("want a rate limit error %q, got %v", code, err)
+	}
+	if e.RetryAfter <= 0 {
+		t.Fatalf("no RetryAfter in %+v", e)
+	}
+	return e
+}
+
+func TestLoginSameErrorForUnknownUser(t *testing.T) {
+	uc, _, _ := newTestUserUseCase(t, LoginLimits{})
+
+	if err := login(uc, "alice", "wrong", "1.1.1.1"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("wrong password: %v", err)
+	}
+	if err := login(uc, "bob", "wrong", "1.1.1.1"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("unknown user: %v", err)
+	}
+}
+
+func TestLoginLockout(t *testing.T) {
+	uc, alice, now := newTestUserUseCase(t, LoginLimits{LockoutAfter: 3, LockoutBase: time.Minute, LockoutMax: 3 * time.Minute})
+
+	for i := 0; i < 3; i++ {
+		if err := login(uc, "alice", "wrong", "1.1.1.1"); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("failure %d: %v", i+1, err)
+		}
+	}
+	// locked: even the right password is refused, from any address
+	e := wantRateLimited(t, login(uc, "Alice", "right-password", "2.2.2.2"), CodeAccountLocked)
+	if e.RetryAfter != time.Minute {
+		t.Fatalf("first lockout %v, want 1m", e.RetryAfter)
+	}
+
+	// every failure after the lockout doubles it, up to the max
+	*now = now.Add(time.Minute)
+	_ = login(uc, "alice", "wrong", "1.1.1.1")
+	e = wantRateLimited(t, login(uc, "alice", "right-password", "1.1.1.1"), CodeAccountLocked)
+	if e.RetryAfter != 2*time.Minute {
+		t.Fatalf("second lockout %v, want 2m", e.RetryAfter)
+	}
+	*now = now.Add(2 * time.Minute)
+	_ = login(uc, "alice", "wrong", "1.1.1.1")
+	e = wantRateLimited(t, login(uc, "alice", "right-password", "1.1.1.1"), CodeAccountLocked)
+	if e.RetryAfter != 3*time.Minute {
+		t.Fatalf("third lockout %v, want the max of 3m", e.RetryAfter)
+	}
+
+	if err := uc.Unlock(alice.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := login(uc, "alice", "right-password", "1.1.1.1"); err != nil {
+		t.Fatalf("after unlock: %v", err)
+	}
+	// a success forgets the failures
+	if err := login(uc, "alice", "wrong", "1.1.1.1"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("after success: %v", err)
+	}
+
+	if err := uc.Unlock(999); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("unlock unknown user: %v", err)
+	}
+}
+
+func TestLoginRateLimit(t *testing.T) {
+	uc, _, _ := newTestUserUseCase(t, LoginLimits{IPAttempts: 2, IPWindow: time.Minute, UserAttempts: 2, UserWindow: time.Minute})
+
+	for _, name := range []string{"bob", "carol"} {
+		if err := login(uc, name, "wrong", "1.1.1.1"); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatal(err)
+		}
+	}
+	wantRateLimited(t, login(uc, "alice", "right-password", "1.1.1.1"), CodeTooManyAttempts)
+
+	// the user name has its own limit, whatever the address
+	for _, ip := range []string{"2.2.2.2", "3.3.3.3"} {
+		if err := login(uc, "alice", "right-password", ip); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wantRateLimited(t, login(uc, "alice", "right-password", "4.4.4.4"), CodeTooManyAttempts)
+}
+
+func TestAddChecksPasswordPolicy(t *testing.T) {
+	uc, _, _ := newTestUserUseCase(t, LoginLimits{})
+
+	for _, pw := range []string{"", "short", "password123", "Bob.Builder"} {
+		_, err := uc.Add(dto.AddUserInput{UserName: "bob.builder", Password: pw})
+		if !hasFieldCode(err, "password", CodeWeakPassword) {
+			t.Errorf("password %q: %v", pw, err)
+		}
+	}
+	if _, err := uc.Add(dto.AddUserInput{UserName: "bob.builder", Password: "long enough, not common"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func hasFieldCode(err error, field string, code string) bool {
+	var e *Error
+	if !errors.As(err, &e) {
+		return false
+	}
+	for _, f := range e.Fields {
+		if f.Field == field && f.Code == code {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,250 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
+)
+
+// Event types a webhook can subscribe to.
+const (
+	EventPurchaseCreated = "purchase.created"
+	EventPurchaseUpdated = "purchase.updated"
+	EventPurchaseDeleted = "purchase.deleted"
+	// EventPing is only sent by Ping; it needs no subscription.
+	EventPing = "ping"
+
+	webhookSecretPrefix = "whsec_"
+)
+
+var webhookEvents = []string{EventPurchaseCreated, EventPurchaseUpdated, EventPurchaseDeleted}
+
+// Event is the body of every webhook request.
+type Event struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+type WebhookUseCase struct {
+	Repo         entity.WebhookRepository
+	Outbox       entity.OutboxRepository
+	DeliveryRepo entity.WebhookDeliveryRepository
+
+	now func() time.Time
+}
+
+func NewWebhookUseCase(repo entity.WebhookRepository, outbox entity.OutboxRepository, deliveries entity.WebhookDeliveryRepository) *WebhookUseCase {
+	return &WebhookUseCase{
+		Repo:         repo,
+		Outbox:       outbox,
+		DeliveryRepo: deliveries,
+		now:          time.Now,
+	}
+}
+
+// Create subscribes a URL to events. The signing secret is only returned
+// here.
+func (uc *WebhookUseCase) Create(req dto.CreateWebhookRequest) (*dto.WebhookCreated, error) {
+	events, err := checkEvents(req.Events)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	webhook, err := entity.NewWebhook(strings.TrimSpace(req.URL), secret, events, strings.TrimSpace(req.Description))
+	if err != nil {
+		return nil, InvalidField("url", "url", err.Error())
+	}
+	if err := uc.Repo.Insert(webhook); err != nil {
+		return nil, err
+	}
+
+	return &dto.WebhookCreated{
+		ID:          webhook.ID,
+		URL:         webhook.URL,
+		Events:      webhook.EventList(),
+		Description: webhook.Description,
+		Active:      webhook.Active,
+		CreatedAt:   webhook.CreatedAt,
+		Secret:      secret,
+	}, nil
+}
+
+func (uc *WebhookUseCase) List() ([]entity.Webhook, error) {
+	return uc.Repo.FindAll()
+}
+
+func (uc *WebhookUseCase) Update(id uint, req dto.UpdateWebhookRequest) (*entity.Webhook, error) {
+	webhook, err := uc.Repo.FindById(id)
+	if err != nil || webhook == nil {
+		return nil, ErrWebhookNotFound
+	}
+
+	if req.URL != nil {
+		if err := webhook.SetURL(strings.TrimSpace(*req.URL)); err != nil {
+			return nil, InvalidField("url", "url", err.Error())
+		}
+	}
+	if req.Events != nil {
+		events, err := checkEvents(req.Events)
+		if err != nil {
+			return nil, err
+		}
+		if err := webhook.SetEvents(events); err != nil {
+			return nil, InvalidField("events", "required", err.Error())
+		}
+	}
+	if req.Description != nil {
+		webhook.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+
+	webhook.UpdatedAt = uc.now()
+	if err := uc.Repo.Update(webhook); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// Remove deletes the webhook with its pending messages and delivery log.
+func (uc *WebhookUseCase) Remove(id uint) error {
+	if err := uc.Repo.Delete(id); err != nil {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+func (uc *WebhookUseCase) Deliveries(id uint, start int, limit int) ([]entity.WebhookDelivery, int, error) {
+	if _, err := uc.Repo.FindById(id); err != nil {
+		return nil, 0, ErrWebhookNotFound
+	}
+	if start < 0 {
+		start = 0
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 30
+	}
+	return uc.DeliveryRepo.FindByWebhook(id, start, limit)
+}
+
+// Ping queues a ping event for the webhook, whatever it subscribes to, so
+// a receiver can be tried out.
+func (uc *WebhookUseCase) Ping(id uint) (*Event, error) {
+	webhook, err := uc.Repo.FindById(id)
+	if err != nil || webhook == nil {
+		return nil, ErrWebhookNotFound
+	}
+
+	event, payload, err := uc.newEvent(EventPing, map[string]uint{"webhook_id": webhook.ID})
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.enqueue(uc.Outbox, webhook.ID, event, payload); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// Publish queues the event for every active webhook subscribed to it.
+// outbox should belong to the transaction that made the change, so the
+// event is sent if and only if the change is committed.
+func (uc *WebhookUseCase) Publish(outbox entity.OutboxRepository, eventType string, data interface{}) error {
+	webhooks, err := uc.Repo.FindActive()
+	if err != nil {
+		return err
+	}
+
+	var event *Event
+	var payload []byte
+	for _, webhook := range webhooks {
+		if !webhook.Wants(eventType) {
+			continue
+		}
+		// every subscriber gets the same event id
+		if event == nil {
+			if event, payload, err = uc.newEvent(eventType, data); err != nil {
+				return err
+			}
+		}
+		if err := uc.enqueue(outbox, webhook.ID, event, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (uc *WebhookUseCase) newEvent(eventType string, data interface{}) (*Event, []byte, error) {
+	id, err := randomHex(12)
+	if err != nil {
+		return nil, nil, err
+	}
+	event := &Event{ID: "evt_" + id, Type: eventType, CreatedAt: uc.now().UTC(), Data: data}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, nil, err
+	}
+	return event, payload, nil
+}
+
+func (uc *WebhookUseCase) enqueue(outbox entity.OutboxRepository, webhookID uint, event *Event, payload []byte) error {
+	return outbox.Insert(&entity.OutboxMessage{
+		WebhookID:     webhookID,
+		EventID:       event.ID,
+		EventType:     event.Type,
+		Payload:       payload,
+		NextAttemptAt: event.CreatedAt,
+		CreatedAt:     event.CreatedAt,
+	})
+}
+
+// checkEvents refuses unknown event types and drops repeated ones.
+func checkEvents(events []string) ([]string, error) {
+	seen := map[string]bool{}
+	out := make([]string, 0, len(events))
+	for _, e := range events {
+		e = strings.TrimSpace(e)
+		known := false
+		for _, w := range webhookEvents {
+			known = known || e == w
+		}
+		if !known {
+			return nil, InvalidField("events", "oneof", "unknown event: "+e+", want one of "+strings.Join(webhookEvents, ", "))
+		}
+		if !seen[e] {
+			seen[e] = true
+			out = append(out, e)
+		}
+	}
+	if len(out) == 0 {
+		return nil, InvalidField("events", "required", "events are required")
+	}
+	return out, nil
+}
+
+func generateWebhookSecret() (string, error) {
+	s, err := randomHex(24)
+	if err != nil {
+		return "", err
+	}
+	return webhookSecretPrefix + s, nil
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"money-tracker/internal/dto"
+	"money-tracker/internal/entity"
+	"money-tracker/internal/repository/memory"
+	"testing"
+	"time"
+)
+
+// queued claims every message in the outbox.
+func queued(t *testing.T, outbox entity.OutboxRepository) []entity.OutboxMessage {
+	t.Helper()
+	msgs, err := outbox.Claim(time.Now().Add(time.Hour), time.Hour, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return msgs
+}
+
+func TestPurchaseEventsAreQueued(t *testing.T) {
+	store := memory.NewStore()
+	hooks := NewWebhookUseCase(memory.NewWebhookRepo(store), memory.NewOutboxRepo(store), memory.NewWebhookDeliveryRepo(store))
+	uc := NewPurchaseUseCase(memory.NewPurchaseRepo(store), memory.NewTagRepo(store), memory.NewCategoryRepo(store), memory.NewTransactor(store), nil, hooks, nil, nil)
+	food, err := entity.NewCategory("Food", "food", 1, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := uc.CatRepo.Insert(food); err != nil {
+		t.Fatal(err)
+	}
+
+	created, err := hooks.Create(dto.CreateWebhookRequest{
+		URL:    "https://example.com/hook",
+		Events: []string{EventPurchaseCreated, EventPurchaseDeleted, EventPurchaseCreated},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(created.Secret) < 30 || len(created.Events) != 2 {
+		t.Fatalf("Create returned %+v", created)
+	}
+	paused, err := hooks.Create(dto.CreateWebhookRequest{URL: "https://example.com/paused", Events: []string{EventPurchaseUpdated}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	off := false
+	if _, err := hooks.Update(paused.ID, dto.UpdateWebhookRequest{Active: &off}); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := uc.Add(dto.AddPurchaseInput{CategoryId: &food.ID, Amount: 100, Date: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// nobody active listens to updates
+	if _, err := uc.Update(dto.UpdatePurchaseInput{ID: p.ID, Amount: 150, Date: p.Date, Version: &p.Version}); err != nil {
+		t.Fatal(err)
+	}
+	if err := uc.Remove(p.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	msgs := queued(t, hooks.Outbox)
+	if len(msgs) != 2 || msgs[0].EventType != EventPurchaseCreated || msgs[1].EventType != EventPurchaseDeleted {
+		t.Fatalf("queued %+v", msgs)
+	}
+	var event struct {
+		ID   string          `json:"id"`
+		Type string          `json:"type"`
+		Data entity.Purchase `json:"data"`
+	}
+	if err := json.Unmarshal(msgs[0].Payload, &event); err != nil {
+		t.Fatal(err)
+	}
+	if event.ID != msgs[0].EventID || event.Type != EventPurchaseCreated || event.Data.ID != p.ID || event.Data.Amount != 100 {
+		t.Fatalf("payload %s", msgs[0].Payload)
+	}
+
+	// a rolled back batch queues nothing
+	missing := uint(999)
+	result, err := uc.BatchCreate(dto.BatchCreatePurchaseInput{
+		AllOrNothing: true,
+		Items: []dto.AddPurchaseInput{
+			{CategoryId: &food.ID, Amount: 100, Date: time.Now()},
+			{CategoryId: &missing, Amount: 200, Date: time.Now()},
+		},
+	})
+	if err != nil || !result.RolledBack {
+		t.Fatalf("BatchCreate: %+v, %v", result, err)
+	}
+	if msgs := queued(t, hooks.Outbox); len(msgs) != 0 {
+		t.Fatalf("rolled back batch queued %+v", msgs)
+	}
+}
+
+func TestWebhookUnknownEvent(t *testing.T) {
+	store := memory.NewStore()
+	hooks := NewWebhookUseCase(memory.NewWebhookRepo(store), memory.NewOutboxRepo(store), memory.NewWebhookDeliveryRepo(store))
+
+	_, err := hooks.Create(dto.CreateWebhookRequest{URL: "https://example.com/hook", Events: []string{"budget.exceeded"}})
+	if !hasFieldCode(err, "events", "oneof") {
+		t.Fatalf("want an events error, got %v", err)
+	}
+	_, err = hooks.Create(dto.CreateWebhookRequest{URL: "ftp://example.com/hook", Events: []string{EventPurchaseCreated}})
+	if !hasFieldCode(err, "url", "url") {
+		t.Fatalf("want a url error, got %v", err)
+	}
+	_, err = hooks.Ping(42)
+	if !errors.Is(err, ErrWebhookNotFound) {
+		t.Fatalf("want ErrWebhookNotFound, got %v", err)
+	}
+}
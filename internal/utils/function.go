@@ -93,19 +93,6 @@ func GenerateSlugUnicode(text string) string {
 	return slug
 }
 
-func GetEnvInt(key string, defaultVal int) int {
-	if val, err := strconv.Atoi(os.Getenv(key)); err == nil && val > 0 {
-		return val
-	}
-	return defaultVal
-}
-func GetEnvBool(key string, defaultVal bool) bool {
-	if val, err := strconv.ParseBool(os.Getenv(key)); err == nil {
-		return val
-	}
-	return defaultVal
-}
-
 // GetVideoDuration returns video duration in seconds
 func GetVideoDuration(filePath string) (float64, error) {
 	cmd := exec.Command(
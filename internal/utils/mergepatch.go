@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+// MergePatch applies a JSON Merge Patch (RFC 7396) to a JSON object and
+// returns the patched document. Members set to null in the patch are
+// removed from the document; nested objects are merged recursively.
+func MergePatch(doc []byte, patch []byte) ([]byte, error) {
+	var target, p interface{}
+	if err := decodeJSONNumber(doc, &target); err != nil {
+		return nil, err
+	}
+	if err := decodeJSONNumber(patch, &p); err != nil {
+		return nil, errors.New("invalid merge patch: " + err.Error())
+	}
+	if _, ok := p.(map[string]interface{}); !ok {
+		return nil, errors.New("invalid merge patch: must be a JSON object")
+	}
+
+	return json.Marshal(mergePatchValue(target, p))
+}
+
+func mergePatchValue(target interface{}, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = map[string]interface{}{}
+	}
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+		} else {
+			targetObj[key] = mergePatchValue(targetObj[key], value)
+		}
+	}
+	return targetObj
+}
+
+// decodeJSONNumber keeps numbers as json.Number so large integers survive.
+func decodeJSONNumber(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
@@ -0,0 +1,40 @@
+package utils
+
+import "testing"
+
+func TestMergePatch(t *testing.T) {
+	// mostly the examples of RFC 7396, appendix A
+	cases := []struct {
+		doc, patch, want string
+	}{
+		{doc: `{"a":"b"}`, patch: `{"a":"c"}`, want: `{"a":"c"}`},
+		{doc: `{"a":"b"}`, patch: `{"b":"c"}`, want: `{"a":"b","b":"c"}`},
+		// null deletes a member
+		{doc: `{"a":"b"}`, patch: `{"a":null}`, want: `{}`},
+		{doc: `{"a":"b","b":"c"}`, patch: `{"a":null}`, want: `{"b":"c"}`},
+		{doc: `{"a":"b"}`, patch: `{"missing":null}`, want: `{"a":"b"}`},
+		// arrays are replaced, not merged
+		{doc: `{"a":["b"]}`, patch: `{"a":"c"}`, want: `{"a":"c"}`},
+		{doc: `{"a":"c"}`, patch: `{"a":["b"]}`, want: `{"a":["b"]}`},
+		{doc: `{"a":[{"b":"c"}]}`, patch: `{"a":[1]}`, want: `{"a":[1]}`},
+		// nested objects merge
+		{doc: `{"a":{"b":"c"}}`, patch: `{"a":{"b":"d","c":null}}`, want: `{"a":{"b":"d"}}`},
+		{doc: `{"a":{"b":"c","d":"e"}}`, patch: `{"a":{"d":null,"f":1}}`, want: `{"a":{"b":"c","f":1}}`},
+		{doc: `{"e":null}`, patch: `{"a":1}`, want: `{"a":1,"e":null}`},
+		{doc: `{}`, patch: `{"a":{"bb":{"ccc":null}}}`, want: `{"a":{"bb":{}}}`},
+		// large integers are kept as they are
+		{doc: `{"id":9007199254740993}`, patch: `{"n":1}`, want: `{"id":9007199254740993,"n":1}`},
+	}
+	for _, c := range cases {
+		got, err := MergePatch([]byte(c.doc), []byte(c.patch))
+		if err != nil || string(got) != c.want {
+			t.Errorf("MergePatch(%s, %s) = %s, %v; want %s", c.doc, c.patch, got, err, c.want)
+		}
+	}
+
+	for _, patch := range []string{`["a"]`, `"a"`, `null`, `{"a":`} {
+		if _, err := MergePatch([]byte(`{"a":"b"}`), []byte(patch)); err == nil {
+			t.Errorf("MergePatch with patch %s did not fail", patch)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"money-tracker/internal/entity"
+)
+
+const (
+	batchSize = 20
+	// maxResponse is how much of a response body the delivery log keeps
+	maxResponse = 1024
+	userAgent   = "money-tracker-webhooks/1"
+)
+
+// Options configure a Dispatcher; see config.WebhookConfig.
+type Options struct {
+	MaxAttempts  int
+	BackoffBase  time.Duration
+	BackoffMax   time.Duration
+	PollInterval time.Duration
+	Timeout      time.Duration
+}
+
+// Dispatcher posts the due outbox messages to their webhooks. A message is
+// claimed before it is sent, so several servers can run dispatchers on one
+// database without sending a message twice at the same time. Delivery is
+// at least once: a receiver may see an event again, e.g. when it answered
+// too late.
+type Dispatcher struct {
+	Outbox     entity.OutboxRepository
+	Webhooks   entity.WebhookRepository
+	Deliveries entity.WebhookDeliveryRepository
+	Client     *http.Client
+	Options    Options
+
+	now func() time.Time
+}
+
+func NewDispatcher(outbox entity.OutboxRepository, webhooks entity.WebhookRepository, deliveries entity.WebhookDeliveryRepository, opts Options) *Dispatcher {
+	return &Dispatcher{
+		Outbox:     outbox,
+		Webhooks:   webhooks,
+		Deliveries: deliveries,
+		Client:     &http.Client{Timeout: opts.Timeout},
+		Options:    opts,
+		now:        time.Now,
+	}
+}
+
+// Run sends the due messages every PollInterval until ctx is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.Options.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// a full batch means there may be more waiting
+		for {
+			n, err := d.RunOnce(ctx)
+			if err != nil {
+				log.Printf("webhooks: dispatch failed: %v", err)
+			}
+			if err != nil || n < batchSize || ctx.Err() != nil {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce claims one batch of due messages, sends them and returns how
+// many it claimed.
+func (d *Dispatcher) RunOnce(ctx context.Context) (int, error) {
+	// the lease has to outlast sending the whole batch
+	lease := batchSize*d.Options.Timeout + time.Minute
+	msgs, err := d.Outbox.Claim(d.now(), lease, batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range msgs {
+		if ctx.Err() != nil {
+			// the rest stay claimed until the lease ends
+			break
+		}
+		if err := d.deliver(ctx, &msgs[i]); err != nil {
+			return len(msgs), err
+		}
+	}
+	return len(msgs), nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, msg *entity.OutboxMessage) error {
+	webhook, err := d.Webhooks.FindById(msg.WebhookID)
+	if err != nil || webhook == nil || !webhook.Active {
+		// events queued for a paused webhook are dropped, not held back
+		now := d.now()
+		msg.FailedAt = &now
+		msg.LastError = "webhook is deleted or inactive"
+		return d.Outbox.Update(msg)
+	}
+
+	msg.Attempts++
+	delivery := d.post(ctx, webhook, msg)
+	if err := d.Deliveries.Insert(delivery); err != nil {
+		return err
+	}
+
+	now := d.now()
+	switch {
+	case delivery.Error == "":
+		msg.DeliveredAt = &now
+		msg.LastError = ""
+	case msg.Attempts >= d.Options.MaxAttempts:
+		msg.FailedAt = &now
+		msg.LastError = delivery.Error
+	default:
+		msg.NextAttemptAt = now.Add(d.backoff(msg.Attempts))
+		msg.LastError = delivery.Error
+	}
+	return d.Outbox.Update(msg)
+}
+
+// post sends one attempt and describes it; any answer but a 2xx is a
+// failure.
+func (d *Dispatcher) post(ctx context.Context, webhook *entity.Webhook, msg *entity.OutboxMessage) *entity.WebhookDelivery {
+	delivery := &entity.WebhookDelivery{
+		WebhookID: webhook.ID,
+		MessageID: msg.ID,
+		EventID:   msg.EventID,
+		EventType: msg.EventType,
+		Attempt:   msg.Attempts,
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(msg.Payload))
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	start := d.now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(EventHeader, msg.EventType)
+	req.Header.Set(IDHeader, msg.EventID)
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, start, msg.Payload))
+
+	resp, err := d.Client.Do(req)
+	delivery.DurationMs = d.now().Sub(start).Milliseconds()
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponse))
+	delivery.StatusCode = resp.StatusCode
+	delivery.Response = string(bytes.ToValidUTF8(body, nil))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		delivery.Error = "HTTP " + strconv.Itoa(resp.StatusCode)
+	}
+	return delivery
+}
+
+// backoff is the wait after the attempts-th failed attempt.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	wait := d.Options.BackoffBase
+	for i := 1; i < attempts && wait < d.Options.BackoffMax; i++ {
+		wait *= 2
+	}
+	return min(wait, d.Options.BackoffMax)
+}
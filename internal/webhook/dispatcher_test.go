@@ -0,0 +1,223 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"money-tracker/internal/entity"
+	"money-tracker/internal/repository/memory"
+)
+
+// receiver is a local webhook endpoint that answers with the given status
+// codes in turn, then 200.
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	bodies   []string
+	err      error
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := Verify("whsec_test", req.Header.Get(SignatureHeader), body, time.Now(), 5*time.Minute); err != nil {
+		r.err = err
+	}
+	if req.Header.Get(IDHeader) != "evt_1" || req.Header.Get(EventHeader) != "purchase.created" {
+		r.err = ErrInvalidSignature
+	}
+	r.bodies = append(r.bodies, string(body))
+
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte("ok"))
+}
+
+type fixture struct {
+	d       *Dispatcher
+	webhook *entity.Webhook
+	msg     *entity.OutboxMessage
+	clock   time.Time
+}
+
+func newFixture(t *testing.T, target string) *fixture {
+	t.Helper()
+	store := memory.NewStore()
+	f := &fixture{clock: time.Now()}
+	f.d = NewDispatcher(memory.NewOutboxRepo(store), memory.NewWebhookRepo(store), memory.NewWebhookDeliveryRepo(store), Options{
+		MaxAttempts:  3,
+		BackoffBase:  time.Minute,
+		BackoffMax:   90 * time.Second,
+		PollInterval: time.Second,
+		Timeout:      5 * time.Second,
+	})
+	f.d.now = func() time.Time { return f.clock }
+
+	var err error
+	f.webhook, err = entity.NewWebhook(target, "whsec_test", []string{"purchase.created"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.d.Webhooks.Insert(f.webhook); err != nil {
+		t.Fatal(err)
+	}
+	f.msg = &entity.OutboxMessage{
+		WebhookID: f.webhook.ID, EventID: "evt_1", EventType: "purchase.created",
+		Payload: []byte(`{"id":"evt_1"}`), NextAttemptAt: f.clock,
+	}
+	if err := f.d.Outbox.Insert(f.msg); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+// run advances the clock by d and runs the dispatcher once.
+func (f *fixture) run(t *testing.T, d time.Duration) int {
+	t.Helper()
+	f.clock = f.clock.Add(d)
+	n, err := f.d.RunOnce(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func (f *fixture) deliveries(t *testing.T) []entity.WebhookDelivery {
+	t.Helper()
+	deliveries, _, err := f.d.Deliveries.FindByWebhook(f.webhook.ID, 0, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return deliveries
+}
+
+func TestDispatcherRetriesWithBackoff(t *testing.T) {
+	recv := &receiver{statuses: []int{500, 503}}
+	srv := httptest.NewServer(recv)
+	defer srv.Close()
+	f := newFixture(t, srv.URL)
+
+	if n := f.run(t, 0); n != 1 {
+		t.Fatalf("claimed %d messages, want 1", n)
+	}
+	// the first retry waits BackoffBase
+	if n := f.run(t, 59*time.Second); n != 0 {
+		t.Fatal("retried before the backoff")
+	}
+	if n := f.run(t, time.Second); n != 1 {
+		t.Fatal("did not retry after the backoff")
+	}
+	// the second waits twice as long, capped at BackoffMax
+	if n := f.run(t, 89*time.Second); n != 0 {
+		t.Fatal("retried before the backoff")
+	}
+	if n := f.run(t, time.Second); n != 1 {
+		t.Fatal("did not retry after the backoff")
+	}
+	if n := f.run(t, time.Hour); n != 0 {
+		t.Fatal("a delivered message was sent again")
+	}
+
+	if recv.err != nil {
+		t.Fatal(recv.err)
+	}
+	if len(recv.bodies) != 3 || recv.bodies[2] != `{"id":"evt_1"}` {
+		t.Fatalf("receiver got %q", recv.bodies)
+	}
+
+	deliveries := f.deliveries(t)
+	if len(deliveries) != 3 {
+		t.Fatalf("logged %d deliveries, want 3", len(deliveries))
+	}
+	last, first := deliveries[0], deliveries[2]
+	if last.Attempt != 3 || last.StatusCode != 200 || last.Error != "" || last.Response != "ok" {
+		t.Fatalf("last delivery %+v", last)
+	}
+	if first.Attempt != 1 || first.StatusCode != 500 || first.Error != "HTTP 500" || first.EventID != "evt_1" {
+		t.Fatalf("first delivery %+v", first)
+	}
+}
+
+func TestDispatcherGivesUp(t *testing.T) {
+	recv := &receiver{statuses: []int{500, 500, 500, 500}}
+	srv := httptest.NewServer(recv)
+	defer srv.Close()
+	f := newFixture(t, srv.URL)
+
+	for i := 0; i < 5; i++ {
+		f.run(t, time.Hour)
+	}
+	if len(recv.bodies) != 3 {
+		t.Fatalf("sent %d times, want MaxAttempts (3)", len(recv.bodies))
+	}
+	if deliveries := f.deliveries(t); len(deliveries) != 3 || deliveries[0].Error != "HTTP 500" {
+		t.Fatalf("deliveries %+v", deliveries)
+	}
+}
+
+func TestDispatcherUnreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	target := srv.URL
+	srv.Close()
+	f := newFixture(t, target)
+
+	f.run(t, 0)
+	deliveries := f.deliveries(t)
+	if len(deliveries) != 1 || deliveries[0].StatusCode != 0 || deliveries[0].Error == "" {
+		t.Fatalf("deliveries %+v", deliveries)
+	}
+}
+
+func TestDispatcherDropsInactiveWebhooks(t *testing.T) {
+	recv := &receiver{}
+	srv := httptest.NewServer(recv)
+	defer srv.Close()
+	f := newFixture(t, srv.URL)
+
+	f.webhook.Active = false
+	if err := f.d.Webhooks.Update(f.webhook); err != nil {
+		t.Fatal(err)
+	}
+	f.run(t, 0)
+	if n := f.run(t, time.Hour); n != 0 || len(recv.bodies) != 0 {
+		t.Fatalf("sent %d, claimed %d after the webhook was paused", len(recv.bodies), n)
+	}
+}
+
+func TestSignature(t *testing.T) {
+	now := time.Unix(1_800_000_000, 0)
+	body := []byte(`{"a":1}`)
+	header := Sign("secret", now, body)
+
+	if err := Verify("secret", header, body, now.Add(time.Minute), 5*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	bad := []struct {
+		name   string
+		secret string
+		header string
+		body   string
+		now    time.Time
+	}{
+		{"wrong secret", "other", header, `{"a":1}`, now},
+		{"changed body", "secret", header, `{"a":2}`, now},
+		{"too old", "secret", header, `{"a":1}`, now.Add(10 * time.Minute)},
+		{"no signature", "secret", "t=1800000000", `{"a":1}`, now},
+		{"garbage", "secret", "nonsense", `{"a":1}`, now},
+	}
+	for _, tc := range bad {
+		if err := Verify(tc.secret, tc.header, []byte(tc.body), tc.now, 5*time.Minute); err == nil {
+			t.Errorf("%s: verified", tc.name)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const migrationTemplate = `package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func %[1]s() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "%[2]s",
+		Migrate: func(tx *gorm.DB) error {
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return nil
+		},
+	}
+}
+`
+
+var nonWord = regexp.MustCompile(`[^a-z0-9]+`)
+
+func runCreate(args []string) {
+	fs := flag.NewFlagSet("create", flag.ExitOnError)
+	dir := fs.String("dir", "migrations", "directory of the migrations package")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: migrate_runner create [--dir <dir>] <name>")
+		os.Exit(2)
+	}
+
+	path, err := create(*dir, fs.Arg(0), time.Now())
+	if err != nil {
+		log.Fatalf("create failed: %v", err)
+	}
+	fmt.Printf("✅ Created %s and added it to migrations.All\n", path)
+}
+
+// create writes <dir>/<timestamp>_<name>.go with an empty migration and
+// appends it to the list in <dir>/migrate.go.
+func create(dir string, name string, now time.Time) (string, error) {
+	slug := strings.Trim(nonWord.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if slug == "" {
+		return "", errors.New("the name needs at least one letter or digit")
+	}
+	id := now.Format("200601021504") + "_" + slug
+	funcName := camelCase(slug) + "Migrate"
+
+	path := filepath.Join(dir, id+".go")
+	if _, err := os.Stat(path); err == nil {
+		return "", fmt.Errorf("%s already exists", path)
+	}
+
+	src, err := format.Source([]byte(fmt.Sprintf(migrationTemplate, funcName, id)))
+	if err != nil {
+		return "", err
+	}
+	if err := register(filepath.Join(dir, "migrate.go"), funcName); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, src, 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// register adds funcName() as the last entry of the list returned by All.
+func register(path string, funcName string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	src := string(b)
+	if strings.Contains(src, funcName+"()") {
+		return fmt.Errorf("%s already lists %s", path, funcName)
+	}
+
+	start := strings.Index(src, "func All()")
+	if start < 0 {
+		return fmt.Errorf("no func All in %s", path)
+	}
+	end := strings.Index(src[start:], "\n\t}\n}")
+	if end < 0 {
+		return fmt.Errorf("cannot find the end of the list in func All of %s", path)
+	}
+	end += start + 1
+
+	out, err := format.Source([]byte(src[:end] + "\t\t" + funcName + "(),\n" + src[end:]))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, out, 0o644)
+}
+
+// camelCase turns add_purchase_notes into AddPurchaseNotes.
+func camelCase(slug string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(slug, "_") {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	name := b.String()
+	if name[0] >= '0' && name[0] <= '9' {
+		name = "Migration" + name
+	}
+	return name
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testMigrateGo = `package migrations
+
+import "github.com/go-gormigrate/gormigrate/v2"
+
+func All() []*gormigrate.Migration {
+	return []*gormigrate.Migration{
+		CreateCatMigrate(),
+	}
+}
+`
+
+func TestCreateRegisters(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "migrate.go"), []byte(testMigrateGo), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 10, 19, 15, 4, 0, 0, time.UTC)
+
+	path, err := create(dir, "Add purchase notes!", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != filepath.Join(dir, "202610191504_add_purchase_notes.go") {
+		t.Fatalf("path %s", path)
+	}
+	src, _ := os.ReadFile(path)
+	if !strings.Contains(string(src), "func AddPurchaseNotesMigrate() *gormigrate.Migration") ||
+		!strings.Contains(string(src), `ID: "202610191504_add_purchase_notes"`) {
+		t.Errorf("migration:\n%s", src)
+	}
+
+	// a name starting with a digit still makes a valid identifier
+	if _, err := create(dir, "2fa codes", now); err != nil {
+		t.Fatal(err)
+	}
+	list, _ := os.ReadFile(filepath.Join(dir, "migrate.go"))
+	if !strings.Contains(string(list), "\t\tCreateCatMigrate(),\n\t\tAddPurchaseNotesMigrate(),\n\t\tMigration2faCodesMigrate(),\n\t}\n}") {
+		t.Errorf("migrate.go:\n%s", list)
+	}
+
+	if _, err := create(dir, "add purchase notes", now); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("same id twice: %v", err)
+	}
+	if _, err := create(dir, "add purchase notes", now.Add(time.Minute)); err == nil || !strings.Contains(err.Error(), "already lists") {
+		t.Errorf("same function twice: %v", err)
+	}
+	if _, err := create(dir, "!!", now); err == nil {
+		t.Error("a name without letters or digits was accepted")
+	}
+	// nothing is written when the registration fails
+	if _, err := os.Stat(filepath.Join(dir, "202610191505_add_purchase_notes.go")); !os.IsNotExist(err) {
+		t.Errorf("stray migration file: %v", err)
+	}
+}
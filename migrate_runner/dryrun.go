@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var errDryRun = errors.New("dry run")
+
+// printSQL runs the plan in a transaction that is always rolled back and
+// prints every statement it sends, the checks of the migrations included.
+// Postgres rolls back DDL too, so nothing is left behind except used
+// sequence values. The migrations print their progress with fmt.Println;
+// that goes to stderr so that stdout holds only the SQL.
+func printSQL(db *gorm.DB, plan []step) error {
+	out := os.Stdout
+	os.Stdout = os.Stderr
+	defer func() { os.Stdout = out }()
+
+	err := db.Session(&gorm.Session{Logger: sqlPrinter{out: out}}).Transaction(func(tx *gorm.DB) error {
+		for _, s := range plan {
+			var err error
+			if s.down {
+				fmt.Fprintf(out, "-- down %s\n", s.migration.ID)
+				if s.migration.Rollback == nil {
+					return fmt.Errorf("%s: %w", s.migration.ID, gormigrate.ErrRollbackImpossible)
+				}
+				err = s.migration.Rollback(tx)
+			} else {
+				fmt.Fprintf(out, "-- up %s\n", s.migration.ID)
+				err = s.migration.Migrate(tx)
+			}
+			if err != nil {
+				return fmt.Errorf("%s: %w", s.migration.ID, err)
+			}
+		}
+		return errDryRun
+	})
+	if errors.Is(err, errDryRun) {
+		fmt.Fprintln(out, "-- dry run: rolled back, nothing was changed")
+		return nil
+	}
+	return err
+}
+
+// sqlPrinter is a gorm logger that prints each statement to out as plain
+// SQL.
+type sqlPrinter struct {
+	out io.Writer
+}
+
+func (p sqlPrinter) LogMode(logger.LogLevel) logger.Interface { return p }
+
+func (sqlPrinter) Info(context.Context, string, ...interface{}) {}
+
+func (sqlPrinter) Warn(context.Context, string, ...interface{}) {}
+
+func (sqlPrinter) Error(_ context.Context, msg string, data ...interface{}) {
+	log.Printf(msg, data...)
+}
+
+func (p sqlPrinter) Trace(_ context.Context, _ time.Time, fc func() (string, int64), _ error) {
+	sql, _ := fc()
+	fmt.Fprintln(p.out, sql+";")
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// recordingPool stands in for the database: it records the statements it
+// is sent and how the transaction ends. The test migrations only use Exec.
+type recordingPool struct {
+	execs      []string
+	committed  bool
+	rolledBack bool
+}
+
+func (p *recordingPool) PrepareContext(context.Context, string) (*sql.Stmt, error) {
+	return nil, errors.New("prepare is not supported")
+}
+
+func (p *recordingPool) ExecContext(_ context.Context, query string, _ ...interface{}) (sql.Result, error) {
+	p.execs = append(p.execs, query)
+	return driver.RowsAffected(0), nil
+}
+
+func (p *recordingPool) QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error) {
+	return nil, errors.New("query is not supported")
+}
+
+func (p *recordingPool) QueryRowContext(context.Context, string, ...interface{}) *sql.Row {
+	return nil
+}
+
+func (p *recordingPool) BeginTx(context.Context, *sql.TxOptions) (gorm.ConnPool, error) {
+	return &recordingTx{p}, nil
+}
+
+type recordingTx struct {
+	*recordingPool
+}
+
+func (tx *recordingTx) Commit() error {
+	tx.committed = true
+	return nil
+}
+
+func (tx *recordingTx) Rollback() error {
+	tx.rolledBack = true
+	return nil
+}
+
+func openRecording(t *testing.T) (*gorm.DB, *recordingPool) {
+	t.Helper()
+	pool := &recordingPool{}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: pool}), &gorm.Config{DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, pool
+}
+
+// captureOutput runs f and returns what it wrote to stdout and to stderr.
+func captureOutput(t *testing.T, f func()) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	stdout, err := os.Create(dir + "/stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr, err := os.Create(dir + "/stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	savedOut, savedErr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = stdout, stderr
+	defer func() { os.Stdout, os.Stderr = savedOut, savedErr }()
+
+	f()
+
+	stdout.Close()
+	stderr.Close()
+	out, _ := os.ReadFile(stdout.Name())
+	errOut, _ := os.ReadFile(stderr.Name())
+	return string(out), string(errOut)
+}
+
+func thingsMigration() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "202610191500_create_things",
+		Migrate: func(tx *gorm.DB) error {
+			fmt.Println("Creating things...")
+			return tx.Exec("CREATE TABLE things (id bigint)").Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			fmt.Println("Dropping things...")
+			return tx.Exec("DROP TABLE things").Error
+		},
+	}
+}
+
+func TestPrintSQLWritesOnlySQLToStdout(t *testing.T) {
+	db, pool := openRecording(t)
+	things := thingsMigration()
+
+	var err error
+	stdout, stderr := captureOutput(t, func() {
+		err = printSQL(db, []step{{migration: things, down: true}, {migration: things}})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "-- down 202610191500_create_things\n" +
+		"DROP TABLE things;\n" +
+		"-- up 202610191500_create_things\n" +
+		"CREATE TABLE things (id bigint);\n" +
+		"-- dry run: rolled back, nothing was changed\n"
+	if stdout != want {
+		t.Errorf("stdout:\n%s\nwant:\n%s", stdout, want)
+	}
+	if stderr != "Dropping things...\nCreating things...\n" {
+		t.Errorf("stderr: %q", stderr)
+	}
+	if len(pool.execs) != 2 || pool.committed || !pool.rolledBack {
+		t.Errorf("execs %q, committed %v, rolled back %v", pool.execs, pool.committed, pool.rolledBack)
+	}
+}
+
+func TestPrintSQLFails(t *testing.T) {
+	db, pool := openRecording(t)
+	broken := &gormigrate.Migration{
+		ID:      "202610191600_broken",
+		Migrate: func(*gorm.DB) error { return errors.New("boom") },
+	}
+
+	var err error
+	stdout, _ := captureOutput(t, func() {
+		err = printSQL(db, []step{{migration: thingsMigration()}, {migration: broken, down: true}})
+	})
+	if !errors.Is(err, gormigrate.ErrRollbackImpossible) || !strings.Contains(err.Error(), "202610191600_broken") {
+		t.Errorf("err = %v", err)
+	}
+	if strings.Contains(stdout, "-- dry run") || pool.committed || !pool.rolledBack {
+		t.Errorf("stdout %q, committed %v, rolled back %v", stdout, pool.committed, pool.rolledBack)
+	}
+
+	_, _ = captureOutput(t, func() {
+		err = printSQL(db, []step{{migration: broken}})
+	})
+	if err == nil || err.Error() != "202610191600_broken: boom" {
+		t.Errorf("err = %v", err)
+	}
+}
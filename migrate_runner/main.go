@@ -1,3 +1,17 @@
+// migrate_runner applies and rolls back the migrations of the migrations
+// package. Without a command it applies every pending migration.
+//
+//	go run ./migrate_runner status
+//	go run ./migrate_runner up [--to <id>] [--dry-run]
+//	go run ./migrate_runner down [--to <id>] [--dry-run]
+//	go run ./migrate_runner redo [--dry-run]
+//	go run ./migrate_runner create [--dir migrations] <name>
+//
+// up --to applies the pending migrations up to and including <id>. down
+// rolls back the last applied migration, or with --to every migration after
+// <id>, keeping <id> itself. redo rolls back the last applied migration and
+// applies it again. --dry-run prints the SQL in a transaction that is rolled
+// back instead of committed.
 package main
 
 import (
@@ -6,30 +20,275 @@ import (
 	"log"
 	"money-tracker/internal/config"
 	"money-tracker/migrations"
-)
+	"os"
+	"sort"
 
-func init() {
-	config.ConnectToDB()
-}
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
 
 func main() {
-	rollback := flag.Bool("rollback", false, "rollback last migration")
+	rollback := flag.Bool("rollback", false, "rollback last migration (same as the down command)")
+	flag.Usage = usage
 	flag.Parse()
 
-	// config.ConnectToDB()
+	cmd, args := "up", flag.Args()
+	if *rollback {
+		cmd = "down"
+	}
+	if len(args) > 0 {
+		cmd, args = args[0], args[1:]
+	}
 
-	m := migrations.RunMigration(config.DB) // return *gormigrate.Gormigrate
+	if cmd == "create" {
+		runCreate(args)
+		return
+	}
 
-	if *rollback {
-		if err := m.RollbackLast(); err != nil {
-			log.Fatalf("Rollback failed: %v", err)
+	switch cmd {
+	case "status", "up", "down", "redo":
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", cmd)
+		usage()
+		os.Exit(2)
+	}
+
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	var to string
+	if cmd == "up" || cmd == "down" {
+		fs.StringVar(&to, "to", "", "id of the migration to stop at")
+	}
+	dryRun := false
+	if cmd != "status" {
+		fs.BoolVar(&dryRun, "dry-run", false, "print the SQL instead of running it")
+	}
+	fs.Parse(args)
+
+	dbConfig, err := config.LoadDB()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db, err := config.OpenDB(dbConfig)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch cmd {
+	case "status":
+		err = status(db)
+	case "up":
+		err = up(db, to, dryRun)
+	case "down":
+		err = down(db, to, dryRun)
+	case "redo":
+		err = redo(db, dryRun)
+	}
+	if err != nil {
+		log.Fatalf("%s failed: %v", cmd, err)
+	}
+}
+
+func usage() {
+	fmt.Fprint(os.Stderr, `usage: migrate_runner [command] [flags]
+
+commands:
+  status                        list applied and pending migrations
+  up [--to <id>] [--dry-run]    apply pending migrations (the default)
+  down [--to <id>] [--dry-run]  roll back the last migration, or those after <id>
+  redo [--dry-run]              roll back the last migration and apply it again
+  create [--dir <dir>] <name>   scaffold a timestamped migration
+
+flags:
+`)
+	flag.PrintDefaults()
+}
+
+func status(db *gorm.DB) error {
+	applied, err := migrations.Applied(db)
+	if err != nil {
+		return err
+	}
+
+	all := migrations.All()
+	known := map[string]bool{}
+	fmt.Printf("%-45s %s\n", "ID", "STATUS")
+	for _, m := range all {
+		known[m.ID] = true
+		state := "pending"
+		if applied[m.ID] {
+			state = "applied"
 		}
-		fmt.Println("✅ Last migration rolled back successfully!")
-		return
+		fmt.Printf("%-45s %s\n", m.ID, state)
+	}
+
+	// ids in the table but not in this build, e.g. from a newer branch
+	var unknown []string
+	for id := range applied {
+		if !known[id] {
+			unknown = append(unknown, id)
+		}
+	}
+	sort.Strings(unknown)
+	for _, id := range unknown {
+		fmt.Printf("%-45s %s\n", id, "applied, unknown to this build")
+	}
+	return nil
+}
+
+func up(db *gorm.DB, to string, dryRun bool) error {
+	applied, err := migrations.Applied(db)
+	if err != nil {
+		return err
+	}
+	plan, err := pendingUpTo(migrations.All(), applied, to)
+	if err != nil {
+		return err
+	}
+	if len(plan) == 0 {
+		fmt.Println("Nothing to apply, the database is up to date.")
+		return nil
+	}
+	if dryRun {
+		return printSQL(db, plan)
+	}
+
+	m := migrations.RunMigration(db)
+	if to == "" {
+		err = m.Migrate()
+	} else {
+		err = m.MigrateTo(to)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ %d migration(s) applied successfully!\n", len(plan))
+	return nil
+}
+
+func down(db *gorm.DB, to string, dryRun bool) error {
+	applied, err := migrations.Applied(db)
+	if err != nil {
+		return err
+	}
+	plan, err := appliedAfter(migrations.All(), applied, to)
+	if err != nil {
+		return err
+	}
+	if len(plan) == 0 {
+		fmt.Println("Nothing to roll back.")
+		return nil
+	}
+	if dryRun {
+		return printSQL(db, plan)
+	}
+
+	m := migrations.RunMigration(db)
+	if to == "" {
+		err = m.RollbackLast()
+	} else {
+		err = m.RollbackTo(to)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ %d migration(s) rolled back successfully!\n", len(plan))
+	return nil
+}
+
+func redo(db *gorm.DB, dryRun bool) error {
+	applied, err := migrations.Applied(db)
+	if err != nil {
+		return err
+	}
+	plan, err := redoPlan(migrations.All(), applied)
+	if err != nil {
+		return err
+	}
+	last := plan[0].migration
+	if dryRun {
+		return printSQL(db, plan)
+	}
+
+	if err := migrations.RunMigration(db).RollbackLast(); err != nil {
+		return err
+	}
+	// MigrateTo would also apply the pending migrations before last
+	only := gormigrate.New(db, gormigrate.DefaultOptions, []*gormigrate.Migration{last})
+	if err := only.Migrate(); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Migration %s redone successfully!\n", last.ID)
+	return nil
+}
+
+// step is one migration to apply, or to roll back when down is set.
+type step struct {
+	migration *gormigrate.Migration
+	down      bool
+}
+
+// pendingUpTo returns the migrations that are not applied, in order, up to
+// and including to; all of them when to is empty.
+func pendingUpTo(all []*gormigrate.Migration, applied map[string]bool, to string) ([]step, error) {
+	if err := checkID(all, to); err != nil {
+		return nil, err
+	}
+	var plan []step
+	for _, m := range all {
+		if !applied[m.ID] {
+			plan = append(plan, step{migration: m})
+		}
+		if m.ID == to {
+			break
+		}
+	}
+	return plan, nil
+}
+
+// appliedAfter returns the applied migrations after to, newest first; only
+// the newest one when to is empty.
+func appliedAfter(all []*gormigrate.Migration, applied map[string]bool, to string) ([]step, error) {
+	if err := checkID(all, to); err != nil {
+		return nil, err
 	}
+	var plan []step
+	for i := len(all) - 1; i >= 0; i-- {
+		m := all[i]
+		if m.ID == to {
+			break
+		}
+		if applied[m.ID] {
+			plan = append(plan, step{migration: m, down: true})
+			if to == "" {
+				break
+			}
+		}
+	}
+	return plan, nil
+}
+
+// redoPlan rolls back the last applied migration and applies it again, and
+// nothing else: the pending migrations before it stay pending.
+func redoPlan(all []*gormigrate.Migration, applied map[string]bool) ([]step, error) {
+	plan, err := appliedAfter(all, applied, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(plan) == 0 {
+		return nil, gormigrate.ErrNoRunMigration
+	}
+	return append(plan, step{migration: plan[0].migration}), nil
+}
 
-	if err := m.Migrate(); err != nil {
-		log.Fatalf("Migration failed: %v", err)
+func checkID(all []*gormigrate.Migration, id string) error {
+	if id == "" {
+		return nil
+	}
+	for _, m := range all {
+		if m.ID == id {
+			return nil
+		}
 	}
-	fmt.Println("✅ All migrations applied successfully!")
+	return fmt.Errorf("%w: %s", gormigrate.ErrMigrationIDDoesNotExist, id)
 }
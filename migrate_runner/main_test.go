@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"money-tracker/migrations"
+	"reflect"
+	"testing"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+)
+
+func testMigrations(ids ...string) []*gormigrate.Migration {
+	all := make([]*gormigrate.Migration, len(ids))
+	for i, id := range ids {
+		all[i] = &gormigrate.Migration{ID: id}
+	}
+	return all
+}
+
+// describe turns a plan into "up a", "down b", ... for comparison.
+func describe(plan []step) []string {
+	var out []string
+	for _, s := range plan {
+		verb := "up "
+		if s.down {
+			verb = "down "
+		}
+		out = append(out, verb+s.migration.ID)
+	}
+	return out
+}
+
+func TestPendingUpTo(t *testing.T) {
+	all := testMigrations("a", "b", "c", "d")
+	applied := map[string]bool{"a": true, "c": true}
+
+	cases := map[string][]string{
+		"":  {"up b", "up d"},
+		"a": nil,
+		"b": {"up b"},
+		"c": {"up b"},
+		"d": {"up b", "up d"},
+	}
+	for to, want := range cases {
+		plan, err := pendingUpTo(all, applied, to)
+		if err != nil {
+			t.Fatalf("pendingUpTo(%q): %v", to, err)
+		}
+		if got := describe(plan); !reflect.DeepEqual(got, want) {
+			t.Errorf("pendingUpTo(%q) = %q, want %q", to, got, want)
+		}
+	}
+
+	if _, err := pendingUpTo(all, applied, "e"); !errors.Is(err, gormigrate.ErrMigrationIDDoesNotExist) {
+		t.Errorf("unknown id: %v", err)
+	}
+}
+
+func TestAppliedAfter(t *testing.T) {
+	all := testMigrations("a", "b", "c", "d")
+	// c is pending, e.g. merged from a branch after d was applied
+	applied := map[string]bool{"a": true, "b": true, "d": true}
+
+	cases := map[string][]string{
+		"":  {"down d"},
+		"a": {"down d", "down b"},
+		"b": {"down d"},
+		"d": nil,
+	}
+	for to, want := range cases {
+		plan, err := appliedAfter(all, applied, to)
+		if err != nil {
+			t.Fatalf("appliedAfter(%q): %v", to, err)
+		}
+		if got := describe(plan); !reflect.DeepEqual(got, want) {
+			t.Errorf("appliedAfter(%q) = %q, want %q", to, got, want)
+		}
+	}
+
+	if plan, _ := appliedAfter(all, map[string]bool{}, ""); len(plan) != 0 {
+		t.Errorf("nothing applied: %q", describe(plan))
+	}
+	if _, err := appliedAfter(all, applied, "e"); !errors.Is(err, gormigrate.ErrMigrationIDDoesNotExist) {
+		t.Errorf("unknown id: %v", err)
+	}
+}
+
+func TestRedoPlan(t *testing.T) {
+	all := testMigrations("a", "b", "c", "d")
+
+	// only the last applied migration, not the pending ones before it
+	plan, err := redoPlan(all, map[string]bool{"a": true, "c": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := describe(plan), []string{"down c", "up c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("redoPlan = %q, want %q", got, want)
+	}
+
+	if _, err := redoPlan(all, map[string]bool{}); !errors.Is(err, gormigrate.ErrNoRunMigration) {
+		t.Errorf("nothing applied: %v", err)
+	}
+}
+
+func TestMigrationsRegistered(t *testing.T) {
+	seen := map[string]bool{}
+	for i, m := range migrations.All() {
+		if m.ID == "" || m.Migrate == nil {
+			t.Errorf("migration %d (%q) has no id or no Migrate", i, m.ID)
+		}
+		if seen[m.ID] {
+			t.Errorf("migration id %q is used twice", m.ID)
+		}
+		seen[m.ID] = true
+	}
+}
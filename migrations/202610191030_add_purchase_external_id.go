@@ -0,0 +1,46 @@
+package migrations
+
+import (
+	"fmt"
+	"money-tracker/internal/repository"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func addPurchaseExternalID(tx *gorm.DB) error {
+	if !tx.Migrator().HasColumn(&repository.Purchase{}, "ExternalID") {
+		fmt.Println("Adding column 'purchase.external_id'...")
+		if err := tx.Migrator().AddColumn(&repository.Purchase{}, "ExternalID"); err != nil {
+			return err
+		}
+		if err := tx.Migrator().CreateIndex(&repository.Purchase{}, "ExternalID"); err != nil {
+			return err
+		}
+		fmt.Println("✅ 'purchase.external_id' column added successfully!")
+	}
+	return nil
+}
+
+func dropPurchaseExternalID(tx *gorm.DB) error {
+	if tx.Migrator().HasColumn(&repository.Purchase{}, "ExternalID") {
+		fmt.Println("Dropping column 'purchase.external_id'...")
+		if err := tx.Migrator().DropColumn(&repository.Purchase{}, "ExternalID"); err != nil {
+			return err
+		}
+		fmt.Println("🗑️  'purchase.external_id' column dropped successfully!")
+	}
+	return nil
+}
+
+func AddPurchaseExternalIDMigrate() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "202610191030_add_purchase_external_id",
+		Migrate: func(tx *gorm.DB) error {
+			return addPurchaseExternalID(tx)
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return dropPurchaseExternalID(tx)
+		},
+	}
+}
@@ -0,0 +1,43 @@
+package migrations
+
+import (
+	"fmt"
+	"money-tracker/internal/repository"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func createIdempotencyKeyTable(tx *gorm.DB) error {
+	if !tx.Migrator().HasTable(&repository.IdempotencyKey{}) {
+		fmt.Println("Creating table 'idempotency_key'...")
+		if err := tx.Migrator().CreateTable(&repository.IdempotencyKey{}); err != nil {
+			return err
+		}
+		fmt.Println("✅ 'idempotency_key' table created successfully!")
+	}
+	return nil
+}
+
+func dropIdempotencyKeyTable(tx *gorm.DB) error {
+	if tx.Migrator().HasTable(&repository.IdempotencyKey{}) {
+		fmt.Println("Dropping table 'idempotency_key'...")
+		if err := tx.Migrator().DropTable(&repository.IdempotencyKey{}); err != nil {
+			return err
+		}
+		fmt.Println("🗑️  'idempotency_key' table dropped successfully!")
+	}
+	return nil
+}
+
+func CreateIdempotencyKeyMigrate() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "202610191130_create_idempotency_key",
+		Migrate: func(tx *gorm.DB) error {
+			return createIdempotencyKeyTable(tx)
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return dropIdempotencyKeyTable(tx)
+		},
+	}
+}
@@ -0,0 +1,49 @@
+package migrations
+
+import (
+	"fmt"
+	"money-tracker/internal/repository"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// ---------- Add Columns ----------
+func addVersionColumns(tx *gorm.DB) error {
+	for _, model := range []interface{}{&repository.Category{}, &repository.Tag{}, &repository.Purchase{}} {
+		if !tx.Migrator().HasColumn(model, "Version") {
+			fmt.Printf("Adding column 'version' to %T...\n", model)
+			if err := tx.Migrator().AddColumn(model, "Version"); err != nil {
+				return err
+			}
+		}
+	}
+	fmt.Println("✅ 'version' columns added successfully!")
+	return nil
+}
+
+// ---------- Drop Columns ----------
+func dropVersionColumns(tx *gorm.DB) error {
+	for _, model := range []interface{}{&repository.Category{}, &repository.Tag{}, &repository.Purchase{}} {
+		if tx.Migrator().HasColumn(model, "Version") {
+			if err := tx.Migrator().DropColumn(model, "Version"); err != nil {
+				return err
+			}
+		}
+	}
+	fmt.Println("🗑️  'version' columns dropped successfully!")
+	return nil
+}
+
+// ---------- Migration Definition ----------
+func AddVersionColumnsMigrate() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "202610191230_add_version_columns",
+		Migrate: func(tx *gorm.DB) error {
+			return addVersionColumns(tx)
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return dropVersionColumns(tx)
+		},
+	}
+}
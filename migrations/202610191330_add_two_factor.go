@@ -0,0 +1,63 @@
+package migrations
+
+import (
+	"fmt"
+	"money-tracker/internal/repository"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+var twoFactorUserColumns = []string{"TOTPSecret", "TOTPEnabled", "TOTPLastStep"}
+
+// ---------- Add Columns and Table ----------
+func addTwoFactor(tx *gorm.DB) error {
+	for _, column := range twoFactorUserColumns {
+		if !tx.Migrator().HasColumn(&repository.User{}, column) {
+			fmt.Printf("Adding column '%s' to 'users'...\n", column)
+			if err := tx.Migrator().AddColumn(&repository.User{}, column); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !tx.Migrator().HasTable(&repository.RecoveryCode{}) {
+		fmt.Println("Creating table 'recovery_codes'...")
+		if err := tx.Migrator().CreateTable(&repository.RecoveryCode{}); err != nil {
+			return err
+		}
+	}
+	fmt.Println("✅ two-factor columns and 'recovery_codes' table added successfully!")
+	return nil
+}
+
+// ---------- Drop Columns and Table ----------
+func dropTwoFactor(tx *gorm.DB) error {
+	if tx.Migrator().HasTable(&repository.RecoveryCode{}) {
+		if err := tx.Migrator().DropTable(&repository.RecoveryCode{}); err != nil {
+			return err
+		}
+	}
+	for _, column := range twoFactorUserColumns {
+		if tx.Migrator().HasColumn(&repository.User{}, column) {
+			if err := tx.Migrator().DropColumn(&repository.User{}, column); err != nil {
+				return err
+			}
+		}
+	}
+	fmt.Println("🗑️  two-factor columns and 'recovery_codes' table dropped successfully!")
+	return nil
+}
+
+// ---------- Migration Definition ----------
+func AddTwoFactorMigrate() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "202610191330_add_two_factor",
+		Migrate: func(tx *gorm.DB) error {
+			return addTwoFactor(tx)
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return dropTwoFactor(tx)
+		},
+	}
+}
@@ -0,0 +1,47 @@
+package migrations
+
+import (
+	"fmt"
+	"money-tracker/internal/repository"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// ---------- Create Table ----------
+func createPasswordResetTokens(tx *gorm.DB) error {
+	if !tx.Migrator().HasTable(&repository.PasswordResetToken{}) {
+		fmt.Println("Creating table 'password_reset_tokens'...")
+		if err := tx.Migrator().CreateTable(&repository.PasswordResetToken{}); err != nil {
+			return err
+		}
+		fmt.Println("✅ Table 'password_reset_tokens' created successfully!")
+	} else {
+		fmt.Println("ℹ️  Table 'password_reset_tokens' already exists, skipping creation.")
+	}
+	return nil
+}
+
+// ---------- Drop Table ----------
+func dropPasswordResetTokens(tx *gorm.DB) error {
+	if tx.Migrator().HasTable(&repository.PasswordResetToken{}) {
+		if err := tx.Migrator().DropTable(&repository.PasswordResetToken{}); err != nil {
+			return err
+		}
+		fmt.Println("🗑️  Table 'password_reset_tokens' dropped successfully!")
+	}
+	return nil
+}
+
+// ---------- Migration Definition ----------
+func CreatePasswordResetTokenMigrate() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "202610191500_create_password_reset_tokens",
+		Migrate: func(tx *gorm.DB) error {
+			return createPasswordResetTokens(tx)
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return dropPasswordResetTokens(tx)
+		},
+	}
+}
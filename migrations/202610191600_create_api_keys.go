@@ -0,0 +1,47 @@
+package migrations
+
+import (
+	"fmt"
+	"money-tracker/internal/repository"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// ---------- Create Table ----------
+func createAPIKeys(tx *gorm.DB) error {
+	if !tx.Migrator().HasTable(&repository.APIKey{}) {
+		fmt.Println("Creating table 'api_keys'...")
+		if err := tx.Migrator().CreateTable(&repository.APIKey{}); err != nil {
+			return err
+		}
+		fmt.Println("✅ Table 'api_keys' created successfully!")
+	} else {
+		fmt.Println("ℹ️  Table 'api_keys' already exists, skipping creation.")
+	}
+	return nil
+}
+
+// ---------- Drop Table ----------
+func dropAPIKeys(tx *gorm.DB) error {
+	if tx.Migrator().HasTable(&repository.APIKey{}) {
+		if err := tx.Migrator().DropTable(&repository.APIKey{}); err != nil {
+			return err
+		}
+		fmt.Println("🗑️  Table 'api_keys' dropped successfully!")
+	}
+	return nil
+}
+
+// ---------- Migration Definition ----------
+func CreateAPIKeyMigrate() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "202610191600_create_api_keys",
+		Migrate: func(tx *gorm.DB) error {
+			return createAPIKeys(tx)
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return dropAPIKeys(tx)
+		},
+	}
+}
@@ -0,0 +1,63 @@
+package migrations
+
+import (
+	"fmt"
+	"money-tracker/internal/repository"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// webhookTables are created in this order and dropped in reverse.
+var webhookTables = []struct {
+	name  string
+	model interface{}
+}{
+	{"webhooks", &repository.Webhook{}},
+	{"outbox_messages", &repository.OutboxMessage{}},
+	{"webhook_deliveries", &repository.WebhookDelivery{}},
+}
+
+// ---------- Create Tables ----------
+func createWebhooks(tx *gorm.DB) error {
+	for _, table := range webhookTables {
+		if tx.Migrator().HasTable(table.model) {
+			fmt.Printf("ℹ️  Table '%s' already exists, skipping creation.\n", table.name)
+			continue
+		}
+		fmt.Printf("Creating table '%s'...\n", table.name)
+		if err := tx.Migrator().CreateTable(table.model); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Table '%s' created successfully!\n", table.name)
+	}
+	return nil
+}
+
+// ---------- Drop Tables ----------
+func dropWebhooks(tx *gorm.DB) error {
+	for i := len(webhookTables) - 1; i >= 0; i-- {
+		table := webhookTables[i]
+		if !tx.Migrator().HasTable(table.model) {
+			continue
+		}
+		if err := tx.Migrator().DropTable(table.model); err != nil {
+			return err
+		}
+		fmt.Printf("🗑️  Table '%s' dropped successfully!\n", table.name)
+	}
+	return nil
+}
+
+// ---------- Migration Definition ----------
+func CreateWebhookMigrate() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "202610191700_create_webhooks",
+		Migrate: func(tx *gorm.DB) error {
+			return createWebhooks(tx)
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return dropWebhooks(tx)
+		},
+	}
+}
@@ -0,0 +1,62 @@
+package migrations
+
+import (
+	"fmt"
+	"money-tracker/internal/repository"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// goalTables are created in this order and dropped in reverse.
+var goalTables = []struct {
+	name  string
+	model interface{}
+}{
+	{"goals", &repository.Goal{}},
+	{"goal_contributions", &repository.GoalContribution{}},
+}
+
+// ---------- Create Tables ----------
+func createGoals(tx *gorm.DB) error {
+	for _, table := range goalTables {
+		if tx.Migrator().HasTable(table.model) {
+			fmt.Printf("ℹ️  Table '%s' already exists, skipping creation.\n", table.name)
+			continue
+		}
+		fmt.Printf("Creating table '%s'...\n", table.name)
+		if err := tx.Migrator().CreateTable(table.model); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Table '%s' created successfully!\n", table.name)
+	}
+	return nil
+}
+
+// ---------- Drop Tables ----------
+func dropGoals(tx *gorm.DB) error {
+	for i := len(goalTables) - 1; i >= 0; i-- {
+		table := goalTables[i]
+		if !tx.Migrator().HasTable(table.model) {
+			continue
+		}
+		if err := tx.Migrator().DropTable(table.model); err != nil {
+			return err
+		}
+		fmt.Printf("🗑️  Table '%s' dropped successfully!\n", table.name)
+	}
+	return nil
+}
+
+// ---------- Migration Definition ----------
+func CreateGoalMigrate() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "202610191800_create_goals",
+		Migrate: func(tx *gorm.DB) error {
+			return createGoals(tx)
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return dropGoals(tx)
+		},
+	}
+}
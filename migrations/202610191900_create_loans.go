@@ -0,0 +1,62 @@
+package migrations
+
+import (
+	"fmt"
+	"money-tracker/internal/repository"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// loanTables are created in this order and dropped in reverse.
+var loanTables = []struct {
+	name  string
+	model interface{}
+}{
+	{"loans", &repository.Loan{}},
+	{"loan_payments", &repository.LoanPayment{}},
+}
+
+// ---------- Create Tables ----------
+func createLoans(tx *gorm.DB) error {
+	for _, table := range loanTables {
+		if tx.Migrator().HasTable(table.model) {
+			fmt.Printf("ℹ️  Table '%s' already exists, skipping creation.\n", table.name)
+			continue
+		}
+		fmt.Printf("Creating table '%s'...\n", table.name)
+		if err := tx.Migrator().CreateTable(table.model); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Table '%s' created successfully!\n", table.name)
+	}
+	return nil
+}
+
+// ---------- Drop Tables ----------
+func dropLoans(tx *gorm.DB) error {
+	for i := len(loanTables) - 1; i >= 0; i-- {
+		table := loanTables[i]
+		if !tx.Migrator().HasTable(table.model) {
+			continue
+		}
+		if err := tx.Migrator().DropTable(table.model); err != nil {
+			return err
+		}
+		fmt.Printf("🗑️  Table '%s' dropped successfully!\n", table.name)
+	}
+	return nil
+}
+
+// ---------- Migration Definition ----------
+func CreateLoanMigrate() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "202610191900_create_loans",
+		Migrate: func(tx *gorm.DB) error {
+			return createLoans(tx)
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return dropLoans(tx)
+		},
+	}
+}
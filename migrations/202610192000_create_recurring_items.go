@@ -0,0 +1,47 @@
+package migrations
+
+import (
+	"fmt"
+	"money-tracker/internal/repository"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// ---------- Create Table ----------
+func createRecurringItems(tx *gorm.DB) error {
+	if !tx.Migrator().HasTable(&repository.RecurringItem{}) {
+		fmt.Println("Creating table 'recurring_items'...")
+		if err := tx.Migrator().CreateTable(&repository.RecurringItem{}); err != nil {
+			return err
+		}
+		fmt.Println("✅ Table 'recurring_items' created successfully!")
+	} else {
+		fmt.Println("ℹ️  Table 'recurring_items' already exists, skipping creation.")
+	}
+	return nil
+}
+
+// ---------- Drop Table ----------
+func dropRecurringItems(tx *gorm.DB) error {
+	if tx.Migrator().HasTable(&repository.RecurringItem{}) {
+		if err := tx.Migrator().DropTable(&repository.RecurringItem{}); err != nil {
+			return err
+		}
+		fmt.Println("🗑️  Table 'recurring_items' dropped successfully!")
+	}
+	return nil
+}
+
+// ---------- Migration Definition ----------
+func CreateRecurringItemMigrate() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "202610192000_create_recurring_items",
+		Migrate: func(tx *gorm.DB) error {
+			return createRecurringItems(tx)
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return dropRecurringItems(tx)
+		},
+	}
+}
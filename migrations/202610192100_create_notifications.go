@@ -0,0 +1,47 @@
+package migrations
+
+import (
+	"fmt"
+	"money-tracker/internal/repository"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// ---------- Create Table ----------
+func createNotifications(tx *gorm.DB) error {
+	if !tx.Migrator().HasTable(&repository.Notification{}) {
+		fmt.Println("Creating table 'notifications'...")
+		if err := tx.Migrator().CreateTable(&repository.Notification{}); err != nil {
+			return err
+		}
+		fmt.Println("✅ Table 'notifications' created successfully!")
+	} else {
+		fmt.Println("ℹ️  Table 'notifications' already exists, skipping creation.")
+	}
+	return nil
+}
+
+// ---------- Drop Table ----------
+func dropNotifications(tx *gorm.DB) error {
+	if tx.Migrator().HasTable(&repository.Notification{}) {
+		if err := tx.Migrator().DropTable(&repository.Notification{}); err != nil {
+			return err
+		}
+		fmt.Println("🗑️  Table 'notifications' dropped successfully!")
+	}
+	return nil
+}
+
+// ---------- Migration Definition ----------
+func CreateNotificationMigrate() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "202610192100_create_notifications",
+		Migrate: func(tx *gorm.DB) error {
+			return createNotifications(tx)
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return dropNotifications(tx)
+		},
+	}
+}
@@ -0,0 +1,48 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// ---------- Create Indexes ----------
+// The anomaly checks read a category's purchases by date and look up
+// merchants by their reason.
+func addPurchaseAnomalyIndexes(tx *gorm.DB) error {
+	fmt.Println("Adding the purchase anomaly indexes...")
+	if err := tx.Exec("CREATE INDEX IF NOT EXISTS idx_purchases_category_date ON purchases (category_id, date)").Error; err != nil {
+		return err
+	}
+	if err := tx.Exec("CREATE INDEX IF NOT EXISTS idx_purchases_merchant ON purchases (lower(btrim(reason)))").Error; err != nil {
+		return err
+	}
+	fmt.Println("✅ Purchase anomaly indexes added successfully!")
+	return nil
+}
+
+// ---------- Drop Indexes ----------
+func dropPurchaseAnomalyIndexes(tx *gorm.DB) error {
+	if err := tx.Exec("DROP INDEX IF EXISTS idx_purchases_merchant").Error; err != nil {
+		return err
+	}
+	if err := tx.Exec("DROP INDEX IF EXISTS idx_purchases_category_date").Error; err != nil {
+		return err
+	}
+	fmt.Println("🗑️  Purchase anomaly indexes dropped successfully!")
+	return nil
+}
+
+// ---------- Migration Definition ----------
+func AddPurchaseAnomalyIndexesMigrate() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "202610192200_add_purchase_anomaly_indexes",
+		Migrate: func(tx *gorm.DB) error {
+			return addPurchaseAnomalyIndexes(tx)
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return dropPurchaseAnomalyIndexes(tx)
+		},
+	}
+}
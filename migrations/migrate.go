@@ -7,13 +7,49 @@ import (
 
 func RunMigration(db *gorm.DB) *gormigrate.Gormigrate {
 
-	return gormigrate.New(db, gormigrate.DefaultOptions, []*gormigrate.Migration{
+	return gormigrate.New(db, gormigrate.DefaultOptions, All())
 
+}
+
+// All returns every migration in the order they run. `migrate_runner create`
+// appends the ones it scaffolds at the end of the list.
+func All() []*gormigrate.Migration {
+	return []*gormigrate.Migration{
 		CreateCatMigrate(),
 		CreateUserMigrate(),
 		CreateUserTokenMigrate(),
 		CreateTagMigrate(),
 		CreatePurchaseMigrate(),
-	})
+		AddPurchaseExternalIDMigrate(),
+		CreateIdempotencyKeyMigrate(),
+		AddVersionColumnsMigrate(),
+		AddTwoFactorMigrate(),
+		CreatePasswordResetTokenMigrate(),
+		CreateAPIKeyMigrate(),
+		CreateWebhookMigrate(),
+		CreateGoalMigrate(),
+		CreateLoanMigrate(),
+		CreateRecurringItemMigrate(),
+		CreateNotificationMigrate(),
+		AddPurchaseAnomalyIndexesMigrate(),
+	}
+}
+
+// Applied returns the ids recorded in gormigrate's table, which is empty
+// before the first migration.
+func Applied(db *gorm.DB) (map[string]bool, error) {
+	opts := gormigrate.DefaultOptions
+	applied := map[string]bool{}
+	if !db.Migrator().HasTable(opts.TableName) {
+		return applied, nil
+	}
 
+	var ids []string
+	if err := db.Table(opts.TableName).Pluck(opts.IDColumnName, &ids).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		applied[id] = true
+	}
+	return applied, nil
 }